@@ -3,13 +3,17 @@ package main
 import (
     "log"
     "fmt"
+    "time"
     
     "github.com/gin-gonic/gin"
     
-    "pganalytics-v2/internal/config"
-    "pganalytics-v2/internal/database"
-    "pganalytics-v2/internal/handlers"
-    "pganalytics-v2/internal/middleware"
+    "pganalytics-backend/internal/config"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/mailer"
+    "pganalytics-backend/internal/middleware"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
 )
 
 func main() {
@@ -34,13 +38,104 @@ func main() {
     }
     defer db.Close()
     
+    // Initialize repositories
+    analyticsRepo := repositories.NewAnalyticsRepository(db)
+    configRepo := repositories.NewConfigRepository(db)
+    snapshotRepo := repositories.NewSnapshotRepository(db)
+    historyRepo := repositories.NewHistoryRepository(db)
+    targetRepo := repositories.NewTargetRepository(db)
+    userRepo := repositories.NewUserRepository(db)
+    refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+    auditRepo := repositories.NewAuditRepository(db)
+    alertRepo := repositories.NewAlertRepository(db)
+    alertRuleRepo := repositories.NewAlertRuleRepository(db)
+    notificationRepo := repositories.NewNotificationChannelRepository(db)
+    planRepo := repositories.NewPlanRepository(db)
+    passwordResetRepo := repositories.NewPasswordResetRepository(db)
+    
+    auditService := services.NewAuditService(auditRepo)
+    
+    // Credential references may only read allow-listed env vars and files
+    database.ConfigureCredentials(cfg.Secrets.EnvPrefix, cfg.Secrets.Dir)
+    
+    // Monitored targets share the snapshot settings of the local database
+    snapshotConfig := services.DefaultSnapshotConfig(configRepo)
+    targetPool := database.NewTargetPool()
+    defer targetPool.CloseAll()
+    targetService := services.NewTargetService(targetRepo, targetPool, snapshotRepo, auditService, snapshotConfig)
+    
+    // Start background snapshot writers
+    snapshots := services.NewSnapshotService(analyticsRepo, snapshotRepo, snapshotConfig)
+    snapshots.Start()
+    defer snapshots.Stop()
+    targetService.StartCollectors()
+    defer targetService.StopCollectors()
+    
+    mail, err := mailer.New(mailer.Config{
+        Driver:       cfg.Mail.Driver,
+        From:         cfg.Mail.From,
+        SMTPHost:     cfg.Mail.SMTPHost,
+        SMTPPort:     cfg.Mail.SMTPPort,
+        SMTPUsername: cfg.Mail.SMTPUsername,
+        SMTPPassword: cfg.Mail.SMTPPassword,
+        FilePath:     cfg.Mail.FilePath,
+    })
+    if err != nil {
+        log.Fatalf("Failed to configure mailer: %v", err)
+    }
+    
+    // Alert events are delivered to the notification channels
+    notificationService := services.NewNotificationService(notificationRepo, mail, auditService)
+    notificationService.Start()
+    defer notificationService.Stop()
+    alertService := services.NewAlertService(alertRepo)
+    alertService.SetNotifications(notificationService)
+    
+    // Query regression detector records its findings as alert events
+    regressionService := services.NewQueryRegressionService(historyRepo, alertService, targetService, services.DefaultRegressionOptions(configRepo), snapshotConfig.Interval)
+    regressionService.Start()
+    defer regressionService.Stop()
+    
+    // Alert rules are evaluated over the metrics recorded by the snapshot writers
+    alertRuleService := services.NewAlertRuleService(alertRuleRepo, historyRepo, alertService, targetService, auditService, snapshotConfig.Interval)
+    alertRuleService.Start()
+    defer alertRuleService.Stop()
+    
+    accessTTL := time.Duration(cfg.Auth.AccessTokenMinutes) * time.Minute
+    authService := services.NewAuthService(userRepo, refreshTokenRepo, auditService, configRepo, cfg.Auth.JWTSecret, accessTTL)
+    passwordService := services.NewPasswordService(userRepo, passwordResetRepo, refreshTokenRepo, auditService, configRepo, mail, cfg.Auth.PasswordResetURL)
+    defer passwordService.Stop()
+    
+    userService := services.NewUserService(userRepo, refreshTokenRepo, passwordService, auditService)
+    
+    analyticsService := services.NewAnalyticsService(analyticsRepo, targetService)
+    analyticsService.SetPlanStore(planRepo)
+    analyticsService.SetLocalCollector(snapshots)
+    if cfg.Server.DemoMode {
+        log.Println("Demo mode enabled: local database analytics will return sample data")
+        analyticsService.SetDemoMode(true)
+    }
+    
     // Initialize handlers
-    authHandler := handlers.NewAuthHandler(db, cfg.Auth.JWTSecret)
-    healthHandler := handlers.NewHealthHandler(db)
-    metricsHandler := handlers.NewMetricsHandler(db)
+    h := &appHandlers{
+        auth:          handlers.NewAuthHandler(authService),
+        password:      handlers.NewPasswordHandler(passwordService),
+        users:         handlers.NewUserHandler(userService),
+        health:        handlers.NewHealthHandler(db),
+        metrics:       handlers.NewMetricsHandler(db),
+        analytics:     handlers.NewAnalyticsHandler(analyticsService),
+        history:       handlers.NewHistoryHandler(services.NewHistoryService(historyRepo)),
+        regressions:   handlers.NewRegressionHandler(regressionService),
+        alerts:        handlers.NewAlertHandler(alertService, alertRuleService),
+        notifications: handlers.NewNotificationHandler(notificationService),
+        targets:       handlers.NewTargetHandler(targetService),
+        config:        handlers.NewConfigHandler(services.NewConfigService(configRepo, auditService)),
+        audit:         handlers.NewAuditHandler(auditService),
+    }
     
     // Setup router
-    router := setupRouter(authHandler, healthHandler, metricsHandler, cfg.Auth.JWTSecret)
+    sessionUsers := middleware.NewUserCache(userRepo, middleware.UserCacheTTL)
+    router := setupRouter(h, cfg.Auth.JWTSecret, sessionUsers, cfg.Server.TrustedProxies, auditService)
     
     // Start server
     port := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -50,13 +145,37 @@ func main() {
     }
 }
 
-func setupRouter(auth *handlers.AuthHandler, health *handlers.HealthHandler, metrics *handlers.MetricsHandler, jwtSecret string) *gin.Engine {
+// appHandlers agrupa os handlers registrados no router
+type appHandlers struct {
+    auth          *handlers.AuthHandler
+    password      *handlers.PasswordHandler
+    users         *handlers.UserHandler
+    health        *handlers.HealthHandler
+    metrics       *handlers.MetricsHandler
+    analytics     *handlers.AnalyticsHandler
+    history       *handlers.HistoryHandler
+    regressions   *handlers.RegressionHandler
+    alerts        *handlers.AlertHandler
+    notifications *handlers.NotificationHandler
+    targets       *handlers.TargetHandler
+    config        *handlers.ConfigHandler
+    audit         *handlers.AuditHandler
+}
+
+func setupRouter(h *appHandlers, jwtSecret string, sessionUsers middleware.UserFinder, trustedProxies []string, auditor middleware.AuditRecorder) *gin.Engine {
     router := gin.Default()
+    authenticate := middleware.AuthMiddleware(jwtSecret, sessionUsers)
+    
+    // Sem proxies confiáveis, ClientIP usa o endereço da conexão e ignora X-Forwarded-For,
+    // que de outro modo permitiria contornar o bloqueio de login por IP
+    if err := router.SetTrustedProxies(trustedProxies); err != nil {
+        log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+    }
     
     // CORS middleware
     router.Use(func(c *gin.Context) {
         c.Header("Access-Control-Allow-Origin", "*")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
         c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
         
         if c.Request.Method == "OPTIONS" {
@@ -67,14 +186,117 @@ func setupRouter(auth *handlers.AuthHandler, health *handlers.HealthHandler, met
     })
     
     // Public routes
-    router.GET("/health", health.Health)
-    router.POST("/auth/login", auth.Login)
+    router.GET("/health", h.health.Health)
+    router.POST("/auth/login", h.auth.Login)
+    router.POST("/auth/refresh", h.auth.Refresh)
+    router.POST("/auth/logout", h.auth.Logout)
+    router.POST("/auth/password/forgot", h.password.Forgot)
+    router.POST("/auth/password/reset", h.password.Reset)
     
     // Protected routes
     protected := router.Group("/")
-    protected.Use(middleware.AuthMiddleware(jwtSecret))
+    protected.Use(middleware.AuditTrail(auditor), authenticate)
+    {
+        protected.GET("/metrics", h.metrics.Metrics)
+        protected.GET("/auth/me", h.auth.Me)
+    }
+    
+    // Analytics routes
+    analytics := router.Group("/api/v1/analytics")
+    analytics.Use(middleware.AuditTrail(auditor), authenticate, middleware.RequirePermission(middleware.PermissionReadAnalytics))
+    {
+        analytics.GET("/queries/slow", h.analytics.GetSlowQueries)
+        analytics.GET("/queries/statements", h.analytics.GetStatements)
+        analytics.GET("/queries/statements/:queryid", h.analytics.GetStatement)
+        analytics.GET("/queries/regressions", h.regressions.GetRegressions)
+        analytics.POST("/queries/explain", middleware.RequirePermission(middleware.PermissionExplainQueries), h.analytics.Explain)
+        analytics.GET("/queries/plans", h.analytics.GetPlans)
+        analytics.GET("/queries/plans/diff", h.analytics.DiffPlans)
+        analytics.GET("/queries/plans/:id", h.analytics.GetPlan)
+        analytics.GET("/queries/plans/:id/analysis", h.analytics.AnalyzePlan)
+        analytics.GET("/tables/stats", h.analytics.GetTableStats)
+        analytics.GET("/connections", h.analytics.GetConnectionStats)
+        analytics.GET("/database/size", h.analytics.GetDatabaseSize)
+        analytics.GET("/performance", h.analytics.GetPerformanceStats)
+        analytics.GET("/all", h.analytics.GetFullAnalytics)
+        analytics.GET("/locks", h.analytics.GetLocks)
+        analytics.GET("/locks/history", h.history.GetLockSnapshots)
+        analytics.GET("/bloat", h.analytics.GetBloat)
+        analytics.GET("/indexes/advice", h.analytics.GetIndexAdvice)
+        analytics.GET("/vacuum", h.analytics.GetVacuumStatus)
+        analytics.GET("/vacuum/wraparound", h.analytics.GetWraparoundRisk)
+        analytics.GET("/replication", h.analytics.GetReplicationTopology)
+        analytics.GET("/checkpoints", h.analytics.GetCheckpointStats)
+        analytics.GET("/capabilities", h.analytics.GetCapabilities)
+        analytics.GET("/history", h.history.ListMetrics)
+        analytics.GET("/history/:metric", h.history.GetHistory)
+    }
+    
+    // Alert events, rules and notification channels
+    alerts := router.Group("/api/v1/alerts")
+    alerts.Use(middleware.AuditTrail(auditor), authenticate)
+    {
+        read := middleware.RequirePermission(middleware.PermissionReadAnalytics)
+        manage := middleware.RequirePermission(middleware.PermissionManageAlerts)
+        alerts.GET("/events", read, h.alerts.ListEvents)
+        alerts.GET("/metrics", read, h.alerts.ListMetrics)
+        alerts.GET("/rules", read, h.alerts.ListRules)
+        alerts.POST("/rules", manage, h.alerts.CreateRule)
+        alerts.GET("/rules/:id", read, h.alerts.GetRule)
+        alerts.PUT("/rules/:id", manage, h.alerts.UpdateRule)
+        alerts.DELETE("/rules/:id", manage, h.alerts.DeleteRule)
+        alerts.GET("/rules/:id/history", read, h.alerts.RuleHistory)
+        alerts.GET("/channels", manage, h.notifications.List)
+        alerts.POST("/channels", manage, h.notifications.Create)
+        alerts.GET("/channels/:id", manage, h.notifications.Get)
+        alerts.PUT("/channels/:id", manage, h.notifications.Update)
+        alerts.DELETE("/channels/:id", manage, h.notifications.Delete)
+        alerts.POST("/channels/:id/test", manage, h.notifications.Test)
+    }
+    
+    // Monitored target registry
+    targets := router.Group("/api/v1/targets")
+    targets.Use(middleware.AuditTrail(auditor), authenticate)
+    {
+        read := middleware.RequirePermission(middleware.PermissionReadTargets)
+        manage := middleware.RequirePermission(middleware.PermissionManageTargets)
+        targets.GET("", read, h.targets.List)
+        targets.POST("", manage, h.targets.Create)
+        targets.GET("/:id", read, h.targets.Get)
+        targets.PUT("/:id", manage, h.targets.Update)
+        targets.DELETE("/:id", manage, h.targets.Delete)
+        targets.POST("/:id/test", manage, h.targets.Test)
+    }
+    
+    // User administration
+    users := router.Group("/api/v1/users")
+    users.Use(middleware.AuditTrail(auditor), authenticate, middleware.RequirePermission(middleware.PermissionManageUsers))
+    {
+        users.GET("", h.users.List)
+        users.POST("", h.users.Create)
+        users.GET("/:id", h.users.Get)
+        users.PUT("/:id", h.users.Update)
+        users.DELETE("/:id", h.users.Delete)
+        users.PUT("/:id/role", h.users.ChangeRole)
+        users.POST("/:id/deactivate", h.users.Deactivate)
+        users.POST("/:id/activate", h.users.Activate)
+        users.POST("/:id/force-password-reset", h.users.ForceReset)
+        users.POST("/:id/unlock", h.auth.Unlock)
+    }
+    
+    // Runtime configuration (system_config)
+    configs := router.Group("/api/v1/config")
+    configs.Use(middleware.AuditTrail(auditor), authenticate, middleware.RequirePermission(middleware.PermissionManageConfig))
+    {
+        configs.GET("", h.config.List)
+        configs.PUT("/:key", h.config.Update)
+    }
+    
+    // Audit trail
+    audit := router.Group("/api/v1/audit")
+    audit.Use(middleware.AuditTrail(auditor), authenticate, middleware.RequirePermission(middleware.PermissionReadAudit))
     {
-        protected.GET("/metrics", metrics.Metrics)
+        audit.GET("", h.audit.List)
     }
     
     return router
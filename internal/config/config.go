@@ -4,6 +4,7 @@ import (
     "fmt"
     "os"
     "strconv"
+    "strings"
     "log"
 )
 
@@ -11,6 +12,8 @@ type Config struct {
     Database DatabaseConfig
     Server   ServerConfig
     Auth     AuthConfig
+    Mail     MailConfig
+    Secrets  SecretsConfig
 }
 
 type DatabaseConfig struct {
@@ -25,10 +28,30 @@ type ServerConfig struct {
     Port        int
     Environment string
     LogLevel    string
+    DemoMode    bool // Dados fictícios nas estatísticas do banco local (apenas demonstração)
+    // Proxies (IPs ou CIDRs) cujo X-Forwarded-For é aceito como IP do cliente; vazio = nenhum
+    TrustedProxies []string
 }
 
 type AuthConfig struct {
-    JWTSecret string
+    JWTSecret          string
+    AccessTokenMinutes int
+    PasswordResetURL   string
+}
+
+type MailConfig struct {
+    Driver       string
+    From         string
+    SMTPHost     string
+    SMTPPort     int
+    SMTPUsername string
+    SMTPPassword string
+    FilePath     string
+}
+
+type SecretsConfig struct {
+    EnvPrefix string // Prefixo obrigatório das variáveis referenciadas por env:VAR
+    Dir       string // Diretório dos arquivos referenciados por file: (vazio desativa file:)
 }
 
 func Load() (*Config, error) {
@@ -41,12 +64,29 @@ func Load() (*Config, error) {
             Password: getEnv("DB_PASSWORD", ""),
         },
         Server: ServerConfig{
-            Port:        getEnvInt("PORT", 8080),
-            Environment: getEnv("ENVIRONMENT", "development"),
-            LogLevel:    getEnv("LOG_LEVEL", "info"),
+            Port:           getEnvInt("PORT", 8080),
+            Environment:    getEnv("ENVIRONMENT", "development"),
+            LogLevel:       getEnv("LOG_LEVEL", "info"),
+            DemoMode:       getEnvBool("DEMO_MODE", false),
+            TrustedProxies: getEnvList("TRUSTED_PROXIES"),
         },
         Auth: AuthConfig{
-            JWTSecret: getEnv("JWT_SECRET", ""),
+            JWTSecret:          getEnv("JWT_SECRET", ""),
+            AccessTokenMinutes: getEnvInt("JWT_ACCESS_TOKEN_MINUTES", 15),
+            PasswordResetURL:   getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+        },
+        Mail: MailConfig{
+            Driver:       getEnv("MAIL_DRIVER", "file"),
+            From:         getEnv("MAIL_FROM", "pganalytics@localhost"),
+            SMTPHost:     getEnv("SMTP_HOST", ""),
+            SMTPPort:     getEnvInt("SMTP_PORT", 587),
+            SMTPUsername: getEnv("SMTP_USERNAME", ""),
+            SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+            FilePath:     getEnv("MAIL_FILE_PATH", ""),
+        },
+        Secrets: SecretsConfig{
+            EnvPrefix: getEnv("CREDENTIALS_ENV_PREFIX", "PGA_"),
+            Dir:       getEnv("CREDENTIALS_DIR", ""),
         },
     }
     
@@ -69,6 +109,15 @@ func getEnv(key, defaultValue string) string {
     return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+    if value := os.Getenv(key); value != "" {
+        if boolValue, err := strconv.ParseBool(value); err == nil {
+            return boolValue
+        }
+    }
+    return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
     if value := os.Getenv(key); value != "" {
         if intValue, err := strconv.Atoi(value); err == nil {
@@ -77,3 +126,14 @@ func getEnvInt(key string, defaultValue int) int {
     }
     return defaultValue
 }
+
+// getEnvList lê uma lista separada por vírgulas, ignorando itens vazios
+func getEnvList(key string) []string {
+    var values []string
+    for _, item := range strings.Split(os.Getenv(key), ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            values = append(values, item)
+        }
+    }
+    return values
+}
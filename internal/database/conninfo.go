@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// parseConninfo interpreta um DSN key=value com as regras do libpq: espaços opcionais em volta
+// do '=', valores entre aspas simples e '\' escapando o caractere seguinte
+func parseConninfo(dsn string) (map[string]string, error) {
+	settings := map[string]string{}
+	r := []rune(dsn)
+	i := 0
+
+	skipSpaces := func() {
+		for i < len(r) && unicode.IsSpace(r[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpaces()
+		if i >= len(r) {
+			return settings, nil
+		}
+
+		start := i
+		for i < len(r) && r[i] != '=' && !unicode.IsSpace(r[i]) {
+			i++
+		}
+		key := string(r[start:i])
+
+		skipSpaces()
+		if i >= len(r) || r[i] != '=' {
+			return nil, fmt.Errorf("parâmetro '%s' sem '=' no DSN", key)
+		}
+		i++
+		skipSpaces()
+
+		var value strings.Builder
+		if i < len(r) && r[i] == '\'' {
+			i++
+			closed := false
+			for i < len(r) {
+				if r[i] == '\\' && i+1 < len(r) {
+					value.WriteRune(r[i+1])
+					i += 2
+					continue
+				}
+				if r[i] == '\'' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(r[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("aspas não fechadas no valor de '%s' no DSN", key)
+			}
+		} else {
+			for i < len(r) && !unicode.IsSpace(r[i]) {
+				if r[i] == '\\' && i+1 < len(r) {
+					i++
+				}
+				value.WriteRune(r[i])
+				i++
+			}
+		}
+
+		settings[key] = value.String()
+	}
+}
@@ -34,7 +34,6 @@ func NewDB() (*DB, error) {
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
 		log.Printf("⚠️ Erro ao conectar ao PostgreSQL: %v", err)
-		log.Printf("⚠️ Usando dados mock para desenvolvimento")
 		return nil, err
 	}
 
@@ -0,0 +1,291 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// DefaultTargetConnectTimeout limita a abertura da conexão com um target
+const DefaultTargetConnectTimeout = 10 * time.Second
+
+// TargetPool mantém uma conexão por instância monitorada, aberta sob demanda
+type TargetPool struct {
+	ConnectTimeout time.Duration // Tempo máximo para conectar e concluir o handshake
+
+	mu          sync.Mutex
+	conns       map[string]*DB
+	generations map[string]int
+}
+
+// NewTargetPool cria um pool vazio de conexões de targets
+func NewTargetPool() *TargetPool {
+	return &TargetPool{
+		ConnectTimeout: DefaultTargetConnectTimeout,
+		conns:          make(map[string]*DB),
+		generations:    make(map[string]int),
+	}
+}
+
+// Get retorna a conexão do target identificado por key, conectando na primeira chamada.
+// A conexão é aberta fora do lock para que um target inacessível não bloqueie os demais.
+func (p *TargetPool) Get(key, dsn, credentialsRef string) (*DB, error) {
+	p.mu.Lock()
+	if db, ok := p.conns[key]; ok {
+		p.mu.Unlock()
+		return db, nil
+	}
+	generation := p.generations[key]
+	p.mu.Unlock()
+
+	password, err := ResolveCredentials(credentialsRef)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.ConnectTimeout)
+	defer cancel()
+
+	conn, err := sqlx.ConnectContext(ctx, "postgres", WithConnectTimeout(WithPassword(dsn, password), p.ConnectTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar ao target %s: %w", key, err)
+	}
+
+	// Conexões de monitoramento são leves; um pool pequeno por target basta
+	conn.SetMaxOpenConns(5)
+	conn.SetMaxIdleConns(2)
+	conn.SetConnMaxLifetime(5 * time.Minute)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Outra chamada conectou primeiro, ou o target foi removido/alterado durante a conexão
+	if existing, ok := p.conns[key]; ok {
+		conn.Close()
+		return existing, nil
+	}
+	if p.generations[key] != generation {
+		conn.Close()
+		return nil, fmt.Errorf("target %s alterado durante a conexão", key)
+	}
+
+	db := &DB{conn}
+	p.conns[key] = db
+	log.Printf("✅ Conectado ao target %s", key)
+	return db, nil
+}
+
+// Remove fecha e descarta a conexão de um target (ex: após alteração do DSN)
+func (p *TargetPool) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if db, ok := p.conns[key]; ok {
+		db.DB.Close()
+		delete(p.conns, key)
+	}
+	p.generations[key]++
+}
+
+// CloseAll fecha todas as conexões abertas
+func (p *TargetPool) CloseAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, db := range p.conns {
+		db.DB.Close()
+		delete(p.conns, key)
+	}
+}
+
+// DefaultCredentialEnvPrefix é o prefixo exigido das variáveis referenciadas por env:VAR
+const DefaultCredentialEnvPrefix = "PGA_"
+
+// credentialPolicy restringe quais variáveis e arquivos podem ser lidos por ResolveCredentials,
+// impedindo que uma referência cadastrada exponha segredos da própria API (ex: env:JWT_SECRET)
+var credentialPolicy = struct {
+	sync.RWMutex
+	envPrefix  string
+	secretsDir string
+}{envPrefix: DefaultCredentialEnvPrefix}
+
+// ConfigureCredentials define o prefixo aceito em referências env: e o diretório aceito em
+// referências file:. Um prefixo vazio mantém o padrão; um diretório vazio desativa file:.
+func ConfigureCredentials(envPrefix, secretsDir string) {
+	if envPrefix == "" {
+		envPrefix = DefaultCredentialEnvPrefix
+	}
+	if secretsDir != "" {
+		secretsDir = filepath.Clean(secretsDir)
+	}
+
+	credentialPolicy.Lock()
+	defer credentialPolicy.Unlock()
+	credentialPolicy.envPrefix = envPrefix
+	credentialPolicy.secretsDir = secretsDir
+}
+
+// ResolveCredentials lê a senha apontada por uma referência env:VAR ou file:/caminho.
+// Uma referência vazia significa conexão sem senha (trust, .pgpass, certificado).
+// Apenas variáveis com o prefixo configurado e arquivos dentro do diretório de segredos são aceitos.
+func ResolveCredentials(ref string) (string, error) {
+	credentialPolicy.RLock()
+	envPrefix, secretsDir := credentialPolicy.envPrefix, credentialPolicy.secretsDir
+	credentialPolicy.RUnlock()
+
+	switch {
+	case ref == "":
+		return "", nil
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		if !strings.HasPrefix(name, envPrefix) {
+			return "", fmt.Errorf("variável de ambiente %s não permitida: use o prefixo %s", name, envPrefix)
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("variável de ambiente %s não definida", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, "file:"):
+		path, err := secretPath(secretsDir, strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("falha ao ler credencial: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("referência de credencial inválida: %q (use env:VAR ou file:/caminho)", ref)
+	}
+}
+
+// secretPath garante que o arquivo referenciado, após resolver links simbólicos, está dentro de dir
+func secretPath(dir, path string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("referências file: exigem um diretório de segredos configurado")
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("diretório de segredos inválido: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(resolvedDir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("falha ao ler credencial: %w", err)
+	}
+
+	rel, err := filepath.Rel(resolvedDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("arquivo de credencial fora do diretório de segredos %s", dir)
+	}
+	return resolved, nil
+}
+
+// DSNHasPassword indica se o DSN já traz uma senha embutida. URLs são convertidas para
+// key=value pelo driver, o que cobre também ?password= na query string.
+func DSNHasPassword(dsn string) bool {
+	settings, err := dsnSettings(dsn)
+	if err != nil {
+		return false
+	}
+	_, ok := settings["password"]
+	return ok
+}
+
+// ValidateDSN verifica se o DSN pode ser interpretado pelo driver
+func ValidateDSN(dsn string) error {
+	if isURLDSN(dsn) {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return fmt.Errorf("DSN inválido: %w", err)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("DSN inválido: host ausente")
+		}
+	}
+
+	settings, err := dsnSettings(dsn)
+	if err != nil {
+		return fmt.Errorf("DSN inválido: %w", err)
+	}
+	if !isURLDSN(dsn) && settings["host"] == "" {
+		return fmt.Errorf("DSN inválido: informe uma URL postgres:// ou host=... dbname=...")
+	}
+	return nil
+}
+
+// dsnSettings retorna os parâmetros do DSN, em formato URL ou key=value
+func dsnSettings(dsn string) (map[string]string, error) {
+	if isURLDSN(dsn) {
+		conninfo, err := pq.ParseURL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		dsn = conninfo
+	}
+	return parseConninfo(dsn)
+}
+
+// WithPassword injeta a senha no DSN, em formato URL ou key=value
+func WithPassword(dsn, password string) string {
+	if password == "" {
+		return dsn
+	}
+
+	if isURLDSN(dsn) {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		username := ""
+		if u.User != nil {
+			username = u.User.Username()
+		}
+		u.User = url.UserPassword(username, password)
+		return u.String()
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(password)
+	return fmt.Sprintf("%s password='%s'", dsn, escaped)
+}
+
+// WithConnectTimeout acrescenta connect_timeout ao DSN quando ele ainda não define um.
+// O driver aplica o limite também ao handshake, que o contexto da conexão não cobre.
+func WithConnectTimeout(dsn string, timeout time.Duration) string {
+	seconds := int(timeout.Round(time.Second) / time.Second)
+	if seconds <= 0 || strings.Contains(dsn, "connect_timeout") {
+		return dsn
+	}
+
+	if isURLDSN(dsn) {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := u.Query()
+		query.Set("connect_timeout", strconv.Itoa(seconds))
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	return fmt.Sprintf("%s connect_timeout=%d", dsn, seconds)
+}
+
+func isURLDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
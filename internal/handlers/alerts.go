@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
+)
+
+// defaultAlertEvents é a quantidade de eventos retornada quando 'limit' não é informado
+const defaultAlertEvents = 100
+
+// AlertHandler gerencia os endpoints de alertas
+type AlertHandler struct {
+	service *services.AlertService
+	rules   *services.AlertRuleService
+}
+
+// NewAlertHandler cria um novo handler de alertas
+func NewAlertHandler(service *services.AlertService, rules *services.AlertRuleService) *AlertHandler {
+	return &AlertHandler{service: service, rules: rules}
+}
+
+// @Summary      Listar eventos de alerta
+// @Description  Retorna os eventos gravados pelos detectores (ex: regressões de queries), do mais recente para o mais antigo
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from      query  string  false  "Início (RFC3339 ou epoch), padrão: 24h atrás"
+// @Param        to        query  string  false  "Fim (RFC3339 ou epoch), padrão: agora"
+// @Param        target    query  string  false  "Filtrar por target monitorado"
+// @Param        source    query  string  false  "Filtrar por detector (ex: query_regression)"
+// @Param        severity  query  string  false  "Filtrar por severidade (info, warning, critical)"
+// @Param        limit     query  int     false  "Máximo de eventos (padrão 100)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/events [get]
+func (h *AlertHandler) ListEvents(c *gin.Context) {
+	query, err := parseAlertEventQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.ListEvents(query)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Listar métricas de alerta
+// @Description  Retorna as métricas aceitas pelas regras de alerta e se aceitam a condição rate_of_change
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.AlertMetric
+// @Failure      401  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/metrics [get]
+func (h *AlertHandler) ListMetrics(c *gin.Context) {
+	metrics := services.AlertMetrics()
+	c.JSON(http.StatusOK, gin.H{"metrics": metrics, "total": len(metrics)})
+}
+
+// @Summary      Listar regras de alerta
+// @Description  Retorna as regras de alerta com o estado corrente (inactive, pending, firing ou resolved)
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        state  query     string  false  "Filtrar por estado (inactive, pending, firing, resolved)"
+// @Success      200    {array}   models.AlertRuleStatus
+// @Failure      400    {object}  models.ErrorResponse
+// @Failure      401    {object}  models.ErrorResponse
+// @Failure      500    {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules [get]
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	state := c.Query("state")
+	switch state {
+	case "", models.AlertStateInactive, models.AlertStatePending, models.AlertStateFiring, models.AlertStateResolved:
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("estado '%s' inválido", state)})
+		return
+	}
+
+	rules, err := h.rules.List(state)
+	if err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "total": len(rules)})
+}
+
+// @Summary      Obter regra de alerta
+// @Description  Retorna uma regra de alerta pelo ID ou nome, com o estado corrente
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ou nome da regra"
+// @Success      200  {object}  models.AlertRuleStatus
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules/{id} [get]
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	rule, err := h.rules.Get(c.Param("id"))
+	if err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary      Criar regra de alerta
+// @Description  Cadastra uma regra por limite (threshold) ou taxa de variação por minuto (rate_of_change) sobre uma métrica coletada
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        rule  body      models.AlertRuleRequest  true  "Dados da regra"
+// @Success      201   {object}  models.AlertRule
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules [post]
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	rule, err := h.rules.Create(req, middleware.Actor(c))
+	if err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// @Summary      Atualizar regra de alerta
+// @Description  Atualiza uma regra de alerta; o estado corrente é reavaliado no próximo ciclo
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                   true  "ID ou nome da regra"
+// @Param        rule  body      models.AlertRuleRequest  true  "Dados da regra"
+// @Success      200   {object}  models.AlertRule
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules/{id} [put]
+func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	rule, err := h.rules.Update(c.Param("id"), req, middleware.Actor(c))
+	if err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary      Remover regra de alerta
+// @Description  Remove uma regra de alerta; o histórico de eventos é mantido
+// @Tags         Alerts
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID ou nome da regra"
+// @Success      204
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules/{id} [delete]
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	if err := h.rules.Delete(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Histórico da regra de alerta
+// @Description  Retorna as transições da regra para firing e resolved, da mais recente para a mais antiga
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path   string  true   "ID ou nome da regra"
+// @Param        from   query  string  false  "Início (RFC3339 ou epoch), padrão: 24h atrás"
+// @Param        to     query  string  false  "Fim (RFC3339 ou epoch), padrão: agora"
+// @Param        limit  query  int     false  "Máximo de eventos (padrão 100)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/rules/{id}/history [get]
+func (h *AlertHandler) RuleHistory(c *gin.Context) {
+	query, err := parseAlertEventQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.rules.History(c.Param("id"), query)
+	respondAnalytics(c, response, err)
+}
+
+// respondAlertRuleError mapeia erros do serviço de regras de alerta para status HTTP
+func respondAlertRuleError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrAlertRuleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrAlertRuleExists):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidAlertRule):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
+
+// parseAlertEventQuery lê from, to, target, source, severity e limit da requisição
+func parseAlertEventQuery(c *gin.Context) (models.AlertEventQuery, error) {
+	q := models.AlertEventQuery{
+		Target:   c.Query("target"),
+		Source:   c.Query("source"),
+		Severity: c.Query("severity"),
+		To:       time.Now().UTC(),
+		Limit:    defaultAlertEvents,
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'to' inválido: %w", err)
+		}
+		q.To = t
+	}
+
+	q.From = q.To.Add(-24 * time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'from' inválido: %w", err)
+		}
+		q.From = t
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'limit' inválido: %w", err)
+		}
+		q.Limit = limit
+	}
+
+	return q, nil
+}
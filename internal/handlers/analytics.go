@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
 	"pganalytics-backend/internal/services"
 )
 
@@ -24,15 +28,17 @@ func NewAnalyticsHandler(service *services.AnalyticsService) *AnalyticsHandler {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/queries/slow [get]
 func (h *AnalyticsHandler) GetSlowQueries(c *gin.Context) {
-	// Adicionar dados do usuário autenticado
-	response := h.service.GetSlowQueries()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetSlowQueries(c.Query("target"))
+	respondAnalytics(c, response, err)
 }
 
 // @Summary      Obter estatísticas das tabelas
@@ -41,14 +47,17 @@ func (h *AnalyticsHandler) GetSlowQueries(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/tables/stats [get]
 func (h *AnalyticsHandler) GetTableStats(c *gin.Context) {
-	response := h.service.GetTableStats()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetTableStats(c.Query("target"))
+	respondAnalytics(c, response, err)
 }
 
 // @Summary      Obter estatísticas de conexões
@@ -57,14 +66,17 @@ func (h *AnalyticsHandler) GetTableStats(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/connections [get]
 func (h *AnalyticsHandler) GetConnectionStats(c *gin.Context) {
-	response := h.service.GetConnectionStats()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetConnectionStats(c.Query("target"))
+	respondAnalytics(c, response, err)
 }
 
 // @Summary      Obter tamanho do banco
@@ -73,30 +85,36 @@ func (h *AnalyticsHandler) GetConnectionStats(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/database/size [get]
 func (h *AnalyticsHandler) GetDatabaseSize(c *gin.Context) {
-	response := h.service.GetDatabaseSize()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetDatabaseSize(c.Query("target"))
+	respondAnalytics(c, response, err)
 }
 
 // @Summary      Obter estatísticas de performance
-// @Description  Retorna métricas de performance do PostgreSQL
+// @Description  Retorna métricas de performance do PostgreSQL, com as taxas (TPS, commits, leituras) entre as duas últimas coletas do gravador de snapshots (null até a segunda coleta)
 // @Tags         Analytics
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/performance [get]
 func (h *AnalyticsHandler) GetPerformanceStats(c *gin.Context) {
-	response := h.service.GetPerformanceStats()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetPerformanceStats(c.Query("target"))
+	respondAnalytics(c, response, err)
 }
 
 // @Summary      Obter todas as estatísticas
@@ -105,21 +123,487 @@ func (h *AnalyticsHandler) GetPerformanceStats(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
 // @Success      200  {object}  models.AnalyticsResponse
 // @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
 // @Router       /api/v1/analytics/all [get]
 func (h *AnalyticsHandler) GetFullAnalytics(c *gin.Context) {
-	response := h.service.GetFullAnalytics()
-	addUserContext(c, response)
-	c.JSON(http.StatusOK, response)
+	response, err := h.service.GetFullAnalytics(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter árvore de bloqueio
+// @Description  Retorna os bloqueadores raiz e os backends que aguardam lock, com modos, relações, tempos de espera e queries
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/locks [get]
+func (h *AnalyticsHandler) GetLocks(c *gin.Context) {
+	response, err := h.service.GetLocks(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter bloat de tabelas e índices
+// @Description  Estima o espaço desperdiçado por tabela e índice btree (pg_stats/pg_class, ou pgstattuple quando instalada), ordenado por espaço recuperável
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Param        type    query  string  false  "Filtrar por tipo de objeto (table ou index)"
+// @Param        limit   query  int     false  "Máximo de objetos (padrão 50)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/bloat [get]
+func (h *AnalyticsHandler) GetBloat(c *gin.Context) {
+	limit, err := queryInt(c, "limit", services.DefaultBloatLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetBloat(c.Query("target"), c.Query("type"), limit)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter recomendações de índices
+// @Description  Aponta índices sem uso, duplicados, redundantes por prefixo e inválidos, e tabelas grandes dominadas por sequential scans, com o comando sugerido e o impacto estimado em disco
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/indexes/advice [get]
+func (h *AnalyticsHandler) GetIndexAdvice(c *gin.Context) {
+	response, err := h.service.GetIndexAdvice(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter estado de vacuum
+// @Description  Retorna a idade de datfrozenxid dos bancos, tuplas mortas e tabelas atrasadas para autovacuum pelos limites efetivos, e os vacuums em execução
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Param        limit   query  int     false  "Máximo de tabelas (padrão 50)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/vacuum [get]
+func (h *AnalyticsHandler) GetVacuumStatus(c *gin.Context) {
+	limit, err := queryInt(c, "limit", services.DefaultVacuumLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetVacuumStatus(c.Query("target"), limit)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter risco de wraparound
+// @Description  Retorna bancos e tabelas ordenados pela distância até autovacuum_freeze_max_age
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Param        limit   query  int     false  "Máximo de tabelas (padrão 50)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/vacuum/wraparound [get]
+func (h *AnalyticsHandler) GetWraparoundRisk(c *gin.Context) {
+	limit, err := queryInt(c, "limit", services.DefaultVacuumLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetWraparoundRisk(c.Query("target"), limit)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter topologia de replicação
+// @Description  Retorna o papel da instância (primary ou standby), o lag de write/flush/replay de cada standby, o receptor de WAL, os slots com WAL retido e a saúde de cada componente
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/replication [get]
+func (h *AnalyticsHandler) GetReplicationTopology(c *gin.Context) {
+	response, err := h.service.GetReplicationTopology(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter estatísticas de checkpoint, bgwriter e WAL
+// @Description  Retorna os contadores de checkpoints (agendados e solicitados), bgwriter, fsyncs de backends e volume de WAL, com as taxas entre as duas últimas coletas do gravador de snapshots (null até a segunda coleta). A origem dos dados depende da versão do PostgreSQL (pg_stat_bgwriter, pg_stat_checkpointer, pg_stat_wal)
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/checkpoints [get]
+func (h *AnalyticsHandler) GetCheckpointStats(c *gin.Context) {
+	response, err := h.service.GetCheckpointStats(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter capacidades do servidor
+// @Description  Retorna a versão do PostgreSQL, as extensões instaladas e, para cada coletor, se é suportado pelo target e qual variante de SQL é usada
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/capabilities [get]
+func (h *AnalyticsHandler) GetCapabilities(c *gin.Context) {
+	response, err := h.service.GetCapabilities(c.Query("target"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Explorar pg_stat_statements
+// @Description  Lista as entradas de pg_stat_statements com ordenação, filtros por banco, usuário, queryid ou texto e paginação por cursor. Use next_cursor da resposta para obter a próxima página.
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target    query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Param        sort      query  string  false  "Ordenação: total_time, mean_time, calls, rows, shared_blks_read, shared_blks_hit, temp_blks ou wal_bytes"  default(total_time)
+// @Param        order     query  string  false  "asc ou desc"  default(desc)
+// @Param        database  query  string  false  "Filtrar por banco"
+// @Param        user      query  string  false  "Filtrar por usuário"
+// @Param        queryid   query  int     false  "Filtrar por queryid"
+// @Param        search    query  string  false  "Buscar no texto da query"
+// @Param        limit     query  int     false  "Itens por página (máximo 500)"  default(50)
+// @Param        cursor    query  string  false  "Cursor retornado em next_cursor"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/statements [get]
+func (h *AnalyticsHandler) GetStatements(c *gin.Context) {
+	q, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetStatements(c.Query("target"), q, c.Query("cursor"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Detalhar uma query de pg_stat_statements
+// @Description  Retorna o texto normalizado completo e todos os contadores de um queryid, com uma entrada por banco, usuário e nível de execução
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        queryid  path   int     true   "queryid"
+// @Param        target   query  string  false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/statements/{queryid} [get]
+func (h *AnalyticsHandler) GetStatement(c *gin.Context) {
+	queryID, err := strconv.ParseInt(c.Param("queryid"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "queryid inválido"})
+		return
+	}
+
+	response, err := h.service.GetStatement(c.Query("target"), queryID)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Capturar plano de uma query
+// @Description  Executa EXPLAIN (FORMAT JSON) de um queryid de pg_stat_statements ou de um texto no target, em transação somente leitura com statement_timeout, e grava o plano em slow_queries_log. Exige a permissão queries:explain (admin) e aceita no máximo 30 capturas por minuto em cada target. ANALYZE é opcional e só aceito para SELECT; textos com parâmetros ($1, $2...) usam o plano genérico.
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target   query  string                 false  "Target monitorado (nome ou ID), padrão: banco local"
+// @Param        request  body   models.ExplainRequest  true   "Query e opções do EXPLAIN"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      424  {object}  models.ErrorResponse
+// @Failure      429  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/explain [post]
+func (h *AnalyticsHandler) Explain(c *gin.Context) {
+	var req models.ExplainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	response, err := h.service.Explain(c.Query("target"), req)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Listar planos capturados
+// @Description  Retorna os planos gravados de um target, dos mais recentes para os mais antigos
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target      query  string  false  "Target monitorado (nome), padrão: banco local"
+// @Param        query_hash  query  string  false  "Filtrar por md5 do texto"
+// @Param        queryid     query  int     false  "Filtrar por queryid"
+// @Param        limit       query  int     false  "Máximo de planos (padrão 20)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/plans [get]
+func (h *AnalyticsHandler) GetPlans(c *gin.Context) {
+	q := models.QueryPlanQuery{
+		Target:    c.Query("target"),
+		QueryHash: c.Query("query_hash"),
+		Limit:     services.DefaultPlanLimit,
+	}
+	if raw := c.Query("queryid"); raw != "" {
+		queryID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "queryid inválido"})
+			return
+		}
+		q.QueryID = &queryID
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "limit inválido"})
+			return
+		}
+		q.Limit = limit
+	}
+
+	response, err := h.service.GetPlans(q)
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Comparar planos capturados
+// @Description  Compara dois planos gravados da mesma query (mesmo query_hash): tipos de nó trocados, ordem de junção, troca de índice ou seq scan por relação e variação entre linhas estimadas e reais
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        before  query  string  true  "ID do plano antigo"
+// @Param        after   query  string  true  "ID do plano novo"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/plans/diff [get]
+func (h *AnalyticsHandler) DiffPlans(c *gin.Context) {
+	response, err := h.service.DiffPlans(c.Query("before"), c.Query("after"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Obter plano capturado
+// @Description  Retorna um plano gravado em slow_queries_log pelo ID
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "ID do plano"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/plans/{id} [get]
+func (h *AnalyticsHandler) GetPlan(c *gin.Context) {
+	response, err := h.service.GetPlan(c.Param("id"))
+	respondAnalytics(c, response, err)
+}
+
+// @Summary      Analisar plano capturado
+// @Description  Percorre um plano gravado e aponta estimativas erradas (>10x), seq scans grandes, sorts e hashes em disco, nested loops com muitas linhas externas, index scans com filtro pesado e os nós que concentram o tempo. Com format=text retorna a árvore anotada em texto.
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json,plain
+// @Security     BearerAuth
+// @Param        id      path   string  true   "ID do plano"
+// @Param        format  query  string  false  "json (padrão) ou text"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/plans/{id}/analysis [get]
+func (h *AnalyticsHandler) AnalyzePlan(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "text" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "parâmetro 'format' deve ser json ou text"})
+		return
+	}
+
+	if format == "text" {
+		_, report, err := h.service.PlanReport(c.Param("id"))
+		if err != nil {
+			respondAnalytics(c, nil, err)
+			return
+		}
+		c.String(http.StatusOK, report.Text)
+		return
+	}
+
+	response, err := h.service.AnalyzePlan(c.Param("id"))
+	respondAnalytics(c, response, err)
+}
+
+// parseStatementQuery lê ordenação, filtros e tamanho de página do explorador
+func parseStatementQuery(c *gin.Context) (models.StatementQuery, error) {
+	q := models.StatementQuery{
+		SortBy:   c.Query("sort"),
+		Database: c.Query("database"),
+		User:     c.Query("user"),
+		Search:   c.Query("search"),
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		q.Ascending = true
+	default:
+		return q, fmt.Errorf("parâmetro 'order' deve ser asc ou desc")
+	}
+
+	if raw := c.Query("queryid"); raw != "" {
+		queryID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'queryid' inválido: %w", err)
+		}
+		q.QueryID = &queryID
+	}
+
+	limit, err := queryInt(c, "limit", services.DefaultStatementLimit)
+	if err != nil {
+		return q, err
+	}
+	q.Limit = limit
+
+	return q, nil
+}
+
+// queryInt lê um parâmetro inteiro opcional da query string
+func queryInt(c *gin.Context, name string, fallback int) (int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("parâmetro '%s' inválido: %w", name, err)
+	}
+	return value, nil
 }
 
-// Função helper para adicionar contexto do usuário à resposta
-func addUserContext(c *gin.Context, response *models.AnalyticsResponse) {
+// respondAnalytics escreve a resposta de analytics, mapeando erros de resolução de target
+func respondAnalytics(c *gin.Context, response *models.AnalyticsResponse, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, repositories.ErrTargetNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrTargetUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, services.ErrStatementNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, repositories.ErrPlanNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, repositories.ErrAlertRuleNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalidAnalyticsQuery):
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrAnalyticsUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, services.ErrAnalyticsDependency):
+			status = http.StatusFailedDependency
+		case errors.Is(err, services.ErrTooManyPlans):
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondAnalyticsOK(c, response)
+}
+
+// respondAnalyticsOK completa e envia uma AnalyticsResponse de sucesso. É o único ponto que define
+// data_source: os serviços só precisam preenchê-lo para dados fictícios (demo).
+func respondAnalyticsOK(c *gin.Context, response *models.AnalyticsResponse) {
+	if response.DataSource == "" {
+		response.DataSource = models.DataSourceLive
+	}
 	response.User = gin.H{
-		"id":    c.GetInt("user_id"),
+		"id":    c.GetString("user_id"),
 		"email": c.GetString("email"),
 		"role":  c.GetString("role"),
 	}
+	c.JSON(http.StatusOK, response)
 }
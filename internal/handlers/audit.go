@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/services"
+)
+
+// defaultAuditPageSize é o tamanho de página usado quando 'limit' não é informado
+const defaultAuditPageSize = 100
+
+// AuditHandler gerencia a consulta de audit_logs
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler cria um novo handler de auditoria
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// @Summary      Consultar auditoria
+// @Description  Retorna registros de audit_logs filtrados por usuário, ação, recurso e intervalo de tempo
+// @Tags         Audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id        query  string  false  "ID do usuário que executou a ação"
+// @Param        action         query  string  false  "Ação (LOGIN, LOGIN_FAILED, CREATE, UPDATE, DELETE, ACCESS_DENIED...)"
+// @Param        resource_type  query  string  false  "Tipo do recurso (session, user, target, config, http)"
+// @Param        resource_id    query  string  false  "ID do recurso"
+// @Param        from           query  string  false  "Início (RFC3339 ou epoch), padrão: 24h atrás"
+// @Param        to             query  string  false  "Fim (RFC3339 ou epoch), padrão: agora"
+// @Param        limit          query  int     false  "Tamanho da página (padrão 100, máximo 1000)"
+// @Param        offset         query  int     false  "Deslocamento da página"
+// @Success      200  {object}  models.AuditPage
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /api/v1/audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page, err := h.service.List(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidAuditQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseAuditQuery lê filtros, intervalo e paginação da requisição
+func parseAuditQuery(c *gin.Context) (models.AuditQuery, error) {
+	q := models.AuditQuery{
+		UserID:       c.Query("user_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		To:           time.Now().UTC(),
+		Limit:        defaultAuditPageSize,
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'to' inválido: %w", err)
+		}
+		q.To = t
+	}
+
+	q.From = q.To.Add(-24 * time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'from' inválido: %w", err)
+		}
+		q.From = t
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'limit' inválido: %w", err)
+		}
+		q.Limit = limit
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'offset' inválido: %w", err)
+		}
+		q.Offset = offset
+	}
+
+	return q, nil
+}
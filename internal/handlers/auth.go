@@ -1,73 +1,201 @@
 package handlers
 
 import (
-    "net/http"
-    "time"
-    
-    "github.com/gin-gonic/gin"
-    "github.com/golang-jwt/jwt/v5"
-    "golang.org/x/crypto/bcrypt"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
 )
 
+// AuthHandler gerencia login, rotação de refresh tokens e logout
 type AuthHandler struct {
-    db        Database
-    jwtSecret string
+	service *services.AuthService
 }
 
-type Database interface {
-    QueryRow(query string, args ...interface{}) *sql.Row
-    Health() error
+// NewAuthHandler cria um novo handler de autenticação
+func NewAuthHandler(service *services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
 }
 
-type Credentials struct {
-    Username string `json:"username" binding:"required,min=3,max=50"`
-    Password string `json:"password" binding:"required,min=6"`
+// @Summary      Login
+// @Description  Autentica o usuário e retorna um access token JWT de curta duração e um refresh token
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.LoginRequest  true  "Email e senha"
+// @Success      200          {object}  models.LoginResponse
+// @Failure      400          {object}  models.ErrorResponse
+// @Failure      401          {object}  models.ErrorResponse
+// @Failure      403          {object}  models.ErrorResponse
+// @Failure      423          {object}  models.LockoutResponse
+// @Failure      429          {object}  models.LockoutResponse
+// @Router       /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	response, err := h.service.Login(req.Username, req.Password, clientInfo(c))
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-func NewAuthHandler(db Database, jwtSecret string) *AuthHandler {
-    return &AuthHandler{
-        db:        db,
-        jwtSecret: jwtSecret,
-    }
+// @Summary      Renovar tokens
+// @Description  Troca um refresh token válido por um novo par de tokens. O token usado é revogado; reutilizá-lo revoga a sessão inteira.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200    {object}  models.LoginResponse
+// @Failure      400    {object}  models.ErrorResponse
+// @Failure      401    {object}  models.ErrorResponse
+// @Router       /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	response, err := h.service.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-func (h *AuthHandler) Login(c *gin.Context) {
-    var creds Credentials
-    if err := c.ShouldBindJSON(&creds); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
-        return
-    }
-    
-    // Validate credentials
-    if !h.validateCredentials(creds.Username, creds.Password) {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-        return
-    }
-    
-    // Generate JWT token
-    token, err := h.generateToken(creds.Username)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-        return
-    }
-    
-    c.JSON(http.StatusOK, gin.H{"token": token})
+// @Summary      Logout
+// @Description  Revoga a sessão do refresh token informado, ou todas as sessões do usuário com all_sessions
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body  models.LogoutRequest  true  "Refresh token"
+// @Success      204
+// @Failure      400    {object}  models.ErrorResponse
+// @Failure      401    {object}  models.ErrorResponse
+// @Router       /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if err := h.service.Logout(req.RefreshToken, req.AllSessions); err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Perfil do usuário autenticado
+// @Description  Retorna os dados atuais do usuário do access token; usuário removido ou desativado recebe 401
+// @Tags         Auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Router       /auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, err := h.service.CurrentUser(c.GetString(middleware.ContextUserID))
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProfileResponse{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Role:    user.Role,
+		Message: "Profile data",
+	})
+}
+
+// @Summary      Desbloquear conta
+// @Description  Remove o bloqueio por tentativas falhadas de login de um usuário
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID do usuário"
+// @Success      204
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id}/unlock [post]
+func (h *AuthHandler) Unlock(c *gin.Context) {
+	if err := h.service.Unlock(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
-func (h *AuthHandler) validateCredentials(username, password string) bool {
-    var hashedPassword string
-    err := h.db.QueryRow("SELECT password FROM users WHERE username = $1", username).Scan(&hashedPassword)
-    if err == nil {
-        return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
-    }
-    return false
+// clientInfo extrai IP e User-Agent gravados junto ao refresh token
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
 }
 
-func (h *AuthHandler) generateToken(username string) (string, error) {
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "username": username,
-        "exp":      time.Now().Add(time.Hour * 24).Unix(),
-    })
-    
-    return token.SignedString([]byte(h.jwtSecret))
+// respondAuthError mapeia erros do serviço de autenticação para status HTTP
+func respondAuthError(c *gin.Context, err error) {
+	var lockout *services.LockoutError
+	if errors.As(err, &lockout) {
+		respondLockout(c, lockout)
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid credentials"})
+	case errors.Is(err, services.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Account disabled"})
+	case errors.Is(err, services.ErrInvalidRefreshToken), errors.Is(err, services.ErrRefreshTokenReused),
+		errors.Is(err, services.ErrInvalidSession):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, repositories.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Authentication failed"})
+	}
+}
+
+// respondLockout responde 423 para conta bloqueada e 429 para IP bloqueado, com Retry-After
+func respondLockout(c *gin.Context, lockout *services.LockoutError) {
+	retryAfter := int(math.Ceil(time.Until(lockout.Until).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	status, message := http.StatusLocked, "Account temporarily locked"
+	switch {
+	case errors.Is(lockout, services.ErrTooManyAttempts):
+		status, message = http.StatusTooManyRequests, "Too many failed login attempts"
+	case errors.Is(lockout, services.ErrTooManyResetRequests):
+		status, message = http.StatusTooManyRequests, "Too many password reset requests"
+	}
+
+	c.JSON(status, models.LockoutResponse{
+		Error:       message,
+		LockedUntil: lockout.Until,
+		RetryAfter:  retryAfter,
+	})
 }
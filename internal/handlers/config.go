@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
+)
+
+// ConfigHandler gerencia os endpoints de system_config
+type ConfigHandler struct {
+	service *services.ConfigService
+}
+
+// NewConfigHandler cria um novo handler de configuração
+func NewConfigHandler(service *services.ConfigService) *ConfigHandler {
+	return &ConfigHandler{service: service}
+}
+
+// @Summary      Listar configurações
+// @Description  Retorna as chaves de system_config, com valores sensíveis mascarados
+// @Tags         Config
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.ConfigEntry
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /api/v1/config [get]
+func (h *ConfigHandler) List(c *gin.Context) {
+	entries, err := h.service.List()
+	if err != nil {
+		respondConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"config": entries, "total": len(entries)})
+}
+
+// @Summary      Alterar configuração
+// @Description  Altera o valor de uma chave de system_config; a mudança é auditada
+// @Tags         Config
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key     path      string                      true  "Chave da configuração"
+// @Param        config  body      models.ConfigUpdateRequest  true  "Novo valor"
+// @Success      200     {object}  models.ConfigEntry
+// @Failure      400     {object}  models.ErrorResponse
+// @Failure      403     {object}  models.ErrorResponse
+// @Failure      404     {object}  models.ErrorResponse
+// @Router       /api/v1/config/{key} [put]
+func (h *ConfigHandler) Update(c *gin.Context) {
+	var req models.ConfigUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	entry, err := h.service.Update(c.Param("key"), req.Value, middleware.Actor(c))
+	if err != nil {
+		respondConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// respondConfigError mapeia erros do serviço de configuração para status HTTP
+func respondConfigError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrConfigNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidConfigValue):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
@@ -1,12 +1,18 @@
 package handlers
 
 import (
+    "database/sql"
     "net/http"
     "time"
     
     "github.com/gin-gonic/gin"
 )
 
+type Database interface {
+    QueryRow(query string, args ...interface{}) *sql.Row
+    Health() error
+}
+
 type HealthHandler struct {
     db Database
 }
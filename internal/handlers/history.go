@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/services"
+)
+
+// defaultHistoryPoints é a quantidade de buckets usada quando 'step' não é informado
+const defaultHistoryPoints = 120
+
+// defaultLockSnapshots é a quantidade de snapshots de bloqueio retornada quando 'limit' não é informado
+const defaultLockSnapshots = 50
+
+// HistoryHandler gerencia endpoints de séries temporais
+type HistoryHandler struct {
+	service *services.HistoryService
+}
+
+// NewHistoryHandler cria um novo handler de histórico
+func NewHistoryHandler(service *services.HistoryService) *HistoryHandler {
+	return &HistoryHandler{service: service}
+}
+
+// @Summary      Listar métricas com histórico
+// @Description  Retorna as métricas disponíveis para consulta de séries temporais
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/history [get]
+func (h *HistoryHandler) ListMetrics(c *gin.Context) {
+	response := h.service.GetAvailableMetrics()
+	respondAnalyticsOK(c, response)
+}
+
+// @Summary      Obter histórico de uma métrica
+// @Description  Retorna a série temporal agregada (avg/min/max/last por bucket) de uma métrica gravada
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        metric    path   string  true   "Nome da métrica (ex: cache_hit_ratio, n_dead_tup)"
+// @Param        from      query  string  false  "Início (RFC3339 ou epoch), padrão: 1h atrás"
+// @Param        to        query  string  false  "Fim (RFC3339 ou epoch), padrão: agora"
+// @Param        step      query  string  false  "Tamanho do bucket (ex: 5m ou 300)"
+// @Param        table     query  string  false  "schema.tabela, obrigatório para métricas de tabela"
+// @Param        database  query  string  false  "Filtrar por banco"
+// @Param        target    query  string  false  "Target monitorado (vazio = banco local)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/history/{metric} [get]
+func (h *HistoryHandler) GetHistory(c *gin.Context) {
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetHistory(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidHistoryQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondAnalyticsOK(c, response)
+}
+
+// @Summary      Obter snapshots de bloqueio
+// @Description  Retorna as árvores de bloqueio gravadas quando uma espera por lock excedeu o limite configurado
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from    query  string  false  "Início (RFC3339 ou epoch), padrão: 24h atrás"
+// @Param        to      query  string  false  "Fim (RFC3339 ou epoch), padrão: agora"
+// @Param        target  query  string  false  "Filtrar por target monitorado"
+// @Param        limit   query  int     false  "Máximo de snapshots (padrão 50)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/locks/history [get]
+func (h *HistoryHandler) GetLockSnapshots(c *gin.Context) {
+	query, err := parseLockSnapshotQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetLockSnapshots(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidHistoryQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondAnalyticsOK(c, response)
+}
+
+// parseLockSnapshotQuery lê from, to, target e limit da requisição
+func parseLockSnapshotQuery(c *gin.Context) (models.LockSnapshotQuery, error) {
+	q := models.LockSnapshotQuery{
+		Target: c.Query("target"),
+		To:     time.Now().UTC(),
+		Limit:  defaultLockSnapshots,
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'to' inválido: %w", err)
+		}
+		q.To = t
+	}
+
+	q.From = q.To.Add(-24 * time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'from' inválido: %w", err)
+		}
+		q.From = t
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'limit' inválido: %w", err)
+		}
+		q.Limit = limit
+	}
+
+	return q, nil
+}
+
+// parseHistoryQuery lê metric, from, to, step, table e database da requisição
+func parseHistoryQuery(c *gin.Context) (models.HistoryQuery, error) {
+	q := models.HistoryQuery{
+		Metric:   c.Param("metric"),
+		Table:    c.Query("table"),
+		Database: c.Query("database"),
+		Target:   c.Query("target"),
+		To:       time.Now().UTC(),
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'to' inválido: %w", err)
+		}
+		q.To = t
+	}
+
+	q.From = q.To.Add(-time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'from' inválido: %w", err)
+		}
+		q.From = t
+	}
+
+	if raw := c.Query("step"); raw != "" {
+		step, err := parseDurationParam(raw)
+		if err != nil {
+			return q, fmt.Errorf("parâmetro 'step' inválido: %w", err)
+		}
+		q.Step = step
+	} else {
+		q.Step = (q.To.Sub(q.From) / defaultHistoryPoints).Round(time.Second)
+		if q.Step < time.Minute {
+			q.Step = time.Minute
+		}
+	}
+
+	return q, nil
+}
+
+// parseTimeParam aceita RFC3339 ou segundos desde epoch
+func parseTimeParam(raw string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// parseDurationParam aceita durações Go (5m, 1h) ou segundos
+func parseDurationParam(raw string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return time.ParseDuration(raw)
+}
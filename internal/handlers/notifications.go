@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
+)
+
+// NotificationHandler gerencia o CRUD dos canais de notificação de alertas
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+// NewNotificationHandler cria um novo handler de canais de notificação
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// @Summary      Listar canais de notificação
+// @Description  Retorna os canais que recebem os eventos de alerta
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.NotificationChannel
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	channels, err := h.service.List()
+	if err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channels": channels, "total": len(channels)})
+}
+
+// @Summary      Obter canal de notificação
+// @Description  Retorna um canal de notificação pelo ID ou nome
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ou nome do canal"
+// @Success      200  {object}  models.NotificationChannel
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels/{id} [get]
+func (h *NotificationHandler) Get(c *gin.Context) {
+	channel, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// @Summary      Criar canal de notificação
+// @Description  Cadastra um canal webhook (JSON assinado com HMAC), slack, email ou pagerduty (Events API v2)
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        channel  body      models.NotificationChannelRequest  true  "Dados do canal"
+// @Success      201      {object}  models.NotificationChannel
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Failure      409      {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels [post]
+func (h *NotificationHandler) Create(c *gin.Context) {
+	var req models.NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	channel, err := h.service.Create(req, middleware.Actor(c))
+	if err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+// @Summary      Atualizar canal de notificação
+// @Description  Atualiza um canal de notificação
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                             true  "ID ou nome do canal"
+// @Param        channel  body      models.NotificationChannelRequest  true  "Dados do canal"
+// @Success      200      {object}  models.NotificationChannel
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      409      {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels/{id} [put]
+func (h *NotificationHandler) Update(c *gin.Context) {
+	var req models.NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	channel, err := h.service.Update(c.Param("id"), req, middleware.Actor(c))
+	if err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// @Summary      Remover canal de notificação
+// @Description  Remove um canal de notificação e descarta seus eventos ainda não entregues
+// @Tags         Alerts
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID ou nome do canal"
+// @Success      204
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels/{id} [delete]
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Testar canal de notificação
+// @Description  Envia imediatamente uma notificação de exemplo ao canal, sem retentativas
+// @Tags         Alerts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ou nome do canal"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      502  {object}  models.ErrorResponse
+// @Router       /api/v1/alerts/channels/{id}/test [post]
+func (h *NotificationHandler) Test(c *gin.Context) {
+	if err := h.service.Test(c.Param("id")); err != nil {
+		respondChannelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "delivered"})
+}
+
+// respondChannelError mapeia erros do serviço de notificações para status HTTP
+func respondChannelError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrChannelNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrChannelExists):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidChannel):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrNotificationFailed):
+		status = http.StatusBadGateway
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/services"
+)
+
+// PasswordHandler gerencia o fluxo de redefinição de senha
+type PasswordHandler struct {
+	service *services.PasswordService
+}
+
+// NewPasswordHandler cria um novo handler de senhas
+func NewPasswordHandler(service *services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{service: service}
+}
+
+// @Summary      Solicitar redefinição de senha
+// @Description  Envia por email um link de redefinição de uso único. A resposta é a mesma para emails desconhecidos. Solicitações são limitadas por IP e por email.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ForgotPasswordRequest  true  "Email da conta"
+// @Success      202      {object}  map[string]interface{}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      429      {object}  models.LockoutResponse
+// @Router       /auth/password/forgot [post]
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if err := h.service.Forgot(req.Email, middleware.Actor(c)); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a reset link has been sent"})
+}
+
+// @Summary      Redefinir senha
+// @Description  Define uma nova senha usando o token recebido por email. O token é de uso único e todas as sessões são encerradas.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  models.ResetPasswordRequest  true  "Token e nova senha"
+// @Success      204
+// @Failure      400      {object}  models.ErrorResponse
+// @Router       /auth/password/reset [post]
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if err := h.service.Reset(req.Token, req.Password, middleware.Actor(c)); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondPasswordError mapeia erros do serviço de senhas para status HTTP
+func respondPasswordError(c *gin.Context, err error) {
+	var lockout *services.LockoutError
+	if errors.As(err, &lockout) {
+		respondLockout(c, lockout)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrWeakPassword), errors.Is(err, services.ErrInvalidResetToken):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/services"
+)
+
+// RegressionHandler gerencia o endpoint do detector de regressões de queries
+type RegressionHandler struct {
+	service *services.QueryRegressionService
+}
+
+// NewRegressionHandler cria um novo handler de regressões
+func NewRegressionHandler(service *services.QueryRegressionService) *RegressionHandler {
+	return &RegressionHandler{service: service}
+}
+
+// @Summary      Detectar regressões de queries
+// @Description  Compara a latência média e o p95 de cada query normalizada na janela recente com sua linha de base em slow_queries_log e sinaliza regressões e queries novas que dominam o tempo total
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target            query  string  false  "Nome ou ID do target monitorado (padrão: banco local)"
+// @Param        factor            query  number  false  "Aumento mínimo da média ou do p95 (padrão em system_config)"
+// @Param        dominant_percent  query  number  false  "Percentual do tempo recente que sinaliza uma query nova"
+// @Param        window            query  string  false  "Janela recente (ex: 1h)"
+// @Param        baseline          query  string  false  "Linha de base anterior à janela recente (ex: 168h)"
+// @Success      200  {object}  models.AnalyticsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Router       /api/v1/analytics/queries/regressions [get]
+func (h *RegressionHandler) GetRegressions(c *gin.Context) {
+	opts, err := parseRegressionOptions(c, h.service.Options())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := h.service.GetRegressions(c.Query("target"), opts)
+	respondAnalytics(c, response, err)
+}
+
+// parseRegressionOptions sobrescreve os limites padrão com factor, dominant_percent, window e baseline
+func parseRegressionOptions(c *gin.Context, opts models.RegressionOptions) (models.RegressionOptions, error) {
+	for name, field := range map[string]*float64{"factor": &opts.Factor, "dominant_percent": &opts.DominantPercent} {
+		if raw := c.Query(name); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return opts, fmt.Errorf("parâmetro '%s' inválido: %w", name, err)
+			}
+			*field = value
+		}
+	}
+
+	if raw := c.Query("window"); raw != "" {
+		window, err := parseDurationParam(raw)
+		if err != nil {
+			return opts, fmt.Errorf("parâmetro 'window' inválido: %w", err)
+		}
+		opts.Window = window
+	}
+	if raw := c.Query("baseline"); raw != "" {
+		baseline, err := parseDurationParam(raw)
+		if err != nil {
+			return opts, fmt.Errorf("parâmetro 'baseline' inválido: %w", err)
+		}
+		opts.Baseline = baseline
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
+)
+
+// TargetHandler gerencia o CRUD de instâncias monitoradas
+type TargetHandler struct {
+	service *services.TargetService
+}
+
+// NewTargetHandler cria um novo handler de targets
+func NewTargetHandler(service *services.TargetService) *TargetHandler {
+	return &TargetHandler{service: service}
+}
+
+// @Summary      Listar targets
+// @Description  Retorna todas as instâncias PostgreSQL registradas para monitoramento
+// @Tags         Targets
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.Target
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /api/v1/targets [get]
+func (h *TargetHandler) List(c *gin.Context) {
+	targets, err := h.service.List()
+	if err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"targets": targets, "total": len(targets)})
+}
+
+// @Summary      Obter target
+// @Description  Retorna uma instância monitorada pelo ID ou nome
+// @Tags         Targets
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ou nome do target"
+// @Success      200  {object}  models.Target
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/targets/{id} [get]
+func (h *TargetHandler) Get(c *gin.Context) {
+	target, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// @Summary      Registrar target
+// @Description  Registra uma nova instância PostgreSQL e inicia sua coleta
+// @Tags         Targets
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target  body      models.TargetRequest  true  "Dados do target"
+// @Success      201     {object}  models.Target
+// @Failure      400     {object}  models.ErrorResponse
+// @Failure      409     {object}  models.ErrorResponse
+// @Failure      403     {object}  models.ErrorResponse
+// @Router       /api/v1/targets [post]
+func (h *TargetHandler) Create(c *gin.Context) {
+	var req models.TargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	target, err := h.service.Create(req, middleware.Actor(c))
+	if err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, target)
+}
+
+// @Summary      Atualizar target
+// @Description  Atualiza uma instância monitorada e reinicia sua coleta
+// @Tags         Targets
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string                true  "ID ou nome do target"
+// @Param        target  body      models.TargetRequest  true  "Dados do target"
+// @Success      200     {object}  models.Target
+// @Failure      400     {object}  models.ErrorResponse
+// @Failure      404     {object}  models.ErrorResponse
+// @Failure      403     {object}  models.ErrorResponse
+// @Router       /api/v1/targets/{id} [put]
+func (h *TargetHandler) Update(c *gin.Context) {
+	var req models.TargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	target, err := h.service.Update(c.Param("id"), req, middleware.Actor(c))
+	if err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// @Summary      Remover target
+// @Description  Remove uma instância monitorada e interrompe sua coleta
+// @Tags         Targets
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID ou nome do target"
+// @Success      204
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /api/v1/targets/{id} [delete]
+func (h *TargetHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Testar conexão do target
+// @Description  Verifica se a API consegue conectar à instância monitorada
+// @Tags         Targets
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ou nome do target"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /api/v1/targets/{id}/test [post]
+func (h *TargetHandler) Test(c *gin.Context) {
+	if err := h.service.Test(c.Param("id")); err != nil {
+		respondTargetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "connected"})
+}
+
+// respondTargetError mapeia erros do serviço de targets para status HTTP
+func respondTargetError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrTargetNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrTargetExists):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidTarget):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrTargetUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pganalytics-backend/internal/middleware"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+	"pganalytics-backend/internal/services"
+)
+
+// UserHandler gerencia a administração de usuários
+type UserHandler struct {
+	service *services.UserService
+}
+
+// NewUserHandler cria um novo handler de usuários
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// @Summary      Listar usuários
+// @Description  Retorna os usuários cadastrados, opcionalmente filtrados por papel
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  query     string  false  "Filtrar por papel (admin, user, readonly)"
+// @Success      200   {array}   models.User
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Router       /api/v1/users [get]
+func (h *UserHandler) List(c *gin.Context) {
+	users, err := h.service.List(c.Query("role"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users, "total": len(users)})
+}
+
+// @Summary      Obter usuário
+// @Description  Retorna um usuário pelo ID
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID do usuário"
+// @Success      200  {object}  models.User
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id} [get]
+func (h *UserHandler) Get(c *gin.Context) {
+	user, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Criar usuário
+// @Description  Cadastra um usuário com senha inicial validada pela política de senhas
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user  body      models.UserCreateRequest  true  "Dados do usuário"
+// @Success      201   {object}  models.User
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /api/v1/users [post]
+func (h *UserHandler) Create(c *gin.Context) {
+	var req models.UserCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, err := h.service.Create(req, middleware.Actor(c))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// @Summary      Atualizar usuário
+// @Description  Altera email, nome ou verificação de email; campos omitidos não mudam
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                    true  "ID do usuário"
+// @Param        user  body      models.UserUpdateRequest  true  "Campos a alterar"
+// @Success      200   {object}  models.User
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id} [put]
+func (h *UserHandler) Update(c *gin.Context) {
+	var req models.UserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, err := h.service.Update(c.Param("id"), req, middleware.Actor(c))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Alterar papel
+// @Description  Troca o papel do usuário e encerra suas sessões
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                    true  "ID do usuário"
+// @Param        role  body      models.RoleUpdateRequest  true  "Novo papel"
+// @Success      200   {object}  models.User
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id}/role [put]
+func (h *UserHandler) ChangeRole(c *gin.Context) {
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, err := h.service.ChangeRole(c.Param("id"), req.Role, middleware.Actor(c))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Desativar usuário
+// @Description  Impede novos logins do usuário e encerra suas sessões
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID do usuário"
+// @Success      200  {object}  models.User
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id}/deactivate [post]
+func (h *UserHandler) Deactivate(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// @Summary      Reativar usuário
+// @Description  Permite novamente o login de um usuário desativado
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID do usuário"
+// @Success      200  {object}  models.User
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id}/activate [post]
+func (h *UserHandler) Activate(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *UserHandler) setActive(c *gin.Context, active bool) {
+	user, err := h.service.SetActive(c.Param("id"), active, middleware.Actor(c))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Remover usuário
+// @Description  Remove o usuário e suas sessões; os registros de auditoria são mantidos
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID do usuário"
+// @Success      204
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id} [delete]
+func (h *UserHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Forçar redefinição de senha
+// @Description  Invalida a senha atual, encerra as sessões e envia um link de redefinição ao usuário
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id   path  string  true  "ID do usuário"
+// @Success      202
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /api/v1/users/{id}/force-password-reset [post]
+func (h *UserHandler) ForceReset(c *gin.Context) {
+	if err := h.service.ForceReset(c.Param("id"), middleware.Actor(c)); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// respondUserError mapeia erros do serviço de usuários para status HTTP
+func respondUserError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrUserExists), errors.Is(err, services.ErrLastAdmin):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrWeakPassword), errors.Is(err, services.ErrInvalidUser):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.ErrorResponse{Error: err.Error()})
+}
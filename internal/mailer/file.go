@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FileMailer grava as mensagens em um arquivo, ou apenas loga destinatários e assunto quando
+// Path é vazio; o corpo pode conter tokens de redefinição e nunca vai para o log.
+// Destinado a desenvolvimento local, onde não há servidor SMTP.
+type FileMailer struct {
+	Path string
+	From string
+
+	mu sync.Mutex
+}
+
+// Send implementa Mailer
+func (m *FileMailer) Send(msg Message) error {
+	if m.Path == "" {
+		log.Printf("📧 Email não enviado (MAIL_FILE_PATH vazio): para %s, assunto %q, corpo omitido (%d bytes)",
+			strings.Join(msg.To, ", "), msg.Subject, len(msg.Body))
+		return nil
+	}
+
+	from := m.From
+	if from == "" {
+		from = "pganalytics@localhost"
+	}
+	data := render(from, msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("falha ao abrir arquivo de emails: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, []byte("\r\n")...)); err != nil {
+		return fmt.Errorf("falha ao gravar email: %w", err)
+	}
+	return nil
+}
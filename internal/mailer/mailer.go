@@ -0,0 +1,70 @@
+// Package mailer envia emails transacionais (ex.: redefinição de senha).
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message é um email em texto simples
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer entrega mensagens. Implementações: SMTPMailer e FileMailer.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config seleciona e configura a implementação de Mailer
+type Config struct {
+	Driver       string // "smtp" ou "file" (padrão)
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	FilePath     string // vazio: apenas loga destinatários e assunto, sem o corpo
+}
+
+// New cria o Mailer indicado em cfg.Driver
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.From == "" {
+			return nil, fmt.Errorf("mailer smtp requer host e remetente")
+		}
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}, nil
+	case "", "file", "log":
+		return &FileMailer{Path: cfg.FilePath, From: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("mailer desconhecido: %q", cfg.Driver)
+	}
+}
+
+// render monta a mensagem no formato RFC 5322 com corpo UTF-8
+func render(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
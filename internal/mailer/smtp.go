@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPMailer entrega mensagens por SMTP, usando STARTTLS quando o servidor oferece
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send implementa Mailer
+func (m *SMTPMailer) Send(msg Message) error {
+	port := m.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, m.From, msg.To, render(m.From, msg)); err != nil {
+		return fmt.Errorf("falha ao enviar email via %s: %w", addr, err)
+	}
+	return nil
+}
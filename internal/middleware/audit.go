@@ -0,0 +1,77 @@
+package middleware
+
+import (
+    "net/http"
+    
+    "github.com/gin-gonic/gin"
+    "pganalytics-backend/internal/models"
+)
+
+// ContextAudited marca requisições cujo handler grava a própria auditoria detalhada
+const ContextAudited = "audited"
+
+// AuditRecorder grava registros de auditoria (implementado por services.AuditService)
+type AuditRecorder interface {
+    Record(entry *models.AuditLog)
+}
+
+// AuditTrail grava em audit_logs os acessos negados (401/403) e as requisições de escrita
+// bem-sucedidas que não foram auditadas pelo próprio handler.
+// Deve ser registrado antes de AuthMiddleware para enxergar as respostas 401.
+func AuditTrail(recorder AuditRecorder) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.Next()
+        
+        status := c.Writer.Status()
+        action := ""
+        switch {
+        case status == http.StatusUnauthorized || status == http.StatusForbidden:
+            action = models.AuditActionAccessDenied
+        case isWriteMethod(c.Request.Method) && status < http.StatusBadRequest && !c.GetBool(ContextAudited):
+            action = models.AuditActionRequest
+        default:
+            return
+        }
+        
+        actor := actorFrom(c)
+        var userID *string
+        if actor.UserID != "" {
+            userID = &actor.UserID
+        }
+        
+        recorder.Record(&models.AuditLog{
+            UserID:       userID,
+            Action:       action,
+            ResourceType: "http",
+            ResourceID:   c.Request.Method + " " + c.Request.URL.Path,
+            NewValues:    models.AuditValues{"status": status, "route": c.FullPath()},
+            IPAddress:    actor.IPAddress,
+            UserAgent:    actor.UserAgent,
+            SessionID:    actor.SessionID,
+        })
+    }
+}
+
+// Actor retorna quem executa a requisição para auditoria detalhada no serviço.
+// Marca a requisição como auditada, evitando o registro genérico de AuditTrail.
+func Actor(c *gin.Context) models.AuditActor {
+    c.Set(ContextAudited, true)
+    return actorFrom(c)
+}
+
+func actorFrom(c *gin.Context) models.AuditActor {
+    return models.AuditActor{
+        UserID:    c.GetString(ContextUserID),
+        IPAddress: c.ClientIP(),
+        UserAgent: c.Request.UserAgent(),
+        SessionID: c.GetString(ContextSessionID),
+    }
+}
+
+func isWriteMethod(method string) bool {
+    switch method {
+    case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+        return true
+    }
+    return false
+}
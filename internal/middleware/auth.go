@@ -1,14 +1,35 @@
 package middleware
 
 import (
+    "errors"
+    "log"
     "net/http"
     "strings"
     
     "github.com/gin-gonic/gin"
     "github.com/golang-jwt/jwt/v5"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// Chaves do gin.Context preenchidas por AuthMiddleware
+const (
+    ContextUserID    = "user_id"
+    ContextEmail     = "email"
+    ContextRole      = "role"
+    ContextSessionID = "session_id"
+    ContextClaims    = "claims"
+)
+
+// UserFinder carrega o usuário dono de um access token
+type UserFinder interface {
+    FindByID(id string) (*models.User, error)
+}
+
+// AuthMiddleware valida o access token e carrega o usuário atual com users.
+// Usuários removidos ou desativados são recusados e o papel vem do cadastro,
+// não do token, para que desativação e troca de papel valham antes do token expirar.
+func AuthMiddleware(jwtSecret string, users UserFinder) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
@@ -24,16 +45,36 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
             return
         }
         
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+        claims := &models.Claims{}
+        token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
             return []byte(jwtSecret), nil
-        })
+        }, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
         
-        if err != nil || !token.Valid {
+        if err != nil || !token.Valid || claims.UserID == "" {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
             c.Abort()
             return
         }
         
+        user, err := users.FindByID(claims.UserID)
+        if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+            log.Printf("⚠️ Erro ao carregar usuário do token: %v", err)
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
+            c.Abort()
+            return
+        }
+        if err != nil || !user.IsActive {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found or disabled"})
+            c.Abort()
+            return
+        }
+        
+        c.Set(ContextUserID, user.ID)
+        c.Set(ContextEmail, user.Email)
+        c.Set(ContextRole, user.Role)
+        c.Set(ContextSessionID, claims.ID)
+        c.Set(ContextClaims, claims)
+        
         c.Next()
     }
 }
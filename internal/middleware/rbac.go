@@ -0,0 +1,72 @@
+package middleware
+
+import (
+    "net/http"
+    
+    "github.com/gin-gonic/gin"
+    "pganalytics-backend/internal/models"
+)
+
+// Permission identifica uma ação protegida por papel
+type Permission string
+
+const (
+    PermissionReadAnalytics Permission = "analytics:read"
+    PermissionReadTargets   Permission = "targets:read"
+    PermissionManageTargets Permission = "targets:manage"
+    PermissionManageConfig  Permission = "config:manage"
+    PermissionManageUsers   Permission = "users:manage"
+    PermissionReadAudit     Permission = "audit:read"
+    PermissionManageAlerts  Permission = "alerts:manage"
+
+    // PermissionExplainQueries permite capturar planos (EXPLAIN e EXPLAIN ANALYZE) no banco monitorado.
+    // Cada captura grava uma linha em slow_queries_log e ANALYZE executa a query.
+    PermissionExplainQueries Permission = "queries:explain"
+)
+
+// rolePermissions define o que cada papel de users.role pode fazer.
+// Ações destrutivas ou administrativas ficam restritas a admin.
+var rolePermissions = map[string][]Permission{
+    models.RoleAdmin: {
+        PermissionReadAnalytics,
+        PermissionReadTargets,
+        PermissionManageTargets,
+        PermissionManageConfig,
+        PermissionManageUsers,
+        PermissionReadAudit,
+        PermissionManageAlerts,
+        PermissionExplainQueries,
+    },
+    models.RoleUser: {
+        PermissionReadAnalytics,
+        PermissionReadTargets,
+    },
+    models.RoleReadonly: {
+        PermissionReadAnalytics,
+        PermissionReadTargets,
+    },
+}
+
+// HasPermission indica se o papel concede a permissão
+func HasPermission(role string, permission Permission) bool {
+    for _, p := range rolePermissions[role] {
+        if p == permission {
+            return true
+        }
+    }
+    return false
+}
+
+// RequirePermission nega com 403 requisições cujo papel não concede a permissão.
+// Deve ser registrado depois de AuthMiddleware.
+func RequirePermission(permission Permission) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !HasPermission(c.GetString(ContextRole), permission) {
+            c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Insufficient permissions"})
+            c.Abort()
+            return
+        }
+        
+        c.Next()
+    }
+}
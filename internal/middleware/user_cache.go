@@ -0,0 +1,62 @@
+package middleware
+
+import (
+    "sync"
+    "time"
+    
+    "pganalytics-backend/internal/models"
+)
+
+// UserCacheTTL é por quanto tempo AuthMiddleware reaproveita um usuário carregado.
+// Desativação e troca de papel levam no máximo esse tempo para valer.
+const UserCacheTTL = 30 * time.Second
+
+// UserCache guarda por ttl os usuários encontrados por users.
+// Usuários inexistentes e erros não são guardados.
+type UserCache struct {
+    users   UserFinder
+    ttl     time.Duration
+    mu      sync.Mutex
+    entries map[string]cachedUser
+}
+
+type cachedUser struct {
+    user      *models.User
+    expiresAt time.Time
+}
+
+// NewUserCache cria um cache de usuários sobre users
+func NewUserCache(users UserFinder, ttl time.Duration) *UserCache {
+    return &UserCache{
+        users:   users,
+        ttl:     ttl,
+        entries: make(map[string]cachedUser),
+    }
+}
+
+// FindByID implementa UserFinder
+func (c *UserCache) FindByID(id string) (*models.User, error) {
+    now := time.Now()
+    
+    c.mu.Lock()
+    entry, ok := c.entries[id]
+    c.mu.Unlock()
+    if ok && now.Before(entry.expiresAt) {
+        return entry.user, nil
+    }
+    
+    user, err := c.users.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for key, cached := range c.entries {
+        if !now.Before(cached.expiresAt) {
+            delete(c.entries, key)
+        }
+    }
+    c.entries[id] = cachedUser{user: user, expiresAt: now.Add(c.ttl)}
+    return user, nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Severidades de eventos de alerta
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// AlertEvent representa um evento gravado em alert_events
+type AlertEvent struct {
+	ID         string          `json:"id" db:"id"`                   // ID do evento
+	TargetName *string         `json:"target_name" db:"target_name"` // Target de origem (nulo = banco local)
+	Source     string          `json:"source" db:"source"`           // Detector que gerou o evento (ex: query_regression)
+	EventKey   string          `json:"event_key" db:"event_key"`     // Chave de deduplicação
+	Severity   string          `json:"severity" db:"severity"`       // info, warning ou critical
+	Title      string          `json:"title" db:"title"`             // Resumo
+	Message    string          `json:"message" db:"message"`         // Descrição
+	Details    json.RawMessage `json:"details" db:"details"`         // Dados do detector
+	State      *string         `json:"state" db:"state"`             // Estado da regra na transição (firing ou resolved)
+	Value      *float64        `json:"value" db:"value"`             // Valor da métrica na transição
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`   // Momento do evento
+}
+
+// AlertEventQuery filtra os eventos de alerta gravados
+type AlertEventQuery struct {
+	Target   string    // Target monitorado (vazio = todos)
+	Source   string    // Detector (vazio = todos)
+	Severity string    // Severidade (vazio = todas)
+	EventKey string    // Chave do evento (vazio = todas)
+	From     time.Time // Início do período
+	To       time.Time // Fim do período
+	Limit    int       // Máximo de eventos retornados
+}
+
+// Condições de regras de alerta
+const (
+	AlertConditionThreshold    = "threshold"      // Valor atual comparado ao limite
+	AlertConditionRateOfChange = "rate_of_change" // Variação por minuto na janela comparada ao limite
+)
+
+// Estados de regras de alerta
+const (
+	AlertStateInactive = "inactive"
+	AlertStatePending  = "pending"
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// Tratamento de uma avaliação sem amostras na janela (no_data_state)
+const (
+	AlertNoDataOK       = "ok"       // Trata como condição não atendida: resolve a regra disparada
+	AlertNoDataAlerting = "alerting" // Trata como condição atendida: dispara após for_seconds
+	AlertNoDataKeep     = "keep"     // Mantém o último estado
+)
+
+// AlertRule representa uma regra de alerta gravada em alert_rules
+// @Description Regra de alerta avaliada sobre as métricas coletadas
+type AlertRule struct {
+	ID            string    `json:"id" db:"id"`                                         // ID da regra
+	Name          string    `json:"name" db:"name" example:"conexoes-altas"`            // Nome único
+	Description   string    `json:"description" db:"description"`                       // Descrição livre
+	TargetName    *string   `json:"target_name" db:"target_name" example:"prod-orders"` // Target avaliado (nulo = banco local)
+	Metric        string    `json:"metric" db:"metric" example:"connection_percent"`    // Métrica avaliada
+	Condition     string    `json:"condition" db:"condition" example:"threshold"`       // threshold ou rate_of_change
+	Operator      string    `json:"operator" db:"operator" example:">"`                 // >, >=, < ou <=
+	Threshold     float64   `json:"threshold" db:"threshold" example:"80"`              // Limite
+	WindowSeconds int       `json:"window_seconds" db:"window_seconds" example:"300"`   // Janela de amostras
+	ForSeconds    int       `json:"for_seconds" db:"for_seconds" example:"120"`         // Duração mínima da condição antes de disparar
+	Severity      string    `json:"severity" db:"severity" example:"warning"`           // info, warning ou critical
+	NoDataState   string    `json:"no_data_state" db:"no_data_state" example:"ok"`      // ok, alerting ou keep
+	Enabled       bool      `json:"enabled" db:"enabled" example:"true"`                // Avaliação habilitada
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`                         // Data de criação
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`                         // Data de atualização
+}
+
+// AlertRuleRequest representa o corpo de criação/atualização de uma regra de alerta
+// @Description Dados para cadastrar uma regra de alerta
+type AlertRuleRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"conexoes-altas"` // Nome único
+	Description   string   `json:"description"`                                              // Descrição livre
+	Target        string   `json:"target" example:"prod-orders"`                             // Target avaliado (vazio = banco local)
+	Metric        string   `json:"metric" binding:"required" example:"connection_percent"`   // Métrica avaliada
+	Condition     string   `json:"condition" example:"threshold"`                            // threshold (padrão) ou rate_of_change
+	Operator      string   `json:"operator" binding:"required" example:">"`                  // >, >=, < ou <=
+	Threshold     *float64 `json:"threshold" binding:"required" example:"80"`                // Limite
+	WindowSeconds int      `json:"window_seconds" example:"300"`                             // Janela de amostras (padrão 300, mínimo 60)
+	ForSeconds    int      `json:"for_seconds" example:"120"`                                // Duração mínima da condição (padrão 0)
+	Severity      string   `json:"severity" example:"warning"`                               // info, warning (padrão) ou critical
+	NoDataState   string   `json:"no_data_state" example:"ok"`                               // Janela sem amostras: ok (padrão), alerting ou keep
+	Enabled       *bool    `json:"enabled" example:"true"`                                   // Avaliação habilitada (padrão true)
+}
+
+// AlertRuleState representa o estado corrente de uma regra, gravado em alert_rule_states
+type AlertRuleState struct {
+	RuleID      string     `json:"rule_id" db:"rule_id"`           // ID da regra
+	State       string     `json:"state" db:"state"`               // inactive, pending, firing ou resolved
+	Value       *float64   `json:"value" db:"value"`               // Último valor avaliado (nulo = janela sem amostras)
+	ActiveSince *time.Time `json:"active_since" db:"active_since"` // Início da condição atual (pending/firing)
+	FiredAt     *time.Time `json:"fired_at" db:"fired_at"`         // Último disparo
+	ResolvedAt  *time.Time `json:"resolved_at" db:"resolved_at"`   // Última resolução
+	EvaluatedAt time.Time  `json:"evaluated_at" db:"evaluated_at"` // Última avaliação
+}
+
+// AlertRuleStatus combina a regra com seu estado corrente
+type AlertRuleStatus struct {
+	AlertRule
+	State *AlertRuleState `json:"state"` // Nulo enquanto a regra não foi avaliada
+}
+
+// AlertMetric descreve uma métrica disponível para regras de alerta
+type AlertMetric struct {
+	Name         string `json:"name" example:"connection_percent"`    // Nome usado na regra
+	Source       string `json:"source" example:"connections_percent"` // Métrica de system_metrics_log
+	Unit         string `json:"unit" example:"percent"`               // Unidade do valor avaliado
+	Description  string `json:"description"`                          // Descrição
+	RateOfChange bool   `json:"rate_of_change"`                       // Aceita a condição rate_of_change
+}
+
+// MetricSample representa o valor de uma métrica em um snapshot
+type MetricSample struct {
+	At    time.Time `db:"at"`    // Momento do snapshot
+	Value float64   `db:"value"` // Valor combinado das linhas do snapshot
+}
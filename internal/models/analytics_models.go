@@ -1,11 +1,15 @@
 package models
 
+import "time"
+
 // SlowQuery representa uma query lenta do PostgreSQL
 type SlowQuery struct {
-	QueryText  string  `json:"query" db:"query_text"`        // Texto da query
-	DurationMs float64 `json:"duration_ms" db:"duration_ms"` // Duração em milissegundos
-	Calls      int     `json:"calls" db:"calls"`             // Número de chamadas
-	Rows       int     `json:"rows" db:"rows"`               // Número de linhas retornadas
+	QueryText  string  `json:"query" db:"query_text"`                // Texto da query
+	DurationMs float64 `json:"duration_ms" db:"duration_ms"`         // Duração em milissegundos
+	Calls      int     `json:"calls" db:"calls"`                     // Número de chamadas
+	Rows       int     `json:"rows" db:"rows"`                       // Número de linhas retornadas
+	Username   string  `json:"username,omitempty" db:"username"`     // Usuário que executou
+	QueryHash  string  `json:"query_hash,omitempty" db:"query_hash"` // Hash MD5 da query normalizada
 }
 
 // TableStat representa estatísticas de uma tabela
@@ -36,23 +40,57 @@ type DatabaseSize struct {
 
 // PerformanceStats representa estatísticas de performance
 type PerformanceStats struct {
-	CacheHitRatio  float64 `json:"cache_hit_ratio" db:"cache_hit_ratio"`   // Cache hit ratio
-	TuplesReturned int64   `json:"tuples_returned" db:"tuples_returned"`   // Tuplas retornadas
-	TuplesFetched  int64   `json:"tuples_fetched" db:"tuples_fetched"`     // Tuplas buscadas
-	TuplesInserted int64   `json:"tuples_inserted" db:"tuples_inserted"`   // Tuplas inseridas
-	TuplesUpdated  int64   `json:"tuples_updated" db:"tuples_updated"`     // Tuplas atualizadas
-	TuplesDeleted  int64   `json:"tuples_deleted" db:"tuples_deleted"`     // Tuplas deletadas
-	Conflicts      int64   `json:"conflicts" db:"conflicts"`               // Conflitos
-	TempFiles      int64   `json:"temp_files" db:"temp_files"`             // Arquivos temporários
-	Deadlocks      int64   `json:"deadlocks" db:"deadlocks"`               // Deadlocks
+	CacheHitRatio  float64    `json:"cache_hit_ratio" db:"cache_hit_ratio"` // Cache hit ratio
+	TuplesReturned int64      `json:"tuples_returned" db:"tuples_returned"` // Tuplas retornadas
+	TuplesFetched  int64      `json:"tuples_fetched" db:"tuples_fetched"`   // Tuplas buscadas
+	TuplesInserted int64      `json:"tuples_inserted" db:"tuples_inserted"` // Tuplas inseridas
+	TuplesUpdated  int64      `json:"tuples_updated" db:"tuples_updated"`   // Tuplas atualizadas
+	TuplesDeleted  int64      `json:"tuples_deleted" db:"tuples_deleted"`   // Tuplas deletadas
+	Conflicts      int64      `json:"conflicts" db:"conflicts"`             // Conflitos
+	TempFiles      int64      `json:"temp_files" db:"temp_files"`           // Arquivos temporários
+	Deadlocks      int64      `json:"deadlocks" db:"deadlocks"`             // Deadlocks
+	XactCommit     int64      `json:"xact_commit" db:"xact_commit"`         // Transações confirmadas
+	XactRollback   int64      `json:"xact_rollback" db:"xact_rollback"`     // Transações desfeitas
+	BlocksRead     int64      `json:"blks_read" db:"blks_read"`             // Blocos lidos do disco
+	BlocksHit      int64      `json:"blks_hit" db:"blks_hit"`               // Blocos encontrados no cache
+	TempBytes      int64      `json:"temp_bytes" db:"temp_bytes"`           // Bytes em arquivos temporários
+	StatsReset     *time.Time `json:"stats_reset" db:"stats_reset"`         // Último reset dos contadores
+	CollectedAt    time.Time  `json:"collected_at" db:"collected_at"`       // Momento da coleta no servidor
+}
+
+// PerformanceRates representa taxas por segundo e deltas entre duas amostras de pg_stat_database
+type PerformanceRates struct {
+	IntervalSeconds       float64 `json:"interval_seconds"`           // Intervalo entre as amostras
+	CounterReset          bool    `json:"counter_reset"`              // Contadores foram resetados no intervalo
+	TransactionsPerSecond float64 `json:"tps"`                        // Commits + rollbacks por segundo
+	CommitsPerSecond      float64 `json:"commits_per_second"`         // Commits por segundo
+	RollbacksPerSecond    float64 `json:"rollbacks_per_second"`       // Rollbacks por segundo
+	ReturnedPerSecond     float64 `json:"tuples_returned_per_second"` // Tuplas retornadas por segundo
+	FetchedPerSecond      float64 `json:"tuples_fetched_per_second"`  // Tuplas buscadas por segundo
+	InsertsPerSecond      float64 `json:"inserts_per_second"`         // Inserts por segundo
+	UpdatesPerSecond      float64 `json:"updates_per_second"`         // Updates por segundo
+	DeletesPerSecond      float64 `json:"deletes_per_second"`         // Deletes por segundo
+	BlocksReadPerSecond   float64 `json:"blks_read_per_second"`       // Leituras de disco por segundo
+	IntervalCacheHitRatio float64 `json:"interval_cache_hit_ratio"`   // Cache hit ratio apenas do intervalo
+	DeadlocksDelta        int64   `json:"deadlocks_delta"`            // Deadlocks no intervalo
+	ConflictsDelta        int64   `json:"conflicts_delta"`            // Conflitos no intervalo
+	TempFilesDelta        int64   `json:"temp_files_delta"`           // Arquivos temporários no intervalo
+	TempBytesDelta        int64   `json:"temp_bytes_delta"`           // Bytes temporários no intervalo
 }
 
+// Origens dos dados de uma resposta de analytics
+const (
+	DataSourceLive = "live" // Coletado do banco monitorado
+	DataSourceDemo = "demo" // Dados fictícios do modo demonstração
+)
+
 // AnalyticsResponse representa uma resposta do serviço de analytics
 type AnalyticsResponse struct {
 	Success     bool        `json:"success"`                              // Sucesso da operação
 	Message     string      `json:"message"`                              // Mensagem
 	Timestamp   int64       `json:"timestamp"`                            // Timestamp
 	Environment string      `json:"environment"`                          // Ambiente
+	DataSource  string      `json:"data_source"`                          // live ou demo
 	User        interface{} `json:"user,omitempty"`                       // Dados do usuário
 	Data        interface{} `json:"data"`                                 // Dados da resposta
 }
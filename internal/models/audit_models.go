@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Ações gravadas em audit_logs.action
+const (
+	AuditActionCreate                 = "CREATE"
+	AuditActionUpdate                 = "UPDATE"
+	AuditActionDelete                 = "DELETE"
+	AuditActionAccessDenied           = "ACCESS_DENIED"
+	AuditActionRequest                = "REQUEST"
+	AuditActionLogin                  = "LOGIN"
+	AuditActionLoginFailed            = "LOGIN_FAILED"
+	AuditActionLoginThrottled         = "LOGIN_THROTTLED"
+	AuditActionAccountLocked          = "ACCOUNT_LOCKED"
+	AuditActionAccountUnlocked        = "ACCOUNT_UNLOCKED"
+	AuditActionPasswordResetRequested = "PASSWORD_RESET_REQUESTED"
+	AuditActionPasswordResetThrottled = "PASSWORD_RESET_THROTTLED"
+	AuditActionPasswordReset          = "PASSWORD_RESET"
+	AuditActionPasswordResetForced    = "PASSWORD_RESET_FORCED"
+)
+
+// AuditValues representa old_values/new_values, gravados como JSONB
+type AuditValues map[string]interface{}
+
+// Value implementa driver.Valuer; um mapa nil é gravado como NULL
+func (v AuditValues) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan implementa sql.Scanner
+func (v *AuditValues) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("tipo não suportado para valores de auditoria: %T", src)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// AuditLog representa uma entrada de audit_logs
+// @Description Registro de auditoria de uma ação do sistema
+type AuditLog struct {
+	ID           string      `json:"id" db:"id"`                                                // ID do registro
+	UserID       *string     `json:"user_id,omitempty" db:"user_id"`                            // Usuário que executou a ação
+	Action       string      `json:"action" db:"action" example:"LOGIN"`                        // Ação realizada
+	ResourceType string      `json:"resource_type,omitempty" db:"resource_type" example:"user"` // Tipo do recurso afetado
+	ResourceID   string      `json:"resource_id,omitempty" db:"resource_id"`                    // ID do recurso afetado
+	OldValues    AuditValues `json:"old_values,omitempty" db:"old_values"`                      // Valores antes da mudança
+	NewValues    AuditValues `json:"new_values,omitempty" db:"new_values"`                      // Valores depois da mudança
+	IPAddress    string      `json:"ip_address,omitempty" db:"ip_address"`                      // IP de origem
+	UserAgent    string      `json:"user_agent,omitempty" db:"user_agent"`                      // User-Agent de origem
+	SessionID    string      `json:"session_id,omitempty" db:"session_id"`                      // Sessão (família de refresh tokens)
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`                                // Data do registro
+}
+
+// AuditActor identifica quem executou uma ação auditada e de onde
+type AuditActor struct {
+	UserID    string
+	IPAddress string
+	UserAgent string
+	SessionID string
+}
+
+// AuditQuery descreve os filtros de GET /api/v1/audit
+type AuditQuery struct {
+	UserID       string    `json:"user_id,omitempty"`
+	Action       string    `json:"action,omitempty"`
+	ResourceType string    `json:"resource_type,omitempty"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Limit        int       `json:"limit"`
+	Offset       int       `json:"offset"`
+}
+
+// AuditPage é uma página de registros de auditoria
+// @Description Registros de auditoria filtrados, do mais recente para o mais antigo
+type AuditPage struct {
+	Entries []AuditLog `json:"entries"`             // Registros da página
+	Total   int        `json:"total" example:"42"`  // Total de registros que atendem aos filtros
+	Limit   int        `json:"limit" example:"100"` // Tamanho da página
+	Offset  int        `json:"offset" example:"0"`  // Deslocamento da página
+	Query   AuditQuery `json:"query"`               // Filtros aplicados
+}
@@ -0,0 +1,21 @@
+package models
+
+// Métodos de cálculo de bloat
+const (
+	BloatMethodEstimate    = "estimate"    // Estimativa a partir de pg_stats e pg_class
+	BloatMethodPgstattuple = "pgstattuple" // Medição com a extensão pgstattuple
+)
+
+// BloatEstimate representa o espaço desperdiçado em uma tabela ou índice btree
+type BloatEstimate struct {
+	ObjectType    string  `json:"object_type" db:"object_type"`         // table ou index
+	SchemaName    string  `json:"schema_name" db:"schema_name"`         // Schema do objeto
+	TableName     string  `json:"table_name" db:"table_name"`           // Tabela (ou tabela do índice)
+	IndexName     string  `json:"index_name,omitempty" db:"index_name"` // Índice (vazio para tabelas)
+	SizeBytes     int64   `json:"size_bytes" db:"size_bytes"`           // Tamanho atual em bytes
+	ExpectedBytes int64   `json:"expected_bytes" db:"expected_bytes"`   // Tamanho esperado sem bloat (considerando fillfactor)
+	WastedBytes   int64   `json:"wasted_bytes" db:"wasted_bytes"`       // Espaço recuperável em bytes
+	BloatPercent  float64 `json:"bloat_percent" db:"bloat_percent"`     // Percentual do tamanho atual que é bloat
+	Fillfactor    int     `json:"fillfactor" db:"fillfactor"`           // Fillfactor do objeto
+	Method        string  `json:"method" db:"method"`                   // estimate ou pgstattuple
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Coletores do catálogo de consultas
+const (
+	CollectorSlowQueries    = "slow_queries"    // pg_stat_statements
+	CollectorCheckpoints    = "checkpoints"     // pg_stat_bgwriter, pg_stat_checkpointer e pg_stat_wal
+	CollectorLocks          = "locks"           // pg_blocking_pids
+	CollectorReplication    = "replication"     // pg_stat_replication, pg_stat_wal_receiver e slots
+	CollectorVacuumProgress = "vacuum_progress" // pg_stat_progress_vacuum
+	CollectorBloatMeasure   = "bloat_measure"   // pgstattuple
+	CollectorGenericPlan    = "generic_plan"    // EXPLAIN (GENERIC_PLAN) ou PREPARE com plan_cache_mode
+)
+
+// ServerCapabilities representa a versão do servidor e as extensões instaladas em um target
+type ServerCapabilities struct {
+	ServerVersionNum int               `json:"server_version_num"` // server_version_num (ex: 160002)
+	ServerVersion    string            `json:"server_version"`     // server_version (ex: 16.2)
+	Extensions       map[string]string `json:"extensions"`         // Extensões instaladas e suas versões
+	DetectedAt       time.Time         `json:"detected_at"`        // Momento da detecção
+}
+
+// ExtensionVersion representa uma linha de pg_extension
+type ExtensionVersion struct {
+	Name    string `db:"extname"`
+	Version string `db:"extversion"`
+}
+
+// CollectorStatus indica se um coletor é suportado pelo target e qual variante de SQL é usada
+type CollectorStatus struct {
+	Name      string `json:"name"`             // Nome do coletor
+	Supported bool   `json:"supported"`        // Coletor disponível no target
+	Variant   string `json:"variant"`          // Variante de SQL escolhida
+	Reason    string `json:"reason,omitempty"` // Motivo quando não suportado
+}
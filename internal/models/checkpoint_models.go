@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// CheckpointStats representa os contadores cumulativos de checkpoints, bgwriter e WAL.
+// A origem depende da versão: pg_stat_bgwriter até o PostgreSQL 16, pg_stat_checkpointer a partir
+// do 17, e pg_stat_wal a partir do 14 (antes disso o volume de WAL vem da posição do LSN).
+type CheckpointStats struct {
+	ServerVersionNum      int        `json:"server_version_num" db:"-"`                              // server_version_num da instância
+	CheckpointsTimed      int64      `json:"checkpoints_timed" db:"checkpoints_timed"`               // Checkpoints por checkpoint_timeout
+	CheckpointsReq        int64      `json:"checkpoints_req" db:"checkpoints_req"`                   // Checkpoints solicitados (max_wal_size, CHECKPOINT)
+	CheckpointWriteTimeMs float64    `json:"checkpoint_write_time_ms" db:"checkpoint_write_time_ms"` // Tempo escrevendo buffers em checkpoints
+	CheckpointSyncTimeMs  float64    `json:"checkpoint_sync_time_ms" db:"checkpoint_sync_time_ms"`   // Tempo em fsync de checkpoints
+	BuffersCheckpoint     int64      `json:"buffers_checkpoint" db:"buffers_checkpoint"`             // Buffers escritos por checkpoints
+	BuffersClean          int64      `json:"buffers_clean" db:"buffers_clean"`                       // Buffers escritos pelo bgwriter
+	MaxwrittenClean       int64      `json:"maxwritten_clean" db:"maxwritten_clean"`                 // Paradas do bgwriter por bgwriter_lru_maxpages
+	BuffersBackend        *int64     `json:"buffers_backend" db:"buffers_backend"`                   // Buffers escritos pelos backends (até o PostgreSQL 16)
+	BackendFsyncs         int64      `json:"backend_fsyncs" db:"backend_fsyncs"`                     // fsyncs feitos pelos próprios backends
+	BuffersAlloc          int64      `json:"buffers_alloc" db:"buffers_alloc"`                       // Buffers alocados
+	WALBytes              int64      `json:"wal_bytes" db:"wal_bytes"`                               // WAL gerado em bytes
+	WALRecords            *int64     `json:"wal_records" db:"wal_records"`                           // Registros de WAL (PostgreSQL 14+)
+	WALFPI                *int64     `json:"wal_fpi" db:"wal_fpi"`                                   // Full-page images (PostgreSQL 14+)
+	WALBuffersFull        *int64     `json:"wal_buffers_full" db:"wal_buffers_full"`                 // Escritas por wal_buffers cheio (PostgreSQL 14+)
+	WALSource             string     `json:"wal_source" db:"wal_source"`                             // pg_stat_wal ou lsn
+	StatsReset            *time.Time `json:"stats_reset" db:"stats_reset"`                           // Último reset dos contadores de checkpoint
+	CollectedAt           time.Time  `json:"collected_at" db:"collected_at"`                         // Momento da coleta no servidor
+}
+
+// CheckpointRates representa taxas entre duas amostras de CheckpointStats
+type CheckpointRates struct {
+	IntervalSeconds            float64  `json:"interval_seconds"`              // Intervalo entre as amostras
+	CounterReset               bool     `json:"counter_reset"`                 // Contadores foram resetados no intervalo
+	WALBytesPerSecond          float64  `json:"wal_bytes_per_second"`          // WAL gerado por segundo
+	WALRecordsPerSecond        *float64 `json:"wal_records_per_second"`        // Registros de WAL por segundo
+	WALFPIPerSecond            *float64 `json:"wal_fpi_per_second"`            // Full-page images por segundo
+	FPIPercent                 *float64 `json:"fpi_percent"`                   // Full-page images em relação aos registros
+	CheckpointsPerHour         float64  `json:"checkpoints_per_hour"`          // Checkpoints por hora
+	RequestedPercent           float64  `json:"requested_percent"`             // Checkpoints solicitados em relação ao total
+	BuffersCheckpointPerSecond float64  `json:"buffers_checkpoint_per_second"` // Buffers escritos por checkpoints por segundo
+	BuffersCleanPerSecond      float64  `json:"buffers_clean_per_second"`      // Buffers escritos pelo bgwriter por segundo
+	BuffersBackendPerSecond    *float64 `json:"buffers_backend_per_second"`    // Buffers escritos pelos backends por segundo
+	BackendFsyncsDelta         int64    `json:"backend_fsyncs_delta"`          // fsyncs de backends no intervalo
+	MaxwrittenCleanDelta       int64    `json:"maxwritten_clean_delta"`        // Paradas do bgwriter no intervalo
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ConfigEntry representa uma chave de system_config
+// @Description Configuração do sistema editável em tempo de execução
+type ConfigEntry struct {
+	Key         string    `json:"key" db:"config_key" example:"auth.max_failed_login_attempts"`         // Chave
+	Value       string    `json:"value" db:"config_value" example:"5"`                                  // Valor (mascarado se sensível)
+	Type        string    `json:"type" db:"config_type" example:"number"`                               // string, number, boolean ou json
+	Description string    `json:"description" db:"description" example:"Máximo de tentativas de login"` // Descrição
+	IsSensitive bool      `json:"is_sensitive" db:"is_sensitive" example:"false"`                       // Valor sensível
+	UpdatedBy   *string   `json:"updated_by,omitempty" db:"updated_by"`                                 // Último usuário a alterar
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`                                           // Data de atualização
+}
+
+// ConfigUpdateRequest representa a alteração de uma chave de system_config
+// @Description Novo valor de uma configuração
+type ConfigUpdateRequest struct {
+	Value string `json:"value" example:"10"` // Novo valor, validado conforme o tipo da chave
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ExplainRequest representa um pedido de captura de plano sob demanda.
+// Informe queryid (texto lido de pg_stat_statements) ou query.
+type ExplainRequest struct {
+	QueryID     *int64 `json:"queryid" example:"-4123456789012345678"`             // queryid de pg_stat_statements
+	Query       string `json:"query" example:"SELECT * FROM orders WHERE id = $1"` // Texto da query
+	Analyze     bool   `json:"analyze" example:"false"`                            // Executar com ANALYZE (somente SELECT, requer admin)
+	Buffers     bool   `json:"buffers" example:"true"`                             // Incluir uso de buffers
+	GenericPlan bool   `json:"generic_plan" example:"false"`                       // Planejar sem valores para os parâmetros $n
+	TimeoutMs   int    `json:"timeout_ms" example:"10000"`                         // statement_timeout da captura
+}
+
+// ExplainOptions define como o EXPLAIN é executado no target
+type ExplainOptions struct {
+	Analyze     bool `json:"analyze"`      // EXPLAIN ANALYZE
+	Buffers     bool `json:"buffers"`      // EXPLAIN BUFFERS
+	GenericPlan bool `json:"generic_plan"` // Plano genérico para textos com parâmetros
+	TimeoutMs   int  `json:"timeout_ms"`   // statement_timeout em ms
+}
+
+// ExplainOutput é o resultado bruto de um EXPLAIN (FORMAT JSON) no target
+type ExplainOutput struct {
+	DatabaseName string          // current_database() do target
+	Username     string          // current_user da conexão
+	Plan         json.RawMessage // Saída do EXPLAIN
+}
+
+// QueryPlan representa um plano capturado e gravado em slow_queries_log
+type QueryPlan struct {
+	ID              string          `json:"id" db:"id"`                               // ID da linha em slow_queries_log
+	TargetName      *string         `json:"target_name" db:"target_name"`             // Target de origem (nulo = banco local)
+	DatabaseName    string          `json:"database_name" db:"database_name"`         // Banco onde o plano foi capturado
+	Username        string          `json:"username" db:"username"`                   // Usuário da captura
+	QueryID         *int64          `json:"queryid" db:"queryid"`                     // queryid de pg_stat_statements, quando informado
+	QueryHash       string          `json:"query_hash" db:"query_hash"`               // md5 do texto
+	QueryText       string          `json:"query_text" db:"query_text"`               // Texto planejado
+	Analyze         bool            `json:"analyze" db:"analyze"`                     // Capturado com ANALYZE
+	Buffers         bool            `json:"buffers" db:"buffers"`                     // Capturado com BUFFERS
+	GenericPlan     bool            `json:"generic_plan" db:"generic_plan"`           // Plano genérico
+	PlanningTimeMs  *float64        `json:"planning_time_ms" db:"planning_time_ms"`   // Tempo de planejamento (ANALYZE)
+	ExecutionTimeMs *float64        `json:"execution_time_ms" db:"execution_time_ms"` // Tempo de execução (ANALYZE)
+	Plan            json.RawMessage `json:"plan" db:"plan"`                           // Saída do EXPLAIN (FORMAT JSON)
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`               // Momento da captura
+}
+
+// QueryPlanQuery filtra os planos gravados
+type QueryPlanQuery struct {
+	Target    string // Target monitorado (vazio = banco local)
+	QueryHash string // md5 do texto (vazio = todos)
+	QueryID   *int64 // queryid (nulo = todos)
+	Limit     int    // Máximo de planos retornados
+}
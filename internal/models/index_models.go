@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipos de recomendação do consultor de índices
+const (
+	IndexFindingUnused    = "unused"           // Índice sem scans desde o último reset de estatísticas
+	IndexFindingDuplicate = "duplicate"        // Índice idêntico a outro da mesma tabela
+	IndexFindingPrefix    = "redundant_prefix" // Colunas do índice são prefixo à esquerda de outro índice
+	IndexFindingInvalid   = "invalid"          // Índice inválido (CREATE INDEX CONCURRENTLY interrompido)
+	IndexFindingMissing   = "missing"          // Tabela grande dominada por sequential scans
+)
+
+// IndexDefinition representa um índice de pg_index com seu uso em pg_stat_user_indexes
+type IndexDefinition struct {
+	SchemaName   string     `json:"schema_name" db:"schema_name"`     // Schema da tabela
+	TableName    string     `json:"table_name" db:"table_name"`       // Tabela indexada
+	IndexName    string     `json:"index_name" db:"index_name"`       // Nome do índice
+	AccessMethod string     `json:"access_method" db:"access_method"` // btree, hash, gin, gist...
+	Definition   string     `json:"definition" db:"definition"`       // Resultado de pg_get_indexdef
+	Columns      string     `json:"columns" db:"columns"`             // indkey (números das colunas, 0 = expressão)
+	KeyColumns   int        `json:"key_columns" db:"key_columns"`     // Colunas de chave (exclui INCLUDE)
+	OpClasses    string     `json:"op_classes" db:"op_classes"`       // indclass (operator classes)
+	Expressions  string     `json:"expressions" db:"expressions"`     // Expressões indexadas
+	Predicate    string     `json:"predicate" db:"predicate"`         // Predicado de índice parcial
+	IsUnique     bool       `json:"is_unique" db:"is_unique"`         // Índice único
+	IsPrimary    bool       `json:"is_primary" db:"is_primary"`       // Índice da chave primária
+	IsValid      bool       `json:"is_valid" db:"is_valid"`           // indisvalid
+	IsConstraint bool       `json:"is_constraint" db:"is_constraint"` // Sustenta PRIMARY KEY, UNIQUE ou EXCLUDE
+	SizeBytes    int64      `json:"size_bytes" db:"size_bytes"`       // Tamanho do índice em bytes
+	IdxScan      int64      `json:"idx_scan" db:"idx_scan"`           // Scans desde o último reset
+	StatsReset   *time.Time `json:"stats_reset" db:"stats_reset"`     // Último reset das estatísticas do banco
+}
+
+// IndexFinding representa uma recomendação do consultor de índices
+type IndexFinding struct {
+	Type            string `json:"type"`                    // unused, duplicate, redundant_prefix, invalid, missing
+	SchemaName      string `json:"schema_name"`             // Schema da tabela
+	TableName       string `json:"table_name"`              // Tabela
+	IndexName       string `json:"index_name,omitempty"`    // Índice recomendado para remoção
+	RelatedIndex    string `json:"related_index,omitempty"` // Índice que torna o índice redundante
+	Reason          string `json:"reason"`                  // Explicação da recomendação
+	Suggestion      string `json:"suggestion"`              // Comando sugerido
+	SizeImpactBytes int64  `json:"size_impact_bytes"`       // Impacto estimado em disco (negativo = espaço liberado)
+}
+
+// IndexAdvisorOptions define os limites usados para recomendar novos índices
+type IndexAdvisorOptions struct {
+	MinTableBytes     int64 `json:"min_table_bytes"`       // Tamanho mínimo da tabela para sugerir índice
+	MinSeqScans       int64 `json:"min_seq_scans"`         // Sequential scans mínimos
+	MinRowsPerSeqScan int64 `json:"min_rows_per_seq_scan"` // Média mínima de linhas lidas por sequential scan
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// LockBackend representa um backend envolvido em uma cadeia de bloqueio
+type LockBackend struct {
+	PID             int        `json:"pid" db:"pid"`                           // PID do backend
+	DatabaseName    string     `json:"database_name" db:"database_name"`       // Banco conectado
+	Username        string     `json:"username" db:"username"`                 // Usuário
+	ApplicationName string     `json:"application_name" db:"application_name"` // Nome da aplicação
+	ClientAddr      *string    `json:"client_addr" db:"client_addr"`           // Endereço do cliente
+	State           string     `json:"state" db:"state"`                       // Estado do backend
+	WaitEventType   *string    `json:"wait_event_type" db:"wait_event_type"`   // Tipo do wait event
+	WaitEvent       *string    `json:"wait_event" db:"wait_event"`             // Wait event
+	Query           string     `json:"query" db:"query"`                       // Texto da query
+	XactStart       *time.Time `json:"xact_start" db:"xact_start"`             // Início da transação
+	QueryStart      *time.Time `json:"query_start" db:"query_start"`           // Início da query atual
+	XactDurationMs  float64    `json:"xact_duration_ms" db:"xact_duration_ms"` // Duração da transação em ms
+	LockType        *string    `json:"lock_type" db:"lock_type"`               // Tipo do lock aguardado (relation, tuple, transactionid...)
+	LockMode        *string    `json:"lock_mode" db:"lock_mode"`               // Modo do lock aguardado
+	Relation        *string    `json:"relation" db:"relation"`                 // Relação do lock aguardado
+	WaitDurationMs  float64    `json:"wait_duration_ms" db:"wait_duration_ms"` // Tempo aguardando o lock em ms
+	BlockedBy       []int      `json:"blocked_by" db:"-"`                      // PIDs que bloqueiam este backend (pg_blocking_pids)
+}
+
+// LockNode é um nó da árvore de bloqueio: o backend e os backends que aguardam por ele
+type LockNode struct {
+	LockBackend
+	Blocking []*LockNode `json:"blocking"` // Backends bloqueados por este
+}
+
+// LockTree representa a árvore de bloqueio a partir dos bloqueadores raiz
+type LockTree struct {
+	Roots        []*LockNode `json:"roots"`         // Bloqueadores raiz (não aguardam nenhum outro backend)
+	WaitingCount int         `json:"waiting_count"` // Backends aguardando lock
+	BlockerCount int         `json:"blocker_count"` // Bloqueadores raiz
+	MaxWaitMs    float64     `json:"max_wait_ms"`   // Maior espera atual em ms
+	CollectedAt  time.Time   `json:"collected_at"`  // Momento da coleta
+}
+
+// LockSnapshot representa uma árvore de bloqueio gravada em lock_snapshots_log
+type LockSnapshot struct {
+	ID           string          `json:"id" db:"id"`                       // ID do snapshot
+	TargetName   *string         `json:"target_name" db:"target_name"`     // Target de origem (nulo = banco local)
+	MaxWaitMs    float64         `json:"max_wait_ms" db:"max_wait_ms"`     // Maior espera no momento da gravação
+	WaitingCount int             `json:"waiting_count" db:"waiting_count"` // Backends aguardando lock
+	BlockerCount int             `json:"blocker_count" db:"blocker_count"` // Bloqueadores raiz
+	Tree         json.RawMessage `json:"tree" db:"tree"`                   // Árvore gravada
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`       // Momento da gravação
+}
+
+// LockSnapshotQuery filtra os snapshots de bloqueio gravados
+type LockSnapshotQuery struct {
+	Target string    `json:"target,omitempty"` // Target monitorado (vazio = todos)
+	From   time.Time `json:"from"`             // Início do período
+	To     time.Time `json:"to"`               // Fim do período
+	Limit  int       `json:"limit"`            // Máximo de snapshots retornados
+}
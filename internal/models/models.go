@@ -5,39 +5,121 @@ import (
     "github.com/golang-jwt/jwt/v5"
 )
 
+// Papéis aceitos em users.role
+const (
+    RoleAdmin    = "admin"
+    RoleUser     = "user"
+    RoleReadonly = "readonly"
+)
+
 // User representa um usuário do sistema
 // @Description Usuário do sistema PG Analytics
 type User struct {
-    ID        int       `json:"id" example:"1"`                                    // ID único do usuário
-    Username  string    `json:"username" example:"admin"`                          // Nome de usuário
-    Email     string    `json:"email" example:"admin@pganalytics.com"`            // Email do usuário
-    Password  string    `json:"-"`                                                 // Senha (não exposta)
-    Role      string    `json:"role" example:"admin"`                              // Papel do usuário
-    CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`        // Data de criação
-    UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`        // Data de atualização
+    ID                  string     `json:"id" db:"id" example:"5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23"`    // ID único do usuário
+    Name                string     `json:"name" db:"name" example:"Administrator"`                       // Nome do usuário
+    Email               string     `json:"email" db:"email" example:"admin@pganalytics.com"`             // Email do usuário
+    PasswordHash        string     `json:"-" db:"password_hash"`                                         // Hash bcrypt da senha (não exposto)
+    Role                string     `json:"role" db:"role" example:"admin"`                               // Papel do usuário
+    EmailVerified       bool       `json:"email_verified" db:"email_verified" example:"true"`            // Email verificado
+    IsActive            bool       `json:"is_active" db:"is_active" example:"true"`                      // Usuário ativo
+    LastLoginAt         *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`                   // Último login bem-sucedido
+    FailedLoginAttempts int        `json:"failed_login_attempts" db:"failed_login_attempts" example:"0"` // Tentativas falhadas consecutivas
+    AccountLockedUntil  *time.Time `json:"account_locked_until,omitempty" db:"account_locked_until"`     // Bloqueio temporário da conta
+    CreatedAt           time.Time  `json:"created_at" db:"created_at" example:"2024-01-01T00:00:00Z"`    // Data de criação
+    UpdatedAt           time.Time  `json:"updated_at" db:"updated_at" example:"2024-01-01T00:00:00Z"`    // Data de atualização
+}
+
+// UserCreateRequest representa o cadastro de um usuário por um administrador
+// @Description Dados para criar um usuário
+type UserCreateRequest struct {
+    Email    string `json:"email" binding:"required,email,max=255" example:"analyst@pganalytics.com"` // Email (login)
+    Name     string `json:"name" binding:"required,max=100" example:"Data Analyst"`                    // Nome
+    Password string `json:"password" binding:"required" example:"Str0ngPass"`                          // Senha inicial
+    Role     string `json:"role" binding:"required,oneof=admin user readonly" example:"user"`          // Papel
+}
+
+// UserUpdateRequest representa a alteração parcial de um usuário
+// @Description Campos omitidos não são alterados
+type UserUpdateRequest struct {
+    Email         *string `json:"email" binding:"omitempty,email,max=255" example:"analyst@pganalytics.com"` // Email (login)
+    Name          *string `json:"name" binding:"omitempty,max=100" example:"Data Analyst"`                   // Nome
+    EmailVerified *bool   `json:"email_verified" example:"true"`                                            // Email verificado
 }
 
-// Claims para JWT
+// RoleUpdateRequest representa a troca de papel de um usuário
+// @Description Novo papel do usuário
+type RoleUpdateRequest struct {
+    Role string `json:"role" binding:"required,oneof=admin user readonly" example:"readonly"` // Papel
+}
+
+// Claims para JWT. O papel não vai no token: AuthMiddleware o lê do cadastro a cada requisição.
 type Claims struct {
-    UserID int    `json:"user_id"`
+    UserID string `json:"user_id"`
     Email  string `json:"email"`
-    Role   string `json:"role"`
     jwt.RegisteredClaims
 }
 
 // LoginRequest representa uma requisição de login
 // @Description Dados necessários para autenticação
 type LoginRequest struct {
-    Username string `json:"username" binding:"required" example:"admin"`      // Nome de usuário ou email
+    Username string `json:"username" binding:"required" example:"admin@pganalytics.com"` // Email do usuário
     Password string `json:"password" binding:"required" example:"admin123"`   // Senha do usuário
 }
 
 // LoginResponse representa a resposta de um login bem-sucedido
-// @Description Resposta da autenticação com token JWT
+// @Description Resposta da autenticação com access token JWT e refresh token
 type LoginResponse struct {
-    Token     string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`  // Token JWT
-    ExpiresIn int    `json:"expires_in" example:"86400"`                                    // Tempo de expiração em segundos
-    User      string `json:"user" example:"admin@pganalytics.com"`                         // Email do usuário
+    Token            string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`  // Access token JWT
+    TokenType        string `json:"token_type" example:"Bearer"`                                   // Tipo do token
+    ExpiresIn        int    `json:"expires_in" example:"900"`                                      // Validade do access token em segundos
+    RefreshToken     string `json:"refresh_token" example:"q9Xw3k...Zt0"`                          // Refresh token (uso único)
+    RefreshExpiresIn int    `json:"refresh_expires_in" example:"604800"`                           // Validade do refresh token em segundos
+    User             string `json:"user" example:"admin@pganalytics.com"`                         // Email do usuário
+}
+
+// RefreshRequest representa uma requisição de rotação ou revogação de refresh token
+// @Description Refresh token recebido no login ou na última rotação
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required" example:"q9Xw3k...Zt0"` // Refresh token
+}
+
+// LogoutRequest representa uma requisição de logout
+// @Description Refresh token da sessão a encerrar
+type LogoutRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required" example:"q9Xw3k...Zt0"` // Refresh token
+    AllSessions  bool   `json:"all_sessions" example:"false"`                             // Encerrar todas as sessões do usuário
+}
+
+// ForgotPasswordRequest representa uma solicitação de redefinição de senha
+// @Description Email da conta que receberá o link de redefinição
+type ForgotPasswordRequest struct {
+    Email string `json:"email" binding:"required,email" example:"admin@pganalytics.com"` // Email da conta
+}
+
+// ResetPasswordRequest representa a redefinição de senha com o token recebido por email
+// @Description Token de redefinição e nova senha
+type ResetPasswordRequest struct {
+    Token    string `json:"token" binding:"required" example:"q9Xw3k...Zt0"`   // Token recebido por email
+    Password string `json:"password" binding:"required" example:"NovaSenha123"` // Nova senha
+}
+
+// RefreshToken representa um refresh token gravado (apenas o hash)
+type RefreshToken struct {
+    ID        string     `db:"id"`
+    UserID    string     `db:"user_id"`
+    FamilyID  string     `db:"family_id"`
+    TokenHash string     `db:"token_hash"`
+    ExpiresAt time.Time  `db:"expires_at"`
+    RevokedAt *time.Time `db:"revoked_at"`
+    IPAddress *string    `db:"ip_address"`
+    UserAgent string     `db:"user_agent"`
+    CreatedAt time.Time  `db:"created_at"`
+}
+
+// ClientInfo identifica a origem de uma requisição de autenticação
+type ClientInfo struct {
+    IPAddress string
+    UserAgent string
 }
 
 // ErrorResponse representa uma resposta de erro
@@ -46,6 +128,14 @@ type ErrorResponse struct {
     Error string `json:"error" example:"Invalid credentials"`  // Mensagem de erro
 }
 
+// LockoutResponse representa a resposta de um login bloqueado
+// @Description Conta (423) ou IP (429) temporariamente bloqueado por excesso de falhas
+type LockoutResponse struct {
+    Error       string    `json:"error" example:"Account temporarily locked"` // Mensagem de erro
+    LockedUntil time.Time `json:"locked_until" example:"2024-01-01T00:15:00Z"` // Fim do bloqueio
+    RetryAfter  int       `json:"retry_after" example:"900"`                    // Segundos até nova tentativa
+}
+
 // HealthResponse representa a resposta do health check
 // @Description Status de saúde da API
 type HealthResponse struct {
@@ -72,7 +162,7 @@ type MetricsResponse struct {
 // ProfileResponse representa o perfil do usuário
 // @Description Dados do perfil do usuário autenticado
 type ProfileResponse struct {
-    UserID  int    `json:"user_id" example:"1"`                          // ID do usuário
+    UserID  string `json:"user_id" example:"5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23"` // ID do usuário
     Email   string `json:"email" example:"admin@pganalytics.com"`        // Email do usuário
     Role    string `json:"role" example:"admin"`                         // Papel do usuário
     Message string `json:"message" example:"Profile data"`               // Mensagem
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Tipos de canal de notificação
+const (
+	ChannelTypeWebhook   = "webhook"
+	ChannelTypeSlack     = "slack"
+	ChannelTypeEmail     = "email"
+	ChannelTypePagerDuty = "pagerduty"
+)
+
+// ChannelSettings guarda a configuração específica do tipo de canal, gravada como JSONB.
+// Segredos são referências env:VAR ou file:/caminho, resolvidas a cada envio.
+type ChannelSettings struct {
+	URL           string            `json:"url,omitempty" example:"https://hooks.example.com/pganalytics"` // webhook e slack; pagerduty: endpoint alternativo
+	URLRef        string            `json:"url_ref,omitempty" example:"env:PGA_SLACK_WEBHOOK"`             // slack: referência para a URL do incoming webhook
+	SecretRef     string            `json:"secret_ref,omitempty" example:"env:PGA_WEBHOOK_SECRET"`         // webhook: segredo da assinatura HMAC
+	Headers       map[string]string `json:"headers,omitempty"`                                             // webhook: cabeçalhos extras
+	Channel       string            `json:"channel,omitempty" example:"#dba"`                              // slack: canal de destino
+	Username      string            `json:"username,omitempty" example:"pgAnalytics"`                      // slack: nome exibido
+	To            []string          `json:"to,omitempty"`                                                  // email: destinatários
+	RoutingKeyRef string            `json:"routing_key_ref,omitempty" example:"env:PGA_PAGERDUTY_KEY"`     // pagerduty: integration key
+}
+
+// Value implementa driver.Valuer
+func (s ChannelSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implementa sql.Scanner
+func (s *ChannelSettings) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = ChannelSettings{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("tipo não suportado para configuração de canal: %T", src)
+	}
+	return json.Unmarshal(data, s)
+}
+
+// NotificationChannel representa um canal gravado em notification_channels
+// @Description Canal que recebe as notificações de alerta
+type NotificationChannel struct {
+	ID                    string          `json:"id" db:"id"`                                                           // ID do canal
+	Name                  string          `json:"name" db:"name" example:"dba-slack"`                                   // Nome único
+	Type                  string          `json:"type" db:"type" example:"slack"`                                       // webhook, slack, email ou pagerduty
+	Settings              ChannelSettings `json:"settings" db:"settings"`                                               // Configuração do tipo
+	TitleTemplate         string          `json:"title_template" db:"title_template"`                                   // text/template do título (vazio = padrão)
+	BodyTemplate          string          `json:"body_template" db:"body_template"`                                     // text/template do corpo (vazio = padrão)
+	GroupBy               pq.StringArray  `json:"group_by" db:"group_by" swaggertype:"array,string"`                    // target, source, event_key, severity
+	MinSeverity           string          `json:"min_severity" db:"min_severity" example:"warning"`                     // Severidade mínima notificada
+	GroupWaitSeconds      int             `json:"group_wait_seconds" db:"group_wait_seconds" example:"30"`              // Espera para agrupar eventos
+	RepeatIntervalSeconds int             `json:"repeat_interval_seconds" db:"repeat_interval_seconds" example:"14400"` // Janela de deduplicação
+	MaxAttempts           int             `json:"max_attempts" db:"max_attempts" example:"5"`                           // Tentativas de entrega
+	Enabled               bool            `json:"enabled" db:"enabled" example:"true"`                                  // Canal habilitado
+	CreatedAt             time.Time       `json:"created_at" db:"created_at"`                                           // Data de criação
+	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`                                           // Data de atualização
+}
+
+// NotificationChannelRequest representa o corpo de criação/atualização de um canal
+// @Description Dados para cadastrar um canal de notificação
+type NotificationChannelRequest struct {
+	Name                  string          `json:"name" binding:"required,max=100" example:"dba-slack"` // Nome único
+	Type                  string          `json:"type" binding:"required" example:"slack"`             // webhook, slack, email ou pagerduty
+	Settings              ChannelSettings `json:"settings"`                                            // Configuração do tipo
+	TitleTemplate         string          `json:"title_template"`                                      // text/template do título (vazio = padrão)
+	BodyTemplate          string          `json:"body_template"`                                       // text/template do corpo (vazio = padrão)
+	GroupBy               []string        `json:"group_by"`                                            // Padrão: target, source, event_key
+	MinSeverity           string          `json:"min_severity" example:"warning"`                      // Padrão: info
+	GroupWaitSeconds      *int            `json:"group_wait_seconds" example:"30"`                     // Padrão: 30
+	RepeatIntervalSeconds *int            `json:"repeat_interval_seconds" example:"14400"`             // Padrão: 14400 (4h)
+	MaxAttempts           int             `json:"max_attempts" example:"5"`                            // Padrão: 5
+	Enabled               *bool           `json:"enabled" example:"true"`                              // Padrão: true
+}
+
+// NotificationFailure representa uma notificação descartada após esgotar as tentativas de
+// entrega, gravada em notification_failures
+type NotificationFailure struct {
+	ID        string          `json:"id" db:"id"`                 // ID da falha
+	ChannelID string          `json:"channel_id" db:"channel_id"` // Canal de destino
+	GroupKey  string          `json:"group_key" db:"group_key"`   // Grupo da notificação
+	DedupKey  string          `json:"dedup_key" db:"dedup_key"`   // Chave de deduplicação do grupo
+	Status    string          `json:"status" db:"status"`         // firing ou resolved
+	Error     string          `json:"error" db:"error"`           // Último erro da entrega
+	Payload   json.RawMessage `json:"payload" db:"payload"`       // Notificação completa
+	CreatedAt time.Time       `json:"created_at" db:"created_at"` // Data da falha
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Tipos de achado do detector de regressões
+const (
+	RegressionKindLatency     = "latency_regression" // Latência média ou p95 acima do fator sobre a linha de base
+	RegressionKindNewDominant = "new_dominant"       // Query sem linha de base que domina o tempo total recente
+)
+
+// QueryWindowStats agrega os intervalos entre snapshots de slow_queries_log de uma query normalizada
+// na linha de base e na janela recente. As latências são calculadas a partir dos deltas de
+// total_time_ms e calls entre snapshots consecutivos.
+type QueryWindowStats struct {
+	QueryHash       string   `json:"query_hash" db:"query_hash"`             // md5 do texto normalizado
+	QueryText       string   `json:"query_text" db:"query_text"`             // Texto normalizado (último snapshot)
+	DatabaseName    string   `json:"database_name" db:"database_name"`       // Banco (último snapshot)
+	BaselineCalls   int64    `json:"baseline_calls" db:"baseline_calls"`     // Execuções na linha de base
+	BaselineTimeMs  float64  `json:"baseline_time_ms" db:"baseline_time_ms"` // Tempo total na linha de base
+	BaselineP95Ms   *float64 `json:"baseline_p95_ms" db:"baseline_p95_ms"`   // p95 das médias por intervalo na linha de base
+	BaselineSamples int      `json:"baseline_samples" db:"baseline_samples"` // Intervalos na linha de base
+	RecentCalls     int64    `json:"recent_calls" db:"recent_calls"`         // Execuções na janela recente
+	RecentTimeMs    float64  `json:"recent_time_ms" db:"recent_time_ms"`     // Tempo total na janela recente
+	RecentP95Ms     *float64 `json:"recent_p95_ms" db:"recent_p95_ms"`       // p95 das médias por intervalo na janela recente
+	RecentSamples   int      `json:"recent_samples" db:"recent_samples"`     // Intervalos na janela recente
+}
+
+// RegressionOptions define os limites do detector de regressões
+type RegressionOptions struct {
+	Factor          float64       `json:"factor"`           // Aumento mínimo (ex: 2 = dobro) da média ou do p95
+	DominantPercent float64       `json:"dominant_percent"` // Percentual do tempo recente que sinaliza uma query nova
+	MinCalls        int64         `json:"min_calls"`        // Execuções mínimas em cada janela para comparar latências
+	Window          time.Duration `json:"-"`                // Tamanho da janela recente
+	Baseline        time.Duration `json:"-"`                // Tamanho da linha de base anterior à janela recente
+}
+
+// QueryRegression representa uma query sinalizada pelo detector
+type QueryRegression struct {
+	Kind           string   `json:"kind"`                       // latency_regression ou new_dominant
+	Severity       string   `json:"severity"`                   // warning ou critical
+	QueryHash      string   `json:"query_hash"`                 // md5 do texto normalizado
+	QueryText      string   `json:"query_text"`                 // Texto normalizado
+	DatabaseName   string   `json:"database_name"`              // Banco
+	BaselineMeanMs *float64 `json:"baseline_mean_ms,omitempty"` // Latência média na linha de base
+	RecentMeanMs   float64  `json:"recent_mean_ms"`             // Latência média na janela recente
+	BaselineP95Ms  *float64 `json:"baseline_p95_ms,omitempty"`  // p95 na linha de base
+	RecentP95Ms    *float64 `json:"recent_p95_ms,omitempty"`    // p95 na janela recente
+	MeanRatio      *float64 `json:"mean_ratio,omitempty"`       // Média recente / média da linha de base
+	P95Ratio       *float64 `json:"p95_ratio,omitempty"`        // p95 recente / p95 da linha de base
+	RecentCalls    int64    `json:"recent_calls"`               // Execuções na janela recente
+	RecentTimeMs   float64  `json:"recent_time_ms"`             // Tempo total na janela recente
+	TimePercent    float64  `json:"time_percent"`               // Participação no tempo total da janela recente
+}
+
+// QueryRegressionReport é o resultado de uma execução do detector
+type QueryRegressionReport struct {
+	Options      RegressionOptions `json:"options"`        // Limites aplicados
+	BaselineFrom time.Time         `json:"baseline_from"`  // Início da linha de base
+	RecentFrom   time.Time         `json:"recent_from"`    // Início da janela recente
+	To           time.Time         `json:"to"`             // Fim da janela recente
+	QueriesSeen  int               `json:"queries_seen"`   // Queries com snapshots no período
+	RecentTimeMs float64           `json:"recent_time_ms"` // Tempo total de todas as queries na janela recente
+	Regressions  []QueryRegression `json:"regressions"`    // Queries sinalizadas, por tempo recente decrescente
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Estados de saúde da replicação
+const (
+	ReplicationHealthy  = "healthy"
+	ReplicationWarning  = "warning"
+	ReplicationCritical = "critical"
+)
+
+// ReplicationNode representa o papel e a posição de WAL da instância consultada
+type ReplicationNode struct {
+	InRecovery         bool       `json:"in_recovery" db:"in_recovery"`                   // pg_is_in_recovery()
+	CurrentLSN         *string    `json:"current_lsn" db:"current_lsn"`                   // LSN atual (primary) ou último aplicado (standby)
+	ReceiveLSN         *string    `json:"receive_lsn" db:"receive_lsn"`                   // Último LSN recebido (standby)
+	ReplayLSN          *string    `json:"replay_lsn" db:"replay_lsn"`                     // Último LSN aplicado (standby)
+	LastReplayAt       *time.Time `json:"last_replay_at" db:"last_replay_at"`             // Timestamp da última transação aplicada (standby)
+	ReplayDelaySeconds *float64   `json:"replay_delay_seconds" db:"replay_delay_seconds"` // Atraso de aplicação no standby
+	ReplayLagBytes     *int64     `json:"replay_lag_bytes" db:"replay_lag_bytes"`         // WAL recebido ainda não aplicado (standby)
+}
+
+// ReplicationStandby representa um standby conectado ao primary (pg_stat_replication)
+type ReplicationStandby struct {
+	PID             int        `json:"pid" db:"pid"`                           // PID do walsender
+	ApplicationName string     `json:"application_name" db:"application_name"` // application_name do standby
+	ClientAddr      *string    `json:"client_addr" db:"client_addr"`           // Endereço do standby
+	State           string     `json:"state" db:"state"`                       // startup, catchup, streaming, backup, stopping
+	SyncState       string     `json:"sync_state" db:"sync_state"`             // async, potential, sync, quorum
+	SentLSN         *string    `json:"sent_lsn" db:"sent_lsn"`                 // Último LSN enviado
+	WriteLSN        *string    `json:"write_lsn" db:"write_lsn"`               // Último LSN escrito no standby
+	FlushLSN        *string    `json:"flush_lsn" db:"flush_lsn"`               // Último LSN com flush no standby
+	ReplayLSN       *string    `json:"replay_lsn" db:"replay_lsn"`             // Último LSN aplicado no standby
+	WriteLagMs      *float64   `json:"write_lag_ms" db:"write_lag_ms"`         // write_lag em ms
+	FlushLagMs      *float64   `json:"flush_lag_ms" db:"flush_lag_ms"`         // flush_lag em ms
+	ReplayLagMs     *float64   `json:"replay_lag_ms" db:"replay_lag_ms"`       // replay_lag em ms
+	SentLagBytes    int64      `json:"sent_lag_bytes" db:"sent_lag_bytes"`     // WAL gerado ainda não enviado
+	ReplayLagBytes  int64      `json:"replay_lag_bytes" db:"replay_lag_bytes"` // WAL gerado ainda não aplicado
+	BackendStart    *time.Time `json:"backend_start" db:"backend_start"`       // Conexão do standby
+	Health          string     `json:"health" db:"-"`                          // healthy, warning ou critical
+	Issues          []string   `json:"issues" db:"-"`                          // Motivos do estado de saúde
+}
+
+// WalReceiver representa o receptor de WAL de um standby (pg_stat_wal_receiver)
+type WalReceiver struct {
+	PID                int        `json:"pid" db:"pid"`                                     // PID do walreceiver
+	Status             string     `json:"status" db:"status"`                               // Estado do receptor
+	SenderHost         *string    `json:"sender_host" db:"sender_host"`                     // Primary de origem
+	SenderPort         *int       `json:"sender_port" db:"sender_port"`                     // Porta do primary
+	SlotName           *string    `json:"slot_name" db:"slot_name"`                         // Slot usado no primary
+	ReceivedLSN        *string    `json:"received_lsn" db:"received_lsn"`                   // Último LSN recebido com flush
+	LatestEndLSN       *string    `json:"latest_end_lsn" db:"latest_end_lsn"`               // Último LSN informado pelo primary
+	LastMsgReceiptTime *time.Time `json:"last_msg_receipt_time" db:"last_msg_receipt_time"` // Última mensagem recebida
+	ReceiptDelayMs     *float64   `json:"receipt_delay_ms" db:"receipt_delay_ms"`           // Tempo desde a última mensagem
+}
+
+// ReplicationSlot representa um slot de replicação e o WAL retido por ele
+type ReplicationSlot struct {
+	SlotName         string  `json:"slot_name" db:"slot_name"`                   // Nome do slot
+	SlotType         string  `json:"slot_type" db:"slot_type"`                   // physical ou logical
+	Plugin           *string `json:"plugin" db:"plugin"`                         // Plugin de decodificação (lógico)
+	DatabaseName     *string `json:"database_name" db:"database_name"`           // Banco (lógico)
+	Active           bool    `json:"active" db:"active"`                         // Slot em uso
+	ActivePID        *int    `json:"active_pid" db:"active_pid"`                 // PID que usa o slot
+	RestartLSN       *string `json:"restart_lsn" db:"restart_lsn"`               // LSN mais antigo necessário
+	RetainedWALBytes int64   `json:"retained_wal_bytes" db:"retained_wal_bytes"` // WAL retido pelo slot
+	WALStatus        *string `json:"wal_status" db:"wal_status"`                 // reserved, extended, unreserved, lost (PostgreSQL 13+)
+}
+
+// ReplicationTopology representa a visão de replicação a partir da instância consultada
+type ReplicationTopology struct {
+	Role        string               `json:"role"`         // primary ou standby
+	Node        *ReplicationNode     `json:"node"`         // Estado da instância consultada
+	Standbys    []ReplicationStandby `json:"standbys"`     // Standbys conectados (primary)
+	WalReceiver *WalReceiver         `json:"wal_receiver"` // Receptor de WAL (standby)
+	Slots       []ReplicationSlot    `json:"slots"`        // Slots de replicação
+	Health      string               `json:"health"`       // Pior estado entre os componentes
+	Issues      []string             `json:"issues"`       // Problemas encontrados
+}
+
+// ReplicationThresholds define os limites de saúde da replicação
+type ReplicationThresholds struct {
+	LagWarningSeconds    float64 `json:"lag_warning_seconds"`    // replay_lag para warning
+	LagCriticalSeconds   float64 `json:"lag_critical_seconds"`   // replay_lag para critical
+	LagWarningBytes      int64   `json:"lag_warning_bytes"`      // WAL não aplicado para warning
+	SlotRetainedCritical int64   `json:"slot_retained_critical"` // WAL retido por slot para critical
+}
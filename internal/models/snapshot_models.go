@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// TableStatSnapshot representa uma linha de pg_stat_user_tables gravada em table_stats_log
+type TableStatSnapshot struct {
+	SchemaName       string     `json:"schema_name" db:"schema_name"`             // Schema da tabela
+	TableName        string     `json:"table_name" db:"table_name"`               // Nome da tabela
+	RowCount         int64      `json:"row_count" db:"row_count"`                 // Número estimado de linhas
+	TableSizeBytes   int64      `json:"table_size_bytes" db:"table_size_bytes"`   // Tamanho da heap em bytes
+	IndexSizeBytes   int64      `json:"index_size_bytes" db:"index_size_bytes"`   // Tamanho dos índices em bytes
+	TotalSizeBytes   int64      `json:"total_size_bytes" db:"total_size_bytes"`   // Tamanho total em bytes
+	SeqScanCount     int64      `json:"seq_scan_count" db:"seq_scan_count"`       // Sequential scans
+	SeqTupRead       int64      `json:"seq_tup_read" db:"seq_tup_read"`           // Tuplas lidas por seq scan
+	IdxScanCount     int64      `json:"idx_scan_count" db:"idx_scan_count"`       // Index scans
+	IdxTupFetch      int64      `json:"idx_tup_fetch" db:"idx_tup_fetch"`         // Tuplas buscadas por índice
+	NTupIns          int64      `json:"n_tup_ins" db:"n_tup_ins"`                 // Tuplas inseridas
+	NTupUpd          int64      `json:"n_tup_upd" db:"n_tup_upd"`                 // Tuplas atualizadas
+	NTupDel          int64      `json:"n_tup_del" db:"n_tup_del"`                 // Tuplas deletadas
+	NTupHotUpd       int64      `json:"n_tup_hot_upd" db:"n_tup_hot_upd"`         // Updates HOT
+	NLiveTup         int64      `json:"n_live_tup" db:"n_live_tup"`               // Tuplas vivas
+	NDeadTup         int64      `json:"n_dead_tup" db:"n_dead_tup"`               // Tuplas mortas
+	VacuumCount      int64      `json:"vacuum_count" db:"vacuum_count"`           // Vacuums manuais
+	AutovacuumCount  int64      `json:"autovacuum_count" db:"autovacuum_count"`   // Autovacuums
+	AnalyzeCount     int64      `json:"analyze_count" db:"analyze_count"`         // Analyzes manuais
+	AutoanalyzeCount int64      `json:"autoanalyze_count" db:"autoanalyze_count"` // Autoanalyzes
+	LastVacuum       *time.Time `json:"last_vacuum" db:"last_vacuum"`             // Último vacuum
+	LastAutovacuum   *time.Time `json:"last_autovacuum" db:"last_autovacuum"`     // Último autovacuum
+	LastAnalyze      *time.Time `json:"last_analyze" db:"last_analyze"`           // Último analyze
+	LastAutoanalyze  *time.Time `json:"last_autoanalyze" db:"last_autoanalyze"`   // Último autoanalyze
+}
+
+// ConnectionSnapshot representa um backend de pg_stat_activity gravado em pg_connections_log
+type ConnectionSnapshot struct {
+	DatabaseName    string     `json:"database_name" db:"database_name"`                   // Banco conectado
+	Username        string     `json:"username" db:"username"`                             // Usuário
+	ClientAddr      *string    `json:"client_addr" db:"client_addr"`                       // Endereço do cliente
+	ApplicationName string     `json:"application_name" db:"application_name"`             // Nome da aplicação
+	State           string     `json:"state" db:"state"`                                   // Estado do backend
+	BackendStart    *time.Time `json:"backend_start" db:"backend_start"`                   // Início do backend
+	QueryStart      *time.Time `json:"query_start" db:"query_start"`                       // Início da query atual
+	StateChange     *time.Time `json:"state_change" db:"state_change"`                     // Última mudança de estado
+	WaitEventType   *string    `json:"wait_event_type" db:"wait_event_type"`               // Tipo do wait event
+	WaitEvent       *string    `json:"wait_event" db:"wait_event"`                         // Wait event
+	Query           string     `json:"query" db:"query"`                                   // Texto da query
+	DurationMs      int64      `json:"connection_duration_ms" db:"connection_duration_ms"` // Duração da conexão em ms
+}
+
+// SystemMetric representa uma métrica pontual gravada em system_metrics_log
+type SystemMetric struct {
+	MetricType   string  `json:"metric_type" db:"metric_type"`     // Tipo da métrica (db, connections, performance)
+	MetricName   string  `json:"metric_name" db:"metric_name"`     // Nome da métrica
+	MetricValue  float64 `json:"metric_value" db:"metric_value"`   // Valor
+	MetricUnit   string  `json:"metric_unit" db:"metric_unit"`     // Unidade
+	DatabaseName string  `json:"database_name" db:"database_name"` // Banco de origem
+}
+
+// HistoryQuery descreve uma consulta de série temporal sobre os snapshots gravados
+type HistoryQuery struct {
+	Metric   string        `json:"metric"`             // Nome da métrica
+	Target   string        `json:"target,omitempty"`   // Target monitorado (vazio = banco local)
+	Database string        `json:"database,omitempty"` // Filtro opcional por banco
+	Table    string        `json:"table,omitempty"`    // schema.tabela para métricas de table_stats_log
+	From     time.Time     `json:"from"`               // Início do intervalo
+	To       time.Time     `json:"to"`                 // Fim do intervalo
+	Step     time.Duration `json:"-"`                  // Tamanho de cada bucket
+}
+
+// HistoryPoint representa um bucket agregado da série temporal
+type HistoryPoint struct {
+	Bucket  time.Time `json:"bucket" db:"bucket"`   // Início do bucket
+	Avg     float64   `json:"avg" db:"avg"`         // Média
+	Min     float64   `json:"min" db:"min"`         // Mínimo
+	Max     float64   `json:"max" db:"max"`         // Máximo
+	Last    float64   `json:"last" db:"last"`       // Último valor do bucket
+	Samples int       `json:"samples" db:"samples"` // Quantidade de amostras
+}
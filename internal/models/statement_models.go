@@ -0,0 +1,84 @@
+package models
+
+// Colunas de ordenação do explorador de pg_stat_statements
+const (
+	StatementSortTotalTime      = "total_time"
+	StatementSortMeanTime       = "mean_time"
+	StatementSortCalls          = "calls"
+	StatementSortRows           = "rows"
+	StatementSortSharedBlksRead = "shared_blks_read"
+	StatementSortSharedBlksHit  = "shared_blks_hit"
+	StatementSortTempBlks       = "temp_blks"
+	StatementSortWALBytes       = "wal_bytes"
+)
+
+// StatementSortColumns lista as colunas de ordenação aceitas pelo explorador
+var StatementSortColumns = []string{
+	StatementSortTotalTime,
+	StatementSortMeanTime,
+	StatementSortCalls,
+	StatementSortRows,
+	StatementSortSharedBlksRead,
+	StatementSortSharedBlksHit,
+	StatementSortTempBlks,
+	StatementSortWALBytes,
+}
+
+// StatementStats representa uma entrada de pg_stat_statements com todos os contadores.
+// Os tempos são normalizados para as colunas *_exec_time (pg_stat_statements 1.8+);
+// contadores de planejamento e WAL ficam nulos em versões anteriores.
+type StatementStats struct {
+	QueryID           int64    `json:"queryid" db:"queryid"`                         // Identificador da query normalizada
+	DatabaseID        int64    `json:"dbid" db:"dbid"`                               // OID do banco
+	DatabaseName      string   `json:"database_name" db:"database_name"`             // Banco
+	UserID            int64    `json:"userid" db:"userid"`                           // OID do usuário
+	Username          string   `json:"username" db:"username"`                       // Usuário
+	TopLevel          bool     `json:"toplevel" db:"toplevel"`                       // Executada no nível superior (1.9+)
+	Query             string   `json:"query" db:"query"`                             // Texto normalizado (truncado na listagem)
+	QueryHash         string   `json:"query_hash" db:"query_hash"`                   // md5 do texto completo (chave em slow_queries_log)
+	Calls             int64    `json:"calls" db:"calls"`                             // Execuções
+	TotalTimeMs       float64  `json:"total_time_ms" db:"total_time_ms"`             // Tempo total de execução
+	MeanTimeMs        float64  `json:"mean_time_ms" db:"mean_time_ms"`               // Tempo médio de execução
+	MinTimeMs         float64  `json:"min_time_ms" db:"min_time_ms"`                 // Menor tempo de execução
+	MaxTimeMs         float64  `json:"max_time_ms" db:"max_time_ms"`                 // Maior tempo de execução
+	StddevTimeMs      float64  `json:"stddev_time_ms" db:"stddev_time_ms"`           // Desvio padrão do tempo de execução
+	Plans             *int64   `json:"plans" db:"plans"`                             // Planejamentos (1.8+)
+	TotalPlanTimeMs   *float64 `json:"total_plan_time_ms" db:"total_plan_time_ms"`   // Tempo total de planejamento (1.8+)
+	Rows              int64    `json:"rows" db:"rows"`                               // Linhas retornadas ou afetadas
+	SharedBlksHit     int64    `json:"shared_blks_hit" db:"shared_blks_hit"`         // Blocos compartilhados encontrados no cache
+	SharedBlksRead    int64    `json:"shared_blks_read" db:"shared_blks_read"`       // Blocos compartilhados lidos do disco
+	SharedBlksDirtied int64    `json:"shared_blks_dirtied" db:"shared_blks_dirtied"` // Blocos compartilhados sujos
+	SharedBlksWritten int64    `json:"shared_blks_written" db:"shared_blks_written"` // Blocos compartilhados escritos
+	LocalBlksHit      int64    `json:"local_blks_hit" db:"local_blks_hit"`           // Blocos locais encontrados no cache
+	LocalBlksRead     int64    `json:"local_blks_read" db:"local_blks_read"`         // Blocos locais lidos
+	TempBlksRead      int64    `json:"temp_blks_read" db:"temp_blks_read"`           // Blocos temporários lidos
+	TempBlksWritten   int64    `json:"temp_blks_written" db:"temp_blks_written"`     // Blocos temporários escritos
+	BlkReadTimeMs     float64  `json:"blk_read_time_ms" db:"blk_read_time_ms"`       // Tempo lendo blocos (track_io_timing)
+	BlkWriteTimeMs    float64  `json:"blk_write_time_ms" db:"blk_write_time_ms"`     // Tempo escrevendo blocos (track_io_timing)
+	WALRecords        *int64   `json:"wal_records" db:"wal_records"`                 // Registros de WAL gerados (1.8+)
+	WALFPI            *int64   `json:"wal_fpi" db:"wal_fpi"`                         // Full-page images geradas (1.8+)
+	WALBytes          *int64   `json:"wal_bytes" db:"wal_bytes"`                     // WAL gerado em bytes (1.8+)
+	HitPercent        float64  `json:"hit_percent" db:"hit_percent"`                 // Percentual de blocos encontrados no cache
+	SortValue         string   `json:"-" db:"sort_value"`                            // Valor da coluna de ordenação (cursor)
+}
+
+// StatementCursor identifica a última linha de uma página do explorador
+type StatementCursor struct {
+	SortValue  string `json:"v"` // Valor da coluna de ordenação
+	QueryID    int64  `json:"q"` // queryid
+	DatabaseID int64  `json:"d"` // dbid
+	UserID     int64  `json:"u"` // userid
+	TopLevel   bool   `json:"t"` // toplevel
+}
+
+// StatementQuery representa os filtros, a ordenação e a paginação do explorador
+type StatementQuery struct {
+	SortBy    string           // Coluna de ordenação
+	Ascending bool             // Ordem crescente (padrão: decrescente)
+	Database  string           // Filtro por banco
+	User      string           // Filtro por usuário
+	QueryID   *int64           // Filtro por queryid
+	Search    string           // Busca no texto da query
+	Limit     int              // Itens por página
+	Cursor    *StatementCursor // Continua após esta linha
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Labels representa os labels livres de um target, gravados como JSONB
+type Labels map[string]string
+
+// Value implementa driver.Valuer
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implementa sql.Scanner
+func (l *Labels) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = Labels{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("tipo não suportado para labels: %T", src)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Target representa uma instância PostgreSQL monitorada
+// @Description Instância PostgreSQL registrada para monitoramento
+type Target struct {
+	ID                        string    `json:"id" db:"id" example:"5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23"`                      // ID único do target
+	Name                      string    `json:"name" db:"name" example:"prod-orders"`                                           // Nome único usado no parâmetro target
+	DSN                       string    `json:"dsn" db:"dsn" example:"postgres://monitor@10.0.0.5:5432/orders?sslmode=require"` // DSN sem senha
+	CredentialsRef            string    `json:"credentials_ref" db:"credentials_ref" example:"env:PGA_ORDERS_PASSWORD"`         // Referência para a senha
+	Labels                    Labels    `json:"labels" db:"labels"`                                                             // Labels livres
+	CollectionIntervalSeconds int       `json:"collection_interval_seconds" db:"collection_interval_seconds" example:"60"`      // Intervalo de coleta
+	Enabled                   bool      `json:"enabled" db:"enabled" example:"true"`                                            // Coleta habilitada
+	CreatedAt                 time.Time `json:"created_at" db:"created_at"`                                                     // Data de criação
+	UpdatedAt                 time.Time `json:"updated_at" db:"updated_at"`                                                     // Data de atualização
+}
+
+// TargetRequest representa o corpo de criação/atualização de um target
+// @Description Dados para registrar uma instância monitorada
+type TargetRequest struct {
+	Name                      string `json:"name" binding:"required,max=100" example:"prod-orders"`                    // Nome único
+	DSN                       string `json:"dsn" binding:"required" example:"postgres://monitor@10.0.0.5:5432/orders"` // DSN sem senha
+	CredentialsRef            string `json:"credentials_ref" example:"env:PGA_ORDERS_PASSWORD"`                        // Referência para a senha
+	Labels                    Labels `json:"labels"`                                                                   // Labels livres
+	CollectionIntervalSeconds int    `json:"collection_interval_seconds" binding:"omitempty,min=5" example:"60"`       // Intervalo de coleta
+	Enabled                   *bool  `json:"enabled" example:"true"`                                                   // Coleta habilitada (padrão true)
+}
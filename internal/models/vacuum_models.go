@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// MaxTransactionAge é a idade de XID em que o PostgreSQL para de aceitar escritas para evitar wraparound
+const MaxTransactionAge = 2147483647
+
+// WraparoundRisk representa a distância de um banco ou tabela até autovacuum_freeze_max_age
+type WraparoundRisk struct {
+	ObjectType        string  `json:"object_type"`           // database ou table
+	DatabaseName      string  `json:"database_name"`         // Banco
+	SchemaName        string  `json:"schema_name,omitempty"` // Schema (apenas tabelas)
+	TableName         string  `json:"table_name,omitempty"`  // Tabela (apenas tabelas)
+	XIDAge            int64   `json:"xid_age"`               // age(datfrozenxid) ou age(relfrozenxid)
+	FreezeMaxAge      int64   `json:"freeze_max_age"`        // autovacuum_freeze_max_age efetivo
+	RemainingXIDs     int64   `json:"remaining_xids"`        // Transações até o autovacuum anti-wraparound
+	PercentToFreeze   float64 `json:"percent_to_freeze"`     // Idade em relação a autovacuum_freeze_max_age
+	PercentWraparound float64 `json:"percent_wraparound"`    // Idade em relação ao limite de wraparound
+}
+
+// DatabaseXIDAge representa a idade de datfrozenxid de um banco
+type DatabaseXIDAge struct {
+	DatabaseName string `json:"database_name" db:"database_name"`   // Banco
+	XIDAge       int64  `json:"xid_age" db:"xid_age"`               // age(datfrozenxid)
+	FreezeMaxAge int64  `json:"freeze_max_age" db:"freeze_max_age"` // autovacuum_freeze_max_age
+}
+
+// TableVacuumStatus representa o estado de vacuum de uma tabela com os limites efetivos
+// (reloptions da tabela ou configuração global)
+type TableVacuumStatus struct {
+	DatabaseName      string     `json:"database_name" db:"database_name"`           // Banco
+	SchemaName        string     `json:"schema_name" db:"schema_name"`               // Schema
+	TableName         string     `json:"table_name" db:"table_name"`                 // Tabela
+	SizeBytes         int64      `json:"size_bytes" db:"size_bytes"`                 // Tamanho total em bytes
+	XIDAge            int64      `json:"xid_age" db:"xid_age"`                       // Maior age(relfrozenxid) entre a tabela e sua TOAST
+	FreezeMaxAge      int64      `json:"freeze_max_age" db:"freeze_max_age"`         // autovacuum_freeze_max_age efetivo
+	NLiveTup          int64      `json:"n_live_tup" db:"n_live_tup"`                 // Tuplas vivas
+	NDeadTup          int64      `json:"n_dead_tup" db:"n_dead_tup"`                 // Tuplas mortas
+	DeadRatio         float64    `json:"dead_ratio" db:"dead_ratio"`                 // Percentual de tuplas mortas
+	VacuumThreshold   float64    `json:"vacuum_threshold" db:"vacuum_threshold"`     // threshold + scale_factor * reltuples
+	AutovacuumEnabled bool       `json:"autovacuum_enabled" db:"autovacuum_enabled"` // Autovacuum habilitado para a tabela
+	Overdue           bool       `json:"overdue" db:"overdue"`                       // Tuplas mortas acima do limite efetivo
+	LastVacuum        *time.Time `json:"last_vacuum" db:"last_vacuum"`               // Último vacuum manual
+	LastAutovacuum    *time.Time `json:"last_autovacuum" db:"last_autovacuum"`       // Último autovacuum
+	VacuumCount       int64      `json:"vacuum_count" db:"vacuum_count"`             // Vacuums manuais
+	AutovacuumCount   int64      `json:"autovacuum_count" db:"autovacuum_count"`     // Autovacuums
+}
+
+// VacuumProgress representa um vacuum em execução (pg_stat_progress_vacuum)
+type VacuumProgress struct {
+	PID              int        `json:"pid" db:"pid"`                               // PID do backend
+	DatabaseName     string     `json:"database_name" db:"database_name"`           // Banco
+	Relation         string     `json:"relation" db:"relation"`                     // Tabela em vacuum
+	Phase            string     `json:"phase" db:"phase"`                           // Fase atual
+	HeapBlksTotal    int64      `json:"heap_blks_total" db:"heap_blks_total"`       // Blocos da heap
+	HeapBlksScanned  int64      `json:"heap_blks_scanned" db:"heap_blks_scanned"`   // Blocos lidos
+	HeapBlksVacuumed int64      `json:"heap_blks_vacuumed" db:"heap_blks_vacuumed"` // Blocos limpos
+	IndexVacuumCount int64      `json:"index_vacuum_count" db:"index_vacuum_count"` // Ciclos de vacuum de índices
+	PercentComplete  float64    `json:"percent_complete" db:"percent_complete"`     // Blocos lidos em relação ao total
+	IsAutovacuum     bool       `json:"is_autovacuum" db:"is_autovacuum"`           // Executado pelo autovacuum
+	Query            string     `json:"query" db:"query"`                           // Comando em execução
+	XactStart        *time.Time `json:"xact_start" db:"xact_start"`                 // Início do vacuum
+}
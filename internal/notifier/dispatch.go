@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// Route liga um canal às suas regras de filtragem, agrupamento e deduplicação
+type Route struct {
+	ID             string        // Identificador do canal
+	Name           string        // Nome do canal, usado em logs
+	Notifier       Notifier      // Entrega (normalmente envolvido por WithRetry)
+	GroupBy        []string      // Campos de agrupamento (padrão DefaultGroupBy)
+	GroupWait      time.Duration // Espera para acumular eventos do mesmo grupo antes de enviar
+	RepeatInterval time.Duration // Janela em que uma notificação idêntica não é reenviada
+	MinSeverity    string        // Eventos com severidade menor são ignorados (vazio = todos)
+}
+
+// Delivery é uma notificação pronta para entrega em um canal
+type Delivery struct {
+	Route        Route
+	Notification Notification
+}
+
+// Key identifica o canal e o grupo da entrega
+func (d Delivery) Key() string {
+	return d.Route.ID + "|" + d.Notification.GroupKey
+}
+
+type batch struct {
+	route  Route
+	events []models.AlertEvent
+	first  time.Time
+}
+
+type sentRecord struct {
+	fingerprint string
+	expires     time.Time
+}
+
+// Dispatcher acumula eventos por canal e grupo, libera cada grupo após o group wait e suprime
+// notificações idênticas (mesma dedup key e mesmos alertas no mesmo estado) já entregues dentro
+// do repeat interval. É seguro para uso concorrente.
+type Dispatcher struct {
+	mu      sync.Mutex
+	pending map[string]*batch
+	sent    map[string]sentRecord
+}
+
+// NewDispatcher cria um dispatcher vazio
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		pending: make(map[string]*batch),
+		sent:    make(map[string]sentRecord),
+	}
+}
+
+// Add acumula o evento no grupo do canal. Retorna false quando o evento foi filtrado pela
+// severidade mínima do canal.
+func (d *Dispatcher) Add(route Route, event models.AlertEvent, now time.Time) bool {
+	if route.MinSeverity != "" && !SeverityAtLeast(event.Severity, route.MinSeverity) {
+		return false
+	}
+	if len(route.GroupBy) == 0 {
+		route.GroupBy = DefaultGroupBy
+	}
+
+	key := route.ID + "|" + GroupKey(GroupLabels(route.GroupBy, event))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.pending[key]
+	if !ok {
+		b = &batch{first: now}
+		d.pending[key] = b
+	}
+	b.route = route
+	b.events = append(b.events, event)
+	return true
+}
+
+// Ready remove e retorna os grupos cujo group wait venceu, ordenados por canal e grupo
+func (d *Dispatcher) Ready(now time.Time) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, record := range d.sent {
+		if !now.Before(record.expires) {
+			delete(d.sent, key)
+		}
+	}
+
+	deliveries := []Delivery{}
+	for key, b := range d.pending {
+		if now.Sub(b.first) < b.route.GroupWait {
+			continue
+		}
+		delete(d.pending, key)
+
+		n := NewNotification(b.route.GroupBy, b.events)
+		if record, ok := d.sent[sentKey(b.route, n)]; ok && record.fingerprint == n.Fingerprint() {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{Route: b.route, Notification: n})
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].Route.ID != deliveries[j].Route.ID {
+			return deliveries[i].Route.ID < deliveries[j].Route.ID
+		}
+		return deliveries[i].Notification.GroupKey < deliveries[j].Notification.GroupKey
+	})
+	return deliveries
+}
+
+// Delivered registra a entrega bem-sucedida, base da deduplicação pelo repeat interval
+func (d *Dispatcher) Delivered(delivery Delivery, now time.Time) {
+	if delivery.Route.RepeatInterval <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sent[sentKey(delivery.Route, delivery.Notification)] = sentRecord{
+		fingerprint: delivery.Notification.Fingerprint(),
+		expires:     now.Add(delivery.Route.RepeatInterval),
+	}
+}
+
+// Forget descarta os grupos pendentes e o registro de entregas de um canal (ex: canal removido)
+func (d *Dispatcher) Forget(routeID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prefix := routeID + "|"
+	for key := range d.pending {
+		if strings.HasPrefix(key, prefix) {
+			delete(d.pending, key)
+		}
+	}
+	for key := range d.sent {
+		if strings.HasPrefix(key, prefix) {
+			delete(d.sent, key)
+		}
+	}
+}
+
+func sentKey(route Route, n Notification) string {
+	return route.ID + "|" + n.DedupKey
+}
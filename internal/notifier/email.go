@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+
+	"pganalytics-backend/internal/mailer"
+)
+
+// EmailNotifier envia a notificação por email usando o Mailer da aplicação.
+// O título renderizado é o assunto e o corpo vai em texto simples.
+type EmailNotifier struct {
+	Mailer    mailer.Mailer
+	To        []string
+	Templates *Templates
+}
+
+// Notify implementa Notifier. Respostas SMTP 5xx são permanentes.
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	title, body, err := defaultTemplates(e.Templates).Render(n)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	err = e.Mailer.Send(mailer.Message{To: e.To, Subject: title, Body: body})
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultHTTPTimeout limita cada tentativa de entrega HTTP
+const defaultHTTPTimeout = 10 * time.Second
+
+var defaultClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+func clientOrDefault(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return defaultClient
+}
+
+// postJSON envia o corpo JSON e classifica a resposta: 2xx é sucesso, 429 e 5xx são
+// temporários e os demais 4xx são permanentes. Falhas de rede são temporárias.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("requisição inválida para %s: %w", url, err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pgAnalytics-notifier")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := clientOrDefault(client).Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao enviar para %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s respondeu %d: %s", req.URL.Host, resp.StatusCode, bytes.TrimSpace(detail))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return Permanent(err)
+}
@@ -0,0 +1,204 @@
+// Package notifier entrega eventos de alerta por webhook genérico, Slack, email e PagerDuty,
+// com templates por canal, retentativas com backoff, agrupamento e chaves de deduplicação.
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"pganalytics-backend/internal/models"
+)
+
+// Status de uma notificação
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Campos aceitos no agrupamento de eventos
+const (
+	GroupByTarget   = "target"
+	GroupBySource   = "source"
+	GroupByEventKey = "event_key"
+	GroupBySeverity = "severity"
+)
+
+// DefaultGroupBy agrupa por alerta: cada regra ou achado de um target gera sua própria notificação
+var DefaultGroupBy = []string{GroupByTarget, GroupBySource, GroupByEventKey}
+
+// Notification é um grupo de eventos de alerta entregue de uma vez a um canal
+type Notification struct {
+	GroupKey string              `json:"group_key"`    // Identifica o grupo (labels ordenados)
+	DedupKey string              `json:"dedup_key"`    // Chave estável do grupo entre disparo e resolução
+	Status   string              `json:"status"`       // firing ou resolved
+	Severity string              `json:"severity"`     // Maior severidade do grupo
+	Labels   map[string]string   `json:"group_labels"` // Valores dos campos de agrupamento
+	Alerts   []models.AlertEvent `json:"alerts"`       // Eventos do grupo, do mais antigo para o mais recente
+}
+
+// Notifier entrega uma notificação a um canal. Erros marcados com Permanent não são retentados.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca um erro de entrega que não deve ser retentado (ex: requisição rejeitada com 4xx)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica se o erro foi marcado com Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+var severityRank = map[string]int{
+	models.AlertSeverityInfo:     1,
+	models.AlertSeverityWarning:  2,
+	models.AlertSeverityCritical: 3,
+}
+
+// ValidSeverity indica se a severidade é conhecida
+func ValidSeverity(severity string) bool {
+	_, ok := severityRank[severity]
+	return ok
+}
+
+// SeverityAtLeast indica se severity é maior ou igual a min
+func SeverityAtLeast(severity, min string) bool {
+	return severityRank[severity] >= severityRank[min]
+}
+
+// ValidGroupBy indica se o campo pode ser usado no agrupamento
+func ValidGroupBy(field string) bool {
+	switch field {
+	case GroupByTarget, GroupBySource, GroupByEventKey, GroupBySeverity:
+		return true
+	}
+	return false
+}
+
+// GroupLabels extrai do evento os valores dos campos de agrupamento
+func GroupLabels(groupBy []string, event models.AlertEvent) map[string]string {
+	labels := make(map[string]string, len(groupBy))
+	for _, field := range groupBy {
+		switch field {
+		case GroupByTarget:
+			labels[field] = TargetName(event)
+		case GroupBySource:
+			labels[field] = event.Source
+		case GroupByEventKey:
+			labels[field] = event.EventKey
+		case GroupBySeverity:
+			labels[field] = event.Severity
+		}
+	}
+	return labels
+}
+
+// GroupKey serializa os labels de agrupamento em ordem alfabética
+func GroupKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + labels[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// DedupKey deriva do grupo uma chave curta e estável, usada pelos destinos para correlacionar
+// disparo e resolução (ex: dedup_key do PagerDuty)
+func DedupKey(groupKey string) string {
+	sum := sha256.Sum256([]byte(groupKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NewNotification monta a notificação de um grupo. Eventos repetidos do mesmo alerta
+// (mesma origem, target e chave) são reduzidos ao mais recente. O grupo está resolvido
+// apenas quando todos os seus alertas estão resolvidos.
+func NewNotification(groupBy []string, events []models.AlertEvent) Notification {
+	latest := map[string]int{}
+	alerts := []models.AlertEvent{}
+	for _, event := range events {
+		key := alertKey(event)
+		if i, ok := latest[key]; ok {
+			alerts[i] = event
+			continue
+		}
+		latest[key] = len(alerts)
+		alerts = append(alerts, event)
+	}
+
+	n := Notification{Status: StatusResolved, Alerts: alerts}
+	if len(alerts) > 0 {
+		n.Labels = GroupLabels(groupBy, alerts[0])
+	}
+	n.GroupKey = GroupKey(n.Labels)
+	n.DedupKey = DedupKey(n.GroupKey)
+
+	for _, alert := range alerts {
+		if !isResolved(alert) {
+			n.Status = StatusFiring
+		}
+	}
+	for _, alert := range alerts {
+		if n.Status == StatusFiring && isResolved(alert) {
+			continue
+		}
+		if severityRank[alert.Severity] > severityRank[n.Severity] {
+			n.Severity = alert.Severity
+		}
+	}
+	return n
+}
+
+// Fingerprint identifica o conteúdo da notificação (alertas e seus estados) para deduplicação
+func (n Notification) Fingerprint() string {
+	parts := make([]string, len(n.Alerts))
+	for i, alert := range n.Alerts {
+		state := StatusFiring
+		if isResolved(alert) {
+			state = StatusResolved
+		}
+		parts[i] = alertKey(alert) + "=" + state
+	}
+	sort.Strings(parts)
+	return n.Status + "|" + strings.Join(parts, ",")
+}
+
+// TargetName retorna o target do evento; vazio para o banco local
+func TargetName(event models.AlertEvent) string {
+	if event.TargetName == nil {
+		return ""
+	}
+	return *event.TargetName
+}
+
+// alertKey identifica um alerta entre eventos sucessivos
+func alertKey(event models.AlertEvent) string {
+	return event.Source + "|" + TargetName(event) + "|" + event.EventKey
+}
+
+// isResolved indica se o evento é uma resolução. Eventos sem estado (detectores) são disparos.
+func isResolved(event models.AlertEvent) bool {
+	return event.State != nil && *event.State == models.AlertStateResolved
+}
@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PagerDutyEventsURL é o endpoint da Events API v2
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySummaryLimit é o tamanho máximo de payload.summary aceito pela Events API v2
+const pagerDutySummaryLimit = 1024
+
+// PagerDutyEvent é o corpo de um evento da Events API v2
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger ou resolve
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"` // Omitido em resolve
+}
+
+// PagerDutyPayload descreve o incidente de um evento trigger
+type PagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"` // critical, error, warning ou info
+	Timestamp     time.Time              `json:"timestamp"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// PagerDutyNotifier envia eventos no formato da Events API v2. A DedupKey da notificação
+// correlaciona o trigger do grupo com seu resolve.
+type PagerDutyNotifier struct {
+	RoutingKey string
+	URL        string // Padrão: PagerDutyEventsURL
+	Templates  *Templates
+	Client     *http.Client
+}
+
+// Notify implementa Notifier
+func (p *PagerDutyNotifier) Notify(ctx context.Context, n Notification) error {
+	event, err := p.Event(n)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Permanent(fmt.Errorf("falha ao serializar evento do PagerDuty: %w", err))
+	}
+
+	url := p.URL
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+	return postJSON(ctx, p.Client, url, data, nil)
+}
+
+// Event monta o evento da Events API v2 para a notificação
+func (p *PagerDutyNotifier) Event(n Notification) (*PagerDutyEvent, error) {
+	event := &PagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    n.DedupKey,
+		Client:      "pgAnalytics",
+	}
+	if n.Status == StatusResolved {
+		event.EventAction = "resolve"
+		return event, nil
+	}
+
+	title, body, err := defaultTemplates(p.Templates).Render(n)
+	if err != nil {
+		return nil, err
+	}
+	if runes := []rune(title); len(runes) > pagerDutySummaryLimit {
+		title = string(runes[:pagerDutySummaryLimit])
+	}
+
+	source, class := "", ""
+	timestamp := time.Now().UTC()
+	if len(n.Alerts) > 0 {
+		source = TargetName(n.Alerts[0])
+		class = n.Alerts[0].Source
+		timestamp = n.Alerts[len(n.Alerts)-1].CreatedAt
+	}
+	if source == "" {
+		source = "banco local"
+	}
+
+	event.Payload = &PagerDutyPayload{
+		Summary:   title,
+		Source:    source,
+		Severity:  n.Severity,
+		Timestamp: timestamp,
+		Component: "postgresql",
+		Class:     class,
+		CustomDetails: map[string]interface{}{
+			"body":   body,
+			"alerts": len(n.Alerts),
+		},
+	}
+	return event, nil
+}
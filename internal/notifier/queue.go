@@ -0,0 +1,49 @@
+package notifier
+
+import "sync"
+
+// Queue entrega as notificações de um mesmo canal e grupo uma de cada vez, na ordem em que
+// foram enfileiradas, para que a resolução nunca chegue antes de um disparo ainda em
+// retentativa. Grupos diferentes são entregues em paralelo. É seguro para uso concorrente.
+type Queue struct {
+	deliver func(Delivery)
+	mu      sync.Mutex
+	pending map[string][]Delivery
+}
+
+// NewQueue cria uma fila que entrega cada notificação chamando deliver
+func NewQueue(deliver func(Delivery)) *Queue {
+	return &Queue{deliver: deliver, pending: make(map[string][]Delivery)}
+}
+
+// Push enfileira a entrega atrás das anteriores do mesmo canal e grupo
+func (q *Queue) Push(delivery Delivery) {
+	key := delivery.Key()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if waiting, busy := q.pending[key]; busy {
+		q.pending[key] = append(waiting, delivery)
+		return
+	}
+	q.pending[key] = nil
+	go q.drain(key, delivery)
+}
+
+// drain entrega next e as que chegarem para o mesmo grupo enquanto houver alguma na fila
+func (q *Queue) drain(key string, next Delivery) {
+	for {
+		q.deliver(next)
+
+		q.mu.Lock()
+		waiting := q.pending[key]
+		if len(waiting) == 0 {
+			delete(q.pending, key)
+			q.mu.Unlock()
+			return
+		}
+		next, q.pending[key] = waiting[0], waiting[1:]
+		q.mu.Unlock()
+	}
+}
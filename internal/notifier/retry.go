@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy define as retentativas de entrega com backoff exponencial
+type RetryPolicy struct {
+	MaxAttempts    int           // Total de tentativas, incluindo a primeira
+	InitialBackoff time.Duration // Espera antes da segunda tentativa
+	MaxBackoff     time.Duration // Limite da espera entre tentativas
+}
+
+// DefaultRetryPolicy retorna a política usada quando o canal não define a sua
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+}
+
+// Backoff retorna a espera após a tentativa attempt (1 = primeira), dobrando a cada tentativa
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+type retrying struct {
+	next   Notifier
+	policy RetryPolicy
+}
+
+// WithRetry retenta entregas que falham com erro temporário, esperando Backoff entre tentativas.
+// Erros marcados com Permanent e o cancelamento do contexto interrompem as tentativas.
+func WithRetry(n Notifier, policy RetryPolicy) Notifier {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	return &retrying{next: n, policy: policy}
+}
+
+// Notify implementa Notifier
+func (r *retrying) Notify(ctx context.Context, n Notification) error {
+	var err error
+	for attempt := 1; attempt <= r.policy.MaxAttempts; attempt++ {
+		if err = r.next.Notify(ctx, n); err == nil || IsPermanent(err) {
+			return err
+		}
+		if attempt == r.policy.MaxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(r.policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("entrega cancelada após %d tentativa(s): %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+	return fmt.Errorf("entrega falhou após %d tentativa(s): %w", r.policy.MaxAttempts, err)
+}
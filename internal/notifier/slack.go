@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"pganalytics-backend/internal/models"
+)
+
+// slackColors mapeia severidade e status para a cor do anexo
+var slackColors = map[string]string{
+	models.AlertSeverityInfo:     "#439fe0",
+	models.AlertSeverityWarning:  "#daa038",
+	models.AlertSeverityCritical: "#d00000",
+	StatusResolved:               "#2eb886",
+}
+
+type slackAttachment struct {
+	Color    string `json:"color"`
+	Fallback string `json:"fallback"`
+	Text     string `json:"text"`
+	Footer   string `json:"footer"`
+}
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// SlackNotifier envia para um incoming webhook compatível com Slack (Slack, Mattermost, Rocket.Chat).
+// O título vai em text e o corpo em um anexo colorido pela severidade.
+type SlackNotifier struct {
+	WebhookURL string
+	Channel    string // Sobrescreve o canal padrão do webhook, quando permitido
+	Username   string
+	Templates  *Templates
+	Client     *http.Client
+}
+
+// Notify implementa Notifier
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	title, body, err := defaultTemplates(s.Templates).Render(n)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	color := slackColors[n.Severity]
+	if n.Status == StatusResolved {
+		color = slackColors[StatusResolved]
+	}
+
+	data, err := json.Marshal(slackPayload{
+		Text:     "*" + title + "*",
+		Channel:  s.Channel,
+		Username: s.Username,
+		Attachments: []slackAttachment{
+			{Color: color, Fallback: title, Text: body, Footer: "pgAnalytics"},
+		},
+	})
+	if err != nil {
+		return Permanent(fmt.Errorf("falha ao serializar mensagem do Slack: %w", err))
+	}
+
+	return postJSON(ctx, s.Client, s.WebhookURL, data, nil)
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"pganalytics-backend/internal/models"
+)
+
+// Templates padrão, usados quando o canal não define os seus
+const (
+	DefaultTitleTemplate = `[{{upper .Status}}{{if eq .Status "firing"}}:{{upper .Severity}}{{end}}] ` +
+		`{{if eq (len .Alerts) 1}}{{(index .Alerts 0).Title}}{{else}}{{len .Alerts}} alertas{{with index .Labels "target"}} em {{.}}{{end}}{{end}}`
+	DefaultBodyTemplate = `{{range .Alerts}}- [{{.Severity}}{{with .State}} {{.}}{{end}}] {{.Title}} ({{target .}})
+  {{.Message}}
+{{end}}`
+)
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	// target identifica o target do evento, "banco local" quando vazio
+	"target": func(event models.AlertEvent) string {
+		if name := TargetName(event); name != "" {
+			return name
+		}
+		return "banco local"
+	},
+	// value formata o valor da métrica do evento
+	"value": func(event models.AlertEvent) string {
+		if event.Value == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", *event.Value)
+	},
+}
+
+// Templates renderiza título e corpo de uma notificação com text/template.
+// Os templates recebem Notification e as funções upper, target e value.
+type Templates struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// ParseTemplates compila os templates do canal; vazio usa o template padrão
+func ParseTemplates(title, body string) (*Templates, error) {
+	if title == "" {
+		title = DefaultTitleTemplate
+	}
+	if body == "" {
+		body = DefaultBodyTemplate
+	}
+
+	t, err := template.New("title").Funcs(templateFuncs).Option("missingkey=zero").Parse(title)
+	if err != nil {
+		return nil, fmt.Errorf("template de título inválido: %w", err)
+	}
+	b, err := template.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("template de corpo inválido: %w", err)
+	}
+	return &Templates{title: t, body: b}, nil
+}
+
+// Render retorna título (em uma linha) e corpo da notificação
+func (t *Templates) Render(n Notification) (string, string, error) {
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, n); err != nil {
+		return "", "", fmt.Errorf("falha ao renderizar título: %w", err)
+	}
+	if err := t.body.Execute(&body, n); err != nil {
+		return "", "", fmt.Errorf("falha ao renderizar corpo: %w", err)
+	}
+	return strings.Join(strings.Fields(title.String()), " "), strings.TrimSpace(body.String()), nil
+}
+
+// defaultTemplates é usado pelos notificadores criados sem templates
+func defaultTemplates(t *Templates) *Templates {
+	if t != nil {
+		return t
+	}
+	t, _ = ParseTemplates("", "")
+	return t
+}
@@ -0,0 +1,75 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Cabeçalhos da assinatura do webhook genérico
+const (
+	SignatureHeader = "X-PGAnalytics-Signature"
+	TimestampHeader = "X-PGAnalytics-Timestamp"
+)
+
+// WebhookPayload é o corpo JSON enviado pelo webhook genérico
+type WebhookPayload struct {
+	Version string `json:"version"`
+	Notification
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// WebhookNotifier envia a notificação como JSON para uma URL qualquer. Quando Secret é
+// informado, o corpo é assinado com HMAC-SHA256 (ver Sign).
+type WebhookNotifier struct {
+	URL       string
+	Secret    string
+	Headers   map[string]string
+	Templates *Templates
+	Client    *http.Client
+}
+
+// Sign calcula a assinatura enviada em X-PGAnalytics-Signature: HMAC-SHA256 de
+// "<timestamp>.<corpo>" com o segredo do canal, em hexadecimal e prefixado por "sha256=".
+// O timestamp (epoch em segundos) vai em X-PGAnalytics-Timestamp e permite recusar reenvios antigos.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify implementa Notifier
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	title, body, err := defaultTemplates(w.Templates).Render(n)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	now := time.Now().UTC()
+	data, err := json.Marshal(WebhookPayload{Version: "1", Notification: n, Title: title, Body: body, SentAt: now})
+	if err != nil {
+		return Permanent(fmt.Errorf("falha ao serializar webhook: %w", err))
+	}
+
+	headers := make(map[string]string, len(w.Headers)+2)
+	for k, v := range w.Headers {
+		headers[k] = v
+	}
+	if w.Secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		headers[TimestampHeader] = timestamp
+		headers[SignatureHeader] = Sign(w.Secret, timestamp, data)
+	}
+
+	return postJSON(ctx, w.Client, w.URL, data, headers)
+}
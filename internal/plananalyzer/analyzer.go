@@ -0,0 +1,377 @@
+package plananalyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Tipos de achado
+const (
+	FindingMisestimate      = "misestimate"        // Linhas reais e estimadas divergem além do fator
+	FindingSeqScanLarge     = "seq_scan_large"     // Seq scan lendo muitas linhas
+	FindingDiskSpill        = "disk_spill"         // Sort, hash ou agregação usando disco
+	FindingNestedLoopOuter  = "nested_loop_outer"  // Nested loop com muitas linhas no lado externo
+	FindingFilterHeavyIndex = "filter_heavy_index" // Index scan que descarta a maior parte das linhas lidas
+	FindingExclusiveHotspot = "exclusive_hotspot"  // Nó que concentra o tempo de execução
+)
+
+// Severidades dos achados
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Options define os limites usados pelo analisador
+type Options struct {
+	MisestimateFactor   float64 `json:"misestimate_factor"`     // Divergência mínima entre linhas reais e estimadas
+	MisestimateMinRows  float64 `json:"misestimate_min_rows"`   // Ignora divergências quando os dois lados têm menos linhas
+	LargeScanRows       float64 `json:"large_scan_rows"`        // Linhas lidas que caracterizam um seq scan grande
+	NestedLoopOuterRows float64 `json:"nested_loop_outer_rows"` // Linhas externas que caracterizam um nested loop caro
+	FilterRemovedRatio  float64 `json:"filter_removed_ratio"`   // Fração de linhas descartadas pelo filtro de um index scan
+	FilterMinRemoved    float64 `json:"filter_min_removed"`     // Linhas descartadas mínimas para sinalizar o filtro
+	HotspotPercent      float64 `json:"hotspot_percent"`        // Percentual do tempo total em tempo exclusivo de um nó
+}
+
+// DefaultOptions retorna os limites padrão do analisador
+func DefaultOptions() Options {
+	return Options{
+		MisestimateFactor:   10,
+		MisestimateMinRows:  100,
+		LargeScanRows:       100000,
+		NestedLoopOuterRows: 10000,
+		FilterRemovedRatio:  0.9,
+		FilterMinRemoved:    1000,
+		HotspotPercent:      20,
+	}
+}
+
+// Finding é um problema encontrado em um nó do plano
+type Finding struct {
+	Kind     string             `json:"kind"`              // Tipo do achado
+	Severity string             `json:"severity"`          // info, warning ou critical
+	NodeID   int                `json:"node_id"`           // ID do nó na árvore anotada
+	Node     string             `json:"node"`              // Descrição do nó (ex: Seq Scan on orders)
+	Message  string             `json:"message"`           // Explicação legível
+	Metrics  map[string]float64 `json:"metrics,omitempty"` // Valores que dispararam o achado
+}
+
+// AnnotatedNode é um nó do plano com as métricas calculadas e os achados associados
+type AnnotatedNode struct {
+	ID               int              `json:"id"`                          // Ordem do nó na árvore (pré-ordem, raiz = 1)
+	Depth            int              `json:"depth"`                       // Profundidade na árvore
+	Label            string           `json:"label"`                       // Descrição do nó
+	NodeType         string           `json:"node_type"`                   // Node Type
+	Relation         string           `json:"relation,omitempty"`          // Relação lida
+	IndexName        string           `json:"index_name,omitempty"`        // Índice usado
+	TotalCost        float64          `json:"total_cost"`                  // Custo total estimado
+	EstimatedRows    float64          `json:"estimated_rows"`              // Linhas estimadas por execução
+	ActualRows       *float64         `json:"actual_rows,omitempty"`       // Linhas reais por execução (ANALYZE)
+	Loops            *float64         `json:"loops,omitempty"`             // Execuções do nó (ANALYZE)
+	EstimateFactor   *float64         `json:"estimate_factor,omitempty"`   // Divergência entre linhas reais e estimadas (>1 = subestimado, <1 = superestimado)
+	InclusiveTimeMs  *float64         `json:"inclusive_time_ms,omitempty"` // Tempo do nó e de seus filhos
+	ExclusiveTimeMs  *float64         `json:"exclusive_time_ms,omitempty"` // Tempo do nó sem os filhos
+	ExclusivePercent *float64         `json:"exclusive_percent,omitempty"` // Tempo exclusivo sobre o tempo total
+	Findings         []string         `json:"findings,omitempty"`          // Tipos dos achados neste nó
+	Children         []*AnnotatedNode `json:"children,omitempty"`          // Nós filhos
+}
+
+// Report é o resultado da análise de um plano
+type Report struct {
+	Analyzed        bool           `json:"analyzed"`                    // Plano capturado com ANALYZE
+	PlanningTimeMs  *float64       `json:"planning_time_ms,omitempty"`  // Tempo de planejamento
+	ExecutionTimeMs *float64       `json:"execution_time_ms,omitempty"` // Tempo de execução
+	TotalCost       float64        `json:"total_cost"`                  // Custo estimado da raiz
+	NodeCount       int            `json:"node_count"`                  // Quantidade de nós
+	Options         Options        `json:"options"`                     // Limites aplicados
+	Findings        []Finding      `json:"findings"`                    // Achados, dos mais graves para os menos graves
+	Tree            *AnnotatedNode `json:"tree"`                        // Árvore anotada
+	Text            string         `json:"text"`                        // Árvore anotada em texto
+}
+
+// Analyze percorre o plano, calcula linhas e tempos por nó e aplica as verificações.
+// Sem ANALYZE, apenas seq scans e nested loops são avaliados, a partir das estimativas.
+func Analyze(plan *Plan, opts Options) *Report {
+	a := &analyzer{opts: opts, analyzed: plan.Analyzed()}
+	a.totalMs = a.inclusive(plan.Root, false)
+	tree := a.walk(plan.Root, 0, false)
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		if severityRank[a.findings[i].Severity] != severityRank[a.findings[j].Severity] {
+			return severityRank[a.findings[i].Severity] > severityRank[a.findings[j].Severity]
+		}
+		return a.findings[i].NodeID < a.findings[j].NodeID
+	})
+
+	report := &Report{
+		Analyzed:        a.analyzed,
+		PlanningTimeMs:  plan.PlanningTimeMs,
+		ExecutionTimeMs: plan.ExecutionTimeMs,
+		TotalCost:       plan.Root.TotalCost,
+		NodeCount:       a.nextID,
+		Options:         opts,
+		Findings:        a.findings,
+		Tree:            tree,
+	}
+	report.Text = Render(report)
+	return report
+}
+
+// AnalyzeJSON lê a saída de EXPLAIN (FORMAT JSON) e a analisa
+func AnalyzeJSON(data []byte, opts Options) (*Report, error) {
+	plan, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(plan, opts), nil
+}
+
+var severityRank = map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+type analyzer struct {
+	opts     Options
+	analyzed bool
+	totalMs  float64
+	nextID   int
+	findings []Finding
+}
+
+// inclusive retorna o tempo total do nó somado em todas as execuções.
+// Abaixo de Gather o tempo por execução já é a média entre os processos paralelos,
+// que rodam ao mesmo tempo, e por isso não é multiplicado pelos loops.
+func (a *analyzer) inclusive(n *Node, parallel bool) float64 {
+	if n.ActualTotalTime == nil {
+		return 0
+	}
+	if parallel {
+		return *n.ActualTotalTime
+	}
+	return *n.ActualTotalTime * n.loops()
+}
+
+func (a *analyzer) walk(n *Node, depth int, parallel bool) *AnnotatedNode {
+	a.nextID++
+	node := &AnnotatedNode{
+		ID:            a.nextID,
+		Depth:         depth,
+		Label:         n.label(),
+		NodeType:      n.NodeType,
+		Relation:      n.relation(),
+		IndexName:     n.IndexName,
+		TotalCost:     n.TotalCost,
+		EstimatedRows: n.PlanRows,
+		ActualRows:    n.ActualRows,
+		Loops:         n.ActualLoops,
+	}
+
+	childParallel := parallel || n.NodeType == "Gather" || n.NodeType == "Gather Merge"
+	childrenMs := 0.0
+	for _, child := range n.Plans {
+		childrenMs += a.inclusive(child, childParallel)
+	}
+
+	if a.analyzed && n.ActualTotalTime != nil {
+		inclusive := a.inclusive(n, parallel)
+		exclusive := math.Max(inclusive-childrenMs, 0)
+		node.InclusiveTimeMs = round(inclusive)
+		node.ExclusiveTimeMs = round(exclusive)
+		if a.totalMs > 0 {
+			node.ExclusivePercent = round(exclusive / a.totalMs * 100)
+		}
+	}
+	if n.ActualRows != nil {
+		node.EstimateFactor = round(math.Max(*n.ActualRows, 1) / math.Max(n.PlanRows, 1))
+	}
+
+	a.check(n, node)
+
+	for _, child := range n.Plans {
+		node.Children = append(node.Children, a.walk(child, depth+1, childParallel))
+	}
+	return node
+}
+
+// check aplica as verificações ao nó e registra os achados
+func (a *analyzer) check(n *Node, node *AnnotatedNode) {
+	a.checkMisestimate(n, node)
+	a.checkSeqScan(n, node)
+	a.checkSpill(n, node)
+	a.checkNestedLoop(n, node)
+	a.checkIndexFilter(n, node)
+	a.checkHotspot(n, node)
+}
+
+func (a *analyzer) add(node *AnnotatedNode, kind, severity, message string, metrics map[string]float64) {
+	a.findings = append(a.findings, Finding{
+		Kind:     kind,
+		Severity: severity,
+		NodeID:   node.ID,
+		Node:     node.Label,
+		Message:  message,
+		Metrics:  metrics,
+	})
+	node.Findings = append(node.Findings, kind)
+}
+
+func (a *analyzer) checkMisestimate(n *Node, node *AnnotatedNode) {
+	// Nós nunca executados (loops = 0) não têm linhas reais
+	if n.ActualRows == nil || (n.ActualLoops != nil && *n.ActualLoops == 0) {
+		return
+	}
+	actual, estimated := *n.ActualRows, n.PlanRows
+	if math.Max(actual, estimated) < a.opts.MisestimateMinRows {
+		return
+	}
+
+	factor := math.Max(actual, 1) / math.Max(estimated, 1)
+	direction := "subestimou"
+	if factor < 1 {
+		factor = 1 / factor
+		direction = "superestimou"
+	}
+	if factor < a.opts.MisestimateFactor {
+		return
+	}
+
+	severity := SeverityWarning
+	if factor >= a.opts.MisestimateFactor*100 {
+		severity = SeverityCritical
+	}
+	a.add(node, FindingMisestimate, severity,
+		fmt.Sprintf("O planejador %s as linhas em %.0fx (estimou %.0f, leu %.0f por execução); verifique estatísticas (ANALYZE) e correlação entre colunas",
+			direction, factor, estimated, actual),
+		map[string]float64{"estimated_rows": estimated, "actual_rows": actual, "factor": *round(factor)})
+}
+
+func (a *analyzer) checkSeqScan(n *Node, node *AnnotatedNode) {
+	if n.NodeType != "Seq Scan" {
+		return
+	}
+
+	// Com ANALYZE as linhas lidas incluem as descartadas pelo filtro
+	read := n.PlanRows
+	if n.ActualRows != nil {
+		read = *n.ActualRows
+		if n.RowsRemovedFilter != nil {
+			read += *n.RowsRemovedFilter
+		}
+		read *= n.loops()
+	}
+	if read < a.opts.LargeScanRows {
+		return
+	}
+
+	severity := SeverityWarning
+	if read >= a.opts.LargeScanRows*10 {
+		severity = SeverityCritical
+	}
+	message := fmt.Sprintf("Seq scan em %s lê %.0f linhas", n.relation(), read)
+	if n.Filter != "" {
+		message += fmt.Sprintf(" para aplicar o filtro %s; um índice nas colunas do filtro pode evitar a leitura completa", n.Filter)
+	}
+	a.add(node, FindingSeqScanLarge, severity, message, map[string]float64{"rows_read": read})
+}
+
+func (a *analyzer) checkSpill(n *Node, node *AnnotatedNode) {
+	switch {
+	case n.SortSpaceType == "Disk":
+		space := value(n.SortSpaceUsed)
+		a.add(node, FindingDiskSpill, SeverityWarning,
+			fmt.Sprintf("Sort usou %.0f kB em disco (%s); aumente work_mem ou reduza as linhas ordenadas", space, n.SortMethod),
+			map[string]float64{"sort_space_kb": space})
+	case n.HashBatches != nil && *n.HashBatches > 1:
+		a.add(node, FindingDiskSpill, SeverityWarning,
+			fmt.Sprintf("Hash dividido em %.0f batches (planejado %.0f) por falta de memória; aumente work_mem ou hash_mem_multiplier",
+				*n.HashBatches, value(n.OriginalBatches)),
+			map[string]float64{"hash_batches": *n.HashBatches, "peak_memory_kb": value(n.PeakMemoryUsage)})
+	case n.HashAggBatches != nil && *n.HashAggBatches > 1:
+		a.add(node, FindingDiskSpill, SeverityWarning,
+			fmt.Sprintf("Agregação por hash gravou %.0f kB em disco em %.0f batches; aumente work_mem ou hash_mem_multiplier",
+				value(n.DiskUsage), *n.HashAggBatches),
+			map[string]float64{"hashagg_batches": *n.HashAggBatches, "disk_usage_kb": value(n.DiskUsage)})
+	}
+}
+
+func (a *analyzer) checkNestedLoop(n *Node, node *AnnotatedNode) {
+	if n.NodeType != "Nested Loop" || len(n.Plans) == 0 {
+		return
+	}
+
+	outer := n.Plans[0]
+	rows := outer.rows() * outer.loops()
+	if rows < a.opts.NestedLoopOuterRows {
+		return
+	}
+
+	severity := SeverityWarning
+	if rows >= a.opts.NestedLoopOuterRows*100 {
+		severity = SeverityCritical
+	}
+	inner := ""
+	if len(n.Plans) > 1 {
+		inner = n.Plans[1].label()
+	}
+	a.add(node, FindingNestedLoopOuter, severity,
+		fmt.Sprintf("Nested loop executa o lado interno (%s) para cada uma das %.0f linhas externas; um hash ou merge join tende a ser mais barato",
+			inner, rows),
+		map[string]float64{"outer_rows": rows})
+}
+
+func (a *analyzer) checkIndexFilter(n *Node, node *AnnotatedNode) {
+	switch n.NodeType {
+	case "Index Scan", "Index Only Scan", "Bitmap Heap Scan":
+	default:
+		return
+	}
+	if n.ActualRows == nil {
+		return
+	}
+
+	removed := (value(n.RowsRemovedFilter) + value(n.RowsRemovedRecheck)) * n.loops()
+	returned := *n.ActualRows * n.loops()
+	if removed < a.opts.FilterMinRemoved || removed/(removed+returned) < a.opts.FilterRemovedRatio {
+		return
+	}
+
+	ratio := removed / (removed + returned) * 100
+	condition := n.Filter
+	if condition == "" {
+		condition = n.RecheckCond
+	}
+	a.add(node, FindingFilterHeavyIndex, SeverityWarning,
+		fmt.Sprintf("%s descarta %.1f%% das linhas lidas (%.0f) no filtro %s; inclua as colunas do filtro no índice ou crie um índice parcial",
+			node.Label, ratio, removed, condition),
+		map[string]float64{"rows_removed": removed, "rows_returned": returned, "removed_percent": *round(ratio)})
+}
+
+func (a *analyzer) checkHotspot(n *Node, node *AnnotatedNode) {
+	if node.ExclusivePercent == nil || *node.ExclusivePercent < a.opts.HotspotPercent {
+		return
+	}
+	// Um plano de um único nó não tem onde concentrar o tempo
+	if node.Depth == 0 && len(n.Plans) == 0 {
+		return
+	}
+
+	severity := SeverityInfo
+	if *node.ExclusivePercent >= 50 {
+		severity = SeverityWarning
+	}
+	a.add(node, FindingExclusiveHotspot, severity,
+		fmt.Sprintf("%s concentra %.1f%% do tempo de execução (%.2f ms exclusivos)", node.Label, *node.ExclusivePercent, *node.ExclusiveTimeMs),
+		map[string]float64{"exclusive_time_ms": *node.ExclusiveTimeMs, "exclusive_percent": *node.ExclusivePercent})
+}
+
+func value(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// round arredonda para duas casas decimais
+func round(v float64) *float64 {
+	rounded := math.Round(v*100) / 100
+	return &rounded
+}
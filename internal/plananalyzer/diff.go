@@ -0,0 +1,411 @@
+package plananalyzer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tipos de mudança estrutural entre dois planos
+const (
+	ChangeNodeType    = "node_type"    // Nó correspondente mudou de tipo (ex: Hash Join -> Nested Loop)
+	ChangeNodeAdded   = "node_added"   // Nó existe apenas no plano novo
+	ChangeNodeRemoved = "node_removed" // Nó existe apenas no plano antigo
+)
+
+// NodeChange é uma diferença entre nós correspondentes dos dois planos
+type NodeChange struct {
+	Kind   string `json:"kind"`             // node_type, node_added ou node_removed
+	Path   string `json:"path"`             // Posição no plano novo, ou no antigo para node_removed (ex: 1.2.1 = primeiro filho do segundo filho da raiz)
+	Before string `json:"before,omitempty"` // Nó no plano antigo
+	After  string `json:"after,omitempty"`  // Nó no plano novo
+}
+
+// JoinOrderChange compara a ordem em que as relações aparecem nas folhas dos dois planos
+type JoinOrderChange struct {
+	Changed bool     `json:"changed"` // A ordem mudou
+	Before  []string `json:"before"`  // Relações na ordem do plano antigo
+	After   []string `json:"after"`   // Relações na ordem do plano novo
+}
+
+// AccessChange é uma mudança na forma de ler uma relação (seq scan, índice usado)
+type AccessChange struct {
+	Relation    string `json:"relation"`               // Relação (alias quando presente)
+	Before      string `json:"before"`                 // Acesso no plano antigo (vazio = relação ausente)
+	After       string `json:"after"`                  // Acesso no plano novo (vazio = relação ausente)
+	IndexBefore string `json:"index_before,omitempty"` // Índice usado no plano antigo
+	IndexAfter  string `json:"index_after,omitempty"`  // Índice usado no plano novo
+}
+
+// EstimateDrift compara linhas estimadas e reais de uma relação nos dois planos
+type EstimateDrift struct {
+	Relation        string   `json:"relation"`                // Relação (alias quando presente)
+	BeforeEstimated float64  `json:"before_estimated"`        // Linhas estimadas no plano antigo
+	BeforeActual    *float64 `json:"before_actual,omitempty"` // Linhas reais no plano antigo (ANALYZE)
+	BeforeFactor    *float64 `json:"before_factor,omitempty"` // Reais / estimadas no plano antigo
+	AfterEstimated  float64  `json:"after_estimated"`         // Linhas estimadas no plano novo
+	AfterActual     *float64 `json:"after_actual,omitempty"`  // Linhas reais no plano novo (ANALYZE)
+	AfterFactor     *float64 `json:"after_factor,omitempty"`  // Reais / estimadas no plano novo
+	Worsened        bool     `json:"worsened"`                // Erro de estimativa passou do limite no plano novo
+}
+
+// PlanSummary resume um dos lados da comparação
+type PlanSummary struct {
+	TotalCost       float64  `json:"total_cost"`                  // Custo estimado da raiz
+	ExecutionTimeMs *float64 `json:"execution_time_ms,omitempty"` // Tempo de execução (ANALYZE)
+	NodeCount       int      `json:"node_count"`                  // Quantidade de nós
+	Analyzed        bool     `json:"analyzed"`                    // Capturado com ANALYZE
+}
+
+// PlanDiff é a comparação estrutural entre dois planos da mesma query
+type PlanDiff struct {
+	Identical      bool            `json:"identical"`       // Mesma estrutura, mesmos acessos e mesma ordem de junção
+	Before         PlanSummary     `json:"before"`          // Plano antigo
+	After          PlanSummary     `json:"after"`           // Plano novo
+	CostRatio      *float64        `json:"cost_ratio"`      // Custo novo / custo antigo
+	NodeChanges    []NodeChange    `json:"node_changes"`    // Tipos de nó trocados, nós adicionados ou removidos
+	JoinOrder      JoinOrderChange `json:"join_order"`      // Ordem das relações nas junções
+	AccessChanges  []AccessChange  `json:"access_changes"`  // Mudanças de seq scan/índice por relação
+	EstimateDrifts []EstimateDrift `json:"estimate_drifts"` // Estimado x real por relação
+}
+
+// Diff compara dois planos da mesma query. Os filhos de cada nó, e as próprias raízes, são
+// alinhados por tipo e relação/índice antes de cair na posição, para que um nó inserido
+// (ex: Gather) não desloque os demais; acessos e estimativas são comparados por relação (alias), o que mantém a comparação
+// mesmo quando a ordem de junção muda. factor é o erro de estimativa (ex: 10) a partir do
+// qual uma relação é marcada como piorada.
+func Diff(before, after *Plan, factor float64) *PlanDiff {
+	diff := &PlanDiff{
+		Before:         summarize(before),
+		After:          summarize(after),
+		NodeChanges:    []NodeChange{},
+		AccessChanges:  []AccessChange{},
+		EstimateDrifts: []EstimateDrift{},
+	}
+	if before.Root.TotalCost > 0 {
+		diff.CostRatio = round(after.Root.TotalCost / before.Root.TotalCost)
+	}
+
+	diffRoots(before.Root, after.Root, &diff.NodeChanges)
+
+	beforeScans, afterScans := scans(before.Root), scans(after.Root)
+	diff.JoinOrder = JoinOrderChange{Before: scanOrder(beforeScans), After: scanOrder(afterScans)}
+	diff.JoinOrder.Changed = strings.Join(diff.JoinOrder.Before, ",") != strings.Join(diff.JoinOrder.After, ",")
+
+	beforeByKey := map[string]*Node{}
+	for _, s := range beforeScans {
+		beforeByKey[s.key] = s.node
+	}
+	afterByKey := map[string]*Node{}
+	for _, s := range afterScans {
+		afterByKey[s.key] = s.node
+	}
+
+	for _, key := range mergeKeys(beforeScans, afterScans) {
+		b, a := beforeByKey[key], afterByKey[key]
+		if access(b) != access(a) {
+			change := AccessChange{Relation: key, Before: access(b), After: access(a)}
+			if b != nil {
+				change.IndexBefore = b.IndexName
+			}
+			if a != nil {
+				change.IndexAfter = a.IndexName
+			}
+			diff.AccessChanges = append(diff.AccessChanges, change)
+		}
+		if b != nil && a != nil {
+			diff.EstimateDrifts = append(diff.EstimateDrifts, drift(key, b, a, factor))
+		}
+	}
+
+	diff.Identical = len(diff.NodeChanges) == 0 && len(diff.AccessChanges) == 0 && !diff.JoinOrder.Changed
+	return diff
+}
+
+func summarize(plan *Plan) PlanSummary {
+	count := 0
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		count++
+		for _, child := range n.Plans {
+			visit(child)
+		}
+	}
+	visit(plan.Root)
+
+	return PlanSummary{
+		TotalCost:       plan.Root.TotalCost,
+		ExecutionTimeMs: plan.ExecutionTimeMs,
+		NodeCount:       count,
+		Analyzed:        plan.Analyzed(),
+	}
+}
+
+// diffNodes compara recursivamente dois nós correspondentes. Os filhos são pareados pela
+// maior subsequência comum de tipo e relação/índice (matchChildren); os que sobram entre dois
+// pares são tratados por diffUnmatched. beforePath e afterPath são as posições do nó em cada plano.
+func diffNodes(before, after *Node, beforePath, afterPath string, changes *[]NodeChange) {
+	if before.NodeType != after.NodeType {
+		*changes = append(*changes, NodeChange{Kind: ChangeNodeType, Path: afterPath, Before: before.label(), After: after.label()})
+	}
+
+	nextBefore, nextAfter := 0, 0
+	pairs := append(matchChildren(before.Plans, after.Plans), childPair{before: len(before.Plans), after: len(after.Plans)})
+	for _, p := range pairs {
+		diffUnmatched(before.Plans, after.Plans, nextBefore, p.before, nextAfter, p.after, beforePath, afterPath, changes)
+		if p.before < len(before.Plans) {
+			diffNodes(before.Plans[p.before], after.Plans[p.after], childPath(beforePath, p.before), childPath(afterPath, p.after), changes)
+		}
+		nextBefore, nextAfter = p.before+1, p.after+1
+	}
+}
+
+// diffRoots compara as raízes. Como entre filhos (diffUnmatched), nós de filho único acima de
+// um nó igual à outra raiz são invólucros adicionados ou removidos (ex: Gather ou Gather Merge
+// e Sort de um plano paralelo) e só eles são reportados, sem deslocar o restante do plano.
+func diffRoots(before, after *Node, changes *[]NodeChange) {
+	if !sameNode(before, after) {
+		if wrappers, inner, path := unwrap(after, before); inner != nil {
+			for k, wrapper := range wrappers {
+				*changes = append(*changes, NodeChange{Kind: ChangeNodeAdded, Path: "1" + strings.Repeat(".1", k), After: wrapper.label()})
+			}
+			diffNodes(before, inner, "1", path, changes)
+			return
+		}
+		if wrappers, inner, path := unwrap(before, after); inner != nil {
+			for k, wrapper := range wrappers {
+				*changes = append(*changes, NodeChange{Kind: ChangeNodeRemoved, Path: "1" + strings.Repeat(".1", k), Before: wrapper.label()})
+			}
+			diffNodes(inner, after, path, "1", changes)
+			return
+		}
+	}
+	diffNodes(before, after, "1", "1", changes)
+}
+
+// unwrap desce pelos nós de filho único a partir de root até um nó igual a target (sameNode).
+// Retorna os nós atravessados, o nó encontrado e sua posição, ou inner nil se não houver.
+func unwrap(root, target *Node) (wrappers []*Node, inner *Node, path string) {
+	path = "1"
+	for node := root; len(node.Plans) == 1; node = node.Plans[0] {
+		wrappers = append(wrappers, node)
+		path += ".1"
+		if sameNode(node.Plans[0], target) {
+			return wrappers, node.Plans[0], path
+		}
+	}
+	return nil, nil, ""
+}
+
+// childPair é um filho do plano antigo pareado com um filho do plano novo
+type childPair struct {
+	before int
+	after  int
+}
+
+// matchChildren pareia os filhos com a mesma identidade (sameNode) pela maior subsequência
+// comum, preservando a ordem. Filhos fora dela ficam para diffUnmatched.
+func matchChildren(before, after []*Node) []childPair {
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case sameNode(before[i], after[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	pairs := []childPair{}
+	for i, j := 0, 0; i < len(before) && j < len(after); {
+		switch {
+		case sameNode(before[i], after[j]):
+			pairs = append(pairs, childPair{before: i, after: j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// diffUnmatched trata os filhos before[bFrom:bTo] e after[aFrom:aTo] que não entraram na
+// subsequência comum. Um nó com um único filho idêntico a um nó do outro lado é um invólucro
+// inserido ou removido (ex: Gather, Materialize, Hash) e só ele é reportado; o restante é
+// comparado pela posição, e o excedente de um dos lados vira node_added ou node_removed.
+func diffUnmatched(before, after []*Node, bFrom, bTo, aFrom, aTo int, beforePath, afterPath string, changes *[]NodeChange) {
+	usedBefore := map[int]bool{}
+	usedAfter := map[int]bool{}
+
+	for j := aFrom; j < aTo; j++ {
+		if len(after[j].Plans) != 1 {
+			continue
+		}
+		for i := bFrom; i < bTo; i++ {
+			if !usedBefore[i] && sameNode(before[i], after[j].Plans[0]) {
+				usedBefore[i], usedAfter[j] = true, true
+				*changes = append(*changes, NodeChange{Kind: ChangeNodeAdded, Path: childPath(afterPath, j), After: after[j].label()})
+				diffNodes(before[i], after[j].Plans[0], childPath(beforePath, i), childPath(afterPath, j)+".1", changes)
+				break
+			}
+		}
+	}
+
+	for i := bFrom; i < bTo; i++ {
+		if usedBefore[i] || len(before[i].Plans) != 1 {
+			continue
+		}
+		for j := aFrom; j < aTo; j++ {
+			if !usedAfter[j] && sameNode(before[i].Plans[0], after[j]) {
+				usedBefore[i], usedAfter[j] = true, true
+				*changes = append(*changes, NodeChange{Kind: ChangeNodeRemoved, Path: childPath(beforePath, i), Before: before[i].label()})
+				diffNodes(before[i].Plans[0], after[j], childPath(beforePath, i)+".1", childPath(afterPath, j), changes)
+				break
+			}
+		}
+	}
+
+	var restBefore, restAfter []int
+	for i := bFrom; i < bTo; i++ {
+		if !usedBefore[i] {
+			restBefore = append(restBefore, i)
+		}
+	}
+	for j := aFrom; j < aTo; j++ {
+		if !usedAfter[j] {
+			restAfter = append(restAfter, j)
+		}
+	}
+
+	for k := 0; k < len(restBefore) || k < len(restAfter); k++ {
+		switch {
+		case k >= len(restAfter):
+			i := restBefore[k]
+			*changes = append(*changes, NodeChange{Kind: ChangeNodeRemoved, Path: childPath(beforePath, i), Before: before[i].label()})
+		case k >= len(restBefore):
+			j := restAfter[k]
+			*changes = append(*changes, NodeChange{Kind: ChangeNodeAdded, Path: childPath(afterPath, j), After: after[j].label()})
+		default:
+			i, j := restBefore[k], restAfter[k]
+			diffNodes(before[i], after[j], childPath(beforePath, i), childPath(afterPath, j), changes)
+		}
+	}
+}
+
+// sameNode indica se dois nós têm o mesmo tipo e leem a mesma relação pelo mesmo índice
+func sameNode(a, b *Node) bool {
+	return a.NodeType == b.NodeType && a.relation() == b.relation() && a.Alias == b.Alias && a.IndexName == b.IndexName
+}
+
+func childPath(path string, index int) string {
+	return path + "." + strconv.Itoa(index+1)
+}
+
+// scan é um nó que lê uma relação, identificado pelo alias
+type scan struct {
+	key  string
+	node *Node
+}
+
+// scans lista os nós que leem relações, em pré-ordem. Aliases repetidos (subplanos)
+// recebem o sufixo #2, #3...
+func scans(root *Node) []scan {
+	result := []scan{}
+	seen := map[string]int{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if n.RelationName != "" {
+			key := n.Alias
+			if key == "" {
+				key = n.relation()
+			}
+			seen[key]++
+			if seen[key] > 1 {
+				key = fmt.Sprintf("%s#%d", key, seen[key])
+			}
+			result = append(result, scan{key: key, node: n})
+		}
+		for _, child := range n.Plans {
+			visit(child)
+		}
+	}
+	visit(root)
+	return result
+}
+
+func scanOrder(scans []scan) []string {
+	order := make([]string, 0, len(scans))
+	for _, s := range scans {
+		order = append(order, s.key)
+	}
+	return order
+}
+
+// mergeKeys retorna as relações dos dois planos, na ordem do plano antigo seguida das novas
+func mergeKeys(before, after []scan) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, list := range [][]scan{before, after} {
+		for _, s := range list {
+			if !seen[s.key] {
+				seen[s.key] = true
+				keys = append(keys, s.key)
+			}
+		}
+	}
+	return keys
+}
+
+// access descreve como a relação é lida (ex: "Index Scan using orders_pkey")
+func access(n *Node) string {
+	if n == nil {
+		return ""
+	}
+	description := n.NodeType
+	if n.ParallelAware {
+		description = "Parallel " + description
+	}
+	if n.IndexName != "" {
+		description += " using " + n.IndexName
+	}
+	return description
+}
+
+func drift(key string, before, after *Node, factor float64) EstimateDrift {
+	d := EstimateDrift{
+		Relation:        key,
+		BeforeEstimated: before.PlanRows,
+		BeforeActual:    before.ActualRows,
+		AfterEstimated:  after.PlanRows,
+		AfterActual:     after.ActualRows,
+	}
+	if before.ActualRows != nil {
+		d.BeforeFactor = round(math.Max(*before.ActualRows, 1) / math.Max(before.PlanRows, 1))
+	}
+	if after.ActualRows != nil {
+		d.AfterFactor = round(math.Max(*after.ActualRows, 1) / math.Max(after.PlanRows, 1))
+	}
+
+	afterError := estimateError(d.AfterFactor)
+	d.Worsened = afterError >= factor && afterError > estimateError(d.BeforeFactor)
+	return d
+}
+
+// estimateError converte reais/estimadas em erro absoluto (>= 1), nos dois sentidos
+func estimateError(factor *float64) float64 {
+	if factor == nil || *factor <= 0 {
+		return 1
+	}
+	return math.Max(*factor, 1 / *factor)
+}
@@ -0,0 +1,127 @@
+// Package plananalyzer interpreta planos de EXPLAIN (FORMAT JSON) e aponta os problemas mais
+// comuns de execução: estimativas erradas, seq scans em relações grandes, sorts e hashes que
+// vão para disco, nested loops com muitas linhas externas, index scans que descartam a maior
+// parte das linhas e os nós que concentram o tempo de execução.
+package plananalyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPlan indica que o JSON não é a saída de EXPLAIN (FORMAT JSON)
+var ErrInvalidPlan = errors.New("plano inválido")
+
+// Node é um nó do plano como emitido por EXPLAIN (FORMAT JSON).
+// Campos de execução (Actual*, Rows Removed, Sort Space, Hash Batches) só existem com ANALYZE.
+type Node struct {
+	NodeType           string   `json:"Node Type"`
+	ParentRelationship string   `json:"Parent Relationship,omitempty"`
+	ParallelAware      bool     `json:"Parallel Aware,omitempty"`
+	RelationName       string   `json:"Relation Name,omitempty"`
+	Schema             string   `json:"Schema,omitempty"`
+	Alias              string   `json:"Alias,omitempty"`
+	IndexName          string   `json:"Index Name,omitempty"`
+	JoinType           string   `json:"Join Type,omitempty"`
+	Strategy           string   `json:"Strategy,omitempty"`
+	StartupCost        float64  `json:"Startup Cost"`
+	TotalCost          float64  `json:"Total Cost"`
+	PlanRows           float64  `json:"Plan Rows"`
+	PlanWidth          float64  `json:"Plan Width"`
+	ActualStartupTime  *float64 `json:"Actual Startup Time,omitempty"`
+	ActualTotalTime    *float64 `json:"Actual Total Time,omitempty"`
+	ActualRows         *float64 `json:"Actual Rows,omitempty"`
+	ActualLoops        *float64 `json:"Actual Loops,omitempty"`
+	Filter             string   `json:"Filter,omitempty"`
+	IndexCond          string   `json:"Index Cond,omitempty"`
+	RecheckCond        string   `json:"Recheck Cond,omitempty"`
+	HashCond           string   `json:"Hash Cond,omitempty"`
+	JoinFilter         string   `json:"Join Filter,omitempty"`
+	RowsRemovedFilter  *float64 `json:"Rows Removed by Filter,omitempty"`
+	RowsRemovedRecheck *float64 `json:"Rows Removed by Index Recheck,omitempty"`
+	SortMethod         string   `json:"Sort Method,omitempty"`
+	SortSpaceUsed      *float64 `json:"Sort Space Used,omitempty"`
+	SortSpaceType      string   `json:"Sort Space Type,omitempty"`
+	HashBatches        *float64 `json:"Hash Batches,omitempty"`
+	OriginalBatches    *float64 `json:"Original Hash Batches,omitempty"`
+	PeakMemoryUsage    *float64 `json:"Peak Memory Usage,omitempty"`
+	HashAggBatches     *float64 `json:"HashAgg Batches,omitempty"`
+	DiskUsage          *float64 `json:"Disk Usage,omitempty"`
+	TempWrittenBlocks  *float64 `json:"Temp Written Blocks,omitempty"`
+	Plans              []*Node  `json:"Plans,omitempty"`
+}
+
+// Plan é a saída completa de um EXPLAIN (FORMAT JSON)
+type Plan struct {
+	Root            *Node    `json:"Plan"`
+	PlanningTimeMs  *float64 `json:"Planning Time,omitempty"`
+	ExecutionTimeMs *float64 `json:"Execution Time,omitempty"`
+}
+
+// Analyzed indica se o plano foi capturado com ANALYZE
+func (p *Plan) Analyzed() bool {
+	return p.Root != nil && p.Root.ActualTotalTime != nil
+}
+
+// Parse lê a saída de EXPLAIN (FORMAT JSON): uma lista com um objeto que contém "Plan".
+// Também aceita o objeto sem a lista.
+func Parse(data []byte) (*Plan, error) {
+	var plans []Plan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		var single Plan
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPlan, err)
+		}
+		plans = []Plan{single}
+	}
+	if len(plans) == 0 || plans[0].Root == nil {
+		return nil, fmt.Errorf("%w: nó \"Plan\" ausente", ErrInvalidPlan)
+	}
+	return &plans[0], nil
+}
+
+// loops retorna Actual Loops, considerando 1 quando ausente ou zero
+func (n *Node) loops() float64 {
+	if n.ActualLoops == nil || *n.ActualLoops <= 0 {
+		return 1
+	}
+	return *n.ActualLoops
+}
+
+// rows retorna as linhas reais por execução quando há ANALYZE, senão as estimadas
+func (n *Node) rows() float64 {
+	if n.ActualRows != nil {
+		return *n.ActualRows
+	}
+	return n.PlanRows
+}
+
+// label descreve o nó como no EXPLAIN em texto (ex: "Index Scan using idx on orders o")
+func (n *Node) label() string {
+	label := n.NodeType
+	if n.ParallelAware {
+		label = "Parallel " + label
+	}
+	if n.JoinType != "" && n.JoinType != "Inner" {
+		label += " " + n.JoinType
+	}
+	if n.IndexName != "" {
+		label += " using " + n.IndexName
+	}
+	if n.RelationName != "" {
+		label += " on " + n.relation()
+		if n.Alias != "" && n.Alias != n.RelationName {
+			label += " " + n.Alias
+		}
+	}
+	return label
+}
+
+// relation retorna schema.tabela quando o schema está presente (EXPLAIN VERBOSE)
+func (n *Node) relation() string {
+	if n.Schema != "" && n.RelationName != "" {
+		return n.Schema + "." + n.RelationName
+	}
+	return n.RelationName
+}
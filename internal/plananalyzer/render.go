@@ -0,0 +1,59 @@
+package plananalyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityMarks prefixa os achados na árvore em texto
+var severityMarks = map[string]string{SeverityInfo: "ℹ", SeverityWarning: "⚠", SeverityCritical: "‼"}
+
+// Render escreve a árvore anotada em texto, no formato do EXPLAIN com as métricas calculadas
+// e os achados de cada nó logo abaixo dele
+func Render(report *Report) string {
+	byNode := map[int][]Finding{}
+	for _, f := range report.Findings {
+		byNode[f.NodeID] = append(byNode[f.NodeID], f)
+	}
+
+	var b strings.Builder
+	if report.ExecutionTimeMs != nil {
+		fmt.Fprintf(&b, "Execution Time: %.3f ms", *report.ExecutionTimeMs)
+		if report.PlanningTimeMs != nil {
+			fmt.Fprintf(&b, " (planning %.3f ms)", *report.PlanningTimeMs)
+		}
+		b.WriteString("\n")
+	} else {
+		fmt.Fprintf(&b, "Total Cost: %.2f (sem ANALYZE)\n", report.TotalCost)
+	}
+	renderNode(&b, report.Tree, byNode)
+	return b.String()
+}
+
+func renderNode(b *strings.Builder, node *AnnotatedNode, findings map[int][]Finding) {
+	indent := strings.Repeat("   ", node.Depth)
+	arrow := ""
+	if node.Depth > 0 {
+		arrow = "-> "
+	}
+
+	fmt.Fprintf(b, "%s%s[#%d] %s  (cost=%.2f rows=%.0f", indent, arrow, node.ID, node.Label, node.TotalCost, node.EstimatedRows)
+	if node.ActualRows != nil {
+		fmt.Fprintf(b, " actual_rows=%.0f loops=%.0f", *node.ActualRows, value(node.Loops))
+	}
+	b.WriteString(")")
+	if node.InclusiveTimeMs != nil {
+		fmt.Fprintf(b, " time=%.3fms self=%.3fms", *node.InclusiveTimeMs, value(node.ExclusiveTimeMs))
+		if node.ExclusivePercent != nil {
+			fmt.Fprintf(b, " (%.1f%%)", *node.ExclusivePercent)
+		}
+	}
+	b.WriteString("\n")
+
+	for _, f := range findings[node.ID] {
+		fmt.Fprintf(b, "%s   %s %s: %s\n", indent, severityMarks[f.Severity], f.Kind, f.Message)
+	}
+	for _, child := range node.Children {
+		renderNode(b, child, findings)
+	}
+}
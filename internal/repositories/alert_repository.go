@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// AlertRepository gerencia a tabela alert_events
+type AlertRepository struct {
+	db *database.DB
+}
+
+// NewAlertRepository cria um novo repositório de alertas
+func NewAlertRepository(db *database.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// InsertEvent grava um evento de alerta e preenche ID e CreatedAt
+func (r *AlertRepository) InsertEvent(event *models.AlertEvent) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	details := "{}"
+	if len(event.Details) > 0 {
+		details = string(event.Details)
+	}
+
+	query := `
+	INSERT INTO alert_events (target_name, source, event_key, severity, title, message, details, state, value)
+	VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9)
+	RETURNING id, created_at`
+
+	err := r.db.QueryRowx(query, event.TargetName, event.Source, event.EventKey, event.Severity,
+		event.Title, event.Message, details, event.State, event.Value).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar evento de alerta: %w", err)
+	}
+
+	return nil
+}
+
+// HasRecentEvent indica se já existe um evento com a mesma chave desde since (deduplicação)
+func (r *AlertRepository) HasRecentEvent(target, source, key string, since time.Time) (bool, error) {
+	if r.db == nil {
+		return false, ErrNoDatabase
+	}
+
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM alert_events
+		WHERE target_name IS NOT DISTINCT FROM nullif($1, '')
+			AND source = $2 AND event_key = $3 AND created_at >= $4
+	)`
+
+	var exists bool
+	if err := r.db.Get(&exists, query, target, source, key, since); err != nil {
+		return false, fmt.Errorf("falha ao consultar eventos de alerta: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListEvents retorna os eventos que atendem aos filtros, do mais recente para o mais antigo
+func (r *AlertRepository) ListEvents(q models.AlertEventQuery) ([]models.AlertEvent, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	conditions := []string{"created_at >= $1", "created_at < $2"}
+	args := []interface{}{q.From, q.To}
+	filter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	filter("target_name", q.Target)
+	filter("source", q.Source)
+	filter("severity", q.Severity)
+	filter("event_key", q.EventKey)
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`
+	SELECT id, target_name, source, event_key, severity, title, message, details, state, value, created_at
+	FROM alert_events
+	WHERE %s
+	ORDER BY created_at DESC
+	LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	events := []models.AlertEvent{}
+	if err := r.db.Select(&events, query, args...); err != nil {
+		return nil, fmt.Errorf("falha ao listar eventos de alerta: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrAlertRuleNotFound indica que a regra de alerta solicitada não existe
+var ErrAlertRuleNotFound = errors.New("regra de alerta não encontrada")
+
+// ErrAlertRuleExists indica que já existe uma regra de alerta com o mesmo nome
+var ErrAlertRuleExists = errors.New("já existe uma regra de alerta com este nome")
+
+const alertRuleColumns = `id, name, description, target_name, metric, condition, operator, threshold,
+		window_seconds, for_seconds, severity, no_data_state, enabled, created_at, updated_at`
+
+const alertRuleStateColumns = `rule_id, state, value, active_since, fired_at, resolved_at, evaluated_at`
+
+// AlertRuleRepository gerencia as tabelas alert_rules e alert_rule_states
+type AlertRuleRepository struct {
+	db *database.DB
+}
+
+// NewAlertRuleRepository cria um novo repositório de regras de alerta
+func NewAlertRuleRepository(db *database.DB) *AlertRuleRepository {
+	return &AlertRuleRepository{db: db}
+}
+
+// List retorna todas as regras de alerta
+func (r *AlertRuleRepository) List() ([]models.AlertRule, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	rules := []models.AlertRule{}
+	query := "SELECT " + alertRuleColumns + " FROM alert_rules ORDER BY name"
+	if err := r.db.Select(&rules, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar regras de alerta: %w", err)
+	}
+
+	return rules, nil
+}
+
+// ListEnabled retorna apenas as regras com avaliação habilitada
+func (r *AlertRuleRepository) ListEnabled() ([]models.AlertRule, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	rules := []models.AlertRule{}
+	query := "SELECT " + alertRuleColumns + " FROM alert_rules WHERE enabled ORDER BY name"
+	if err := r.db.Select(&rules, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar regras de alerta: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Find busca uma regra pelo ID ou pelo nome
+func (r *AlertRuleRepository) Find(idOrName string) (*models.AlertRule, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	rule := &models.AlertRule{}
+	query := "SELECT " + alertRuleColumns + " FROM alert_rules WHERE id::text = $1 OR name = $1 LIMIT 1"
+	if err := r.db.Get(rule, query, idOrName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar regra de alerta: %w", err)
+	}
+
+	return rule, nil
+}
+
+// Create grava uma nova regra de alerta
+func (r *AlertRuleRepository) Create(rule *models.AlertRule) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO alert_rules (name, description, target_name, metric, condition, operator, threshold,
+		window_seconds, for_seconds, severity, no_data_state, enabled)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowx(query, rule.Name, rule.Description, rule.TargetName, rule.Metric, rule.Condition,
+		rule.Operator, rule.Threshold, rule.WindowSeconds, rule.ForSeconds, rule.Severity, rule.NoDataState, rule.Enabled).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlertRuleExists
+		}
+		return fmt.Errorf("falha ao criar regra de alerta: %w", err)
+	}
+
+	return nil
+}
+
+// Update altera uma regra de alerta existente
+func (r *AlertRuleRepository) Update(rule *models.AlertRule) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	UPDATE alert_rules
+	SET name = $2, description = $3, target_name = $4, metric = $5, condition = $6, operator = $7,
+		threshold = $8, window_seconds = $9, for_seconds = $10, severity = $11, no_data_state = $12, enabled = $13
+	WHERE id = $1
+	RETURNING updated_at`
+
+	err := r.db.QueryRowx(query, rule.ID, rule.Name, rule.Description, rule.TargetName, rule.Metric, rule.Condition,
+		rule.Operator, rule.Threshold, rule.WindowSeconds, rule.ForSeconds, rule.Severity, rule.NoDataState, rule.Enabled).
+		Scan(&rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlertRuleNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrAlertRuleExists
+		}
+		return fmt.Errorf("falha ao atualizar regra de alerta: %w", err)
+	}
+
+	return nil
+}
+
+// Delete remove uma regra de alerta e seu estado
+func (r *AlertRuleRepository) Delete(id string) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	result, err := r.db.Exec("DELETE FROM alert_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("falha ao remover regra de alerta: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// ListStates retorna o estado corrente das regras avaliadas, indexado pelo ID da regra
+func (r *AlertRuleRepository) ListStates() (map[string]models.AlertRuleState, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	states := []models.AlertRuleState{}
+	if err := r.db.Select(&states, "SELECT "+alertRuleStateColumns+" FROM alert_rule_states"); err != nil {
+		return nil, fmt.Errorf("falha ao listar estados das regras de alerta: %w", err)
+	}
+
+	byRule := make(map[string]models.AlertRuleState, len(states))
+	for _, state := range states {
+		byRule[state.RuleID] = state
+	}
+	return byRule, nil
+}
+
+// GetState retorna o estado corrente de uma regra; nil quando ela ainda não foi avaliada
+func (r *AlertRuleRepository) GetState(ruleID string) (*models.AlertRuleState, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	state := &models.AlertRuleState{}
+	query := "SELECT " + alertRuleStateColumns + " FROM alert_rule_states WHERE rule_id = $1"
+	if err := r.db.Get(state, query, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao buscar estado da regra de alerta: %w", err)
+	}
+
+	return state, nil
+}
+
+// SaveState grava o estado corrente de uma regra
+func (r *AlertRuleRepository) SaveState(state models.AlertRuleState) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO alert_rule_states (` + alertRuleStateColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (rule_id) DO UPDATE SET
+		state = EXCLUDED.state,
+		value = EXCLUDED.value,
+		active_since = EXCLUDED.active_since,
+		fired_at = EXCLUDED.fired_at,
+		resolved_at = EXCLUDED.resolved_at,
+		evaluated_at = EXCLUDED.evaluated_at`
+
+	_, err := r.db.Exec(query, state.RuleID, state.State, state.Value, state.ActiveSince,
+		state.FiredAt, state.ResolvedAt, state.EvaluatedAt)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar estado da regra de alerta: %w", err)
+	}
+
+	return nil
+}
@@ -1,14 +1,26 @@
 package repositories
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/lib/pq"
 	"pganalytics-backend/internal/database"
 	"pganalytics-backend/internal/models"
 )
 
+// ErrNoDatabase indica que o repositório foi criado sem conexão com o banco
+var ErrNoDatabase = errors.New("banco de dados não conectado")
+
 // AnalyticsRepository maneja operações de analytics no banco
 type AnalyticsRepository struct {
 	db *database.DB
+
+	mu      sync.Mutex
+	catalog *QueryCatalog
 }
 
 // NewAnalyticsRepository cria um novo repositório de analytics
@@ -22,32 +34,21 @@ func (r *AnalyticsRepository) GetSlowQueries() ([]models.SlowQuery, error) {
 
 	// Verificar se o banco está conectado
 	if r.db == nil {
-		return getMockSlowQueries(), nil
+		return nil, ErrNoDatabase
 	}
 
-	// Query para obter queries lentas (precisa da extensão pg_stat_statements)
-	query := `
-	SELECT 
-		substring(query, 1, 150) as query_text,
-		round(mean_exec_time, 2) as duration_ms,
-		calls,
-		rows
-	FROM pg_stat_statements 
-	WHERE mean_exec_time > 100
-	ORDER BY mean_exec_time DESC
-	LIMIT 10`
-
-	// Tentar executar a query
-	err := r.db.Select(&queries, query)
+	// A coluna de tempo depende da versão de pg_stat_statements
+	catalog, err := r.Catalog()
 	if err != nil {
-		log.Printf("⚠️ Erro ao buscar queries lentas: %v", err)
-		log.Printf("⚠️ Usando dados mock")
-		return getMockSlowQueries(), nil
+		return nil, err
+	}
+	query, err := catalog.SlowQueriesQuery()
+	if err != nil {
+		return nil, err
 	}
 
-	// Se não houver resultados, usar mock
-	if len(queries) == 0 {
-		return getMockSlowQueries(), nil
+	if err := r.db.Select(&queries, query); err != nil {
+		return nil, fmt.Errorf("falha ao buscar queries lentas: %w", err)
 	}
 
 	return queries, nil
@@ -59,7 +60,7 @@ func (r *AnalyticsRepository) GetTableStats() ([]models.TableStat, error) {
 
 	// Verificar se o banco está conectado
 	if r.db == nil {
-		return getMockTableStats(), nil
+		return nil, ErrNoDatabase
 	}
 
 	// Query para obter estatísticas das tabelas
@@ -78,17 +79,8 @@ func (r *AnalyticsRepository) GetTableStats() ([]models.TableStat, error) {
 	ORDER BY pg_total_relation_size(schemaname || '.' || relname) DESC
 	LIMIT 10`
 
-	// Tentar executar a query
-	err := r.db.Select(&stats, query)
-	if err != nil {
-		log.Printf("⚠️ Erro ao buscar estatísticas de tabelas: %v", err)
-		log.Printf("⚠️ Usando dados mock")
-		return getMockTableStats(), nil
-	}
-
-	// Se não houver resultados, usar mock
-	if len(stats) == 0 {
-		return getMockTableStats(), nil
+	if err := r.db.Select(&stats, query); err != nil {
+		return nil, fmt.Errorf("falha ao buscar estatísticas de tabelas: %w", err)
 	}
 
 	return stats, nil
@@ -98,7 +90,7 @@ func (r *AnalyticsRepository) GetTableStats() ([]models.TableStat, error) {
 func (r *AnalyticsRepository) GetConnectionStats() (*models.ConnectionStats, error) {
 	// Verificar se o banco está conectado
 	if r.db == nil {
-		return getMockConnectionStats(), nil
+		return nil, ErrNoDatabase
 	}
 
 	stats := &models.ConnectionStats{}
@@ -106,8 +98,7 @@ func (r *AnalyticsRepository) GetConnectionStats() (*models.ConnectionStats, err
 	// Query para obter máximo de conexões configuradas
 	err := r.db.Get(&stats.MaxConnections, "SHOW max_connections")
 	if err != nil {
-		log.Printf("⚠️ Erro ao buscar max_connections: %v", err)
-		return getMockConnectionStats(), nil
+		return nil, fmt.Errorf("falha ao buscar max_connections: %w", err)
 	}
 
 	// Query para obter estatísticas de conexões
@@ -127,8 +118,7 @@ func (r *AnalyticsRepository) GetConnectionStats() (*models.ConnectionStats, err
 	)
 
 	if err != nil {
-		log.Printf("⚠️ Erro ao buscar estatísticas de conexões: %v", err)
-		return getMockConnectionStats(), nil
+		return nil, fmt.Errorf("falha ao buscar estatísticas de conexões: %w", err)
 	}
 
 	return stats, nil
@@ -138,7 +128,7 @@ func (r *AnalyticsRepository) GetConnectionStats() (*models.ConnectionStats, err
 func (r *AnalyticsRepository) GetDatabaseSize() (*models.DatabaseSize, error) {
 	// Verificar se o banco está conectado
 	if r.db == nil {
-		return getMockDatabaseSize(), nil
+		return nil, ErrNoDatabase
 	}
 
 	size := &models.DatabaseSize{}
@@ -159,8 +149,7 @@ func (r *AnalyticsRepository) GetDatabaseSize() (*models.DatabaseSize, error) {
 	)
 
 	if err != nil {
-		log.Printf("⚠️ Erro ao buscar tamanho do banco: %v", err)
-		return getMockDatabaseSize(), nil
+		return nil, fmt.Errorf("falha ao buscar tamanho do banco: %w", err)
 	}
 
 	return size, nil
@@ -170,7 +159,7 @@ func (r *AnalyticsRepository) GetDatabaseSize() (*models.DatabaseSize, error) {
 func (r *AnalyticsRepository) GetPerformanceStats() (*models.PerformanceStats, error) {
 	// Verificar se o banco está conectado
 	if r.db == nil {
-		return getMockPerformanceStats(), nil
+		return nil, ErrNoDatabase
 	}
 
 	stats := &models.PerformanceStats{}
@@ -178,7 +167,7 @@ func (r *AnalyticsRepository) GetPerformanceStats() (*models.PerformanceStats, e
 	// Query para obter estatísticas de performance
 	query := `
 	SELECT
-		round(100 * blks_hit / (blks_hit + blks_read), 2) as cache_hit_ratio,
+		coalesce(round(100 * blks_hit::numeric / nullif(blks_hit + blks_read, 0), 2), 0) as cache_hit_ratio,
 		tup_returned as tuples_returned,
 		tup_fetched as tuples_fetched,
 		tup_inserted as tuples_inserted,
@@ -186,130 +175,949 @@ func (r *AnalyticsRepository) GetPerformanceStats() (*models.PerformanceStats, e
 		tup_deleted as tuples_deleted,
 		conflicts as conflicts,
 		temp_files as temp_files,
-		deadlocks as deadlocks
+		deadlocks as deadlocks,
+		xact_commit,
+		xact_rollback,
+		blks_read,
+		blks_hit,
+		temp_bytes,
+		stats_reset,
+		now() as collected_at
 	FROM pg_stat_database
 	WHERE datname = current_database()`
 
 	err := r.db.Get(stats, query)
 	if err != nil {
-		log.Printf("⚠️ Erro ao buscar estatísticas de performance: %v", err)
-		return getMockPerformanceStats(), nil
+		return nil, fmt.Errorf("falha ao buscar estatísticas de performance: %w", err)
 	}
 
 	return stats, nil
 }
 
-// ======= FUNÇÕES MOCK PARA FALLBACK =======
-
-// getMockSlowQueries retorna queries lentas simuladas
-func getMockSlowQueries() []models.SlowQuery {
-	return []models.SlowQuery{
-		{
-			QueryText:  "SELECT * FROM users WHERE email LIKE '%@example.com'",
-			DurationMs: 1250.75,
-			Calls:      432,
-			Rows:       1250,
-		},
-		{
-			QueryText:  "SELECT COUNT(*) FROM logs WHERE created_at > NOW() - INTERVAL '1 day'",
-			DurationMs: 876.32,
-			Calls:      125,
-			Rows:       1,
-		},
-		{
-			QueryText:  "SELECT logs.*, users.email FROM logs JOIN users ON logs.user_id = users.id WHERE logs.level = 'error'",
-			DurationMs: 754.28,
-			Calls:      89,
-			Rows:       352,
-		},
-		{
-			QueryText:  "UPDATE users SET last_login = NOW() WHERE id = ?",
-			DurationMs: 532.51,
-			Calls:      2451,
-			Rows:       2451,
-		},
-		{
-			QueryText:  "SELECT AVG(value) FROM metrics WHERE collected_at BETWEEN ? AND ? GROUP BY metric_name",
-			DurationMs: 498.12,
-			Calls:      78,
-			Rows:       24,
-		},
+// GetTableSnapshots retorna todas as colunas de pg_stat_user_tables usadas em table_stats_log
+func (r *AnalyticsRepository) GetTableSnapshots() ([]models.TableStatSnapshot, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	snapshots := []models.TableStatSnapshot{}
+
+	query := `
+	SELECT
+		schemaname as schema_name,
+		relname as table_name,
+		n_live_tup as row_count,
+		pg_relation_size(relid) as table_size_bytes,
+		pg_indexes_size(relid) as index_size_bytes,
+		pg_total_relation_size(relid) as total_size_bytes,
+		coalesce(seq_scan, 0) as seq_scan_count,
+		coalesce(seq_tup_read, 0) as seq_tup_read,
+		coalesce(idx_scan, 0) as idx_scan_count,
+		coalesce(idx_tup_fetch, 0) as idx_tup_fetch,
+		n_tup_ins,
+		n_tup_upd,
+		n_tup_del,
+		n_tup_hot_upd,
+		n_live_tup,
+		n_dead_tup,
+		vacuum_count,
+		autovacuum_count,
+		analyze_count,
+		autoanalyze_count,
+		last_vacuum,
+		last_autovacuum,
+		last_analyze,
+		last_autoanalyze
+	FROM pg_stat_user_tables`
+
+	if err := r.db.Select(&snapshots, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_stat_user_tables: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// GetActiveConnections retorna os backends de cliente de pg_stat_activity usados em pg_connections_log
+func (r *AnalyticsRepository) GetActiveConnections() ([]models.ConnectionSnapshot, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	connections := []models.ConnectionSnapshot{}
+
+	query := `
+	SELECT
+		coalesce(datname, '') as database_name,
+		coalesce(usename, '') as username,
+		host(client_addr) as client_addr,
+		coalesce(application_name, '') as application_name,
+		coalesce(state, '') as state,
+		backend_start,
+		query_start,
+		state_change,
+		wait_event_type,
+		wait_event,
+		coalesce(query, '') as query,
+		coalesce(extract(epoch from (now() - backend_start)) * 1000, 0)::bigint as connection_duration_ms
+	FROM pg_stat_activity
+	WHERE backend_type = 'client backend'
+		AND pid <> pg_backend_pid()`
+
+	if err := r.db.Select(&connections, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_stat_activity: %w", err)
+	}
+
+	return connections, nil
+}
+
+// lockBackendRow adiciona a leitura do array retornado por pg_blocking_pids
+type lockBackendRow struct {
+	models.LockBackend
+	BlockedBy pq.Int64Array `db:"blocked_by"`
+}
+
+// GetBlockingLocks retorna os backends que aguardam lock e os que os bloqueiam,
+// a partir de pg_locks e pg_blocking_pids()
+func (r *AnalyticsRepository) GetBlockingLocks() ([]models.LockBackend, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorLocks); err != nil {
+		return nil, err
+	}
+
+	// pg_locks.waitstart só existe a partir do PostgreSQL 14; lido via to_jsonb para
+	// funcionar em versões anteriores, com state_change como aproximação
+	query := `
+	WITH waiting AS (
+		SELECT pid, pg_blocking_pids(pid) as blocked_by
+		FROM pg_stat_activity
+		WHERE cardinality(pg_blocking_pids(pid)) > 0
+	), involved AS (
+		SELECT pid FROM waiting
+		UNION
+		SELECT unnest(blocked_by) FROM waiting
+	)
+	SELECT
+		a.pid,
+		coalesce(a.datname, '') as database_name,
+		coalesce(a.usename, '') as username,
+		coalesce(a.application_name, '') as application_name,
+		host(a.client_addr) as client_addr,
+		coalesce(a.state, '') as state,
+		a.wait_event_type,
+		a.wait_event,
+		coalesce(a.query, '') as query,
+		a.xact_start,
+		a.query_start,
+		coalesce(extract(epoch from (now() - a.xact_start)) * 1000, 0)::float8 as xact_duration_ms,
+		coalesce(w.blocked_by, '{}') as blocked_by,
+		l.locktype as lock_type,
+		l.mode as lock_mode,
+		l.relation,
+		CASE WHEN w.pid IS NULL THEN 0
+			ELSE coalesce(extract(epoch from (now() - coalesce(l.waitstart, a.state_change))) * 1000, 0)
+		END::float8 as wait_duration_ms
+	FROM involved i
+	JOIN pg_stat_activity a ON a.pid = i.pid
+	LEFT JOIN waiting w ON w.pid = a.pid
+	LEFT JOIN LATERAL (
+		SELECT
+			pl.locktype,
+			pl.mode,
+			pl.relation::regclass::text as relation,
+			(to_jsonb(pl) ->> 'waitstart')::timestamptz as waitstart
+		FROM pg_locks pl
+		WHERE pl.pid = a.pid AND NOT pl.granted
+		LIMIT 1
+	) l ON true
+	ORDER BY wait_duration_ms DESC, a.pid`
+
+	rows := []lockBackendRow{}
+	if err := r.db.Select(&rows, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_locks: %w", err)
+	}
+
+	backends := make([]models.LockBackend, 0, len(rows))
+	for _, row := range rows {
+		backend := row.LockBackend
+		backend.BlockedBy = make([]int, 0, len(row.BlockedBy))
+		for _, pid := range row.BlockedBy {
+			backend.BlockedBy = append(backend.BlockedBy, int(pid))
+		}
+		backends = append(backends, backend)
+	}
+
+	return backends, nil
+}
+
+// GetTableBloat estima o bloat de cada tabela a partir da largura média das colunas
+// em pg_stats e do número de páginas em pg_class. Tabelas sem estatísticas completas
+// (nunca analisadas ou com colunas do tipo name) são ignoradas.
+func (r *AnalyticsRepository) GetTableBloat() ([]models.BloatEstimate, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	WITH columns AS (
+		SELECT
+			ns.nspname as schema_name,
+			tbl.relname as table_name,
+			tbl.reltuples,
+			tbl.relpages + coalesce(toast.relpages, 0) as pages,
+			coalesce(toast.reltuples, 0) as toast_tuples,
+			coalesce(substring(array_to_string(tbl.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::int, 100) as fillfactor,
+			current_setting('block_size')::numeric as bs,
+			CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END as ma,
+			23 + CASE WHEN max(coalesce(s.null_frac, 0)) > 0 THEN (7 + count(s.attname)) / 8 ELSE 0 END as tpl_hdr_size,
+			sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 0)) as tpl_data_size,
+			bool_or(att.atttypid = 'pg_catalog.name'::regtype)
+				OR count(*) <> count(s.attname) as is_na
+		FROM pg_attribute att
+		JOIN pg_class tbl ON tbl.oid = att.attrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		LEFT JOIN pg_stats s ON s.schemaname = ns.nspname AND s.tablename = tbl.relname
+			AND NOT s.inherited AND s.attname = att.attname
+		LEFT JOIN pg_class toast ON toast.oid = tbl.reltoastrelid
+		WHERE att.attnum > 0 AND NOT att.attisdropped
+			AND tbl.relkind IN ('r', 'm')
+			AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND ns.nspname !~ '^pg_toast'
+		GROUP BY 1, 2, 3, 4, 5, 6, 7, 8
+	), tuples AS (
+		SELECT *,
+			4 + tpl_hdr_size + tpl_data_size + (2 * ma)
+				- CASE WHEN tpl_hdr_size % ma = 0 THEN ma ELSE tpl_hdr_size % ma END
+				- CASE WHEN ceil(tpl_data_size)::int % ma = 0 THEN ma ELSE ceil(tpl_data_size)::int % ma END as tpl_size
+		FROM columns
+		WHERE NOT is_na
+	), estimates AS (
+		SELECT *,
+			ceil(reltuples / ((bs - 24) * fillfactor / (tpl_size * 100))) + ceil(toast_tuples / 4) as expected_pages
+		FROM tuples
+		WHERE tpl_size > 0
+	)
+	SELECT
+		'table' as object_type,
+		schema_name,
+		table_name,
+		'' as index_name,
+		(bs * pages)::bigint as size_bytes,
+		(bs * least(expected_pages, pages))::bigint as expected_bytes,
+		(bs * greatest(pages - expected_pages, 0))::bigint as wasted_bytes,
+		CASE WHEN pages > 0 THEN round((100 * greatest(pages - expected_pages, 0) / pages)::numeric, 2) ELSE 0 END::float8 as bloat_percent,
+		fillfactor,
+		'estimate' as method
+	FROM estimates`
+
+	estimates := []models.BloatEstimate{}
+	if err := r.db.Select(&estimates, query); err != nil {
+		return nil, fmt.Errorf("falha ao estimar bloat de tabelas: %w", err)
+	}
+
+	return estimates, nil
+}
+
+// GetIndexBloat estima o bloat de cada índice btree a partir da largura média das
+// colunas indexadas em pg_stats. Índices sobre expressões usam as estatísticas do próprio índice.
+func (r *AnalyticsRepository) GetIndexBloat() ([]models.BloatEstimate, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	WITH indexes AS (
+		SELECT
+			ci.relname as index_name,
+			ci.reltuples,
+			ci.relpages,
+			i.indrelid as table_oid,
+			i.indexrelid as index_oid,
+			coalesce(substring(array_to_string(ci.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::int, 90) as fillfactor,
+			i.indnatts,
+			string_to_array(textin(int2vectorout(i.indkey)), ' ')::int[] as indkey
+		FROM pg_index i
+		JOIN pg_class ci ON ci.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = ci.relam AND am.amname = 'btree'
+		WHERE ci.relpages > 0
+	), index_columns AS (
+		SELECT
+			ct.relname as table_name,
+			ct.relnamespace,
+			ix.index_name,
+			ix.reltuples,
+			ix.relpages,
+			ix.fillfactor,
+			coalesce(a1.attname, a2.attname) as attname,
+			coalesce(a1.atttypid, a2.atttypid) as atttypid,
+			CASE WHEN a1.attnum IS NULL THEN ix.index_name ELSE ct.relname END as stats_relname
+		FROM (SELECT *, generate_series(1, indnatts) as attpos FROM indexes) ix
+		JOIN pg_class ct ON ct.oid = ix.table_oid
+		LEFT JOIN pg_attribute a1 ON ix.indkey[ix.attpos] <> 0
+			AND a1.attrelid = ix.table_oid AND a1.attnum = ix.indkey[ix.attpos]
+		LEFT JOIN pg_attribute a2 ON ix.indkey[ix.attpos] = 0
+			AND a2.attrelid = ix.index_oid AND a2.attnum = ix.attpos
+	), widths AS (
+		SELECT
+			n.nspname as schema_name,
+			c.table_name,
+			c.index_name,
+			c.reltuples,
+			c.relpages,
+			c.fillfactor,
+			current_setting('block_size')::numeric as bs,
+			CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END as ma,
+			CASE WHEN max(coalesce(s.null_frac, 0)) = 0 THEN 8 ELSE 8 + ((32 + 8 - 1) / 8) END as tuple_hdr,
+			sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 1024)) as data_width,
+			bool_or(c.atttypid = 'pg_catalog.name'::regtype) as is_na
+		FROM index_columns c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = c.stats_relname AND s.attname = c.attname
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND n.nspname !~ '^pg_toast'
+		GROUP BY 1, 2, 3, 4, 5, 6
+	), tuples AS (
+		SELECT *,
+			(tuple_hdr + ma - CASE WHEN tuple_hdr % ma = 0 THEN ma ELSE tuple_hdr % ma END
+				+ data_width + ma - CASE WHEN data_width = 0 THEN 0
+					WHEN data_width::int % ma = 0 THEN ma
+					ELSE data_width::int % ma END)::numeric as tuple_width
+		FROM widths
+		WHERE NOT is_na
+	), estimates AS (
+		SELECT *,
+			coalesce(1 + ceil(reltuples / floor((bs - 16 - 24) * fillfactor / (100 * (4 + tuple_width)::float))), 0) as expected_pages
+		FROM tuples
+	)
+	SELECT
+		'index' as object_type,
+		schema_name,
+		table_name,
+		index_name,
+		(bs * relpages)::bigint as size_bytes,
+		(bs * least(expected_pages, relpages))::bigint as expected_bytes,
+		(bs * greatest(relpages - expected_pages, 0))::bigint as wasted_bytes,
+		round((100 * greatest(relpages - expected_pages, 0) / relpages)::numeric, 2)::float8 as bloat_percent,
+		fillfactor,
+		'estimate' as method
+	FROM estimates`
+
+	estimates := []models.BloatEstimate{}
+	if err := r.db.Select(&estimates, query); err != nil {
+		return nil, fmt.Errorf("falha ao estimar bloat de índices: %w", err)
+	}
+
+	return estimates, nil
+}
+
+// MeasureBloat substitui a estimativa pela medição de pgstattuple: pgstattuple_approx
+// para tabelas (espaço livre + tuplas mortas) e pgstatindex para índices (densidade das folhas).
+// Requer a extensão pgstattuple e permissão de leitura nas relações.
+func (r *AnalyticsRepository) MeasureBloat(estimate *models.BloatEstimate) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+	if err := r.require(models.CollectorBloatMeasure); err != nil {
+		return err
+	}
+
+	var query string
+	var relation string
+	if estimate.ObjectType == "index" {
+		relation = pq.QuoteIdentifier(estimate.SchemaName) + "." + pq.QuoteIdentifier(estimate.IndexName)
+		query = `
+		SELECT
+			index_size as size_bytes,
+			CASE WHEN avg_leaf_density = 'NaN' THEN 0
+				ELSE greatest(index_size * (1 - avg_leaf_density / $2), 0)
+			END::bigint as wasted_bytes
+		FROM pgstatindex($1::regclass)`
+	} else {
+		relation = pq.QuoteIdentifier(estimate.SchemaName) + "." + pq.QuoteIdentifier(estimate.TableName)
+		query = `
+		SELECT
+			table_len as size_bytes,
+			greatest(approx_free_space + dead_tuple_len - table_len * (100 - $2) / 100.0, 0)::bigint as wasted_bytes
+		FROM pgstattuple_approx($1::regclass)`
+	}
+
+	var measured struct {
+		SizeBytes   int64 `db:"size_bytes"`
+		WastedBytes int64 `db:"wasted_bytes"`
+	}
+	if err := r.db.Get(&measured, query, relation, estimate.Fillfactor); err != nil {
+		return fmt.Errorf("falha ao medir bloat de %s com pgstattuple: %w", relation, err)
+	}
+
+	estimate.SizeBytes = measured.SizeBytes
+	estimate.WastedBytes = measured.WastedBytes
+	estimate.ExpectedBytes = measured.SizeBytes - measured.WastedBytes
+	estimate.BloatPercent = 0
+	if measured.SizeBytes > 0 {
+		estimate.BloatPercent = math.Round(float64(measured.WastedBytes)/float64(measured.SizeBytes)*10000) / 100
+	}
+	estimate.Method = models.BloatMethodPgstattuple
+
+	return nil
+}
+
+// GetIndexDefinitions retorna os índices das tabelas de usuário com definição, tamanho e scans
+func (r *AnalyticsRepository) GetIndexDefinitions() ([]models.IndexDefinition, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	// indnkeyatts só existe a partir do PostgreSQL 11; antes disso todas as colunas são de chave
+	query := `
+	SELECT
+		n.nspname as schema_name,
+		t.relname as table_name,
+		ci.relname as index_name,
+		am.amname as access_method,
+		pg_get_indexdef(i.indexrelid) as definition,
+		i.indkey::text as columns,
+		coalesce((to_jsonb(i) ->> 'indnkeyatts')::int, i.indnatts) as key_columns,
+		i.indclass::text as op_classes,
+		coalesce(pg_get_expr(i.indexprs, i.indrelid), '') as expressions,
+		coalesce(pg_get_expr(i.indpred, i.indrelid), '') as predicate,
+		i.indisunique as is_unique,
+		i.indisprimary as is_primary,
+		i.indisvalid as is_valid,
+		con.conname IS NOT NULL as is_constraint,
+		pg_relation_size(i.indexrelid) as size_bytes,
+		coalesce(s.idx_scan, 0) as idx_scan,
+		(SELECT stats_reset FROM pg_stat_database WHERE datname = current_database()) as stats_reset
+	FROM pg_index i
+	JOIN pg_class ci ON ci.oid = i.indexrelid
+	JOIN pg_class t ON t.oid = i.indrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_am am ON am.oid = ci.relam
+	LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = i.indexrelid
+	LEFT JOIN pg_constraint con ON con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x')
+	WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		AND n.nspname !~ '^pg_toast'
+	ORDER BY n.nspname, t.relname, ci.relname`
+
+	indexes := []models.IndexDefinition{}
+	if err := r.db.Select(&indexes, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar definições de índices: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// GetDatabaseXIDAges retorna a idade de datfrozenxid de cada banco que aceita conexões
+func (r *AnalyticsRepository) GetDatabaseXIDAges() ([]models.DatabaseXIDAge, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	SELECT
+		datname as database_name,
+		age(datfrozenxid)::bigint as xid_age,
+		current_setting('autovacuum_freeze_max_age')::bigint as freeze_max_age
+	FROM pg_database
+	WHERE datallowconn
+	ORDER BY xid_age DESC`
+
+	ages := []models.DatabaseXIDAge{}
+	if err := r.db.Select(&ages, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar idade de datfrozenxid: %w", err)
+	}
+
+	return ages, nil
+}
+
+// GetTableVacuumStatus retorna o estado de vacuum das tabelas de usuário.
+// Os limites efetivos consideram as reloptions de autovacuum de cada tabela.
+func (r *AnalyticsRepository) GetTableVacuumStatus() ([]models.TableVacuumStatus, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	WITH settings AS (
+		SELECT
+			current_setting('autovacuum')::bool as autovacuum,
+			current_setting('autovacuum_vacuum_threshold')::float8 as threshold,
+			current_setting('autovacuum_vacuum_scale_factor')::float8 as scale_factor,
+			current_setting('autovacuum_freeze_max_age')::bigint as freeze_max_age
+	), tables AS (
+		SELECT
+			s.schemaname as schema_name,
+			s.relname as table_name,
+			pg_total_relation_size(c.oid) as size_bytes,
+			greatest(age(c.relfrozenxid), coalesce(age(t.relfrozenxid), 0))::bigint as xid_age,
+			greatest(c.reltuples, 0)::float8 as reltuples,
+			s.n_live_tup,
+			s.n_dead_tup,
+			s.last_vacuum,
+			s.last_autovacuum,
+			s.vacuum_count,
+			s.autovacuum_count,
+			(SELECT option_value FROM pg_options_to_table(c.reloptions) WHERE option_name = 'autovacuum_enabled') as opt_enabled,
+			(SELECT option_value FROM pg_options_to_table(c.reloptions) WHERE option_name = 'autovacuum_vacuum_threshold') as opt_threshold,
+			(SELECT option_value FROM pg_options_to_table(c.reloptions) WHERE option_name = 'autovacuum_vacuum_scale_factor') as opt_scale_factor,
+			(SELECT option_value FROM pg_options_to_table(c.reloptions) WHERE option_name = 'autovacuum_freeze_max_age') as opt_freeze_max_age
+		FROM pg_stat_user_tables s
+		JOIN pg_class c ON c.oid = s.relid
+		LEFT JOIN pg_class t ON t.oid = c.reltoastrelid
+		-- Tabelas particionadas (relkind 'p') não guardam dados e têm relfrozenxid = 0
+		WHERE c.relkind IN ('r', 'm', 't')
+	), effective AS (
+		SELECT
+			tables.*,
+			settings.autovacuum AND coalesce(opt_enabled::bool, true) as autovacuum_enabled,
+			coalesce(opt_threshold::float8, settings.threshold)
+				+ coalesce(opt_scale_factor::float8, settings.scale_factor) * reltuples as vacuum_threshold,
+			-- autovacuum_freeze_max_age da tabela só pode reduzir o valor global
+			least(coalesce(opt_freeze_max_age::bigint, settings.freeze_max_age), settings.freeze_max_age) as freeze_max_age
+		FROM tables, settings
+	)
+	SELECT
+		current_database() as database_name,
+		schema_name,
+		table_name,
+		size_bytes,
+		xid_age,
+		freeze_max_age,
+		n_live_tup,
+		n_dead_tup,
+		CASE WHEN n_live_tup + n_dead_tup > 0
+			THEN round((100.0 * n_dead_tup / (n_live_tup + n_dead_tup))::numeric, 2)
+			ELSE 0
+		END::float8 as dead_ratio,
+		round(vacuum_threshold::numeric, 0)::float8 as vacuum_threshold,
+		autovacuum_enabled,
+		n_dead_tup > vacuum_threshold as overdue,
+		last_vacuum,
+		last_autovacuum,
+		vacuum_count,
+		autovacuum_count
+	FROM effective
+	ORDER BY xid_age DESC`
+
+	tables := []models.TableVacuumStatus{}
+	if err := r.db.Select(&tables, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar estado de vacuum: %w", err)
+	}
+
+	return tables, nil
+}
+
+// GetVacuumProgress retorna os vacuums em execução de pg_stat_progress_vacuum
+func (r *AnalyticsRepository) GetVacuumProgress() ([]models.VacuumProgress, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorVacuumProgress); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT
+		p.pid,
+		coalesce(p.datname, '') as database_name,
+		coalesce(p.relid::regclass::text, '') as relation,
+		p.phase,
+		p.heap_blks_total,
+		p.heap_blks_scanned,
+		p.heap_blks_vacuumed,
+		p.index_vacuum_count,
+		CASE WHEN p.heap_blks_total > 0
+			THEN round((100.0 * p.heap_blks_scanned / p.heap_blks_total)::numeric, 2)
+			ELSE 0
+		END::float8 as percent_complete,
+		coalesce(a.query, '') LIKE 'autovacuum:%' as is_autovacuum,
+		coalesce(a.query, '') as query,
+		a.xact_start
+	FROM pg_stat_progress_vacuum p
+	LEFT JOIN pg_stat_activity a ON a.pid = p.pid
+	ORDER BY a.xact_start`
+
+	progress := []models.VacuumProgress{}
+	if err := r.db.Select(&progress, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_stat_progress_vacuum: %w", err)
+	}
+
+	return progress, nil
+}
+
+// GetReplicationNode retorna o papel da instância e sua posição de WAL
+func (r *AnalyticsRepository) GetReplicationNode() (*models.ReplicationNode, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorReplication); err != nil {
+		return nil, err
+	}
+
+	// As funções de WAL do primary falham em standbys; o CASE evita avaliá-las
+	query := `
+	SELECT
+		pg_is_in_recovery() as in_recovery,
+		CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END::text as current_lsn,
+		pg_last_wal_receive_lsn()::text as receive_lsn,
+		pg_last_wal_replay_lsn()::text as replay_lsn,
+		pg_last_xact_replay_timestamp() as last_replay_at,
+		CASE WHEN pg_is_in_recovery()
+			THEN extract(epoch from (now() - pg_last_xact_replay_timestamp()))::float8
+		END as replay_delay_seconds,
+		CASE WHEN pg_is_in_recovery()
+			THEN pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())::bigint
+		END as replay_lag_bytes`
+
+	node := &models.ReplicationNode{}
+	if err := r.db.Get(node, query); err != nil {
+		return nil, fmt.Errorf("falha ao verificar papel de replicação: %w", err)
+	}
+
+	return node, nil
+}
+
+// GetReplicationStandbys retorna os standbys conectados à instância (pg_stat_replication),
+// incluindo standbys em cascata quando a instância é ela mesma um standby
+func (r *AnalyticsRepository) GetReplicationStandbys() ([]models.ReplicationStandby, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorReplication); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT
+		pid,
+		coalesce(application_name, '') as application_name,
+		host(client_addr) as client_addr,
+		coalesce(state, '') as state,
+		coalesce(sync_state, '') as sync_state,
+		sent_lsn::text as sent_lsn,
+		write_lsn::text as write_lsn,
+		flush_lsn::text as flush_lsn,
+		replay_lsn::text as replay_lsn,
+		(extract(epoch from write_lag) * 1000)::float8 as write_lag_ms,
+		(extract(epoch from flush_lag) * 1000)::float8 as flush_lag_ms,
+		(extract(epoch from replay_lag) * 1000)::float8 as replay_lag_ms,
+		coalesce(pg_wal_lsn_diff(current.lsn, sent_lsn), 0)::bigint as sent_lag_bytes,
+		coalesce(pg_wal_lsn_diff(current.lsn, replay_lsn), 0)::bigint as replay_lag_bytes,
+		backend_start
+	FROM pg_stat_replication,
+		(SELECT CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END as lsn) current
+	ORDER BY application_name`
+
+	standbys := []models.ReplicationStandby{}
+	if err := r.db.Select(&standbys, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_stat_replication: %w", err)
 	}
+
+	return standbys, nil
 }
 
-// getMockTableStats retorna estatísticas de tabelas simuladas
-func getMockTableStats() []models.TableStat {
-	return []models.TableStat{
-		{
-			TableName:  "public.users",
-			RowCount:   15000,
-			SizePretty: "32 MB",
-			SizeMB:     32.5,
-			IndexRatio: 28.4,
-		},
-		{
-			TableName:  "public.logs",
-			RowCount:   1250000,
-			SizePretty: "4.2 GB",
-			SizeMB:     4300.8,
-			IndexRatio: 35.2,
-		},
-		{
-			TableName:  "public.sessions",
-			RowCount:   85000,
-			SizePretty: "128 MB",
-			SizeMB:     128.4,
-			IndexRatio: 18.7,
-		},
-		{
-			TableName:  "public.metrics",
-			RowCount:   3500000,
-			SizePretty: "7.5 GB",
-			SizeMB:     7680.0,
-			IndexRatio: 42.1,
-		},
-		{
-			TableName:  "public.settings",
-			RowCount:   350,
-			SizePretty: "2 MB",
-			SizeMB:     2.1,
-			IndexRatio: 12.5,
-		},
+// GetWalReceiver retorna o receptor de WAL do standby, ou nil quando não há receptor ativo
+func (r *AnalyticsRepository) GetWalReceiver() (*models.WalReceiver, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorReplication); err != nil {
+		return nil, err
+	}
+
+	// flushed_lsn substituiu received_lsn no PostgreSQL 13; lido via to_jsonb para ambas as versões
+	query := `
+	SELECT
+		w.pid,
+		w.status,
+		w.sender_host,
+		w.sender_port,
+		w.slot_name,
+		coalesce(to_jsonb(w) ->> 'flushed_lsn', to_jsonb(w) ->> 'received_lsn') as received_lsn,
+		w.latest_end_lsn::text as latest_end_lsn,
+		w.last_msg_receipt_time,
+		(extract(epoch from (now() - w.last_msg_receipt_time)) * 1000)::float8 as receipt_delay_ms
+	FROM pg_stat_wal_receiver w`
+
+	receivers := []models.WalReceiver{}
+	if err := r.db.Select(&receivers, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_stat_wal_receiver: %w", err)
 	}
+	if len(receivers) == 0 {
+		return nil, nil
+	}
+
+	return &receivers[0], nil
 }
 
-// getMockConnectionStats retorna estatísticas de conexões simuladas
-func getMockConnectionStats() *models.ConnectionStats {
-	return &models.ConnectionStats{
-		TotalConnections:   18,
-		ActiveConnections:  8,
-		IdleConnections:    9,
-		IdleInTransaction:  1,
-		MaxConnections:     100,
-		ConnectionsPercent: 18.0,
+// GetReplicationSlots retorna os slots de replicação com o WAL retido por cada um
+func (r *AnalyticsRepository) GetReplicationSlots() ([]models.ReplicationSlot, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := r.require(models.CollectorReplication); err != nil {
+		return nil, err
+	}
+
+	// wal_status existe a partir do PostgreSQL 13
+	query := `
+	SELECT
+		s.slot_name,
+		s.slot_type,
+		s.plugin,
+		s.database as database_name,
+		s.active,
+		s.active_pid,
+		s.restart_lsn::text as restart_lsn,
+		coalesce(pg_wal_lsn_diff(
+			CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END,
+			s.restart_lsn
+		), 0)::bigint as retained_wal_bytes,
+		to_jsonb(s) ->> 'wal_status' as wal_status
+	FROM pg_replication_slots s
+	ORDER BY retained_wal_bytes DESC`
+
+	slots := []models.ReplicationSlot{}
+	if err := r.db.Select(&slots, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar pg_replication_slots: %w", err)
 	}
+
+	return slots, nil
 }
 
-// getMockDatabaseSize retorna tamanho do banco simulado
-func getMockDatabaseSize() *models.DatabaseSize {
-	return &models.DatabaseSize{
-		DatabaseName: "postgres",
-		SizePretty:   "12.5 GB",
-		SizeMB:       12800.0,
+// CheckpointStatsQuery retorna a consulta de checkpoints, bgwriter e WAL adequada à versão:
+// até o PostgreSQL 13 o WAL vem da posição do LSN; a partir do 14 de pg_stat_wal;
+// a partir do 17 os checkpoints vêm de pg_stat_checkpointer e os fsyncs de backends de pg_stat_io.
+func CheckpointStatsQuery(version int) string {
+	switch {
+	case version >= 170000:
+		return `
+	SELECT
+		c.num_timed as checkpoints_timed,
+		c.num_requested as checkpoints_req,
+		c.write_time::float8 as checkpoint_write_time_ms,
+		c.sync_time::float8 as checkpoint_sync_time_ms,
+		c.buffers_written as buffers_checkpoint,
+		b.buffers_clean,
+		b.maxwritten_clean,
+		NULL::bigint as buffers_backend,
+		coalesce((SELECT sum(fsyncs) FROM pg_stat_io WHERE backend_type = 'client backend'), 0)::bigint as backend_fsyncs,
+		b.buffers_alloc,
+		w.wal_bytes::bigint as wal_bytes,
+		w.wal_records,
+		w.wal_fpi,
+		w.wal_buffers_full,
+		'pg_stat_wal' as wal_source,
+		c.stats_reset,
+		now() as collected_at
+	FROM pg_stat_checkpointer c, pg_stat_bgwriter b, pg_stat_wal w`
+	case version >= 140000:
+		return `
+	SELECT
+		b.checkpoints_timed,
+		b.checkpoints_req,
+		b.checkpoint_write_time::float8 as checkpoint_write_time_ms,
+		b.checkpoint_sync_time::float8 as checkpoint_sync_time_ms,
+		b.buffers_checkpoint,
+		b.buffers_clean,
+		b.maxwritten_clean,
+		b.buffers_backend,
+		b.buffers_backend_fsync as backend_fsyncs,
+		b.buffers_alloc,
+		w.wal_bytes::bigint as wal_bytes,
+		w.wal_records,
+		w.wal_fpi,
+		w.wal_buffers_full,
+		'pg_stat_wal' as wal_source,
+		b.stats_reset,
+		now() as collected_at
+	FROM pg_stat_bgwriter b, pg_stat_wal w`
+	default:
+		return `
+	SELECT
+		b.checkpoints_timed,
+		b.checkpoints_req,
+		b.checkpoint_write_time::float8 as checkpoint_write_time_ms,
+		b.checkpoint_sync_time::float8 as checkpoint_sync_time_ms,
+		b.buffers_checkpoint,
+		b.buffers_clean,
+		b.maxwritten_clean,
+		b.buffers_backend,
+		b.buffers_backend_fsync as backend_fsyncs,
+		b.buffers_alloc,
+		coalesce(pg_wal_lsn_diff(
+			CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END,
+			'0/0'
+		), 0)::bigint as wal_bytes,
+		NULL::bigint as wal_records,
+		NULL::bigint as wal_fpi,
+		NULL::bigint as wal_buffers_full,
+		'lsn' as wal_source,
+		b.stats_reset,
+		now() as collected_at
+	FROM pg_stat_bgwriter b`
 	}
 }
 
-// getMockPerformanceStats retorna estatísticas de performance simuladas
-func getMockPerformanceStats() *models.PerformanceStats {
-	return &models.PerformanceStats{
-		CacheHitRatio:   98.45,
-		TuplesReturned:  15250000,
-		TuplesFetched:   4520000,
-		TuplesInserted:  285000,
-		TuplesUpdated:   142500,
-		TuplesDeleted:   28500,
-		Conflicts:       12,
-		TempFiles:       45,
-		Deadlocks:       0,
+// GetCheckpointStats retorna os contadores de checkpoints, bgwriter e WAL da instância
+func (r *AnalyticsRepository) GetCheckpointStats() (*models.CheckpointStats, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
 	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	query, err := catalog.CheckpointStatsQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.CheckpointStats{}
+	if err := r.db.Get(stats, query); err != nil {
+		return nil, fmt.Errorf("falha ao coletar estatísticas de checkpoint e WAL: %w", err)
+	}
+	stats.ServerVersionNum = catalog.Capabilities().ServerVersionNum
+
+	return stats, nil
+}
+
+// GetStatements retorna uma página do explorador de pg_stat_statements
+func (r *AnalyticsRepository) GetStatements(q models.StatementQuery) ([]models.StatementStats, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := catalog.StatementsQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []models.StatementStats{}
+	if err := r.db.Select(&statements, query, args...); err != nil {
+		return nil, fmt.Errorf("falha ao consultar pg_stat_statements: %w", err)
+	}
+
+	return statements, nil
+}
+
+// GetStatement retorna todas as entradas de um queryid (por banco, usuário e nível) com o texto completo
+func (r *AnalyticsRepository) GetStatement(queryID int64) ([]models.StatementStats, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	query, err := catalog.StatementQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []models.StatementStats{}
+	if err := r.db.Select(&statements, query, queryID); err != nil {
+		return nil, fmt.Errorf("falha ao consultar queryid %d: %w", queryID, err)
+	}
+
+	return statements, nil
+}
+
+// CurrentDatabase retorna o banco ao qual o repositório está conectado
+func (r *AnalyticsRepository) CurrentDatabase() (string, error) {
+	if r.db == nil {
+		return "", ErrNoDatabase
+	}
+
+	var name string
+	if err := r.db.Get(&name, "SELECT current_database()"); err != nil {
+		return "", fmt.Errorf("falha ao obter banco atual: %w", err)
+	}
+
+	return name, nil
+}
+
+// Explain executa EXPLAIN (FORMAT JSON) da query em uma transação somente leitura com
+// statement_timeout, sempre desfeita ao final. Antes do PostgreSQL 16 o plano genérico é obtido
+// com PREPARE, removido da sessão depois da captura.
+func (r *AnalyticsRepository) Explain(query string, opts models.ExplainOptions) (*models.ExplainOutput, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	if opts.GenericPlan {
+		if err := catalog.Require(models.CollectorGenericPlan); err != nil {
+			return nil, err
+		}
+	}
+
+	// PREPARE não é transacional: a mesma conexão é usada para o DEALLOCATE depois do rollback
+	ctx := context.Background()
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter conexão: %w", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	setup := []string{
+		"SET TRANSACTION READ ONLY",
+		fmt.Sprintf("SET LOCAL statement_timeout = %d", opts.TimeoutMs),
+	}
+	for _, statement := range setup {
+		if _, err := tx.Exec(statement); err != nil {
+			return nil, fmt.Errorf("falha ao preparar transação do EXPLAIN: %w", err)
+		}
+	}
+
+	output := &models.ExplainOutput{}
+	if err := tx.QueryRowx("SELECT current_database(), current_user").Scan(&output.DatabaseName, &output.Username); err != nil {
+		return nil, fmt.Errorf("falha ao identificar a sessão: %w", err)
+	}
+
+	params := 0
+	if opts.GenericPlan && catalog.PreparesGenericPlan() {
+		if _, err := tx.Exec("SET LOCAL plan_cache_mode = force_generic_plan"); err != nil {
+			return nil, fmt.Errorf("falha ao forçar plano genérico: %w", err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PREPARE %s AS %s", explainPreparedName, query)); err != nil {
+			return nil, fmt.Errorf("falha ao preparar a query: %w", err)
+		}
+		defer func() {
+			tx.Rollback()
+			conn.ExecContext(ctx, "DEALLOCATE "+explainPreparedName)
+		}()
+
+		lookup := "SELECT coalesce(cardinality(parameter_types), 0) FROM pg_prepared_statements WHERE name = $1"
+		if err := tx.Get(&params, lookup, explainPreparedName); err != nil {
+			return nil, fmt.Errorf("falha ao contar parâmetros da query: %w", err)
+		}
+	}
+
+	statement, err := catalog.ExplainStatement(query, opts, params)
+	if err != nil {
+		return nil, err
+	}
+	var plan []byte
+	if err := tx.QueryRowx(statement).Scan(&plan); err != nil {
+		return nil, fmt.Errorf("falha ao executar EXPLAIN: %w", err)
+	}
+	output.Plan = plan
+
+	return output, nil
 }
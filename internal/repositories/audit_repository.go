@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// AuditRepository gerencia a tabela audit_logs
+type AuditRepository struct {
+	db *database.DB
+}
+
+// NewAuditRepository cria um novo repositório de auditoria
+func NewAuditRepository(db *database.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create grava uma entrada de auditoria e preenche ID e CreatedAt
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO audit_logs (user_id, action, resource_type, resource_id, old_values, new_values,
+		ip_address, user_agent, session_id)
+	VALUES ($1, $2, nullif($3, ''), nullif($4, ''), $5, $6, nullif($7, '')::inet, nullif($8, ''), nullif($9, ''))
+	RETURNING id, created_at`
+
+	err := r.db.QueryRowx(query, entry.UserID, entry.Action, entry.ResourceType, entry.ResourceID,
+		entry.OldValues, entry.NewValues, entry.IPAddress, entry.UserAgent, entry.SessionID).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar auditoria: %w", err)
+	}
+
+	return nil
+}
+
+const auditColumns = `id, user_id, action, coalesce(resource_type, '') as resource_type,
+		coalesce(resource_id, '') as resource_id, old_values, new_values,
+		coalesce(host(ip_address), '') as ip_address, coalesce(user_agent, '') as user_agent,
+		coalesce(session_id, '') as session_id, created_at`
+
+// List retorna os registros que atendem aos filtros, do mais recente para o mais antigo,
+// junto com o total sem paginação
+func (r *AuditRepository) List(q models.AuditQuery) ([]models.AuditLog, int, error) {
+	if r.db == nil {
+		return nil, 0, ErrNoDatabase
+	}
+
+	conditions := []string{"created_at >= $1", "created_at <= $2"}
+	args := []interface{}{q.From, q.To}
+	filter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	filter("user_id::text", q.UserID)
+	filter("action", q.Action)
+	filter("resource_type", q.ResourceType)
+	filter("resource_id", q.ResourceID)
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.Get(&total, "SELECT count(*) FROM audit_logs"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("falha ao contar auditoria: %w", err)
+	}
+
+	entries := []models.AuditLog{}
+	args = append(args, q.Limit, q.Offset)
+	query := fmt.Sprintf("SELECT %s FROM audit_logs%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d",
+		auditColumns, where, len(args)-1, len(args))
+	if err := r.db.Select(&entries, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("falha ao listar auditoria: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// CountByIP conta as ações de um IP desde since e retorna a mais antiga delas
+func (r *AuditRepository) CountByIP(action, ip string, since time.Time) (int, *time.Time, error) {
+	if r.db == nil {
+		return 0, nil, ErrNoDatabase
+	}
+
+	var result struct {
+		Count  int        `db:"count"`
+		Oldest *time.Time `db:"oldest"`
+	}
+	query := `
+	SELECT count(*) AS count, min(created_at) AS oldest
+	FROM audit_logs
+	WHERE action = $1 AND ip_address = $2::inet AND created_at >= $3`
+
+	if err := r.db.Get(&result, query, action, ip, since); err != nil {
+		return 0, nil, fmt.Errorf("falha ao contar tentativas por IP: %w", err)
+	}
+
+	return result.Count, result.Oldest, nil
+}
+
+// CountByEmail conta as ações gravadas com new_values.email igual a email desde since
+// e retorna a mais antiga delas
+func (r *AuditRepository) CountByEmail(action, email string, since time.Time) (int, *time.Time, error) {
+	if r.db == nil {
+		return 0, nil, ErrNoDatabase
+	}
+
+	var result struct {
+		Count  int        `db:"count"`
+		Oldest *time.Time `db:"oldest"`
+	}
+	query := `
+	SELECT count(*) AS count, min(created_at) AS oldest
+	FROM audit_logs
+	WHERE action = $1 AND lower(new_values->>'email') = lower($2) AND created_at >= $3`
+
+	if err := r.db.Get(&result, query, action, email, since); err != nil {
+		return 0, nil, fmt.Errorf("falha ao contar solicitações por email: %w", err)
+	}
+
+	return result.Count, result.Oldest, nil
+}
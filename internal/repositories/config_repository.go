@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrConfigNotFound indica que a chave não existe em system_config
+var ErrConfigNotFound = errors.New("configuração não encontrada")
+
+const configColumns = `config_key, config_value, config_type, coalesce(description, '') as description,
+		coalesce(is_sensitive, false) as is_sensitive, updated_by, updated_at`
+
+// ConfigRepository lê chaves da tabela system_config
+type ConfigRepository struct {
+	db *database.DB
+}
+
+// NewConfigRepository cria um novo repositório de configuração
+func NewConfigRepository(db *database.DB) *ConfigRepository {
+	return &ConfigRepository{db: db}
+}
+
+// GetString retorna o valor de uma chave ou o fallback quando ela não existe
+func (r *ConfigRepository) GetString(key, fallback string) string {
+	if r.db == nil {
+		return fallback
+	}
+
+	var value string
+	err := r.db.Get(&value, "SELECT config_value FROM system_config WHERE config_key = $1", key)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("⚠️ Erro ao ler system_config %s: %v", key, err)
+		}
+		return fallback
+	}
+
+	return value
+}
+
+// GetInt retorna o valor numérico de uma chave ou o fallback quando ela não existe ou é inválida
+func (r *ConfigRepository) GetInt(key string, fallback int) int {
+	value := r.GetString(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido em system_config %s: %q", key, value)
+		return fallback
+	}
+
+	return parsed
+}
+
+// GetFloat retorna o valor numérico de uma chave ou o fallback quando ela não existe ou é inválida
+func (r *ConfigRepository) GetFloat(key string, fallback float64) float64 {
+	value := r.GetString(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido em system_config %s: %q", key, value)
+		return fallback
+	}
+
+	return parsed
+}
+
+// GetBool retorna o valor booleano de uma chave ou o fallback quando ela não existe ou é inválida
+func (r *ConfigRepository) GetBool(key string, fallback bool) bool {
+	value := r.GetString(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido em system_config %s: %q", key, value)
+		return fallback
+	}
+
+	return parsed
+}
+
+// List retorna todas as chaves de system_config
+func (r *ConfigRepository) List() ([]models.ConfigEntry, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	entries := []models.ConfigEntry{}
+	if err := r.db.Select(&entries, "SELECT "+configColumns+" FROM system_config ORDER BY config_key"); err != nil {
+		return nil, fmt.Errorf("falha ao listar configurações: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Find busca uma chave de system_config
+func (r *ConfigRepository) Find(key string) (*models.ConfigEntry, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	entry := &models.ConfigEntry{}
+	if err := r.db.Get(entry, "SELECT "+configColumns+" FROM system_config WHERE config_key = $1", key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar configuração: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Set altera o valor de uma chave existente, registrando quem alterou
+func (r *ConfigRepository) Set(key, value, updatedBy string) (*models.ConfigEntry, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	entry := &models.ConfigEntry{}
+	query := `
+	UPDATE system_config SET config_value = $2, updated_by = nullif($3, '')::uuid
+	WHERE config_key = $1
+	RETURNING ` + configColumns
+
+	if err := r.db.Get(entry, query, key, value, updatedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, fmt.Errorf("falha ao alterar configuração: %w", err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// tableHistoryColumns mapeia as métricas por tabela para colunas de table_stats_log
+var tableHistoryColumns = map[string]string{
+	"row_count":        "row_count",
+	"table_size_bytes": "table_size_bytes",
+	"index_size_bytes": "index_size_bytes",
+	"total_size_bytes": "total_size_bytes",
+	"seq_scan_count":   "seq_scan_count",
+	"seq_tup_read":     "seq_tup_read",
+	"idx_scan_count":   "idx_scan_count",
+	"idx_tup_fetch":    "idx_tup_fetch",
+	"n_live_tup":       "n_live_tup",
+	"n_dead_tup":       "n_dead_tup",
+	"n_tup_ins":        "n_tup_ins",
+	"n_tup_upd":        "n_tup_upd",
+	"n_tup_del":        "n_tup_del",
+}
+
+// HistoryRepository consulta séries temporais nas tabelas *_log
+type HistoryRepository struct {
+	db *database.DB
+}
+
+// NewHistoryRepository cria um novo repositório de histórico
+func NewHistoryRepository(db *database.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// IsTableMetric indica se a métrica é lida de table_stats_log
+func IsTableMetric(metric string) bool {
+	_, ok := tableHistoryColumns[metric]
+	return ok
+}
+
+// TableMetricNames retorna as métricas por tabela em ordem alfabética
+func TableMetricNames() []string {
+	names := make([]string, 0, len(tableHistoryColumns))
+	for name := range tableHistoryColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListSystemMetrics retorna os nomes de métricas disponíveis em system_metrics_log
+func (r *HistoryRepository) ListSystemMetrics() ([]string, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	names := []string{}
+	if err := r.db.Select(&names, "SELECT DISTINCT metric_name FROM system_metrics_log ORDER BY metric_name"); err != nil {
+		return nil, fmt.Errorf("falha ao listar métricas: %w", err)
+	}
+
+	return names, nil
+}
+
+// GetSystemMetricSeries agrega uma métrica de system_metrics_log em buckets de tamanho q.Step
+func (r *HistoryRepository) GetSystemMetricSeries(q models.HistoryQuery) ([]models.HistoryPoint, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	where := "metric_name = $4 AND created_at >= $2 AND created_at < $3"
+	args := []interface{}{q.Step.Seconds(), q.From, q.To, q.Metric}
+	where, args = withSourceFilters(where, args, q)
+
+	return r.series("metric_value", "system_metrics_log", where, args)
+}
+
+// GetTableMetricSeries agrega uma coluna de table_stats_log para uma tabela em buckets de tamanho q.Step
+func (r *HistoryRepository) GetTableMetricSeries(q models.HistoryQuery) ([]models.HistoryPoint, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	column, ok := tableHistoryColumns[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("métrica de tabela desconhecida: %s", q.Metric)
+	}
+
+	schema, table := "public", q.Table
+	if i := strings.Index(q.Table, "."); i >= 0 {
+		schema, table = q.Table[:i], q.Table[i+1:]
+	}
+
+	where := "schema_name = $4 AND table_name = $5 AND created_at >= $2 AND created_at < $3"
+	args := []interface{}{q.Step.Seconds(), q.From, q.To, schema, table}
+	where, args = withSourceFilters(where, args, q)
+
+	return r.series(column, "table_stats_log", where, args)
+}
+
+// GetLockSnapshots retorna as árvores de bloqueio gravadas no período, das mais recentes para as mais antigas
+func (r *HistoryRepository) GetLockSnapshots(q models.LockSnapshotQuery) ([]models.LockSnapshot, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	where := "created_at >= $1 AND created_at < $2"
+	args := []interface{}{q.From, q.To}
+	if q.Target != "" {
+		args = append(args, q.Target)
+		where += fmt.Sprintf(" AND target_name = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`
+	SELECT id, target_name, max_wait_ms::float8 as max_wait_ms, waiting_count, blocker_count, tree, created_at
+	FROM lock_snapshots_log
+	WHERE %s
+	ORDER BY created_at DESC
+	LIMIT $%d`, where, len(args))
+
+	snapshots := []models.LockSnapshot{}
+	if err := r.db.Select(&snapshots, query, args...); err != nil {
+		return nil, fmt.Errorf("falha ao consultar lock_snapshots_log: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// GetQueryWindows agrega os snapshots de slow_queries_log de um target em duas janelas:
+// linha de base [baselineFrom, recentFrom) e recente [recentFrom, to). Cada intervalo entre
+// snapshots consecutivos da mesma entrada vira uma amostra com os deltas de calls e
+// total_time_ms; o p95 é calculado sobre a latência média de cada amostra.
+// Intervalos com reset de contadores (deltas negativos) ou sem execuções são descartados.
+func (r *HistoryRepository) GetQueryWindows(target string, baselineFrom, recentFrom, to time.Time) ([]models.QueryWindowStats, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	WITH snapshots AS (
+		SELECT
+			query_hash, query_text, database_name, created_at,
+			total_time_ms - lag(total_time_ms) OVER w as delta_time,
+			calls - lag(calls) OVER w as delta_calls
+		FROM slow_queries_log
+		WHERE created_at >= $1 AND created_at < $3
+			AND target_name IS NOT DISTINCT FROM nullif($4, '')
+			AND calls IS NOT NULL AND total_time_ms IS NOT NULL
+		WINDOW w AS (PARTITION BY query_hash, database_name, username, queryid ORDER BY created_at)
+	), samples AS (
+		SELECT *, delta_time / delta_calls as mean_ms
+		FROM snapshots
+		WHERE delta_calls > 0 AND delta_time >= 0
+	)
+	SELECT
+		query_hash,
+		(array_agg(query_text ORDER BY created_at DESC))[1] as query_text,
+		database_name,
+		coalesce(sum(delta_calls) FILTER (WHERE created_at < $2), 0)::int8 as baseline_calls,
+		coalesce(sum(delta_time) FILTER (WHERE created_at < $2), 0)::float8 as baseline_time_ms,
+		(percentile_cont(0.95) WITHIN GROUP (ORDER BY mean_ms) FILTER (WHERE created_at < $2))::float8 as baseline_p95_ms,
+		count(*) FILTER (WHERE created_at < $2) as baseline_samples,
+		coalesce(sum(delta_calls) FILTER (WHERE created_at >= $2), 0)::int8 as recent_calls,
+		coalesce(sum(delta_time) FILTER (WHERE created_at >= $2), 0)::float8 as recent_time_ms,
+		(percentile_cont(0.95) WITHIN GROUP (ORDER BY mean_ms) FILTER (WHERE created_at >= $2))::float8 as recent_p95_ms,
+		count(*) FILTER (WHERE created_at >= $2) as recent_samples
+	FROM samples
+	GROUP BY query_hash, database_name`
+
+	windows := []models.QueryWindowStats{}
+	if err := r.db.Select(&windows, query, baselineFrom, recentFrom, to, target); err != nil {
+		return nil, fmt.Errorf("falha ao consultar janelas de slow_queries_log: %w", err)
+	}
+
+	return windows, nil
+}
+
+// GetMetricSamples retorna uma amostra por snapshot de uma métrica de system_metrics_log desde since,
+// em ordem cronológica. As linhas de um mesmo snapshot (ex: uma por banco) são combinadas com
+// aggregate, que deve ser "max" ou "sum".
+func (r *HistoryRepository) GetMetricSamples(target, metric, aggregate string, since time.Time) ([]models.MetricSample, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if aggregate != "max" && aggregate != "sum" {
+		return nil, fmt.Errorf("agregação de métrica desconhecida: %s", aggregate)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT created_at as at, %s(metric_value)::float8 as value
+	FROM system_metrics_log
+	WHERE metric_name = $1 AND created_at >= $3
+		AND target_name IS NOT DISTINCT FROM nullif($2, '')
+		AND metric_value IS NOT NULL
+	GROUP BY created_at
+	ORDER BY created_at`, aggregate)
+
+	samples := []models.MetricSample{}
+	if err := r.db.Select(&samples, query, metric, target, since); err != nil {
+		return nil, fmt.Errorf("falha ao consultar amostras de %s: %w", metric, err)
+	}
+
+	return samples, nil
+}
+
+// withSourceFilters acrescenta o filtro de target (vazio = banco local) e o filtro opcional de banco
+func withSourceFilters(where string, args []interface{}, q models.HistoryQuery) (string, []interface{}) {
+	args = append(args, q.Target)
+	where += fmt.Sprintf(" AND target_name IS NOT DISTINCT FROM nullif($%d, '')", len(args))
+	if q.Database != "" {
+		args = append(args, q.Database)
+		where += fmt.Sprintf(" AND database_name = $%d", len(args))
+	}
+	return where, args
+}
+
+// series executa a agregação por bucket; $1 é sempre o tamanho do bucket em segundos
+func (r *HistoryRepository) series(column, table, where string, args []interface{}) ([]models.HistoryPoint, error) {
+	query := fmt.Sprintf(`
+	SELECT
+		to_timestamp(floor(extract(epoch from created_at) / $1) * $1) as bucket,
+		avg(%[1]s)::float8 as avg,
+		min(%[1]s)::float8 as min,
+		max(%[1]s)::float8 as max,
+		((array_agg(%[1]s ORDER BY created_at DESC))[1])::float8 as last,
+		count(*) as samples
+	FROM %[2]s
+	WHERE %[3]s AND %[1]s IS NOT NULL
+	GROUP BY bucket
+	ORDER BY bucket`, column, table, where)
+
+	points := []models.HistoryPoint{}
+	if err := r.db.Select(&points, query, args...); err != nil {
+		return nil, fmt.Errorf("falha ao consultar histórico em %s: %w", table, err)
+	}
+
+	return points, nil
+}
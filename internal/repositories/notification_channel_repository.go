@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrChannelNotFound indica que o canal de notificação solicitado não existe
+var ErrChannelNotFound = errors.New("canal de notificação não encontrado")
+
+// ErrChannelExists indica que já existe um canal de notificação com o mesmo nome
+var ErrChannelExists = errors.New("já existe um canal de notificação com este nome")
+
+const channelColumns = `id, name, type, settings, title_template, body_template, group_by, min_severity,
+		group_wait_seconds, repeat_interval_seconds, max_attempts, enabled, created_at, updated_at`
+
+// NotificationChannelRepository gerencia as tabelas notification_channels e notification_failures
+type NotificationChannelRepository struct {
+	db *database.DB
+}
+
+// NewNotificationChannelRepository cria um novo repositório de canais de notificação
+func NewNotificationChannelRepository(db *database.DB) *NotificationChannelRepository {
+	return &NotificationChannelRepository{db: db}
+}
+
+// List retorna todos os canais de notificação
+func (r *NotificationChannelRepository) List() ([]models.NotificationChannel, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	channels := []models.NotificationChannel{}
+	query := "SELECT " + channelColumns + " FROM notification_channels ORDER BY name"
+	if err := r.db.Select(&channels, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar canais de notificação: %w", err)
+	}
+
+	return channels, nil
+}
+
+// ListEnabled retorna apenas os canais habilitados
+func (r *NotificationChannelRepository) ListEnabled() ([]models.NotificationChannel, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	channels := []models.NotificationChannel{}
+	query := "SELECT " + channelColumns + " FROM notification_channels WHERE enabled ORDER BY name"
+	if err := r.db.Select(&channels, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar canais de notificação: %w", err)
+	}
+
+	return channels, nil
+}
+
+// Find busca um canal pelo ID ou pelo nome
+func (r *NotificationChannelRepository) Find(idOrName string) (*models.NotificationChannel, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	channel := &models.NotificationChannel{}
+	query := "SELECT " + channelColumns + " FROM notification_channels WHERE id::text = $1 OR name = $1 LIMIT 1"
+	if err := r.db.Get(channel, query, idOrName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChannelNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar canal de notificação: %w", err)
+	}
+
+	return channel, nil
+}
+
+// Create grava um novo canal de notificação
+func (r *NotificationChannelRepository) Create(c *models.NotificationChannel) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO notification_channels (name, type, settings, title_template, body_template, group_by,
+		min_severity, group_wait_seconds, repeat_interval_seconds, max_attempts, enabled)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowx(query, c.Name, c.Type, c.Settings, c.TitleTemplate, c.BodyTemplate, c.GroupBy,
+		c.MinSeverity, c.GroupWaitSeconds, c.RepeatIntervalSeconds, c.MaxAttempts, c.Enabled).
+		Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrChannelExists
+		}
+		return fmt.Errorf("falha ao criar canal de notificação: %w", err)
+	}
+
+	return nil
+}
+
+// Update altera um canal de notificação existente
+func (r *NotificationChannelRepository) Update(c *models.NotificationChannel) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	UPDATE notification_channels
+	SET name = $2, type = $3, settings = $4, title_template = $5, body_template = $6, group_by = $7,
+		min_severity = $8, group_wait_seconds = $9, repeat_interval_seconds = $10, max_attempts = $11, enabled = $12
+	WHERE id = $1
+	RETURNING updated_at`
+
+	err := r.db.QueryRowx(query, c.ID, c.Name, c.Type, c.Settings, c.TitleTemplate, c.BodyTemplate, c.GroupBy,
+		c.MinSeverity, c.GroupWaitSeconds, c.RepeatIntervalSeconds, c.MaxAttempts, c.Enabled).
+		Scan(&c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrChannelNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrChannelExists
+		}
+		return fmt.Errorf("falha ao atualizar canal de notificação: %w", err)
+	}
+
+	return nil
+}
+
+// Delete remove um canal de notificação
+func (r *NotificationChannelRepository) Delete(id string) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	result, err := r.db.Exec("DELETE FROM notification_channels WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("falha ao remover canal de notificação: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrChannelNotFound
+	}
+
+	return nil
+}
+
+// RecordFailure grava uma notificação que não pôde ser entregue ao canal
+func (r *NotificationChannelRepository) RecordFailure(f *models.NotificationFailure) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO notification_failures (channel_id, group_key, dedup_key, status, error, payload)
+	VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+	RETURNING id, created_at`
+
+	err := r.db.QueryRowx(query, f.ChannelID, f.GroupKey, f.DedupKey, f.Status, f.Error, string(f.Payload)).
+		Scan(&f.ID, &f.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar falha de notificação: %w", err)
+	}
+
+	return nil
+}
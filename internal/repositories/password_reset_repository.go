@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pganalytics-backend/internal/database"
+)
+
+// ErrResetTokenNotFound indica token inexistente, expirado ou já utilizado
+var ErrResetTokenNotFound = errors.New("token de redefinição não encontrado")
+
+// PasswordResetRepository gerencia a tabela password_resets
+type PasswordResetRepository struct {
+	db *database.DB
+}
+
+// NewPasswordResetRepository cria um novo repositório de redefinição de senha
+func NewPasswordResetRepository(db *database.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create grava um novo token para o email. Tokens anteriores continuam válidos até
+// expirarem ou até um deles ser consumido, para que solicitações de terceiros não
+// invalidem o link que o dono da conta já recebeu.
+func (r *PasswordResetRepository) Create(email, tokenHash string, expiresAt time.Time) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	if _, err := r.db.Exec("INSERT INTO password_resets (email, token_hash, expires_at) VALUES ($1, $2, $3)", email, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("falha ao gravar token de redefinição: %w", err)
+	}
+	return nil
+}
+
+// Consume marca o token como usado e retorna o email associado.
+// A marcação é atômica: um token só pode ser consumido uma vez. Os demais tokens
+// pendentes do mesmo email são invalidados junto.
+func (r *PasswordResetRepository) Consume(tokenHash string) (string, error) {
+	if r.db == nil {
+		return "", ErrNoDatabase
+	}
+
+	var email string
+	query := `
+	WITH consumed AS (
+		UPDATE password_resets SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING email
+	), siblings AS (
+		UPDATE password_resets SET used_at = NOW()
+		WHERE lower(email) IN (SELECT lower(email) FROM consumed)
+			AND token_hash <> $1 AND used_at IS NULL
+	)
+	SELECT email FROM consumed`
+
+	if err := r.db.Get(&email, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("falha ao consumir token de redefinição: %w", err)
+	}
+
+	return email, nil
+}
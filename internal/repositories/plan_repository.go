@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrPlanNotFound indica que o plano não existe em slow_queries_log
+var ErrPlanNotFound = errors.New("plano não encontrado")
+
+// PlanRepository grava e consulta os planos capturados em slow_queries_log
+type PlanRepository struct {
+	db *database.DB
+}
+
+// NewPlanRepository cria um novo repositório de planos
+func NewPlanRepository(db *database.DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+// planColumns lê o plano de query_plan ou explain_analyze e os tempos do resumo do EXPLAIN
+const planColumns = `id, target_name, database_name, username, queryid, query_hash, query_text,
+		explain_analyze IS NOT NULL as analyze,
+		coalesce((plan_options ->> 'buffers')::bool, false) as buffers,
+		coalesce((plan_options ->> 'generic_plan')::bool, false) as generic_plan,
+		(coalesce(explain_analyze, query_plan) -> 0 ->> 'Planning Time')::float8 as planning_time_ms,
+		(coalesce(explain_analyze, query_plan) -> 0 ->> 'Execution Time')::float8 as execution_time_ms,
+		coalesce(explain_analyze, query_plan) as plan,
+		created_at`
+
+// Insert grava o plano em slow_queries_log (query_plan ou explain_analyze, conforme ANALYZE)
+// e preenche ID e CreatedAt
+func (r *PlanRepository) Insert(plan *models.QueryPlan, opts models.ExplainOptions) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar opções do EXPLAIN: %w", err)
+	}
+
+	var queryPlan, analyzePlan *string
+	text := string(plan.Plan)
+	if opts.Analyze {
+		analyzePlan = &text
+	} else {
+		queryPlan = &text
+	}
+
+	var executionMs int64
+	if plan.ExecutionTimeMs != nil {
+		executionMs = int64(math.Round(*plan.ExecutionTimeMs))
+	}
+
+	query := `
+	INSERT INTO slow_queries_log (
+		target_name, database_name, username, query_text, query_hash, queryid,
+		execution_time_ms, query_plan, explain_analyze, plan_options
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9::jsonb, $10::jsonb)
+	RETURNING id, created_at`
+
+	err = r.db.QueryRowx(query, plan.TargetName, plan.DatabaseName, plan.Username, plan.QueryText,
+		plan.QueryHash, plan.QueryID, executionMs, queryPlan, analyzePlan, string(options)).
+		Scan(&plan.ID, &plan.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar plano: %w", err)
+	}
+
+	return nil
+}
+
+// CountSince conta os planos gravados para o target desde since. targetName vazio é o banco local.
+func (r *PlanRepository) CountSince(targetName string, since time.Time) (int, error) {
+	if r.db == nil {
+		return 0, ErrNoDatabase
+	}
+
+	var count int
+	query := `
+	SELECT count(*) FROM slow_queries_log
+	WHERE target_name IS NOT DISTINCT FROM nullif($1, '') AND created_at >= $2
+		AND (query_plan IS NOT NULL OR explain_analyze IS NOT NULL)`
+
+	if err := r.db.Get(&count, query, targetName, since); err != nil {
+		return 0, fmt.Errorf("falha ao contar planos recentes: %w", err)
+	}
+	return count, nil
+}
+
+// Get busca um plano gravado pelo ID
+func (r *PlanRepository) Get(id string) (*models.QueryPlan, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	plan := &models.QueryPlan{}
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM slow_queries_log
+	WHERE id::text = $1 AND (query_plan IS NOT NULL OR explain_analyze IS NOT NULL)`, planColumns)
+
+	if err := r.db.Get(plan, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar plano: %w", err)
+	}
+
+	return plan, nil
+}
+
+// List retorna os planos gravados de um target, dos mais recentes para os mais antigos
+func (r *PlanRepository) List(q models.QueryPlanQuery) ([]models.QueryPlan, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	where := "(query_plan IS NOT NULL OR explain_analyze IS NOT NULL) AND target_name IS NOT DISTINCT FROM nullif($1, '')"
+	args := []interface{}{q.Target}
+	if q.QueryHash != "" {
+		args = append(args, q.QueryHash)
+		where += fmt.Sprintf(" AND query_hash = $%d", len(args))
+	}
+	if q.QueryID != nil {
+		args = append(args, *q.QueryID)
+		where += fmt.Sprintf(" AND queryid = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM slow_queries_log
+	WHERE %s
+	ORDER BY created_at DESC
+	LIMIT $%d`, planColumns, where, len(args))
+
+	plans := []models.QueryPlan{}
+	if err := r.db.Select(&plans, query, args...); err != nil {
+		return nil, fmt.Errorf("falha ao listar planos: %w", err)
+	}
+
+	return plans, nil
+}
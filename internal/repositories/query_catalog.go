@@ -0,0 +1,470 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// ErrCollectorUnsupported indica que o coletor não é suportado pela versão do servidor ou extensões do target
+var ErrCollectorUnsupported = errors.New("coletor não suportado")
+
+// UnsupportedCollectorError descreve por que um coletor não é suportado
+type UnsupportedCollectorError struct {
+	Collector string
+	Reason    string
+}
+
+func (e *UnsupportedCollectorError) Error() string {
+	return fmt.Sprintf("coletor %s não suportado: %s", e.Collector, e.Reason)
+}
+
+// Is permite errors.Is(err, ErrCollectorUnsupported)
+func (e *UnsupportedCollectorError) Is(target error) bool {
+	return target == ErrCollectorUnsupported
+}
+
+// QueryCatalog escolhe a variante de SQL de cada coletor conforme a versão do servidor
+// e das extensões detectadas no target
+type QueryCatalog struct {
+	capabilities models.ServerCapabilities
+}
+
+// NewQueryCatalog cria um catálogo a partir das capacidades detectadas
+func NewQueryCatalog(capabilities models.ServerCapabilities) *QueryCatalog {
+	return &QueryCatalog{capabilities: capabilities}
+}
+
+// CatalogTTL é a validade das capacidades detectadas; depois disso a versão e as extensões são
+// lidas de novo, para refletir upgrades e extensões instaladas ou removidas no servidor
+const CatalogTTL = 10 * time.Minute
+
+// Expired indica se as capacidades foram detectadas há mais de CatalogTTL.
+// Catálogos montados com capacidades fixas (sem DetectedAt) não expiram.
+func (c *QueryCatalog) Expired() bool {
+	detected := c.capabilities.DetectedAt
+	return !detected.IsZero() && time.Since(detected) > CatalogTTL
+}
+
+// Capabilities retorna a versão e as extensões usadas pelo catálogo
+func (c *QueryCatalog) Capabilities() models.ServerCapabilities {
+	return c.capabilities
+}
+
+// Collectors retorna o estado de todos os coletores do catálogo
+func (c *QueryCatalog) Collectors() []models.CollectorStatus {
+	names := []string{
+		models.CollectorSlowQueries,
+		models.CollectorCheckpoints,
+		models.CollectorLocks,
+		models.CollectorReplication,
+		models.CollectorVacuumProgress,
+		models.CollectorBloatMeasure,
+		models.CollectorGenericPlan,
+	}
+
+	statuses := make([]models.CollectorStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, c.Status(name))
+	}
+	return statuses
+}
+
+// Status retorna se o coletor é suportado e qual variante de SQL será usada
+func (c *QueryCatalog) Status(collector string) models.CollectorStatus {
+	version := c.capabilities.ServerVersionNum
+	status := models.CollectorStatus{Name: collector}
+
+	switch collector {
+	case models.CollectorSlowQueries:
+		extVersion, ok := c.capabilities.Extensions["pg_stat_statements"]
+		switch {
+		case !ok:
+			status.Reason = "extensão pg_stat_statements não instalada no banco monitorado"
+		case extensionAtLeast(extVersion, 1, 8):
+			status.Supported, status.Variant = true, "mean_exec_time"
+		default:
+			status.Supported, status.Variant = true, "mean_time"
+		}
+	case models.CollectorCheckpoints:
+		switch {
+		case version < 100000:
+			status.Reason = "requer PostgreSQL 10 ou superior"
+		case version >= 170000:
+			status.Supported, status.Variant = true, "pg_stat_checkpointer"
+		case version >= 140000:
+			status.Supported, status.Variant = true, "pg_stat_wal"
+		default:
+			status.Supported, status.Variant = true, "pg_stat_bgwriter"
+		}
+	case models.CollectorLocks, models.CollectorVacuumProgress:
+		if version < 90600 {
+			status.Reason = "requer PostgreSQL 9.6 ou superior"
+		} else {
+			status.Supported, status.Variant = true, "default"
+		}
+	case models.CollectorReplication:
+		if version < 100000 {
+			status.Reason = "requer PostgreSQL 10 ou superior"
+		} else {
+			status.Supported, status.Variant = true, "default"
+		}
+	case models.CollectorBloatMeasure:
+		extVersion, ok := c.capabilities.Extensions["pgstattuple"]
+		switch {
+		case !ok:
+			status.Reason = "extensão pgstattuple não instalada no banco monitorado"
+		case !extensionAtLeast(extVersion, 1, 3):
+			status.Reason = "requer pgstattuple 1.3 ou superior (pgstattuple_approx)"
+		default:
+			status.Supported, status.Variant = true, "pgstattuple_approx"
+		}
+	case models.CollectorGenericPlan:
+		switch {
+		case version < 120000:
+			status.Reason = "requer PostgreSQL 12 ou superior (plan_cache_mode)"
+		case version >= 160000:
+			status.Supported, status.Variant = true, "explain_option"
+		default:
+			status.Supported, status.Variant = true, "prepared_statement"
+		}
+	default:
+		status.Reason = "coletor desconhecido"
+	}
+
+	return status
+}
+
+// Require retorna UnsupportedCollectorError quando o coletor não é suportado
+func (c *QueryCatalog) Require(collector string) error {
+	if status := c.Status(collector); !status.Supported {
+		return &UnsupportedCollectorError{Collector: collector, Reason: status.Reason}
+	}
+	return nil
+}
+
+// SlowQueriesQuery retorna a consulta de queries lentas para a versão de pg_stat_statements:
+// até a 1.7 os tempos ficam em mean_time/total_time, a partir da 1.8 em mean_exec_time/total_exec_time
+func (c *QueryCatalog) SlowQueriesQuery() (string, error) {
+	if err := c.Require(models.CollectorSlowQueries); err != nil {
+		return "", err
+	}
+
+	meanColumn := c.Status(models.CollectorSlowQueries).Variant
+	return fmt.Sprintf(`
+	SELECT 
+		substring(query, 1, 150) as query_text,
+		round(%[1]s::numeric, 2) as duration_ms,
+		calls,
+		rows,
+		pg_get_userbyid(userid) as username,
+		md5(query) as query_hash
+	FROM pg_stat_statements 
+	WHERE %[1]s > 100
+	ORDER BY %[1]s DESC
+	LIMIT 10`, meanColumn), nil
+}
+
+// CheckpointStatsQuery retorna a consulta de checkpoints, bgwriter e WAL para a versão do servidor
+func (c *QueryCatalog) CheckpointStatsQuery() (string, error) {
+	if err := c.Require(models.CollectorCheckpoints); err != nil {
+		return "", err
+	}
+	return CheckpointStatsQuery(c.capabilities.ServerVersionNum), nil
+}
+
+// statementListTextLength é o tamanho do texto da query na listagem do explorador
+const statementListTextLength = 300
+
+// statementSortExpressions mapeia as colunas de ordenação para expressões sobre statementsBase
+var statementSortExpressions = map[string]string{
+	models.StatementSortTotalTime:      "total_time_ms",
+	models.StatementSortMeanTime:       "mean_time_ms",
+	models.StatementSortCalls:          "calls",
+	models.StatementSortRows:           "rows",
+	models.StatementSortSharedBlksRead: "shared_blks_read",
+	models.StatementSortSharedBlksHit:  "shared_blks_hit",
+	models.StatementSortTempBlks:       "temp_blks_read + temp_blks_written",
+	models.StatementSortWALBytes:       "wal_bytes",
+}
+
+// statementsBase monta o SELECT de pg_stat_statements com as colunas normalizadas para a versão
+// da extensão. Tempos de I/O são lidos via to_jsonb porque blk_read_time foi renomeado para
+// shared_blk_read_time na 1.11, e toplevel só existe a partir da 1.9.
+func (c *QueryCatalog) statementsBase(queryText, where string) string {
+	modern := c.Status(models.CollectorSlowQueries).Variant == "mean_exec_time"
+
+	timeColumn := func(name string) string {
+		if modern {
+			return "s." + strings.Replace(name, "_time", "_exec_time", 1)
+		}
+		return "s." + name
+	}
+	optional := func(expr, cast string) string {
+		if modern {
+			return expr
+		}
+		return "NULL::" + cast
+	}
+
+	return fmt.Sprintf(`
+		SELECT
+			coalesce(s.queryid, 0) as queryid,
+			s.dbid::int8 as dbid,
+			coalesce(d.datname, '') as database_name,
+			s.userid::int8 as userid,
+			coalesce(r.rolname, '') as username,
+			coalesce((to_jsonb(s) ->> 'toplevel')::bool, true) as toplevel,
+			%s as query,
+			md5(s.query) as query_hash,
+			s.calls,
+			%s as total_time_ms,
+			%s as mean_time_ms,
+			%s as min_time_ms,
+			%s as max_time_ms,
+			%s as stddev_time_ms,
+			%s as plans,
+			%s as total_plan_time_ms,
+			s.rows,
+			s.shared_blks_hit,
+			s.shared_blks_read,
+			s.shared_blks_dirtied,
+			s.shared_blks_written,
+			s.local_blks_hit,
+			s.local_blks_read,
+			s.temp_blks_read,
+			s.temp_blks_written,
+			coalesce((to_jsonb(s) ->> 'shared_blk_read_time')::float8, (to_jsonb(s) ->> 'blk_read_time')::float8, 0) as blk_read_time_ms,
+			coalesce((to_jsonb(s) ->> 'shared_blk_write_time')::float8, (to_jsonb(s) ->> 'blk_write_time')::float8, 0) as blk_write_time_ms,
+			%s as wal_records,
+			%s as wal_fpi,
+			%s as wal_bytes,
+			coalesce(round(100 * s.shared_blks_hit::numeric / nullif(s.shared_blks_hit + s.shared_blks_read, 0), 2), 100)::float8 as hit_percent
+		FROM pg_stat_statements s
+		LEFT JOIN pg_database d ON d.oid = s.dbid
+		LEFT JOIN pg_roles r ON r.oid = s.userid
+		WHERE %s`,
+		queryText,
+		timeColumn("total_time"),
+		timeColumn("mean_time"),
+		timeColumn("min_time"),
+		timeColumn("max_time"),
+		timeColumn("stddev_time"),
+		optional("s.plans", "bigint"),
+		optional("s.total_plan_time", "float8"),
+		optional("s.wal_records", "bigint"),
+		optional("s.wal_fpi", "bigint"),
+		optional("s.wal_bytes::bigint", "bigint"),
+		where,
+	)
+}
+
+// StatementsQuery retorna a consulta paginada do explorador de pg_stat_statements e seus argumentos.
+// A paginação é por cursor (keyset) sobre a coluna de ordenação e a chave da entrada.
+func (c *QueryCatalog) StatementsQuery(q models.StatementQuery) (string, []interface{}, error) {
+	if err := c.Require(models.CollectorSlowQueries); err != nil {
+		return "", nil, err
+	}
+
+	sortExpr, ok := statementSortExpressions[q.SortBy]
+	if !ok {
+		return "", nil, fmt.Errorf("coluna de ordenação desconhecida: %s", q.SortBy)
+	}
+	if q.SortBy == models.StatementSortWALBytes && c.Status(models.CollectorSlowQueries).Variant != "mean_exec_time" {
+		return "", nil, &UnsupportedCollectorError{Collector: models.CollectorSlowQueries, Reason: "ordenação por wal_bytes requer pg_stat_statements 1.8 ou superior"}
+	}
+
+	where := "true"
+	args := []interface{}{}
+	if q.Database != "" {
+		args = append(args, q.Database)
+		where += fmt.Sprintf(" AND d.datname = $%d", len(args))
+	}
+	if q.User != "" {
+		args = append(args, q.User)
+		where += fmt.Sprintf(" AND r.rolname = $%d", len(args))
+	}
+	if q.QueryID != nil {
+		args = append(args, *q.QueryID)
+		where += fmt.Sprintf(" AND s.queryid = $%d", len(args))
+	}
+	if q.Search != "" {
+		args = append(args, q.Search)
+		where += fmt.Sprintf(" AND strpos(lower(s.query), lower($%d)) > 0", len(args))
+	}
+
+	direction, comparison := "DESC", "<"
+	if q.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+	sortKey := fmt.Sprintf("coalesce((%s)::numeric, 0)", sortExpr)
+
+	page := "true"
+	if q.Cursor != nil {
+		args = append(args, q.Cursor.SortValue, q.Cursor.QueryID, q.Cursor.DatabaseID, q.Cursor.UserID, q.Cursor.TopLevel)
+		n := len(args)
+		page = fmt.Sprintf("(%s, queryid, dbid, userid, toplevel) %s ($%d::numeric, $%d, $%d, $%d, $%d)",
+			sortKey, comparison, n-4, n-3, n-2, n-1, n)
+	}
+
+	args = append(args, q.Limit)
+	query := fmt.Sprintf(`
+	SELECT b.*, %[1]s::text as sort_value
+	FROM (%[2]s
+	) b
+	WHERE %[3]s
+	ORDER BY %[1]s %[4]s, queryid %[4]s, dbid %[4]s, userid %[4]s, toplevel %[4]s
+	LIMIT $%[5]d`,
+		sortKey,
+		c.statementsBase(fmt.Sprintf("left(s.query, %d)", statementListTextLength), where),
+		page,
+		direction,
+		len(args),
+	)
+
+	return query, args, nil
+}
+
+// StatementQuery retorna a consulta de todas as entradas de um queryid com o texto completo
+func (c *QueryCatalog) StatementQuery() (string, error) {
+	if err := c.Require(models.CollectorSlowQueries); err != nil {
+		return "", err
+	}
+	return c.statementsBase("s.query", "s.queryid = $1") + `
+		ORDER BY total_time_ms DESC`, nil
+}
+
+// explainPreparedName é o nome do prepared statement usado no plano genérico antes do PostgreSQL 16
+const explainPreparedName = "pganalytics_explain"
+
+// ExplainStatement monta o EXPLAIN (FORMAT JSON) da query para as opções e a versão do servidor.
+// No plano genérico o PostgreSQL 16+ usa a opção GENERIC_PLAN; entre 12 e 15 a query é preparada
+// como explainPreparedName e executada com NULL em cada parâmetro sob plan_cache_mode =
+// force_generic_plan (params é a quantidade de parâmetros do prepared statement).
+// BUFFERS sem ANALYZE só é aceito a partir do PostgreSQL 13.
+func (c *QueryCatalog) ExplainStatement(query string, opts models.ExplainOptions, params int) (string, error) {
+	options := []string{"FORMAT JSON"}
+	if opts.Analyze {
+		options = append(options, "ANALYZE")
+	}
+	if opts.Buffers && (opts.Analyze || c.capabilities.ServerVersionNum >= 130000) {
+		options = append(options, "BUFFERS")
+	}
+
+	if opts.GenericPlan {
+		if err := c.Require(models.CollectorGenericPlan); err != nil {
+			return "", err
+		}
+		if c.Status(models.CollectorGenericPlan).Variant == "prepared_statement" {
+			args := ""
+			if params > 0 {
+				args = "(" + strings.TrimSuffix(strings.Repeat("NULL, ", params), ", ") + ")"
+			}
+			query = "EXECUTE " + explainPreparedName + args
+		} else {
+			options = append(options, "GENERIC_PLAN")
+		}
+	}
+
+	return fmt.Sprintf("EXPLAIN (%s) %s", strings.Join(options, ", "), query), nil
+}
+
+// PreparesGenericPlan indica se o plano genérico depende de PREPARE (PostgreSQL 12 a 15)
+func (c *QueryCatalog) PreparesGenericPlan() bool {
+	status := c.Status(models.CollectorGenericPlan)
+	return status.Supported && status.Variant == "prepared_statement"
+}
+
+// DetectCapabilities lê server_version_num e as versões das extensões instaladas
+func (r *AnalyticsRepository) DetectCapabilities() (*models.ServerCapabilities, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	var server struct {
+		VersionNum int    `db:"server_version_num"`
+		Version    string `db:"server_version"`
+	}
+	query := `
+	SELECT
+		current_setting('server_version_num')::int as server_version_num,
+		current_setting('server_version') as server_version`
+	if err := r.db.Get(&server, query); err != nil {
+		return nil, fmt.Errorf("falha ao obter versão do servidor: %w", err)
+	}
+
+	extensions := []models.ExtensionVersion{}
+	if err := r.db.Select(&extensions, "SELECT extname, extversion FROM pg_extension"); err != nil {
+		return nil, fmt.Errorf("falha ao listar extensões: %w", err)
+	}
+
+	capabilities := &models.ServerCapabilities{
+		ServerVersionNum: server.VersionNum,
+		ServerVersion:    server.Version,
+		Extensions:       make(map[string]string, len(extensions)),
+		DetectedAt:       time.Now(),
+	}
+	for _, ext := range extensions {
+		capabilities.Extensions[ext.Name] = ext.Version
+	}
+
+	return capabilities, nil
+}
+
+// Catalog retorna o catálogo de consultas do target, detectando as capacidades na primeira chamada
+// e novamente quando o catálogo tem mais de CatalogTTL
+func (r *AnalyticsRepository) Catalog() (*QueryCatalog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.catalog != nil && !r.catalog.Expired() {
+		return r.catalog, nil
+	}
+
+	capabilities, err := r.DetectCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	r.catalog = NewQueryCatalog(*capabilities)
+	return r.catalog, nil
+}
+
+// SetCatalog reaproveita um catálogo já detectado para o mesmo target
+func (r *AnalyticsRepository) SetCatalog(catalog *QueryCatalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.catalog = catalog
+}
+
+// require verifica no catálogo do target se o coletor é suportado
+func (r *AnalyticsRepository) require(collector string) error {
+	catalog, err := r.Catalog()
+	if err != nil {
+		return err
+	}
+	return catalog.Require(collector)
+}
+
+// extensionAtLeast compara a versão de uma extensão ("1.10") com major.minor
+func extensionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	gotMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	gotMinor := 0
+	if len(parts) > 1 {
+		if gotMinor, err = strconv.Atoi(parts[1]); err != nil {
+			return false
+		}
+	}
+
+	if gotMajor != major {
+		return gotMajor > major
+	}
+	return gotMinor >= minor
+}
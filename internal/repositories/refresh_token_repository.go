@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrRefreshTokenNotFound indica que nenhum refresh token corresponde ao hash informado
+var ErrRefreshTokenNotFound = errors.New("refresh token não encontrado")
+
+// ErrRefreshTokenRevoked indica que o token já havia sido revogado ao tentar rotacioná-lo
+var ErrRefreshTokenRevoked = errors.New("refresh token já revogado")
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, revoked_at,
+		host(ip_address) as ip_address, coalesce(user_agent, '') as user_agent, created_at`
+
+// RefreshTokenRepository gerencia a tabela refresh_tokens
+type RefreshTokenRepository struct {
+	db *database.DB
+}
+
+// NewRefreshTokenRepository cria um novo repositório de refresh tokens
+func NewRefreshTokenRepository(db *database.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create grava um novo token. Um FamilyID vazio inicia uma nova família (login).
+func (r *RefreshTokenRepository) Create(t *models.RefreshToken) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	if err := r.insert(r.db, t); err != nil {
+		return fmt.Errorf("falha ao gravar refresh token: %w", err)
+	}
+	return nil
+}
+
+// FindByHash busca um token pelo hash, inclusive revogados ou expirados
+func (r *RefreshTokenRepository) FindByHash(hash string) (*models.RefreshToken, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	token := &models.RefreshToken{}
+	query := "SELECT " + refreshTokenColumns + " FROM refresh_tokens WHERE token_hash = $1"
+	if err := r.db.Get(token, query, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Rotate revoga oldID e grava next na mesma família, numa única transação.
+// Retorna ErrRefreshTokenRevoked se oldID já tiver sido revogado (uso concorrente).
+func (r *RefreshTokenRepository) Rotate(oldID string, next *models.RefreshToken) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.insert(tx, next); err != nil {
+		return fmt.Errorf("falha ao gravar refresh token: %w", err)
+	}
+
+	result, err := tx.Exec(`
+	UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $2
+	WHERE id = $1 AND revoked_at IS NULL`, oldID, next.ID)
+	if err != nil {
+		return fmt.Errorf("falha ao revogar refresh token: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrRefreshTokenRevoked
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar rotação: %w", err)
+	}
+	return nil
+}
+
+// RevokeFamily revoga todos os tokens ativos de uma família
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) (int64, error) {
+	return r.revoke("family_id = $1", familyID)
+}
+
+// RevokeAllForUser revoga todos os tokens ativos de um usuário
+func (r *RefreshTokenRepository) RevokeAllForUser(userID string) (int64, error) {
+	return r.revoke("user_id = $1", userID)
+}
+
+func (r *RefreshTokenRepository) revoke(where string, arg string) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNoDatabase
+	}
+
+	result, err := r.db.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE revoked_at IS NULL AND "+where, arg)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao revogar refresh tokens: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected, nil
+}
+
+// insert grava t e preenche ID, FamilyID e CreatedAt
+func (r *RefreshTokenRepository) insert(q sqlx.Queryer, t *models.RefreshToken) error {
+	var ip string
+	if t.IPAddress != nil {
+		ip = *t.IPAddress
+	}
+
+	query := `
+	INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, ip_address, user_agent)
+	SELECT new_id, $1, coalesce(nullif($2, '')::uuid, new_id), $3, $4, nullif($5, '')::inet, nullif($6, '')
+	FROM (SELECT uuid_generate_v4() AS new_id) AS n
+	RETURNING id, family_id, created_at`
+
+	return q.QueryRowx(query, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, ip, t.UserAgent).
+		Scan(&t.ID, &t.FamilyID, &t.CreatedAt)
+}
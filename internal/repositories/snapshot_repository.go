@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// SnapshotRepository grava snapshots coletados nas tabelas *_log
+type SnapshotRepository struct {
+	db *database.DB
+}
+
+// NewSnapshotRepository cria um novo repositório de snapshots
+func NewSnapshotRepository(db *database.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// InsertStatementSnapshots grava entradas de pg_stat_statements em slow_queries_log.
+// calls e total_time_ms são os contadores cumulativos, usados para calcular a latência
+// de cada intervalo entre snapshots. target vazio identifica o banco local da API.
+func (r *SnapshotRepository) InsertStatementSnapshots(target string, statements []models.StatementStats) error {
+	query := `
+	INSERT INTO slow_queries_log (
+		target_name, database_name, username, query_text, query_hash, queryid,
+		execution_time_ms, rows_returned, calls, total_time_ms
+	) VALUES (nullif($1, ''), $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	return r.batch("slow_queries_log", query, len(statements), func(stmt *sqlx.Stmt, i int) error {
+		s := statements[i]
+		username := s.Username
+		if username == "" {
+			username = "unknown"
+		}
+		hash := s.QueryHash
+		if hash == "" {
+			sum := md5.Sum([]byte(s.Query))
+			hash = hex.EncodeToString(sum[:])
+		}
+		_, err := stmt.Exec(
+			target, s.DatabaseName, username, s.Query, hash, s.QueryID,
+			int64(math.Round(s.MeanTimeMs)), s.Rows, s.Calls, s.TotalTimeMs,
+		)
+		return err
+	})
+}
+
+// InsertTableStats grava estatísticas de tabelas em table_stats_log
+func (r *SnapshotRepository) InsertTableStats(target, databaseName string, stats []models.TableStatSnapshot) error {
+	query := `
+	INSERT INTO table_stats_log (
+		target_name, database_name, schema_name, table_name, row_count,
+		table_size_bytes, index_size_bytes, total_size_bytes,
+		seq_scan_count, seq_tup_read, idx_scan_count, idx_tup_fetch,
+		n_tup_ins, n_tup_upd, n_tup_del, n_tup_hot_upd, n_live_tup, n_dead_tup,
+		vacuum_count, autovacuum_count, analyze_count, autoanalyze_count,
+		last_vacuum, last_autovacuum, last_analyze, last_autoanalyze
+	) VALUES (nullif($1, ''), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)`
+
+	return r.batch("table_stats_log", query, len(stats), func(stmt *sqlx.Stmt, i int) error {
+		t := stats[i]
+		_, err := stmt.Exec(
+			target, databaseName, t.SchemaName, t.TableName, t.RowCount,
+			t.TableSizeBytes, t.IndexSizeBytes, t.TotalSizeBytes,
+			t.SeqScanCount, t.SeqTupRead, t.IdxScanCount, t.IdxTupFetch,
+			t.NTupIns, t.NTupUpd, t.NTupDel, t.NTupHotUpd, t.NLiveTup, t.NDeadTup,
+			t.VacuumCount, t.AutovacuumCount, t.AnalyzeCount, t.AutoanalyzeCount,
+			t.LastVacuum, t.LastAutovacuum, t.LastAnalyze, t.LastAutoanalyze,
+		)
+		return err
+	})
+}
+
+// InsertConnections grava backends de pg_stat_activity em pg_connections_log
+func (r *SnapshotRepository) InsertConnections(target string, connections []models.ConnectionSnapshot) error {
+	query := `
+	INSERT INTO pg_connections_log (
+		target_name, database_name, username, client_addr, application_name, state,
+		backend_start, query_start, state_change, wait_event_type, wait_event,
+		query, connection_duration_ms
+	) VALUES (nullif($1, ''), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	return r.batch("pg_connections_log", query, len(connections), func(stmt *sqlx.Stmt, i int) error {
+		c := connections[i]
+		_, err := stmt.Exec(
+			target, c.DatabaseName, c.Username, c.ClientAddr, c.ApplicationName, c.State,
+			c.BackendStart, c.QueryStart, c.StateChange, c.WaitEventType, c.WaitEvent,
+			c.Query, c.DurationMs,
+		)
+		return err
+	})
+}
+
+// InsertSystemMetrics grava métricas pontuais em system_metrics_log
+func (r *SnapshotRepository) InsertSystemMetrics(target string, metrics []models.SystemMetric) error {
+	query := `
+	INSERT INTO system_metrics_log (target_name, metric_type, metric_name, metric_value, metric_unit, database_name)
+	VALUES (nullif($1, ''), $2, $3, $4, $5, $6)`
+
+	return r.batch("system_metrics_log", query, len(metrics), func(stmt *sqlx.Stmt, i int) error {
+		m := metrics[i]
+		_, err := stmt.Exec(target, m.MetricType, m.MetricName, m.MetricValue, m.MetricUnit, m.DatabaseName)
+		return err
+	})
+}
+
+// InsertLockSnapshot grava uma árvore de bloqueio em lock_snapshots_log
+func (r *SnapshotRepository) InsertLockSnapshot(target string, tree *models.LockTree) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar árvore de bloqueio: %w", err)
+	}
+
+	query := `
+	INSERT INTO lock_snapshots_log (target_name, max_wait_ms, waiting_count, blocker_count, tree)
+	VALUES (nullif($1, ''), $2, $3, $4, $5::jsonb)`
+
+	if _, err := r.db.Exec(query, target, tree.MaxWaitMs, tree.WaitingCount, tree.BlockerCount, string(data)); err != nil {
+		return fmt.Errorf("falha ao gravar em lock_snapshots_log: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeOlderThan remove snapshots mais antigos que o período de retenção
+func (r *SnapshotRepository) PurgeOlderThan(days int) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNoDatabase
+	}
+
+	var total int64
+	for _, table := range []string{"slow_queries_log", "table_stats_log", "pg_connections_log", "system_metrics_log", "lock_snapshots_log"} {
+		result, err := r.db.Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE created_at < NOW() - make_interval(days => $1)", table),
+			days,
+		)
+		if err != nil {
+			return total, fmt.Errorf("falha ao limpar %s: %w", table, err)
+		}
+		affected, _ := result.RowsAffected()
+		total += affected
+	}
+
+	return total, nil
+}
+
+// batch executa o insert preparado para cada linha dentro de uma única transação
+func (r *SnapshotRepository) batch(table, query string, n int, exec func(stmt *sqlx.Stmt, i int) error) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+	if n == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		return fmt.Errorf("falha ao preparar insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if err := exec(stmt, i); err != nil {
+			return fmt.Errorf("falha ao inserir em %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrTargetNotFound indica que o target solicitado não está registrado
+var ErrTargetNotFound = errors.New("target não encontrado")
+
+// ErrTargetExists indica que já existe um target com o mesmo nome
+var ErrTargetExists = errors.New("já existe um target com este nome")
+
+const targetColumns = `id, name, dsn, coalesce(credentials_ref, '') as credentials_ref, labels,
+		collection_interval_seconds, enabled, created_at, updated_at`
+
+// TargetRepository gerencia a tabela monitored_targets
+type TargetRepository struct {
+	db *database.DB
+}
+
+// NewTargetRepository cria um novo repositório de targets
+func NewTargetRepository(db *database.DB) *TargetRepository {
+	return &TargetRepository{db: db}
+}
+
+// List retorna todos os targets registrados
+func (r *TargetRepository) List() ([]models.Target, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	targets := []models.Target{}
+	query := "SELECT " + targetColumns + " FROM monitored_targets ORDER BY name"
+	if err := r.db.Select(&targets, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// ListEnabled retorna apenas os targets com coleta habilitada
+func (r *TargetRepository) ListEnabled() ([]models.Target, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	targets := []models.Target{}
+	query := "SELECT " + targetColumns + " FROM monitored_targets WHERE enabled ORDER BY name"
+	if err := r.db.Select(&targets, query); err != nil {
+		return nil, fmt.Errorf("falha ao listar targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// Find busca um target pelo ID ou pelo nome
+func (r *TargetRepository) Find(idOrName string) (*models.Target, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	target := &models.Target{}
+	query := "SELECT " + targetColumns + " FROM monitored_targets WHERE id::text = $1 OR name = $1 LIMIT 1"
+	if err := r.db.Get(target, query, idOrName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTargetNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar target: %w", err)
+	}
+
+	return target, nil
+}
+
+// Create registra um novo target
+func (r *TargetRepository) Create(t *models.Target) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO monitored_targets (name, dsn, credentials_ref, labels, collection_interval_seconds, enabled)
+	VALUES ($1, $2, nullif($3, ''), $4, $5, $6)
+	RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowx(query, t.Name, t.DSN, t.CredentialsRef, t.Labels, t.CollectionIntervalSeconds, t.Enabled).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTargetExists
+		}
+		return fmt.Errorf("falha ao criar target: %w", err)
+	}
+
+	return nil
+}
+
+// Update altera um target existente
+func (r *TargetRepository) Update(t *models.Target) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	UPDATE monitored_targets
+	SET name = $2, dsn = $3, credentials_ref = nullif($4, ''), labels = $5,
+		collection_interval_seconds = $6, enabled = $7
+	WHERE id = $1
+	RETURNING updated_at`
+
+	err := r.db.QueryRowx(query, t.ID, t.Name, t.DSN, t.CredentialsRef, t.Labels, t.CollectionIntervalSeconds, t.Enabled).
+		Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTargetNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrTargetExists
+		}
+		return fmt.Errorf("falha ao atualizar target: %w", err)
+	}
+
+	return nil
+}
+
+// Delete remove um target
+func (r *TargetRepository) Delete(id string) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	result, err := r.db.Exec("DELETE FROM monitored_targets WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("falha ao remover target: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrTargetNotFound
+	}
+
+	return nil
+}
+
+// isUniqueViolation indica violação de constraint UNIQUE (SQLSTATE 23505)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
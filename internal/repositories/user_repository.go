@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+)
+
+// ErrUserNotFound indica que o usuário solicitado não existe
+var ErrUserNotFound = errors.New("usuário não encontrado")
+
+// ErrUserExists indica que já existe um usuário com o mesmo email
+var ErrUserExists = errors.New("já existe um usuário com este email")
+
+const userColumns = `id, email, password_hash, name, role, email_verified, is_active, last_login_at,
+		failed_login_attempts, account_locked_until, created_at, updated_at`
+
+// UserRepository gerencia a tabela users
+type UserRepository struct {
+	db *database.DB
+}
+
+// NewUserRepository cria um novo repositório de usuários
+func NewUserRepository(db *database.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// List retorna todos os usuários, opcionalmente filtrando por papel
+func (r *UserRepository) List(role string) ([]models.User, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	users := []models.User{}
+	query := "SELECT " + userColumns + " FROM users WHERE ($1 = '' OR role = $1) ORDER BY email"
+	if err := r.db.Select(&users, query, role); err != nil {
+		return nil, fmt.Errorf("falha ao listar usuários: %w", err)
+	}
+
+	return users, nil
+}
+
+// Create grava um novo usuário e preenche ID e datas
+func (r *UserRepository) Create(u *models.User) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	INSERT INTO users (email, password_hash, name, role, email_verified, is_active)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowx(query, u.Email, u.PasswordHash, u.Name, u.Role, u.EmailVerified, u.IsActive).
+		Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrUserExists
+		}
+		return fmt.Errorf("falha ao criar usuário: %w", err)
+	}
+
+	return nil
+}
+
+// Update grava email, nome, papel, verificação e status de um usuário
+func (r *UserRepository) Update(u *models.User) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	query := `
+	UPDATE users SET email = $2, name = $3, role = $4, email_verified = $5, is_active = $6
+	WHERE id = $1
+	RETURNING updated_at`
+
+	err := r.db.QueryRowx(query, u.ID, u.Email, u.Name, u.Role, u.EmailVerified, u.IsActive).Scan(&u.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrUserExists
+		}
+		return fmt.Errorf("falha ao atualizar usuário: %w", err)
+	}
+
+	return nil
+}
+
+// Delete remove um usuário; refresh tokens são removidos em cascata
+func (r *UserRepository) Delete(id string) error {
+	return r.exec("falha ao remover usuário", "DELETE FROM users WHERE id = $1", id)
+}
+
+// CountActiveAdmins conta administradores ativos
+func (r *UserRepository) CountActiveAdmins() (int, error) {
+	if r.db == nil {
+		return 0, ErrNoDatabase
+	}
+
+	var count int
+	if err := r.db.Get(&count, "SELECT count(*) FROM users WHERE role = 'admin' AND is_active"); err != nil {
+		return 0, fmt.Errorf("falha ao contar administradores: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindByEmail busca um usuário pelo email, sem diferenciar maiúsculas
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	return r.find("SELECT "+userColumns+" FROM users WHERE lower(email) = lower($1)", email)
+}
+
+// FindByID busca um usuário pelo ID
+func (r *UserRepository) FindByID(id string) (*models.User, error) {
+	return r.find("SELECT "+userColumns+" FROM users WHERE id::text = $1", id)
+}
+
+// RegisterFailedLogin incrementa o contador de falhas do usuário e, ao atingir maxAttempts,
+// bloqueia a conta por lockout e zera o contador. Retorna o fim do bloqueio, se houver.
+func (r *UserRepository) RegisterFailedLogin(id string, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	query := `
+	UPDATE users SET
+		account_locked_until = CASE WHEN failed_login_attempts + 1 >= $2
+			THEN NOW() + make_interval(secs => $3) ELSE account_locked_until END,
+		failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2
+			THEN 0 ELSE failed_login_attempts + 1 END
+	WHERE id = $1
+	RETURNING account_locked_until`
+
+	var lockedUntil *time.Time
+	if err := r.db.QueryRowx(query, id, maxAttempts, lockout.Seconds()).Scan(&lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("falha ao registrar tentativa de login: %w", err)
+	}
+
+	return lockedUntil, nil
+}
+
+// RegisterSuccessfulLogin zera o contador de falhas e grava last_login_at
+func (r *UserRepository) RegisterSuccessfulLogin(id string) error {
+	return r.exec("falha ao registrar login", `
+	UPDATE users SET failed_login_attempts = 0, account_locked_until = NULL, last_login_at = NOW()
+	WHERE id = $1`, id)
+}
+
+// Unlock remove o bloqueio e zera o contador de falhas do usuário
+func (r *UserRepository) Unlock(id string) error {
+	return r.exec("falha ao desbloquear usuário", `
+	UPDATE users SET failed_login_attempts = 0, account_locked_until = NULL
+	WHERE id = $1`, id)
+}
+
+// SetPassword grava um novo hash de senha e remove bloqueios por tentativas falhadas
+func (r *UserRepository) SetPassword(id, passwordHash string) error {
+	return r.exec("falha ao alterar senha", `
+	UPDATE users SET password_hash = $2, failed_login_attempts = 0, account_locked_until = NULL
+	WHERE id = $1`, id, passwordHash)
+}
+
+// exec executa um UPDATE de um único usuário, retornando ErrUserNotFound se nenhum for afetado
+func (r *UserRepository) exec(failure, query string, args ...interface{}) error {
+	if r.db == nil {
+		return ErrNoDatabase
+	}
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", failure, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) find(query string, arg string) (*models.User, error) {
+	if r.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	user := &models.User{}
+	if err := r.db.Get(user, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar usuário: %w", err)
+	}
+
+	return user, nil
+}
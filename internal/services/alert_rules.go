@@ -0,0 +1,539 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// AlertSourceRule identifica em alert_events as transições das regras de alerta
+const AlertSourceRule = "alert_rule"
+
+// Janela de amostras das regras de alerta
+const (
+	DefaultAlertWindowSeconds = 300
+	MinAlertWindowSeconds     = 60
+)
+
+// ErrInvalidAlertRule indica dados inválidos no cadastro de uma regra de alerta
+var ErrInvalidAlertRule = errors.New("regra de alerta inválida")
+
+// alertMetric descreve como uma métrica de regra é lida de system_metrics_log
+type alertMetric struct {
+	source      string // metric_name gravado pelos coletores de snapshot
+	aggregate   string // Combinação das linhas de um snapshot (max ou sum)
+	counter     bool   // Amostras são deltas por intervalo; o valor é a taxa por minuto na janela
+	unit        string
+	description string
+}
+
+// alertMetrics lista as métricas aceitas pelas regras de alerta
+var alertMetrics = map[string]alertMetric{
+	"connection_percent":      {source: "connections_percent", aggregate: "max", unit: "percent", description: "Percentual de max_connections em uso"},
+	"cache_hit_ratio":         {source: "cache_hit_ratio", aggregate: "max", unit: "percent", description: "Cache hit ratio do banco"},
+	"deadlock_rate":           {source: "deadlocks_delta", aggregate: "sum", counter: true, unit: "per_minute", description: "Deadlocks por minuto na janela"},
+	"replication_lag_seconds": {source: "replication_lag_seconds", aggregate: "max", unit: "seconds", description: "Atraso de replay da réplica"},
+	"replication_lag_bytes":   {source: "replication_lag_bytes", aggregate: "max", unit: "bytes", description: "WAL ainda não aplicado pela réplica"},
+	"wraparound_age":          {source: "xid_age", aggregate: "max", unit: "xids", description: "Maior idade de XID entre os bancos"},
+	"wraparound_percent":      {source: "xid_percent_to_freeze", aggregate: "max", unit: "percent", description: "Maior idade de XID em relação a autovacuum_freeze_max_age"},
+}
+
+// AlertMetrics retorna as métricas aceitas pelas regras de alerta em ordem alfabética
+func AlertMetrics() []models.AlertMetric {
+	metrics := make([]models.AlertMetric, 0, len(alertMetrics))
+	for name, m := range alertMetrics {
+		metrics = append(metrics, models.AlertMetric{
+			Name:         name,
+			Source:       m.source,
+			Unit:         m.unit,
+			Description:  m.description,
+			RateOfChange: !m.counter,
+		})
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+// AlertRuleValue calcula o valor avaliado pela regra a partir das amostras da janela, em ordem
+// cronológica. Para threshold é o último valor (ou a taxa por minuto na janela, para contadores);
+// para rate_of_change é a variação por minuto entre a primeira e a última amostra.
+// Retorna false quando não há amostras suficientes.
+func AlertRuleValue(rule models.AlertRule, samples []models.MetricSample) (float64, bool) {
+	metric, ok := alertMetrics[rule.Metric]
+	if !ok || len(samples) == 0 {
+		return 0, false
+	}
+
+	if rule.Condition == models.AlertConditionRateOfChange {
+		first, last := samples[0], samples[len(samples)-1]
+		minutes := last.At.Sub(first.At).Minutes()
+		if len(samples) < 2 || minutes <= 0 {
+			return 0, false
+		}
+		return (last.Value - first.Value) / minutes, true
+	}
+
+	if metric.counter {
+		total := 0.0
+		for _, sample := range samples {
+			total += sample.Value
+		}
+		return total / (float64(rule.WindowSeconds) / 60), true
+	}
+
+	return samples[len(samples)-1].Value, true
+}
+
+// AlertConditionMet compara o valor com o limite usando o operador da regra
+func AlertConditionMet(operator string, value, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
+
+// NextAlertState aplica o resultado de uma avaliação ao estado anterior da regra.
+// A condição passa por pending até se manter por forDuration e então dispara (firing);
+// uma regra disparada que deixa de atender à condição vai para resolved.
+// Uma condição que cessa antes de disparar volta para inactive sem gerar histórico.
+func NextAlertState(prev models.AlertRuleState, breached bool, value float64, forDuration time.Duration, now time.Time) models.AlertRuleState {
+	next := prev
+	next.Value = &value
+	next.EvaluatedAt = now
+
+	if breached {
+		switch prev.State {
+		case models.AlertStateFiring:
+		case models.AlertStatePending:
+			if prev.ActiveSince == nil || now.Sub(*prev.ActiveSince) >= forDuration {
+				next.State = models.AlertStateFiring
+				next.FiredAt = &now
+			}
+		default:
+			next.ActiveSince = &now
+			next.State = models.AlertStatePending
+			if forDuration <= 0 {
+				next.State = models.AlertStateFiring
+				next.FiredAt = &now
+			}
+		}
+		return next
+	}
+
+	switch prev.State {
+	case models.AlertStateFiring:
+		next.State = models.AlertStateResolved
+		next.ResolvedAt = &now
+		next.ActiveSince = nil
+	case models.AlertStatePending:
+		next.State = models.AlertStateInactive
+		next.ActiveSince = nil
+	case "":
+		next.State = models.AlertStateInactive
+	}
+	return next
+}
+
+// AlertRuleService gerencia as regras de alerta e as avalia periodicamente sobre as métricas
+// gravadas pelos coletores de snapshot
+type AlertRuleService struct {
+	repo     *repositories.AlertRuleRepository
+	history  *repositories.HistoryRepository
+	alerts   *AlertService
+	targets  *TargetService
+	audit    *AuditService
+	interval time.Duration
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewAlertRuleService cria um novo serviço de regras de alerta.
+// interval é o intervalo entre avaliações em background.
+func NewAlertRuleService(repo *repositories.AlertRuleRepository, history *repositories.HistoryRepository, alerts *AlertService, targets *TargetService, audit *AuditService, interval time.Duration) *AlertRuleService {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &AlertRuleService{
+		repo:     repo,
+		history:  history,
+		alerts:   alerts,
+		targets:  targets,
+		audit:    audit,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// List retorna as regras com seu estado corrente; state filtra pelo estado (vazio = todas)
+func (s *AlertRuleService) List(state string) ([]models.AlertRuleStatus, error) {
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.repo.ListStates()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.AlertRuleStatus{}
+	for _, rule := range rules {
+		status := models.AlertRuleStatus{AlertRule: rule}
+		if st, ok := states[rule.ID]; ok {
+			status.State = &st
+		}
+		if state != "" && (status.State == nil || status.State.State != state) {
+			continue
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Get busca uma regra pelo ID ou nome, com seu estado corrente
+func (s *AlertRuleService) Get(idOrName string) (*models.AlertRuleStatus, error) {
+	rule, err := s.repo.Find(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.repo.GetState(rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AlertRuleStatus{AlertRule: *rule, State: state}, nil
+}
+
+// Create valida e grava uma nova regra de alerta
+func (s *AlertRuleService) Create(req models.AlertRuleRequest, actor models.AuditActor) (*models.AlertRule, error) {
+	rule := &models.AlertRule{}
+	if err := s.applyRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionCreate, "alert_rule", rule.ID, nil, rule)
+	return rule, nil
+}
+
+// Update altera uma regra existente. O estado corrente é mantido e corrigido na próxima avaliação.
+func (s *AlertRuleService) Update(id string, req models.AlertRuleRequest, actor models.AuditActor) (*models.AlertRule, error) {
+	rule, err := s.repo.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *rule
+
+	if err := s.applyRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionUpdate, "alert_rule", rule.ID, before, rule)
+	return rule, nil
+}
+
+// Delete remove uma regra de alerta. O histórico em alert_events é mantido.
+func (s *AlertRuleService) Delete(id string, actor models.AuditActor) error {
+	rule, err := s.repo.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(rule.ID); err != nil {
+		return err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionDelete, "alert_rule", rule.ID, rule, nil)
+	return nil
+}
+
+// History retorna as transições (firing/resolved) gravadas para a regra no período
+func (s *AlertRuleService) History(id string, q models.AlertEventQuery) (*models.AnalyticsResponse, error) {
+	rule, err := s.repo.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Source = AlertSourceRule
+	q.EventKey = rule.ID
+	response, err := s.alerts.ListEvents(q)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Message = "Histórico da regra de alerta obtido com sucesso"
+	if data, ok := response.Data.(map[string]interface{}); ok {
+		data["rule"] = rule
+	}
+	return response, nil
+}
+
+// applyRequest valida a requisição e preenche a regra
+func (s *AlertRuleService) applyRequest(rule *models.AlertRule, req models.AlertRuleRequest) error {
+	if err := applyAlertRuleRequest(rule, req); err != nil {
+		return err
+	}
+
+	rule.TargetName = nil
+	if req.Target != "" {
+		target, err := s.targets.Get(req.Target)
+		if err != nil {
+			if errors.Is(err, repositories.ErrTargetNotFound) {
+				return fmt.Errorf("%w: target '%s' não está registrado", ErrInvalidAlertRule, req.Target)
+			}
+			return err
+		}
+		rule.TargetName = &target.Name
+	}
+	return nil
+}
+
+// Start inicia a avaliação periódica das regras em background
+func (s *AlertRuleService) Start() {
+	go s.run()
+}
+
+// Stop interrompe a avaliação periódica
+func (s *AlertRuleService) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *AlertRuleService) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Printf("🔔 Avaliador de regras de alerta iniciado (intervalo %s)", s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.Evaluate()
+		case <-s.stop:
+			log.Printf("🔔 Avaliador de regras de alerta finalizado")
+			return
+		}
+	}
+}
+
+// Evaluate avalia as regras habilitadas, grava o novo estado de cada uma e registra as
+// transições para firing e resolved em alert_events
+func (s *AlertRuleService) Evaluate() {
+	rules, err := s.repo.ListEnabled()
+	if err != nil {
+		log.Printf("⚠️ Erro ao listar regras de alerta: %v", err)
+		return
+	}
+	states, err := s.repo.ListStates()
+	if err != nil {
+		log.Printf("⚠️ Erro ao listar estados das regras de alerta: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if err := s.evaluateRule(rule, states[rule.ID], now); err != nil {
+			log.Printf("⚠️ Erro ao avaliar regra de alerta %s: %v", rule.Name, err)
+		}
+	}
+}
+
+// evaluateRule avalia uma regra. Uma janela sem amostras suficientes é tratada conforme
+// no_data_state: ok conta como condição não atendida, alerting como atendida e keep mantém o estado.
+func (s *AlertRuleService) evaluateRule(rule models.AlertRule, prev models.AlertRuleState, now time.Time) error {
+	metric, ok := alertMetrics[rule.Metric]
+	if !ok {
+		return fmt.Errorf("métrica desconhecida: %s", rule.Metric)
+	}
+
+	target := ""
+	if rule.TargetName != nil {
+		target = *rule.TargetName
+	}
+	since := now.Add(-time.Duration(rule.WindowSeconds) * time.Second)
+	samples, err := s.history.GetMetricSamples(target, metric.source, metric.aggregate, since)
+	if err != nil {
+		return err
+	}
+
+	value, hasData := AlertRuleValue(rule, samples)
+	breached := hasData && AlertConditionMet(rule.Operator, value, rule.Threshold)
+	if !hasData {
+		switch rule.NoDataState {
+		case models.AlertNoDataKeep:
+			return nil
+		case models.AlertNoDataAlerting:
+			breached = true
+		}
+	}
+
+	prev.RuleID = rule.ID
+	next := NextAlertState(prev, breached, value, time.Duration(rule.ForSeconds)*time.Second, now)
+	if !hasData {
+		next.Value = nil
+	}
+	if err := s.repo.SaveState(next); err != nil {
+		return err
+	}
+
+	if next.State != prev.State && (next.State == models.AlertStateFiring || next.State == models.AlertStateResolved) {
+		return s.alerts.Emit(alertRuleEvent(rule, next))
+	}
+	return nil
+}
+
+// applyAlertRuleRequest valida os campos da requisição e aplica os padrões
+func applyAlertRuleRequest(rule *models.AlertRule, req models.AlertRuleRequest) error {
+	metric, ok := alertMetrics[req.Metric]
+	if !ok {
+		names := make([]string, 0, len(alertMetrics))
+		for name := range alertMetrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("%w: métrica '%s' desconhecida (use %s)", ErrInvalidAlertRule, req.Metric, strings.Join(names, ", "))
+	}
+
+	condition := req.Condition
+	if condition == "" {
+		condition = models.AlertConditionThreshold
+	}
+	switch condition {
+	case models.AlertConditionThreshold:
+	case models.AlertConditionRateOfChange:
+		if metric.counter {
+			return fmt.Errorf("%w: '%s' já é uma taxa, use a condição threshold", ErrInvalidAlertRule, req.Metric)
+		}
+	default:
+		return fmt.Errorf("%w: condição '%s' desconhecida (use threshold ou rate_of_change)", ErrInvalidAlertRule, condition)
+	}
+
+	switch req.Operator {
+	case ">", ">=", "<", "<=":
+	default:
+		return fmt.Errorf("%w: operador '%s' desconhecido (use >, >=, < ou <=)", ErrInvalidAlertRule, req.Operator)
+	}
+	if req.Threshold == nil {
+		return fmt.Errorf("%w: 'threshold' é obrigatório", ErrInvalidAlertRule)
+	}
+
+	window := req.WindowSeconds
+	if window == 0 {
+		window = DefaultAlertWindowSeconds
+	}
+	if window < MinAlertWindowSeconds {
+		return fmt.Errorf("%w: 'window_seconds' deve ser no mínimo %d", ErrInvalidAlertRule, MinAlertWindowSeconds)
+	}
+	if req.ForSeconds < 0 {
+		return fmt.Errorf("%w: 'for_seconds' não pode ser negativo", ErrInvalidAlertRule)
+	}
+
+	noData := req.NoDataState
+	if noData == "" {
+		noData = models.AlertNoDataOK
+	}
+	switch noData {
+	case models.AlertNoDataOK, models.AlertNoDataAlerting, models.AlertNoDataKeep:
+	default:
+		return fmt.Errorf("%w: no_data_state '%s' desconhecido (use ok, alerting ou keep)", ErrInvalidAlertRule, noData)
+	}
+
+	severity := req.Severity
+	if severity == "" {
+		severity = models.AlertSeverityWarning
+	}
+	switch severity {
+	case models.AlertSeverityInfo, models.AlertSeverityWarning, models.AlertSeverityCritical:
+	default:
+		return fmt.Errorf("%w: severidade '%s' desconhecida (use info, warning ou critical)", ErrInvalidAlertRule, severity)
+	}
+
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.Metric = req.Metric
+	rule.Condition = condition
+	rule.Operator = req.Operator
+	rule.Threshold = *req.Threshold
+	rule.WindowSeconds = window
+	rule.ForSeconds = req.ForSeconds
+	rule.Severity = severity
+	rule.NoDataState = noData
+	rule.Enabled = true
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	return nil
+}
+
+// alertRuleEvent monta o evento de histórico de uma transição para firing ou resolved
+func alertRuleEvent(rule models.AlertRule, state models.AlertRuleState) *models.AlertEvent {
+	target := ""
+	if rule.TargetName != nil {
+		target = *rule.TargetName
+	}
+	unit := alertMetrics[rule.Metric].unit
+
+	condition := rule.Metric
+	if rule.Condition == models.AlertConditionRateOfChange {
+		condition = "variação por minuto de " + rule.Metric
+	}
+
+	event := &models.AlertEvent{
+		TargetName: rule.TargetName,
+		Source:     AlertSourceRule,
+		EventKey:   rule.ID,
+		Severity:   rule.Severity,
+		State:      &state.State,
+		Value:      state.Value,
+	}
+	value := derefFloat(state.Value)
+	if state.State == models.AlertStateResolved {
+		event.Title = fmt.Sprintf("Alerta %s resolvido em %s", rule.Name, targetLabel(target))
+		event.Message = fmt.Sprintf("%s voltou a %.2f %s e não atende mais à condição %s %g", condition, value, unit, rule.Operator, rule.Threshold)
+	} else {
+		event.Title = fmt.Sprintf("Alerta %s disparado em %s", rule.Name, targetLabel(target))
+		event.Message = fmt.Sprintf("%s = %.2f %s atende à condição %s %g", condition, value, unit, rule.Operator, rule.Threshold)
+	}
+	if state.Value == nil {
+		event.Message = fmt.Sprintf("sem amostras de %s nos últimos %ds (no_data_state %s)", rule.Metric, rule.WindowSeconds, rule.NoDataState)
+	}
+
+	details := map[string]interface{}{
+		"rule_id":        rule.ID,
+		"rule_name":      rule.Name,
+		"metric":         rule.Metric,
+		"condition":      rule.Condition,
+		"operator":       rule.Operator,
+		"threshold":      rule.Threshold,
+		"unit":           unit,
+		"window_seconds": rule.WindowSeconds,
+		"for_seconds":    rule.ForSeconds,
+		"no_data":        state.Value == nil,
+		"no_data_state":  rule.NoDataState,
+		"active_since":   state.ActiveSince,
+		"fired_at":       state.FiredAt,
+	}
+	if data, err := json.Marshal(details); err == nil {
+		event.Details = data
+	}
+	return event
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// MaxAlertEvents limita a quantidade de eventos retornados por consulta
+const MaxAlertEvents = 1000
+
+// AlertService grava e consulta eventos de alerta gerados pelos detectores
+type AlertService struct {
+	repo          *repositories.AlertRepository
+	notifications *NotificationService
+}
+
+// NewAlertService cria um novo serviço de alertas
+func NewAlertService(repo *repositories.AlertRepository) *AlertService {
+	return &AlertService{repo: repo}
+}
+
+// SetNotifications encaminha os eventos gravados aos canais de notificação.
+// Deve ser chamado antes de iniciar os detectores.
+func (s *AlertService) SetNotifications(notifications *NotificationService) {
+	s.notifications = notifications
+}
+
+// Record grava o evento, a menos que já exista um com a mesma origem, target e chave desde dedupSince.
+// Retorna true quando o evento foi gravado.
+func (s *AlertService) Record(event *models.AlertEvent, dedupSince time.Time) (bool, error) {
+	target := ""
+	if event.TargetName != nil {
+		target = *event.TargetName
+	}
+
+	exists, err := s.repo.HasRecentEvent(target, event.Source, event.EventKey, dedupSince)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if err := s.Emit(event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Emit grava o evento sem deduplicação e o encaminha aos canais de notificação
+func (s *AlertService) Emit(event *models.AlertEvent) error {
+	if err := s.repo.InsertEvent(event); err != nil {
+		return err
+	}
+	log.Printf("🚨 [%s] %s", event.Severity, event.Title)
+
+	if s.notifications != nil {
+		s.notifications.Submit(*event)
+	}
+	return nil
+}
+
+// ListEvents retorna os eventos de alerta gravados no período
+func (s *AlertService) ListEvents(q models.AlertEventQuery) (*models.AnalyticsResponse, error) {
+	if q.To.Before(q.From) {
+		return nil, fmt.Errorf("%w: 'from' deve ser anterior a 'to'", ErrInvalidAnalyticsQuery)
+	}
+	if q.Limit <= 0 || q.Limit > MaxAlertEvents {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxAlertEvents)
+	}
+
+	events, err := s.repo.ListEvents(q)
+	if err != nil {
+		return nil, collectorError("listar eventos de alerta", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Eventos de alerta obtidos com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"events": events,
+			"total":  len(events),
+		},
+	}, nil
+}
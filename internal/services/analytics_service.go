@@ -1,29 +1,109 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"time"
+
+	"github.com/lib/pq"
 	"pganalytics-backend/internal/models"
 	"pganalytics-backend/internal/repositories"
 )
 
+// ErrInvalidAnalyticsQuery indica parâmetros inválidos em uma consulta de analytics
+var ErrInvalidAnalyticsQuery = errors.New("consulta de analytics inválida")
+
+// ErrAnalyticsUnavailable indica que o banco monitorado está inacessível ou sem conexões disponíveis
+var ErrAnalyticsUnavailable = errors.New("banco monitorado indisponível")
+
+// ErrAnalyticsDependency indica que a coleta depende de algo ausente ou com falha no banco monitorado
+// (extensão não instalada, versão não suportada, permissão, consulta com erro)
+var ErrAnalyticsDependency = errors.New("falha de dependência no banco monitorado")
+
 // AnalyticsService gerencia operações de analytics
 type AnalyticsService struct {
-	repo *repositories.AnalyticsRepository
+	repo      *repositories.AnalyticsRepository
+	targets   *TargetService
+	plans     *repositories.PlanRepository
+	collector *SnapshotService
+	demo      bool
+}
+
+// NewAnalyticsService cria um novo serviço de analytics.
+// repo consulta o banco local; targets (opcional) resolve o parâmetro target para outras instâncias.
+func NewAnalyticsService(repo *repositories.AnalyticsRepository, targets *TargetService) *AnalyticsService {
+	return &AnalyticsService{
+		repo:    repo,
+		targets: targets,
+	}
+}
+
+// SetDemoMode habilita dados fictícios nas estatísticas básicas do banco local.
+// Targets monitorados sempre retornam dados reais.
+func (s *AnalyticsService) SetDemoMode(enabled bool) {
+	s.demo = enabled
 }
 
-// NewAnalyticsService cria um novo serviço de analytics
-func NewAnalyticsService(repo *repositories.AnalyticsRepository) *AnalyticsService {
-	return &AnalyticsService{repo: repo}
+// SetPlanStore define onde os planos capturados sob demanda são gravados (banco local da API)
+func (s *AnalyticsService) SetPlanStore(plans *repositories.PlanRepository) {
+	s.plans = plans
+}
+
+// SetLocalCollector define o gravador de snapshots do banco local, de onde vêm as taxas de
+// performance e de checkpoint.
+// Os coletores dos targets são obtidos do TargetService.
+func (s *AnalyticsService) SetLocalCollector(collector *SnapshotService) {
+	s.collector = collector
+}
+
+// sourceFor retorna a origem das estatísticas básicas e o data_source correspondente
+func (s *AnalyticsService) sourceFor(target string) (analyticsSource, string, error) {
+	if s.demo && target == "" {
+		return demoSource{}, models.DataSourceDemo, nil
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, "", err
+	}
+	return repo, models.DataSourceLive, nil
+}
+
+// repoFor retorna o repositório da instância indicada; target vazio é o banco local
+func (s *AnalyticsService) repoFor(target string) (*repositories.AnalyticsRepository, error) {
+	if target == "" {
+		return s.repo, nil
+	}
+	if s.targets == nil {
+		return nil, repositories.ErrTargetNotFound
+	}
+
+	db, t, err := s.targets.Connection(target)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repositories.NewAnalyticsRepository(db)
+	s.targets.AttachCatalog(t, repo)
+	return repo, nil
 }
 
 // GetSlowQueries retorna as queries mais lentas
-func (s *AnalyticsService) GetSlowQueries() *models.AnalyticsResponse {
-	queries, err := s.repo.GetSlowQueries()
+func (s *AnalyticsService) GetSlowQueries(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
 	if err != nil {
-		log.Printf("Erro ao obter queries lentas: %v", err)
-		return createErrorResponse("Erro ao obter queries lentas")
+		return nil, err
+	}
+
+	queries, err := source.GetSlowQueries()
+	if err != nil {
+		return nil, collectorError("obter queries lentas", err)
 	}
 
 	return &models.AnalyticsResponse{
@@ -31,20 +111,26 @@ func (s *AnalyticsService) GetSlowQueries() *models.AnalyticsResponse {
 		Message:     "Queries lentas obtidas com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":       target,
 			"queries":      queries,
 			"total":        len(queries),
 			"last_updated": time.Now().Format(time.RFC3339),
 		},
-	}
+	}, nil
 }
 
 // GetTableStats retorna estatísticas das tabelas
-func (s *AnalyticsService) GetTableStats() *models.AnalyticsResponse {
-	stats, err := s.repo.GetTableStats()
+func (s *AnalyticsService) GetTableStats(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
 	if err != nil {
-		log.Printf("Erro ao obter estatísticas das tabelas: %v", err)
-		return createErrorResponse("Erro ao obter estatísticas das tabelas")
+		return nil, err
+	}
+
+	stats, err := source.GetTableStats()
+	if err != nil {
+		return nil, collectorError("obter estatísticas das tabelas", err)
 	}
 
 	return &models.AnalyticsResponse{
@@ -52,20 +138,26 @@ func (s *AnalyticsService) GetTableStats() *models.AnalyticsResponse {
 		Message:     "Estatísticas das tabelas obtidas com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":       target,
 			"tables":       stats,
 			"total":        len(stats),
 			"last_updated": time.Now().Format(time.RFC3339),
 		},
-	}
+	}, nil
 }
 
 // GetConnectionStats retorna estatísticas de conexões
-func (s *AnalyticsService) GetConnectionStats() *models.AnalyticsResponse {
-	stats, err := s.repo.GetConnectionStats()
+func (s *AnalyticsService) GetConnectionStats(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
 	if err != nil {
-		log.Printf("Erro ao obter estatísticas de conexões: %v", err)
-		return createErrorResponse("Erro ao obter estatísticas de conexões")
+		return nil, err
+	}
+
+	stats, err := source.GetConnectionStats()
+	if err != nil {
+		return nil, collectorError("obter estatísticas de conexões", err)
 	}
 
 	// Calcular percentual de conexões
@@ -78,19 +170,25 @@ func (s *AnalyticsService) GetConnectionStats() *models.AnalyticsResponse {
 		Message:     "Estatísticas de conexões obtidas com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":       target,
 			"connections":  stats,
 			"last_updated": time.Now().Format(time.RFC3339),
 		},
-	}
+	}, nil
 }
 
 // GetDatabaseSize retorna o tamanho do banco de dados
-func (s *AnalyticsService) GetDatabaseSize() *models.AnalyticsResponse {
-	size, err := s.repo.GetDatabaseSize()
+func (s *AnalyticsService) GetDatabaseSize(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
 	if err != nil {
-		log.Printf("Erro ao obter tamanho do banco: %v", err)
-		return createErrorResponse("Erro ao obter tamanho do banco")
+		return nil, err
+	}
+
+	size, err := source.GetDatabaseSize()
+	if err != nil {
+		return nil, collectorError("obter tamanho do banco", err)
 	}
 
 	return &models.AnalyticsResponse{
@@ -98,19 +196,25 @@ func (s *AnalyticsService) GetDatabaseSize() *models.AnalyticsResponse {
 		Message:     "Tamanho do banco obtido com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":       target,
 			"database":     size,
 			"last_updated": time.Now().Format(time.RFC3339),
 		},
-	}
+	}, nil
 }
 
 // GetPerformanceStats retorna estatísticas de performance
-func (s *AnalyticsService) GetPerformanceStats() *models.AnalyticsResponse {
-	stats, err := s.repo.GetPerformanceStats()
+func (s *AnalyticsService) GetPerformanceStats(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
 	if err != nil {
-		log.Printf("Erro ao obter estatísticas de performance: %v", err)
-		return createErrorResponse("Erro ao obter estatísticas de performance")
+		return nil, err
+	}
+
+	stats, err := source.GetPerformanceStats()
+	if err != nil {
+		return nil, collectorError("obter estatísticas de performance", err)
 	}
 
 	return &models.AnalyticsResponse{
@@ -118,41 +222,129 @@ func (s *AnalyticsService) GetPerformanceStats() *models.AnalyticsResponse {
 		Message:     "Estatísticas de performance obtidas com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":       target,
 			"performance":  stats,
+			"rates":        s.performanceRates(target),
 			"last_updated": time.Now().Format(time.RFC3339),
 		},
+	}, nil
+}
+
+// performanceRates lê as taxas do coletor de snapshots, como checkpointRates. Os dados fictícios
+// do modo demo não têm taxas.
+func (s *AnalyticsService) performanceRates(target string) *models.PerformanceRates {
+	if target == "" {
+		if s.demo || s.collector == nil {
+			return nil
+		}
+		return s.collector.PerformanceRates()
+	}
+	if s.targets == nil {
+		return nil
 	}
+	return s.targets.PerformanceRates(target)
 }
 
-// GetFullAnalytics retorna todas as estatísticas
-func (s *AnalyticsService) GetFullAnalytics() *models.AnalyticsResponse {
+// GetFullAnalytics retorna todas as estatísticas.
+// Seções que falharem são informadas em "errors"; se todas falharem o erro é retornado.
+func (s *AnalyticsService) GetFullAnalytics(target string) (*models.AnalyticsResponse, error) {
+	source, dataSource, err := s.sourceFor(target)
+	if err != nil {
+		return nil, err
+	}
+
 	// Obter todas as estatísticas
-	slowQueries, _ := s.repo.GetSlowQueries()
-	tableStats, _ := s.repo.GetTableStats()
-	connectionStats, _ := s.repo.GetConnectionStats()
-	databaseSize, _ := s.repo.GetDatabaseSize()
-	performanceStats, _ := s.repo.GetPerformanceStats()
+	failures := map[string]string{}
+	var firstErr error
+	record := func(section, action string, err error) {
+		if err == nil {
+			return
+		}
+		err = collectorError(action, err)
+		failures[section] = err.Error()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	slowQueries, err := source.GetSlowQueries()
+	record("slow_queries", "obter queries lentas", err)
+	tableStats, err := source.GetTableStats()
+	record("tables", "obter estatísticas das tabelas", err)
+	connectionStats, err := source.GetConnectionStats()
+	record("connections", "obter estatísticas de conexões", err)
+	databaseSize, err := source.GetDatabaseSize()
+	record("database_size", "obter tamanho do banco", err)
+	performanceStats, err := source.GetPerformanceStats()
+	record("performance_stats", "obter estatísticas de performance", err)
+
+	if len(failures) == 5 {
+		return nil, firstErr
+	}
 
 	// Calcular percentual de conexões
 	if connectionStats != nil && connectionStats.MaxConnections > 0 {
 		connectionStats.ConnectionsPercent = float64(connectionStats.TotalConnections) / float64(connectionStats.MaxConnections) * 100
 	}
 
+	var performanceRates *models.PerformanceRates
+	if performanceStats != nil {
+		performanceRates = s.performanceRates(target)
+	}
+
 	return &models.AnalyticsResponse{
 		Success:     true,
 		Message:     "Estatísticas completas obtidas com sucesso",
 		Timestamp:   time.Now().Unix(),
 		Environment: getEnvironment(),
+		DataSource:  dataSource,
 		Data: map[string]interface{}{
+			"target":            target,
 			"slow_queries":      slowQueries,
 			"tables":            tableStats,
 			"connections":       connectionStats,
 			"database_size":     databaseSize,
 			"performance_stats": performanceStats,
+			"performance_rates": performanceRates,
+			"errors":            failures,
 			"last_updated":      time.Now().Format(time.RFC3339),
 		},
+	}, nil
+}
+
+// collectorError registra a falha de um coletor e a classifica para o handler:
+// ErrAnalyticsUnavailable quando o banco está inacessível, ErrAnalyticsDependency nos demais casos
+func collectorError(action string, err error) error {
+	log.Printf("Erro ao %s: %v", action, err)
+	if isUnavailable(err) {
+		return fmt.Errorf("%w: falha ao %s: %w", ErrAnalyticsUnavailable, action, err)
+	}
+	return fmt.Errorf("%w: falha ao %s: %w", ErrAnalyticsDependency, action, err)
+}
+
+// isUnavailable indica falhas de conexão: sem banco, conexão perdida, timeout de rede ou
+// erros do servidor das classes 08 (conexão), 53 (recursos) e 57 (intervenção do operador)
+func isUnavailable(err error) bool {
+	if errors.Is(err, repositories.ErrNoDatabase) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return true
+		}
 	}
+	return false
 }
 
 // Função helper para criar resposta de erro
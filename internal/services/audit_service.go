@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// MaxAuditPageSize limita a quantidade de registros retornados por consulta de auditoria
+const MaxAuditPageSize = 1000
+
+// ErrInvalidAuditQuery indica filtros inválidos em uma consulta de auditoria
+var ErrInvalidAuditQuery = errors.New("consulta de auditoria inválida")
+
+// auditIgnoredFields não entram nos diffs por mudarem a cada gravação
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// AuditService grava e consulta audit_logs
+type AuditService struct {
+	repo *repositories.AuditRepository
+}
+
+// NewAuditService cria um novo serviço de auditoria
+func NewAuditService(repo *repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record grava um registro de auditoria. Falhas são apenas logadas para não
+// interromper a operação auditada.
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if err := s.repo.Create(entry); err != nil {
+		log.Printf("⚠️ Erro ao gravar auditoria %s: %v", entry.Action, err)
+	}
+}
+
+// RecordChange grava uma mudança em um recurso com o diff entre before e after.
+// before nil representa criação; after nil representa remoção.
+func (s *AuditService) RecordChange(actor models.AuditActor, action, resourceType, resourceID string, before, after interface{}) {
+	oldValues, newValues, err := AuditDiff(before, after)
+	if err != nil {
+		log.Printf("⚠️ Erro ao calcular diff de auditoria de %s %s: %v", resourceType, resourceID, err)
+	}
+	s.RecordDiff(actor, action, resourceType, resourceID, oldValues, newValues)
+}
+
+// RecordDiff grava uma mudança com o diff já calculado. Atualizações sem diff não são gravadas.
+func (s *AuditService) RecordDiff(actor models.AuditActor, action, resourceType, resourceID string, oldValues, newValues models.AuditValues) {
+	if action == models.AuditActionUpdate && oldValues == nil && newValues == nil {
+		return
+	}
+
+	s.Record(&models.AuditLog{
+		UserID:       nullableID(actor.UserID),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldValues:    oldValues,
+		NewValues:    newValues,
+		IPAddress:    actor.IPAddress,
+		UserAgent:    actor.UserAgent,
+		SessionID:    actor.SessionID,
+	})
+}
+
+// CountByIP conta as ações de um IP desde since, retornando a mais antiga
+func (s *AuditService) CountByIP(action, ip string, since time.Time) (int, *time.Time, error) {
+	return s.repo.CountByIP(action, ip, since)
+}
+
+// CountByEmail conta as ações registradas para um email desde since, retornando a mais antiga
+func (s *AuditService) CountByEmail(action, email string, since time.Time) (int, *time.Time, error) {
+	return s.repo.CountByEmail(action, email, since)
+}
+
+// List retorna uma página de registros filtrados.
+// Erros de validação são retornados como ErrInvalidAuditQuery.
+func (s *AuditService) List(q models.AuditQuery) (*models.AuditPage, error) {
+	if q.To.Before(q.From) {
+		return nil, fmt.Errorf("%w: 'from' deve ser anterior a 'to'", ErrInvalidAuditQuery)
+	}
+	if q.Limit <= 0 || q.Limit > MaxAuditPageSize {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAuditQuery, MaxAuditPageSize)
+	}
+	if q.Offset < 0 {
+		return nil, fmt.Errorf("%w: 'offset' não pode ser negativo", ErrInvalidAuditQuery)
+	}
+
+	entries, total, err := s.repo.List(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditPage{
+		Entries: entries,
+		Total:   total,
+		Limit:   q.Limit,
+		Offset:  q.Offset,
+		Query:   q,
+	}, nil
+}
+
+// AuditDiff compara a representação JSON de before e after e retorna apenas os campos
+// que mudaram. Com before nil, todo after é retornado em newValues (e vice-versa).
+func AuditDiff(before, after interface{}) (oldValues, newValues models.AuditValues, err error) {
+	oldMap, err := toAuditValues(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	newMap, err := toAuditValues(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if oldMap == nil || newMap == nil {
+		return oldMap, newMap, nil
+	}
+
+	for key, oldValue := range oldMap {
+		if newValue, ok := newMap[key]; ok && reflect.DeepEqual(oldValue, newValue) {
+			delete(oldMap, key)
+			delete(newMap, key)
+		}
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			oldMap[key] = nil
+		}
+	}
+
+	if len(newMap) == 0 && len(oldMap) == 0 {
+		return nil, nil, nil
+	}
+	return oldMap, newMap, nil
+}
+
+// toAuditValues converte um valor para o mapa gravado em JSONB, sem os campos ignorados
+func toAuditValues(v interface{}) (models.AuditValues, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	values := models.AuditValues{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for key := range auditIgnoredFields {
+		delete(values, key)
+	}
+	return values, nil
+}
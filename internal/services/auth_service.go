@@ -0,0 +1,298 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// ErrInvalidCredentials indica email ou senha incorretos
+var ErrInvalidCredentials = errors.New("credenciais inválidas")
+
+// ErrAccountDisabled indica usuário desativado por um administrador
+var ErrAccountDisabled = errors.New("conta desativada")
+
+// ErrInvalidRefreshToken indica refresh token inexistente, expirado ou revogado
+var ErrInvalidRefreshToken = errors.New("refresh token inválido")
+
+// ErrInvalidSession indica access token de um usuário removido ou desativado depois da emissão
+var ErrInvalidSession = errors.New("usuário inexistente ou desativado")
+
+// ErrRefreshTokenReused indica que um refresh token já rotacionado foi apresentado novamente.
+// Toda a família do token é revogada quando isso acontece.
+var ErrRefreshTokenReused = errors.New("refresh token reutilizado, sessão revogada")
+
+// defaultRefreshTokenDays é usado quando auth.jwt_refresh_token_days não está configurado
+const defaultRefreshTokenDays = 7
+
+// AuthService emite access tokens JWT de curta duração e refresh tokens rotativos
+type AuthService struct {
+	users     *repositories.UserRepository
+	tokens    *repositories.RefreshTokenRepository
+	audit     *AuditService
+	config    *repositories.ConfigRepository
+	jwtSecret []byte
+	accessTTL time.Duration
+}
+
+// NewAuthService cria um novo serviço de autenticação.
+// A validade do refresh token e os limites de bloqueio são lidos de system_config a cada uso.
+func NewAuthService(users *repositories.UserRepository, tokens *repositories.RefreshTokenRepository, audit *AuditService, config *repositories.ConfigRepository, jwtSecret string, accessTTL time.Duration) *AuthService {
+	return &AuthService{
+		users:     users,
+		tokens:    tokens,
+		audit:     audit,
+		config:    config,
+		jwtSecret: []byte(jwtSecret),
+		accessTTL: accessTTL,
+	}
+}
+
+// Login valida as credenciais e inicia uma nova família de refresh tokens.
+// Falhas são contadas por conta e por IP; toda tentativa é gravada em audit_logs.
+func (s *AuthService) Login(email, password string, client models.ClientInfo) (*models.LoginResponse, error) {
+	policy := s.lockoutPolicy()
+	if err := s.checkIPThrottle(policy, client); err != nil {
+		// Gravada com ação própria: recusas não entram na contagem por IP nem estendem a janela
+		s.recordLogin(models.AuditActionLoginThrottled, "", email, "ip_throttled", client)
+		return nil, err
+	}
+
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			// Compara com um hash fixo para que email inexistente leve o mesmo tempo que senha errada
+			bcrypt.CompareHashAndPassword(unknownUserHash(), []byte(password))
+			s.recordLogin(models.AuditActionLoginFailed, "", email, "unknown_user", client)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if until, locked := lockedUntil(user); locked {
+		s.recordLogin(models.AuditActionLoginFailed, user.ID, user.Email, "account_locked", client)
+		return nil, &LockoutError{Err: ErrAccountLocked, Until: until}
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, s.registerFailure(policy, user, client)
+	}
+
+	if !user.IsActive {
+		s.recordLogin(models.AuditActionLoginFailed, user.ID, user.Email, "account_disabled", client)
+		return nil, ErrAccountDisabled
+	}
+
+	if err := s.users.RegisterSuccessfulLogin(user.ID); err != nil {
+		return nil, err
+	}
+
+	response, session, err := s.issue(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordSession(models.AuditActionLogin, user.ID, user.Email, "", session.FamilyID, client)
+	return response, nil
+}
+
+// Refresh troca um refresh token válido por um novo par de tokens.
+// O token apresentado é revogado; reapresentá-lo revoga toda a família.
+func (s *AuthService) Refresh(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error) {
+	current, err := s.tokens.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(current)
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.FindByID(current.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	response, err := s.rotate(user, current, client)
+	if errors.Is(err, repositories.ErrRefreshTokenRevoked) {
+		// Outra requisição rotacionou o mesmo token primeiro
+		return nil, s.revokeReusedFamily(current)
+	}
+	return response, err
+}
+
+// Logout revoga a sessão do refresh token informado, ou todas as sessões do usuário.
+// Tokens revogados ou expirados são recusados, para que um token vazado não encerre sessões ativas.
+func (s *AuthService) Logout(refreshToken string, allSessions bool) error {
+	current, err := s.tokens.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return ErrInvalidRefreshToken
+	}
+
+	if allSessions {
+		_, err = s.tokens.RevokeAllForUser(current.UserID)
+	} else {
+		_, err = s.tokens.RevokeFamily(current.FamilyID)
+	}
+	return err
+}
+
+// CurrentUser carrega o usuário do access token; o JWT não reflete remoções e desativações posteriores
+func (s *AuthService) CurrentUser(userID string) (*models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrInvalidSession
+	}
+	return user, nil
+}
+
+// issue gera o access token e grava o primeiro refresh token de uma nova família (sessão)
+func (s *AuthService) issue(user *models.User, client models.ClientInfo) (*models.LoginResponse, *models.RefreshToken, error) {
+	record, plain, err := s.newRefreshToken(user, "", client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.tokens.Create(record); err != nil {
+		return nil, nil, err
+	}
+
+	response, err := s.response(user, plain, record)
+	return response, record, err
+}
+
+// rotate substitui current por um novo refresh token da mesma família
+func (s *AuthService) rotate(user *models.User, current *models.RefreshToken, client models.ClientInfo) (*models.LoginResponse, error) {
+	record, plain, err := s.newRefreshToken(user, current.FamilyID, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Rotate(current.ID, record); err != nil {
+		return nil, err
+	}
+
+	return s.response(user, plain, record)
+}
+
+func (s *AuthService) newRefreshToken(user *models.User, familyID string, client models.ClientInfo) (*models.RefreshToken, string, error) {
+	plain, err := generateSecureToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	days := s.config.GetInt("auth.jwt_refresh_token_days", defaultRefreshTokenDays)
+	if days <= 0 {
+		days = defaultRefreshTokenDays
+	}
+
+	record := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+		UserAgent: client.UserAgent,
+	}
+	if client.IPAddress != "" {
+		record.IPAddress = &client.IPAddress
+	}
+
+	return record, plain, nil
+}
+
+func (s *AuthService) response(user *models.User, refreshToken string, record *models.RefreshToken) (*models.LoginResponse, error) {
+	accessToken, err := s.generateAccessToken(user, record.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:            accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(s.accessTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(time.Until(record.ExpiresAt).Seconds()),
+		User:             user.Email,
+	}, nil
+}
+
+// generateAccessToken assina o JWT; o jti é a família de refresh tokens, usada como session_id na auditoria
+func (s *AuthService) generateAccessToken(user *models.User, sessionID string) (string, error) {
+	now := time.Now()
+	claims := models.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("falha ao assinar access token: %w", err)
+	}
+	return token, nil
+}
+
+// revokeReusedFamily trata a reapresentação de um token já rotacionado como roubo
+func (s *AuthService) revokeReusedFamily(token *models.RefreshToken) error {
+	revoked, err := s.tokens.RevokeFamily(token.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🚨 Reuso de refresh token detectado para o usuário %s: %d tokens da família %s revogados",
+		token.UserID, revoked, token.FamilyID)
+	return ErrRefreshTokenReused
+}
+
+// generateSecureToken retorna 32 bytes aleatórios em base64 URL-safe
+func generateSecureToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("falha ao gerar token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken retorna o SHA-256 em hexadecimal gravado em refresh_tokens.token_hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// DefaultBloatLimit é a quantidade de objetos retornada quando 'limit' não é informado
+const DefaultBloatLimit = 50
+
+// MaxBloatLimit limita a quantidade de objetos retornados por consulta de bloat
+const MaxBloatLimit = 1000
+
+// bloatMeasuredCandidates é quantos dos maiores candidatos são medidos com pgstattuple.
+// A medição lê as relações, então não é aplicada a todos os objetos.
+const bloatMeasuredCandidates = 20
+
+// GetBloat retorna tabelas e índices btree ordenados por espaço recuperável.
+// objectType filtra por "table" ou "index"; vazio retorna ambos.
+func (s *AnalyticsService) GetBloat(target, objectType string, limit int) (*models.AnalyticsResponse, error) {
+	if objectType != "" && objectType != "table" && objectType != "index" {
+		return nil, fmt.Errorf("%w: 'type' deve ser table ou index", ErrInvalidAnalyticsQuery)
+	}
+	if limit <= 0 || limit > MaxBloatLimit {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxBloatLimit)
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates, err := collectBloat(repo, objectType)
+	if err != nil {
+		return nil, collectorError("estimar bloat", err)
+	}
+
+	installed := false
+	if catalog, err := repo.Catalog(); err != nil {
+		log.Printf("⚠️ %v", err)
+	} else {
+		installed = catalog.Status(models.CollectorBloatMeasure).Supported
+	}
+	if installed {
+		RankBloat(estimates)
+		for i := 0; i < len(estimates) && i < bloatMeasuredCandidates; i++ {
+			if err := repo.MeasureBloat(&estimates[i]); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+		}
+	}
+
+	RankBloat(estimates)
+
+	var totalWasted int64
+	for _, e := range estimates {
+		totalWasted += e.WastedBytes
+	}
+	if len(estimates) > limit {
+		estimates = estimates[:limit]
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Estimativa de bloat obtida com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":             target,
+			"objects":            estimates,
+			"total":              len(estimates),
+			"total_wasted_bytes": totalWasted,
+			"pgstattuple":        installed,
+			"last_updated":       time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// collectBloat reúne as estimativas de tabelas e/ou índices
+func collectBloat(repo *repositories.AnalyticsRepository, objectType string) ([]models.BloatEstimate, error) {
+	estimates := []models.BloatEstimate{}
+
+	if objectType != "index" {
+		tables, err := repo.GetTableBloat()
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, tables...)
+	}
+	if objectType != "table" {
+		indexes, err := repo.GetIndexBloat()
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, indexes...)
+	}
+
+	return estimates, nil
+}
+
+// RankBloat ordena os objetos por espaço recuperável, desempatando pelo percentual de bloat
+func RankBloat(estimates []models.BloatEstimate) {
+	sort.SliceStable(estimates, func(i, j int) bool {
+		if estimates[i].WastedBytes != estimates[j].WastedBytes {
+			return estimates[i].WastedBytes > estimates[j].WastedBytes
+		}
+		return estimates[i].BloatPercent > estimates[j].BloatPercent
+	})
+}
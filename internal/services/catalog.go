@@ -0,0 +1,33 @@
+package services
+
+import (
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// GetCapabilities retorna a versão do servidor, as extensões instaladas e o estado de cada coletor
+func (s *AnalyticsService) GetCapabilities(target string) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := repo.Catalog()
+	if err != nil {
+		return nil, collectorError("detectar capacidades do servidor", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Capacidades do servidor obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":       target,
+			"server":       catalog.Capabilities(),
+			"collectors":   catalog.Collectors(),
+			"last_updated": time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
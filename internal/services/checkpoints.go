@@ -0,0 +1,163 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// CheckpointTracker guarda a amostra anterior de CheckpointStats e calcula taxas entre coletas
+type CheckpointTracker struct {
+	mu     sync.Mutex
+	prev   *models.CheckpointStats
+	latest *models.CheckpointRates
+}
+
+// NewCheckpointTracker cria um novo calculador de taxas de checkpoint e WAL
+func NewCheckpointTracker() *CheckpointTracker {
+	return &CheckpointTracker{}
+}
+
+// Observe registra uma nova amostra e retorna as taxas em relação à anterior.
+// Retorna nil na primeira amostra ou quando não é possível determinar o intervalo.
+func (t *CheckpointTracker) Observe(curr *models.CheckpointStats) *models.CheckpointRates {
+	if curr == nil {
+		return nil
+	}
+
+	sample := *curr
+	if sample.CollectedAt.IsZero() {
+		sample.CollectedAt = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.prev
+	t.prev = &sample
+	if prev == nil {
+		return nil
+	}
+
+	t.latest = ComputeCheckpointRates(prev, &sample)
+	return t.latest
+}
+
+// Latest retorna as taxas da última observação, sem registrar uma nova amostra
+func (t *CheckpointTracker) Latest() *models.CheckpointRates {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.latest
+}
+
+// ComputeCheckpointRates calcula taxas e deltas entre duas amostras.
+// Quando algum contador diminui (reset ou restart) apenas CounterReset é marcado.
+func ComputeCheckpointRates(prev, curr *models.CheckpointStats) *models.CheckpointRates {
+	interval := curr.CollectedAt.Sub(prev.CollectedAt).Seconds()
+	if interval <= 0 {
+		return nil
+	}
+	if checkpointCountersDecreased(prev, curr) {
+		return &models.CheckpointRates{IntervalSeconds: interval, CounterReset: true}
+	}
+
+	perSecond := func(c, p int64) float64 {
+		return float64(c-p) / interval
+	}
+	optionalPerSecond := func(c, p *int64) *float64 {
+		if c == nil || p == nil {
+			return nil
+		}
+		v := perSecond(*c, *p)
+		return &v
+	}
+
+	timed := curr.CheckpointsTimed - prev.CheckpointsTimed
+	requested := curr.CheckpointsReq - prev.CheckpointsReq
+
+	rates := &models.CheckpointRates{
+		IntervalSeconds:            interval,
+		WALBytesPerSecond:          perSecond(curr.WALBytes, prev.WALBytes),
+		WALRecordsPerSecond:        optionalPerSecond(curr.WALRecords, prev.WALRecords),
+		WALFPIPerSecond:            optionalPerSecond(curr.WALFPI, prev.WALFPI),
+		CheckpointsPerHour:         float64(timed+requested) / interval * 3600,
+		BuffersCheckpointPerSecond: perSecond(curr.BuffersCheckpoint, prev.BuffersCheckpoint),
+		BuffersCleanPerSecond:      perSecond(curr.BuffersClean, prev.BuffersClean),
+		BuffersBackendPerSecond:    optionalPerSecond(curr.BuffersBackend, prev.BuffersBackend),
+		BackendFsyncsDelta:         curr.BackendFsyncs - prev.BackendFsyncs,
+		MaxwrittenCleanDelta:       curr.MaxwrittenClean - prev.MaxwrittenClean,
+	}
+	if timed+requested > 0 {
+		rates.RequestedPercent = float64(requested) / float64(timed+requested) * 100
+	}
+	if curr.WALRecords != nil && prev.WALRecords != nil && curr.WALFPI != nil && prev.WALFPI != nil {
+		if records := *curr.WALRecords - *prev.WALRecords; records > 0 {
+			fpi := float64(*curr.WALFPI-*prev.WALFPI) / float64(records) * 100
+			rates.FPIPercent = &fpi
+		}
+	}
+
+	return rates
+}
+
+// RequestedCheckpointPercent retorna o percentual de checkpoints solicitados desde o último reset
+func RequestedCheckpointPercent(stats *models.CheckpointStats) float64 {
+	total := stats.CheckpointsTimed + stats.CheckpointsReq
+	if total == 0 {
+		return 0
+	}
+	return roundPercent(float64(stats.CheckpointsReq) / float64(total) * 100)
+}
+
+func checkpointCountersDecreased(prev, curr *models.CheckpointStats) bool {
+	return curr.CheckpointsTimed < prev.CheckpointsTimed ||
+		curr.CheckpointsReq < prev.CheckpointsReq ||
+		curr.BuffersCheckpoint < prev.BuffersCheckpoint ||
+		curr.BuffersClean < prev.BuffersClean ||
+		curr.BackendFsyncs < prev.BackendFsyncs ||
+		curr.WALBytes < prev.WALBytes
+}
+
+// GetCheckpointStats retorna os contadores de checkpoints, bgwriter e WAL e as últimas taxas
+// calculadas pelo coletor de snapshots do target (nil até a segunda coleta)
+func (s *AnalyticsService) GetCheckpointStats(target string) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := repo.GetCheckpointStats()
+	if err != nil {
+		return nil, collectorError("obter estatísticas de checkpoint e WAL", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Estatísticas de checkpoint e WAL obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":            target,
+			"checkpoints":       stats,
+			"requested_percent": RequestedCheckpointPercent(stats),
+			"rates":             s.checkpointRates(target),
+			"last_updated":      time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// checkpointRates lê as taxas do coletor de snapshots. A API não registra amostras: o intervalo
+// entre requisições é arbitrário e cada GET alteraria a base usada pelo coletor.
+func (s *AnalyticsService) checkpointRates(target string) *models.CheckpointRates {
+	if target == "" {
+		if s.collector == nil {
+			return nil
+		}
+		return s.collector.CheckpointRates()
+	}
+	if s.targets == nil {
+		return nil
+	}
+	return s.targets.CheckpointRates(target)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// ErrInvalidConfigValue indica valor incompatível com o config_type da chave
+var ErrInvalidConfigValue = errors.New("valor de configuração inválido")
+
+// redactedValue substitui valores sensíveis nas respostas e na auditoria
+const redactedValue = "********"
+
+// ConfigService expõe system_config para leitura e alteração auditada
+type ConfigService struct {
+	repo  *repositories.ConfigRepository
+	audit *AuditService
+}
+
+// NewConfigService cria um novo serviço de configuração
+func NewConfigService(repo *repositories.ConfigRepository, audit *AuditService) *ConfigService {
+	return &ConfigService{repo: repo, audit: audit}
+}
+
+// List retorna todas as chaves, com valores sensíveis mascarados
+func (s *ConfigService) List() ([]models.ConfigEntry, error) {
+	entries, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		redactConfig(&entries[i])
+	}
+	return entries, nil
+}
+
+// Update valida o valor conforme o tipo da chave, grava e audita a mudança
+func (s *ConfigService) Update(key, value string, actor models.AuditActor) (*models.ConfigEntry, error) {
+	before, err := s.repo.Find(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfigValue(before.Type, value); err != nil {
+		return nil, err
+	}
+
+	after, err := s.repo.Set(key, value, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUpdate(actor, before, after)
+	redactConfig(after)
+	return after, nil
+}
+
+// recordUpdate audita a alteração; o diff é calculado antes de mascarar valores sensíveis
+// para que a mudança fique registrada sem expor o valor
+func (s *ConfigService) recordUpdate(actor models.AuditActor, before, after *models.ConfigEntry) {
+	oldValues, newValues, err := AuditDiff(before, after)
+	if err != nil {
+		log.Printf("⚠️ Erro ao calcular diff de auditoria da configuração %s: %v", before.Key, err)
+	}
+	if before.IsSensitive {
+		for _, values := range []models.AuditValues{oldValues, newValues} {
+			if _, ok := values["value"]; ok {
+				values["value"] = redactedValue
+			}
+		}
+	}
+
+	s.audit.RecordDiff(actor, models.AuditActionUpdate, "config", before.Key, oldValues, newValues)
+}
+
+// validateConfigValue verifica se value é compatível com config_type
+func validateConfigValue(configType, value string) error {
+	var err error
+	switch configType {
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	case "json":
+		if !json.Valid([]byte(value)) {
+			err = errors.New("JSON inválido")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: esperado %s: %v", ErrInvalidConfigValue, configType, err)
+	}
+	return nil
+}
+
+func redactConfig(entry *models.ConfigEntry) {
+	if entry.IsSensitive {
+		entry.Value = redactedValue
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// analyticsSource fornece as estatísticas básicas de analytics: o repositório do banco
+// monitorado ou os dados fictícios do modo demonstração
+type analyticsSource interface {
+	GetSlowQueries() ([]models.SlowQuery, error)
+	GetTableStats() ([]models.TableStat, error)
+	GetConnectionStats() (*models.ConnectionStats, error)
+	GetDatabaseSize() (*models.DatabaseSize, error)
+	GetPerformanceStats() (*models.PerformanceStats, error)
+}
+
+// demoSource retorna dados fictícios; usado apenas com o modo demonstração habilitado
+type demoSource struct{}
+
+func (demoSource) GetSlowQueries() ([]models.SlowQuery, error) { return demoSlowQueries(), nil }
+
+func (demoSource) GetTableStats() ([]models.TableStat, error) { return demoTableStats(), nil }
+
+func (demoSource) GetConnectionStats() (*models.ConnectionStats, error) {
+	return demoConnectionStats(), nil
+}
+
+func (demoSource) GetDatabaseSize() (*models.DatabaseSize, error) { return demoDatabaseSize(), nil }
+
+func (demoSource) GetPerformanceStats() (*models.PerformanceStats, error) {
+	stats := demoPerformanceStats()
+	stats.CollectedAt = time.Now()
+	return stats, nil
+}
+
+// demoSlowQueries retorna queries lentas fictícias
+func demoSlowQueries() []models.SlowQuery {
+	return []models.SlowQuery{
+		{
+			QueryText:  "SELECT * FROM users WHERE email LIKE '%@example.com'",
+			DurationMs: 1250.75,
+			Calls:      432,
+			Rows:       1250,
+		},
+		{
+			QueryText:  "SELECT COUNT(*) FROM logs WHERE created_at > NOW() - INTERVAL '1 day'",
+			DurationMs: 876.32,
+			Calls:      125,
+			Rows:       1,
+		},
+		{
+			QueryText:  "SELECT logs.*, users.email FROM logs JOIN users ON logs.user_id = users.id WHERE logs.level = 'error'",
+			DurationMs: 754.28,
+			Calls:      89,
+			Rows:       352,
+		},
+		{
+			QueryText:  "UPDATE users SET last_login = NOW() WHERE id = ?",
+			DurationMs: 532.51,
+			Calls:      2451,
+			Rows:       2451,
+		},
+		{
+			QueryText:  "SELECT AVG(value) FROM metrics WHERE collected_at BETWEEN ? AND ? GROUP BY metric_name",
+			DurationMs: 498.12,
+			Calls:      78,
+			Rows:       24,
+		},
+	}
+}
+
+// demoTableStats retorna estatísticas de tabelas fictícias
+func demoTableStats() []models.TableStat {
+	return []models.TableStat{
+		{
+			TableName:  "public.users",
+			RowCount:   15000,
+			SizePretty: "32 MB",
+			SizeMB:     32.5,
+			IndexRatio: 28.4,
+		},
+		{
+			TableName:  "public.logs",
+			RowCount:   1250000,
+			SizePretty: "4.2 GB",
+			SizeMB:     4300.8,
+			IndexRatio: 35.2,
+		},
+		{
+			TableName:  "public.sessions",
+			RowCount:   85000,
+			SizePretty: "128 MB",
+			SizeMB:     128.4,
+			IndexRatio: 18.7,
+		},
+		{
+			TableName:  "public.metrics",
+			RowCount:   3500000,
+			SizePretty: "7.5 GB",
+			SizeMB:     7680.0,
+			IndexRatio: 42.1,
+		},
+		{
+			TableName:  "public.settings",
+			RowCount:   350,
+			SizePretty: "2 MB",
+			SizeMB:     2.1,
+			IndexRatio: 12.5,
+		},
+	}
+}
+
+// demoConnectionStats retorna estatísticas de conexões fictícias
+func demoConnectionStats() *models.ConnectionStats {
+	return &models.ConnectionStats{
+		TotalConnections:   18,
+		ActiveConnections:  8,
+		IdleConnections:    9,
+		IdleInTransaction:  1,
+		MaxConnections:     100,
+		ConnectionsPercent: 18.0,
+	}
+}
+
+// demoDatabaseSize retorna tamanho do banco fictício
+func demoDatabaseSize() *models.DatabaseSize {
+	return &models.DatabaseSize{
+		DatabaseName: "postgres",
+		SizePretty:   "12.5 GB",
+		SizeMB:       12800.0,
+	}
+}
+
+// demoPerformanceStats retorna estatísticas de performance fictícias
+func demoPerformanceStats() *models.PerformanceStats {
+	return &models.PerformanceStats{
+		CacheHitRatio:  98.45,
+		TuplesReturned: 15250000,
+		TuplesFetched:  4520000,
+		TuplesInserted: 285000,
+		TuplesUpdated:  142500,
+		TuplesDeleted:  28500,
+		Conflicts:      12,
+		TempFiles:      45,
+		Deadlocks:      0,
+	}
+}
@@ -0,0 +1,485 @@
+package services
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/plananalyzer"
+	"pganalytics-backend/internal/repositories"
+)
+
+const (
+	// DefaultExplainTimeoutMs é o statement_timeout padrão da captura de planos
+	DefaultExplainTimeoutMs = 10000
+	// MaxExplainTimeoutMs é o maior statement_timeout aceito na captura de planos
+	MaxExplainTimeoutMs = 300000
+	// DefaultPlanLimit é a quantidade de planos retornada quando 'limit' não é informado
+	DefaultPlanLimit = 20
+	// MaxPlanLimit é a maior quantidade de planos retornada por consulta
+	MaxPlanLimit = 200
+	// MaxPlansPerMinute é a maior quantidade de planos capturados por minuto em cada target
+	MaxPlansPerMinute = 30
+)
+
+// ErrTooManyPlans indica que o target atingiu MaxPlansPerMinute capturas no último minuto
+var ErrTooManyPlans = errors.New("limite de capturas de plano atingido, tente novamente em instantes")
+
+// queryParameterPattern encontra parâmetros posicionais ($1, $2...) como os de pg_stat_statements
+var queryParameterPattern = regexp.MustCompile(`\$[0-9]+`)
+
+// readOnlyStatementKeywords são os comandos aceitos com ANALYZE
+var readOnlyStatementKeywords = map[string]bool{"select": true, "with": true, "values": true, "table": true}
+
+// Explain captura o plano de uma query no target e o grava em slow_queries_log.
+// O texto vem de pg_stat_statements quando queryid é informado. Textos com parâmetros
+// usam o plano genérico; ANALYZE só é aceito para SELECT e sem parâmetros.
+// Cada target aceita no máximo MaxPlansPerMinute capturas por minuto.
+func (s *AnalyticsService) Explain(target string, req models.ExplainRequest) (*models.AnalyticsResponse, error) {
+	if (req.QueryID == nil) == (req.Query == "") {
+		return nil, fmt.Errorf("%w: informe 'queryid' ou 'query'", ErrInvalidAnalyticsQuery)
+	}
+	opts := models.ExplainOptions{Analyze: req.Analyze, Buffers: req.Buffers, GenericPlan: req.GenericPlan, TimeoutMs: req.TimeoutMs}
+	if opts.TimeoutMs == 0 {
+		opts.TimeoutMs = DefaultExplainTimeoutMs
+	}
+	if opts.TimeoutMs < 0 || opts.TimeoutMs > MaxExplainTimeoutMs {
+		return nil, fmt.Errorf("%w: 'timeout_ms' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxExplainTimeoutMs)
+	}
+
+	if s.plans == nil {
+		return nil, collectorError("gravar plano", repositories.ErrNoDatabase)
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	text := req.Query
+	if req.QueryID != nil {
+		if text, err = s.statementText(repo, *req.QueryID); err != nil {
+			return nil, err
+		}
+	}
+
+	text, err = NormalizeExplainQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	if HasQueryParameters(text) {
+		opts.GenericPlan = true
+	}
+	if opts.Analyze && opts.GenericPlan {
+		return nil, fmt.Errorf("%w: ANALYZE não pode ser usado com plano genérico (texto com parâmetros $n)", ErrInvalidAnalyticsQuery)
+	}
+	if opts.Analyze && !IsReadOnlyStatement(text) {
+		return nil, fmt.Errorf("%w: ANALYZE só é permitido para SELECT", ErrInvalidAnalyticsQuery)
+	}
+
+	var targetName string
+	if target != "" {
+		// Grava sempre o nome do target, mesmo quando a requisição usa o ID
+		registered, err := s.targets.Get(target)
+		if err != nil {
+			return nil, err
+		}
+		targetName = registered.Name
+	}
+
+	recent, err := s.plans.CountSince(targetName, time.Now().Add(-time.Minute))
+	if err != nil {
+		return nil, collectorError("gravar plano", err)
+	}
+	if recent >= MaxPlansPerMinute {
+		return nil, ErrTooManyPlans
+	}
+
+	output, err := repo.Explain(text, opts)
+	if err != nil {
+		return nil, explainError(err)
+	}
+
+	sum := md5.Sum([]byte(text))
+	plan := &models.QueryPlan{
+		DatabaseName: output.DatabaseName,
+		Username:     output.Username,
+		QueryID:      req.QueryID,
+		QueryHash:    hex.EncodeToString(sum[:]),
+		QueryText:    text,
+		Analyze:      opts.Analyze,
+		Buffers:      opts.Buffers,
+		GenericPlan:  opts.GenericPlan,
+		Plan:         output.Plan,
+		CreatedAt:    time.Now(),
+	}
+	if targetName != "" {
+		plan.TargetName = &targetName
+	}
+	plan.PlanningTimeMs, plan.ExecutionTimeMs = ExplainTimings(output.Plan)
+
+	if err := s.plans.Insert(plan, opts); err != nil {
+		return nil, collectorError("gravar plano", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Plano capturado com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":  target,
+			"options": opts,
+			"plan":    plan,
+		},
+	}, nil
+}
+
+// GetPlans lista os planos gravados de um target, filtrando por query_hash ou queryid
+func (s *AnalyticsService) GetPlans(q models.QueryPlanQuery) (*models.AnalyticsResponse, error) {
+	if q.Limit <= 0 || q.Limit > MaxPlanLimit {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxPlanLimit)
+	}
+	if s.plans == nil {
+		return nil, collectorError("listar planos", repositories.ErrNoDatabase)
+	}
+
+	plans, err := s.plans.List(q)
+	if err != nil {
+		return nil, collectorError("listar planos", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Planos obtidos com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target": q.Target,
+			"plans":  plans,
+			"total":  len(plans),
+		},
+	}, nil
+}
+
+// GetPlan retorna um plano gravado pelo ID
+func (s *AnalyticsService) GetPlan(id string) (*models.AnalyticsResponse, error) {
+	plan, err := s.storedPlan(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Plano obtido com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"plan": plan,
+		},
+	}, nil
+}
+
+// AnalyzePlan analisa um plano gravado e retorna os achados e a árvore anotada
+func (s *AnalyticsService) AnalyzePlan(id string) (*models.AnalyticsResponse, error) {
+	plan, report, err := s.PlanReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Plano analisado com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"plan_id":    plan.ID,
+			"query_hash": plan.QueryHash,
+			"query_text": plan.QueryText,
+			"analysis":   report,
+		},
+	}, nil
+}
+
+// PlanReport busca um plano gravado e executa o analisador de planos
+func (s *AnalyticsService) PlanReport(id string) (*models.QueryPlan, *plananalyzer.Report, error) {
+	plan, err := s.storedPlan(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report, err := plananalyzer.AnalyzeJSON(plan.Plan, plananalyzer.DefaultOptions())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidAnalyticsQuery, err)
+	}
+	return plan, report, nil
+}
+
+// DiffPlans compara dois planos gravados da mesma query (mesmo query_hash)
+func (s *AnalyticsService) DiffPlans(beforeID, afterID string) (*models.AnalyticsResponse, error) {
+	if beforeID == "" || afterID == "" {
+		return nil, fmt.Errorf("%w: informe 'before' e 'after'", ErrInvalidAnalyticsQuery)
+	}
+
+	before, err := s.storedPlan(beforeID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.storedPlan(afterID)
+	if err != nil {
+		return nil, err
+	}
+	if before.QueryHash != after.QueryHash {
+		return nil, fmt.Errorf("%w: os planos são de queries diferentes (%s, %s)", ErrInvalidAnalyticsQuery, before.QueryHash, after.QueryHash)
+	}
+
+	beforePlan, err := plananalyzer.Parse(before.Plan)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAnalyticsQuery, err)
+	}
+	afterPlan, err := plananalyzer.Parse(after.Plan)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAnalyticsQuery, err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Planos comparados com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"query_hash": before.QueryHash,
+			"query_text": after.QueryText,
+			"before":     planReference(before),
+			"after":      planReference(after),
+			"diff":       plananalyzer.Diff(beforePlan, afterPlan, plananalyzer.DefaultOptions().MisestimateFactor),
+		},
+	}, nil
+}
+
+// planReference identifica um plano gravado sem o JSON do plano
+func planReference(plan *models.QueryPlan) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           plan.ID,
+		"target_name":  plan.TargetName,
+		"analyze":      plan.Analyze,
+		"generic_plan": plan.GenericPlan,
+		"created_at":   plan.CreatedAt,
+	}
+}
+
+// storedPlan busca um plano gravado, mantendo ErrPlanNotFound para o handler
+func (s *AnalyticsService) storedPlan(id string) (*models.QueryPlan, error) {
+	if s.plans == nil {
+		return nil, collectorError("buscar plano", repositories.ErrNoDatabase)
+	}
+
+	plan, err := s.plans.Get(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPlanNotFound) {
+			return nil, err
+		}
+		return nil, collectorError("buscar plano", err)
+	}
+	return plan, nil
+}
+
+// statementText lê o texto completo do queryid no banco ao qual o target está conectado
+func (s *AnalyticsService) statementText(repo *repositories.AnalyticsRepository, queryID int64) (string, error) {
+	entries, err := repo.GetStatement(queryID)
+	if err != nil {
+		return "", collectorError("consultar pg_stat_statements", err)
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("%w: %d", ErrStatementNotFound, queryID)
+	}
+
+	database, err := repo.CurrentDatabase()
+	if err != nil {
+		return "", collectorError("identificar banco atual", err)
+	}
+	for _, e := range entries {
+		if e.DatabaseName == database {
+			return e.Query, nil
+		}
+	}
+	return "", fmt.Errorf("%w: queryid %d não foi executado no banco %s; registre um target conectado a %s",
+		ErrInvalidAnalyticsQuery, queryID, database, entries[0].DatabaseName)
+}
+
+// NormalizeExplainQuery remove espaços e ponto e vírgula finais e rejeita textos vazios ou com
+// mais de um comando. ';' dentro de strings, identificadores entre aspas e comentários é aceito.
+func NormalizeExplainQuery(text string) (string, error) {
+	text = strings.TrimRight(strings.TrimSpace(text), "; \t\r\n")
+	if text == "" {
+		return "", fmt.Errorf("%w: query vazia", ErrInvalidAnalyticsQuery)
+	}
+	if statementSeparator(text) >= 0 {
+		return "", fmt.Errorf("%w: a query deve conter um único comando", ErrInvalidAnalyticsQuery)
+	}
+	return text, nil
+}
+
+// statementSeparator retorna a posição do primeiro ';' fora de strings ('...', E'...', $tag$...$tag$),
+// identificadores entre aspas e comentários, ou -1. Literais não terminados ficam para o
+// PostgreSQL rejeitar.
+func statementSeparator(text string) int {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == ';':
+			return i
+		case c == '\'' || c == '"':
+			backslash := c == '\'' && i > 0 && (text[i-1] == 'E' || text[i-1] == 'e') && !isIdentifierByte(text, i-2)
+			i = closingQuote(text, i, backslash)
+		case c == '-' && strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return -1
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			i = closingComment(text, i)
+		case c == '$' && !isIdentifierByte(text, i-1):
+			if tag := dollarQuoteTag(text[i:]); tag != "" {
+				end := strings.Index(text[i+len(tag):], tag)
+				if end < 0 {
+					return -1
+				}
+				i += len(tag) + end + len(tag) - 1
+			}
+		}
+	}
+	return -1
+}
+
+// closingQuote retorna a posição da aspa que fecha a iniciada em start. Uma aspa duplicada
+// é parte do texto e, em strings E'...', a barra invertida escapa o caractere seguinte.
+func closingQuote(text string, start int, backslash bool) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case backslash && text[i] == '\\':
+			i++
+		case text[i] == quote && i+1 < len(text) && text[i+1] == quote:
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return len(text)
+}
+
+// closingComment retorna a posição do '/' que fecha o comentário iniciado em start,
+// considerando comentários aninhados como o PostgreSQL
+func closingComment(text string, start int) int {
+	depth := 0
+	for i := start; i+1 < len(text); i++ {
+		switch text[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+// dollarQuoteTag retorna o delimitador ($$ ou $tag$) no início de text, ou "" quando o '$'
+// não abre uma string (ex.: parâmetros $1)
+func dollarQuoteTag(text string) string {
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '$':
+			return text[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80,
+			c >= '0' && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentifierByte indica se text[i] pode fazer parte de um identificador ou número
+func isIdentifierByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	c := text[i]
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// HasQueryParameters indica se o texto tem parâmetros posicionais ($1, $2...)
+func HasQueryParameters(text string) bool {
+	return queryParameterPattern.MatchString(text)
+}
+
+// IsReadOnlyStatement indica se o comando começa com SELECT, WITH, VALUES ou TABLE,
+// ignorando comentários e parênteses iniciais. CTEs que modificam dados continuam
+// bloqueadas pela transação somente leitura.
+func IsReadOnlyStatement(text string) bool {
+	for {
+		text = strings.TrimLeft(text, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(text, "--"):
+			end := strings.Index(text, "\n")
+			if end < 0 {
+				return false
+			}
+			text = text[end+1:]
+		case strings.HasPrefix(text, "/*"):
+			end := strings.Index(text, "*/")
+			if end < 0 {
+				return false
+			}
+			text = text[end+2:]
+		default:
+			words := strings.FieldsFunc(text, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			})
+			return len(words) > 0 && readOnlyStatementKeywords[strings.ToLower(words[0])]
+		}
+	}
+}
+
+// ExplainTimings lê Planning Time e Execution Time do resumo de um EXPLAIN (FORMAT JSON)
+func ExplainTimings(plan json.RawMessage) (planning, execution *float64) {
+	var output []struct {
+		PlanningTime  *float64 `json:"Planning Time"`
+		ExecutionTime *float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(plan, &output); err != nil || len(output) == 0 {
+		return nil, nil
+	}
+	return output[0].PlanningTime, output[0].ExecutionTime
+}
+
+// explainError separa erros causados pela query (sintaxe, objeto inexistente, escrita na
+// transação somente leitura, timeout) das falhas de conexão e dependência
+func explainError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "57014":
+			return fmt.Errorf("%w: EXPLAIN cancelado por statement_timeout; aumente 'timeout_ms'", ErrInvalidAnalyticsQuery)
+		case pqErr.Code == "25006":
+			return fmt.Errorf("%w: a query tenta modificar dados e não pode ser executada na transação somente leitura", ErrInvalidAnalyticsQuery)
+		case pqErr.Code.Class() == "42", pqErr.Code.Class() == "22", pqErr.Code.Class() == "0A":
+			return fmt.Errorf("%w: %s", ErrInvalidAnalyticsQuery, pqErr.Message)
+		}
+	}
+	return collectorError("executar EXPLAIN", err)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// MaxHistoryPoints limita a quantidade de buckets retornados por consulta
+const MaxHistoryPoints = 5000
+
+// ErrInvalidHistoryQuery indica parâmetros inválidos em uma consulta de histórico
+var ErrInvalidHistoryQuery = errors.New("consulta de histórico inválida")
+
+// HistoryService gerencia consultas de séries temporais sobre os snapshots gravados
+type HistoryService struct {
+	repo *repositories.HistoryRepository
+}
+
+// NewHistoryService cria um novo serviço de histórico
+func NewHistoryService(repo *repositories.HistoryRepository) *HistoryService {
+	return &HistoryService{repo: repo}
+}
+
+// GetHistory retorna a série temporal agregada de uma métrica.
+// Erros de validação são retornados como ErrInvalidHistoryQuery.
+func (s *HistoryService) GetHistory(q models.HistoryQuery) (*models.AnalyticsResponse, error) {
+	if err := validateHistoryQuery(q); err != nil {
+		return nil, err
+	}
+
+	var (
+		points []models.HistoryPoint
+		err    error
+		source = "system_metrics_log"
+	)
+	if repositories.IsTableMetric(q.Metric) {
+		source = "table_stats_log"
+		points, err = s.repo.GetTableMetricSeries(q)
+	} else {
+		points, err = s.repo.GetSystemMetricSeries(q)
+	}
+	if err != nil {
+		log.Printf("Erro ao obter histórico de %s: %v", q.Metric, err)
+		return createErrorResponse("Erro ao obter histórico"), nil
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Histórico obtido com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"metric": q.Metric,
+			"source": source,
+			"query":  q,
+			"step":   q.Step.String(),
+			"points": points,
+			"total":  len(points),
+		},
+	}, nil
+}
+
+// GetAvailableMetrics lista as métricas que podem ser consultadas no histórico
+func (s *HistoryService) GetAvailableMetrics() *models.AnalyticsResponse {
+	system, err := s.repo.ListSystemMetrics()
+	if err != nil {
+		log.Printf("Erro ao listar métricas de histórico: %v", err)
+		return createErrorResponse("Erro ao listar métricas de histórico")
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Métricas de histórico obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"system_metrics": system,
+			"table_metrics":  repositories.TableMetricNames(),
+		},
+	}
+}
+
+func validateHistoryQuery(q models.HistoryQuery) error {
+	if q.Metric == "" {
+		return fmt.Errorf("%w: métrica obrigatória", ErrInvalidHistoryQuery)
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: 'from' deve ser anterior a 'to'", ErrInvalidHistoryQuery)
+	}
+	if q.Step < time.Second {
+		return fmt.Errorf("%w: 'step' deve ser de pelo menos 1s", ErrInvalidHistoryQuery)
+	}
+	if q.To.Sub(q.From)/q.Step > MaxHistoryPoints {
+		return fmt.Errorf("%w: intervalo gera mais de %d pontos, aumente 'step'", ErrInvalidHistoryQuery, MaxHistoryPoints)
+	}
+	if repositories.IsTableMetric(q.Metric) && q.Table == "" {
+		return fmt.Errorf("%w: métrica %s exige o parâmetro 'table'", ErrInvalidHistoryQuery, q.Metric)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"pganalytics-backend/internal/models"
+)
+
+// btreeEntryBytes é o custo estimado por linha de um índice btree de uma coluna de 8 bytes
+// (cabeçalho da tupla de índice + dado alinhado + line pointer)
+const btreeEntryBytes = 20
+
+// DefaultIndexAdvisorOptions retorna os limites padrão para sugerir novos índices
+func DefaultIndexAdvisorOptions() models.IndexAdvisorOptions {
+	return models.IndexAdvisorOptions{
+		MinTableBytes:     100 * 1024 * 1024,
+		MinSeqScans:       100,
+		MinRowsPerSeqScan: 10000,
+	}
+}
+
+// GetIndexAdvice retorna as recomendações do consultor de índices
+func (s *AnalyticsService) GetIndexAdvice(target string) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := repo.GetIndexDefinitions()
+	if err != nil {
+		return nil, collectorError("obter definições de índices", err)
+	}
+	tables, err := repo.GetTableSnapshots()
+	if err != nil {
+		return nil, collectorError("obter estatísticas das tabelas", err)
+	}
+
+	options := DefaultIndexAdvisorOptions()
+	findings := AdviseIndexes(indexes, tables, options)
+
+	summary := map[string]int{}
+	var reclaimable int64
+	for _, f := range findings {
+		summary[f.Type]++
+		if f.SizeImpactBytes < 0 {
+			reclaimable -= f.SizeImpactBytes
+		}
+	}
+
+	var statsReset *time.Time
+	if len(indexes) > 0 {
+		statsReset = indexes[0].StatsReset
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Recomendações de índices obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":            target,
+			"findings":          findings,
+			"total":             len(findings),
+			"summary":           summary,
+			"reclaimable_bytes": reclaimable,
+			"stats_reset":       statsReset,
+			"options":           options,
+			"last_updated":      time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// AdviseIndexes analisa os índices e tabelas e retorna as recomendações ordenadas
+// pelo impacto em disco. Índices que sustentam constraints nunca são sugeridos para remoção,
+// e cada índice aparece em no máximo uma recomendação.
+func AdviseIndexes(indexes []models.IndexDefinition, tables []models.TableStatSnapshot, options models.IndexAdvisorOptions) []models.IndexFinding {
+	findings := []models.IndexFinding{}
+	reported := make(map[string]bool)
+	key := func(idx models.IndexDefinition) string { return idx.SchemaName + "." + idx.IndexName }
+
+	for _, idx := range indexes {
+		if idx.IsValid {
+			continue
+		}
+		reported[key(idx)] = true
+		findings = append(findings, models.IndexFinding{
+			Type:            models.IndexFindingInvalid,
+			SchemaName:      idx.SchemaName,
+			TableName:       idx.TableName,
+			IndexName:       idx.IndexName,
+			Reason:          "Índice inválido, provavelmente de um CREATE INDEX CONCURRENTLY interrompido: não é usado por consultas mas é mantido a cada escrita",
+			Suggestion:      fmt.Sprintf("DROP INDEX CONCURRENTLY %s;\n%s;", qualifiedName(idx.SchemaName, idx.IndexName), concurrentDefinition(idx.Definition)),
+			SizeImpactBytes: -idx.SizeBytes,
+		})
+	}
+
+	for _, pair := range redundantIndexes(indexes, reported) {
+		redundant, kept, findingType := pair.redundant, pair.kept, pair.findingType
+		reported[key(redundant)] = true
+
+		reason := fmt.Sprintf("Índice idêntico a %s", kept.IndexName)
+		if findingType == models.IndexFindingPrefix {
+			reason = fmt.Sprintf("As colunas do índice são prefixo à esquerda de %s, que atende as mesmas consultas", kept.IndexName)
+		}
+		findings = append(findings, models.IndexFinding{
+			Type:            findingType,
+			SchemaName:      redundant.SchemaName,
+			TableName:       redundant.TableName,
+			IndexName:       redundant.IndexName,
+			RelatedIndex:    kept.IndexName,
+			Reason:          reason,
+			Suggestion:      fmt.Sprintf("DROP INDEX CONCURRENTLY %s;", qualifiedName(redundant.SchemaName, redundant.IndexName)),
+			SizeImpactBytes: -redundant.SizeBytes,
+		})
+	}
+
+	for _, idx := range indexes {
+		if reported[key(idx)] || idx.IdxScan > 0 || idx.IsUnique || idx.IsConstraint {
+			continue
+		}
+		findings = append(findings, models.IndexFinding{
+			Type:            models.IndexFindingUnused,
+			SchemaName:      idx.SchemaName,
+			TableName:       idx.TableName,
+			IndexName:       idx.IndexName,
+			Reason:          "Nenhum scan desde o último reset de estatísticas; confirme também nas réplicas antes de remover",
+			Suggestion:      fmt.Sprintf("DROP INDEX CONCURRENTLY %s;", qualifiedName(idx.SchemaName, idx.IndexName)),
+			SizeImpactBytes: -idx.SizeBytes,
+		})
+	}
+
+	for _, t := range tables {
+		if t.TotalSizeBytes < options.MinTableBytes || t.SeqScanCount < options.MinSeqScans || t.SeqScanCount <= t.IdxScanCount {
+			continue
+		}
+		rowsPerScan := t.SeqTupRead / t.SeqScanCount
+		if rowsPerScan < options.MinRowsPerSeqScan {
+			continue
+		}
+		findings = append(findings, models.IndexFinding{
+			Type:       models.IndexFindingMissing,
+			SchemaName: t.SchemaName,
+			TableName:  t.TableName,
+			Reason: fmt.Sprintf("%d sequential scans (contra %d index scans) lendo em média %d linhas; identifique as colunas filtradas em pg_stat_statements",
+				t.SeqScanCount, t.IdxScanCount, rowsPerScan),
+			Suggestion:      fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s (<colunas filtradas>);", qualifiedName(t.SchemaName, t.TableName)),
+			SizeImpactBytes: t.NLiveTup * btreeEntryBytes * 100 / 90,
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return abs64(findings[i].SizeImpactBytes) > abs64(findings[j].SizeImpactBytes)
+	})
+	return findings
+}
+
+// redundantIndex associa um índice redundante ao índice que o torna desnecessário
+type redundantIndex struct {
+	redundant   models.IndexDefinition
+	kept        models.IndexDefinition
+	findingType string
+}
+
+// redundantIndexes encontra duplicatas exatas e índices btree cujas colunas de chave
+// são prefixo à esquerda de outro índice com o mesmo predicado
+func redundantIndexes(indexes []models.IndexDefinition, skip map[string]bool) []redundantIndex {
+	byTable := make(map[string][]models.IndexDefinition)
+	for _, idx := range indexes {
+		if !idx.IsValid || skip[idx.SchemaName+"."+idx.IndexName] {
+			continue
+		}
+		table := idx.SchemaName + "." + idx.TableName
+		byTable[table] = append(byTable[table], idx)
+	}
+
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	result := []redundantIndex{}
+	for _, table := range tables {
+		candidates := byTable[table]
+		// Índices que sustentam constraints e os mais usados são mantidos preferencialmente
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].IsConstraint != candidates[j].IsConstraint {
+				return candidates[i].IsConstraint
+			}
+			if candidates[i].IsUnique != candidates[j].IsUnique {
+				return candidates[i].IsUnique
+			}
+			if candidates[i].IdxScan != candidates[j].IdxScan {
+				return candidates[i].IdxScan > candidates[j].IdxScan
+			}
+			return candidates[i].IndexName < candidates[j].IndexName
+		})
+
+		removed := make(map[string]bool)
+		for i, kept := range candidates {
+			if removed[kept.IndexName] {
+				continue
+			}
+			for _, other := range candidates[i+1:] {
+				if removed[other.IndexName] || other.IsConstraint {
+					continue
+				}
+				if sameIndex(kept, other) {
+					removed[other.IndexName] = true
+					result = append(result, redundantIndex{redundant: other, kept: kept, findingType: models.IndexFindingDuplicate})
+				}
+			}
+		}
+
+		for _, short := range candidates {
+			if removed[short.IndexName] || short.IsUnique || short.IsConstraint {
+				continue
+			}
+			// O índice mais longo é apontado para não indicar outro índice também redundante
+			var best *models.IndexDefinition
+			for i, long := range candidates {
+				if long.IndexName == short.IndexName || removed[long.IndexName] || !isLeftPrefix(short, long) {
+					continue
+				}
+				if best == nil || len(keyFields(long.Columns, long.KeyColumns)) > len(keyFields(best.Columns, best.KeyColumns)) {
+					best = &candidates[i]
+				}
+			}
+			if best != nil {
+				removed[short.IndexName] = true
+				result = append(result, redundantIndex{redundant: short, kept: *best, findingType: models.IndexFindingPrefix})
+			}
+		}
+	}
+
+	return result
+}
+
+// sameIndex indica índices equivalentes: mesmo método, colunas, operator classes, expressões e predicado
+func sameIndex(a, b models.IndexDefinition) bool {
+	return a.AccessMethod == b.AccessMethod &&
+		a.Columns == b.Columns &&
+		a.KeyColumns == b.KeyColumns &&
+		a.OpClasses == b.OpClasses &&
+		a.Expressions == b.Expressions &&
+		a.Predicate == b.Predicate
+}
+
+// isLeftPrefix indica se as colunas de chave de short são um prefixo próprio das de long.
+// Apenas índices btree sem expressões são comparados.
+func isLeftPrefix(short, long models.IndexDefinition) bool {
+	if short.AccessMethod != "btree" || long.AccessMethod != "btree" ||
+		short.Expressions != "" || long.Expressions != "" || short.Predicate != long.Predicate {
+		return false
+	}
+
+	shortKeys := keyFields(short.Columns, short.KeyColumns)
+	longKeys := keyFields(long.Columns, long.KeyColumns)
+	shortOps := strings.Fields(short.OpClasses)
+	longOps := strings.Fields(long.OpClasses)
+	if len(shortKeys) == 0 || len(shortKeys) >= len(longKeys) || len(shortOps) < len(shortKeys) || len(longOps) < len(shortKeys) {
+		return false
+	}
+
+	for i := range shortKeys {
+		if shortKeys[i] != longKeys[i] || shortOps[i] != longOps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyFields retorna as primeiras n colunas de indkey (as demais são colunas INCLUDE)
+func keyFields(columns string, n int) []string {
+	fields := strings.Fields(columns)
+	if n > 0 && n < len(fields) {
+		fields = fields[:n]
+	}
+	return fields
+}
+
+// qualifiedName retorna schema.nome com os identificadores escapados
+func qualifiedName(schema, name string) string {
+	return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(name)
+}
+
+// concurrentDefinition converte a saída de pg_get_indexdef em CREATE INDEX CONCURRENTLY
+func concurrentDefinition(definition string) string {
+	return strings.Replace(definition, " INDEX ", " INDEX CONCURRENTLY ", 1)
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
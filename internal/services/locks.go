@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// MaxLockSnapshots limita a quantidade de árvores de bloqueio retornadas por consulta
+const MaxLockSnapshots = 500
+
+// GetLocks retorna a árvore de bloqueio atual da instância
+func (s *AnalyticsService) GetLocks(target string) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := repo.GetBlockingLocks()
+	if err != nil {
+		return nil, collectorError("obter locks", err)
+	}
+
+	tree := BuildLockTree(backends)
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Árvore de bloqueio obtida com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":        target,
+			"roots":         tree.Roots,
+			"waiting_count": tree.WaitingCount,
+			"blocker_count": tree.BlockerCount,
+			"max_wait_ms":   tree.MaxWaitMs,
+			"last_updated":  tree.CollectedAt.Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// GetLockSnapshots retorna as árvores de bloqueio gravadas no período.
+// Erros de validação são retornados como ErrInvalidHistoryQuery.
+func (s *HistoryService) GetLockSnapshots(q models.LockSnapshotQuery) (*models.AnalyticsResponse, error) {
+	if q.To.Before(q.From) {
+		return nil, fmt.Errorf("%w: 'from' deve ser anterior a 'to'", ErrInvalidHistoryQuery)
+	}
+	if q.Limit <= 0 || q.Limit > MaxLockSnapshots {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidHistoryQuery, MaxLockSnapshots)
+	}
+
+	snapshots, err := s.repo.GetLockSnapshots(q)
+	if err != nil {
+		log.Printf("Erro ao obter snapshots de bloqueio: %v", err)
+		return createErrorResponse("Erro ao obter snapshots de bloqueio"), nil
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Snapshots de bloqueio obtidos com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"query":     q,
+			"snapshots": snapshots,
+			"total":     len(snapshots),
+		},
+	}, nil
+}
+
+// BuildLockTree monta a árvore de bloqueio a partir dos backends coletados.
+// As raízes são os backends que bloqueiam outros sem aguardar nenhum; um backend
+// bloqueado por vários aparece sob cada um deles. Ciclos ainda não resolvidos pelo
+// detector de deadlock não têm raiz e entram a partir do backend que espera há mais tempo.
+func BuildLockTree(backends []models.LockBackend) *models.LockTree {
+	tree := &models.LockTree{Roots: []*models.LockNode{}, CollectedAt: time.Now()}
+
+	byPID := make(map[int]models.LockBackend, len(backends))
+	for _, b := range backends {
+		byPID[b.PID] = b
+	}
+
+	waiters := make(map[int][]int)
+	for _, b := range backends {
+		if len(b.BlockedBy) == 0 {
+			continue
+		}
+		tree.WaitingCount++
+		if b.WaitDurationMs > tree.MaxWaitMs {
+			tree.MaxWaitMs = b.WaitDurationMs
+		}
+		for _, blocker := range b.BlockedBy {
+			// Transações preparadas e backends já encerrados não aparecem em pg_stat_activity
+			if _, ok := byPID[blocker]; !ok {
+				byPID[blocker] = models.LockBackend{PID: blocker, State: "unknown"}
+			}
+			waiters[blocker] = append(waiters[blocker], b.PID)
+		}
+	}
+
+	reached := make(map[int]bool)
+	path := make(map[int]bool)
+	var build func(pid int) *models.LockNode
+	build = func(pid int) *models.LockNode {
+		reached[pid] = true
+		path[pid] = true
+		node := &models.LockNode{LockBackend: byPID[pid], Blocking: []*models.LockNode{}}
+		for _, waiter := range waiters[pid] {
+			if !path[waiter] {
+				node.Blocking = append(node.Blocking, build(waiter))
+			}
+		}
+		delete(path, pid)
+		return node
+	}
+
+	// Bloqueadores com mais backends em espera primeiro
+	blockers := make([]int, 0, len(waiters))
+	for pid := range waiters {
+		blockers = append(blockers, pid)
+	}
+	sort.Slice(blockers, func(i, j int) bool {
+		if len(waiters[blockers[i]]) != len(waiters[blockers[j]]) {
+			return len(waiters[blockers[i]]) > len(waiters[blockers[j]])
+		}
+		return blockers[i] < blockers[j]
+	})
+	for _, pid := range blockers {
+		if len(byPID[pid].BlockedBy) == 0 {
+			tree.Roots = append(tree.Roots, build(pid))
+		}
+	}
+
+	cyclic := []models.LockBackend{}
+	for _, pid := range blockers {
+		if !reached[pid] {
+			cyclic = append(cyclic, byPID[pid])
+		}
+	}
+	sort.Slice(cyclic, func(i, j int) bool { return cyclic[i].WaitDurationMs > cyclic[j].WaitDurationMs })
+	for _, b := range cyclic {
+		if !reached[b.PID] {
+			tree.Roots = append(tree.Roots, build(b.PID))
+		}
+	}
+
+	tree.BlockerCount = len(tree.Roots)
+	return tree
+}
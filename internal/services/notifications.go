@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/mailer"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/notifier"
+	"pganalytics-backend/internal/repositories"
+)
+
+// Padrões dos canais de notificação
+const (
+	DefaultChannelGroupWaitSeconds      = 30
+	DefaultChannelRepeatIntervalSeconds = 4 * 60 * 60
+	DefaultChannelMaxAttempts           = 5
+	MaxChannelAttempts                  = 20
+)
+
+// notificationTick é o intervalo em que os grupos prontos são liberados para entrega
+const notificationTick = time.Second
+
+// channelTestTimeout limita o envio da notificação de teste
+const channelTestTimeout = 30 * time.Second
+
+// ErrInvalidChannel indica dados inválidos no cadastro de um canal de notificação
+var ErrInvalidChannel = errors.New("canal de notificação inválido")
+
+// ErrNotificationFailed indica que o destino recusou ou não respondeu à notificação
+var ErrNotificationFailed = errors.New("falha ao entregar notificação")
+
+// NotificationService gerencia os canais de notificação e entrega a eles os eventos de alerta,
+// agrupados e deduplicados pelo notifier.Dispatcher
+type NotificationService struct {
+	repo       *repositories.NotificationChannelRepository
+	mail       mailer.Mailer
+	audit      *AuditService
+	dispatcher *notifier.Dispatcher
+	queue      *notifier.Queue
+	retry      notifier.RetryPolicy
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stop       chan struct{}
+	once       sync.Once
+}
+
+// NewNotificationService cria um novo serviço de notificações.
+// mail é usado pelos canais do tipo email.
+func NewNotificationService(repo *repositories.NotificationChannelRepository, mail mailer.Mailer, audit *AuditService) *NotificationService {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &NotificationService{
+		repo:       repo,
+		mail:       mail,
+		audit:      audit,
+		dispatcher: notifier.NewDispatcher(),
+		retry:      notifier.DefaultRetryPolicy(),
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+	}
+	s.queue = notifier.NewQueue(s.deliver)
+	return s
+}
+
+// NewChannelNotifier cria o notificador do canal, resolvendo as referências de segredo
+func NewChannelNotifier(channel models.NotificationChannel, sender mailer.Mailer) (notifier.Notifier, error) {
+	templates, err := notifier.ParseTemplates(channel.TitleTemplate, channel.BodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := channel.Settings
+	switch channel.Type {
+	case models.ChannelTypeWebhook:
+		secret, err := database.ResolveCredentials(settings.SecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("secret_ref: %w", err)
+		}
+		return &notifier.WebhookNotifier{URL: settings.URL, Secret: secret, Headers: settings.Headers, Templates: templates}, nil
+	case models.ChannelTypeSlack:
+		webhookURL := settings.URL
+		if settings.URLRef != "" {
+			if webhookURL, err = database.ResolveCredentials(settings.URLRef); err != nil {
+				return nil, fmt.Errorf("url_ref: %w", err)
+			}
+		}
+		return &notifier.SlackNotifier{WebhookURL: webhookURL, Channel: settings.Channel, Username: settings.Username, Templates: templates}, nil
+	case models.ChannelTypeEmail:
+		if sender == nil {
+			return nil, fmt.Errorf("mailer não configurado")
+		}
+		return &notifier.EmailNotifier{Mailer: sender, To: settings.To, Templates: templates}, nil
+	case models.ChannelTypePagerDuty:
+		routingKey, err := database.ResolveCredentials(settings.RoutingKeyRef)
+		if err != nil {
+			return nil, fmt.Errorf("routing_key_ref: %w", err)
+		}
+		return &notifier.PagerDutyNotifier{RoutingKey: routingKey, URL: settings.URL, Templates: templates}, nil
+	}
+	return nil, fmt.Errorf("tipo de canal desconhecido: %s", channel.Type)
+}
+
+// List retorna todos os canais de notificação
+func (s *NotificationService) List() ([]models.NotificationChannel, error) {
+	return s.repo.List()
+}
+
+// Get busca um canal pelo ID ou nome
+func (s *NotificationService) Get(idOrName string) (*models.NotificationChannel, error) {
+	return s.repo.Find(idOrName)
+}
+
+// Create valida e grava um novo canal de notificação
+func (s *NotificationService) Create(req models.NotificationChannelRequest, actor models.AuditActor) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{}
+	if err := s.applyRequest(channel, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(channel); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionCreate, "notification_channel", channel.ID, nil, channel)
+	return channel, nil
+}
+
+// Update altera um canal existente. Eventos já agrupados são entregues com a configuração anterior.
+func (s *NotificationService) Update(id string, req models.NotificationChannelRequest, actor models.AuditActor) (*models.NotificationChannel, error) {
+	channel, err := s.repo.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *channel
+
+	if err := s.applyRequest(channel, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(channel); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionUpdate, "notification_channel", channel.ID, before, channel)
+	return channel, nil
+}
+
+// Delete remove um canal e descarta seus grupos pendentes
+func (s *NotificationService) Delete(id string, actor models.AuditActor) error {
+	channel, err := s.repo.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(channel.ID); err != nil {
+		return err
+	}
+	s.dispatcher.Forget(channel.ID)
+
+	s.audit.RecordChange(actor, models.AuditActionDelete, "notification_channel", channel.ID, channel, nil)
+	return nil
+}
+
+// Test envia imediatamente ao canal uma notificação de exemplo, sem retentativas
+func (s *NotificationService) Test(idOrName string) error {
+	channel, err := s.repo.Find(idOrName)
+	if err != nil {
+		return err
+	}
+
+	n, err := NewChannelNotifier(*channel, s.mail)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+
+	event := models.AlertEvent{
+		Source:    "test",
+		EventKey:  "test:" + channel.ID,
+		Severity:  models.AlertSeverityWarning,
+		Title:     "Notificação de teste do pgAnalytics",
+		Message:   fmt.Sprintf("Canal %s configurado corretamente", channel.Name),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), channelTestTimeout)
+	defer cancel()
+	if err := n.Notify(ctx, notifier.NewNotification(channel.GroupBy, []models.AlertEvent{event})); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotificationFailed, err)
+	}
+	return nil
+}
+
+// Submit encaminha o evento aos canais habilitados; a entrega ocorre em background após o
+// group wait de cada canal
+func (s *NotificationService) Submit(event models.AlertEvent) {
+	channels, err := s.repo.ListEnabled()
+	if err != nil {
+		log.Printf("⚠️ Erro ao listar canais de notificação: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, channel := range channels {
+		route, err := s.route(channel)
+		if err != nil {
+			log.Printf("⚠️ Canal de notificação %s inválido: %v", channel.Name, err)
+			continue
+		}
+		s.dispatcher.Add(route, event, now)
+	}
+}
+
+// Start inicia a entrega dos grupos prontos em background
+func (s *NotificationService) Start() {
+	go s.run()
+}
+
+// Stop interrompe a entrega e cancela as retentativas em andamento
+func (s *NotificationService) Stop() {
+	s.once.Do(func() {
+		s.cancel()
+		close(s.stop)
+	})
+}
+
+func (s *NotificationService) run() {
+	ticker := time.NewTicker(notificationTick)
+	defer ticker.Stop()
+
+	log.Printf("📣 Entrega de notificações iniciada")
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.stop:
+			log.Printf("📣 Entrega de notificações finalizada")
+			return
+		}
+	}
+}
+
+// Flush enfileira os grupos cujo group wait venceu. Cada canal e grupo é entregue em ordem,
+// um de cada vez; grupos diferentes seguem em paralelo.
+func (s *NotificationService) Flush() {
+	for _, delivery := range s.dispatcher.Ready(time.Now()) {
+		s.queue.Push(delivery)
+	}
+}
+
+func (s *NotificationService) deliver(delivery notifier.Delivery) {
+	n := delivery.Notification
+	if err := delivery.Route.Notifier.Notify(s.ctx, n); err != nil {
+		log.Printf("⚠️ Falha ao notificar o canal %s (%s, grupo %s, %d alerta(s)): %v",
+			delivery.Route.Name, n.Status, n.GroupKey, len(n.Alerts), err)
+		s.recordFailure(delivery, err)
+		return
+	}
+	s.dispatcher.Delivered(delivery, time.Now())
+	log.Printf("📣 Notificação %s enviada ao canal %s (%d alerta(s))", n.Status, delivery.Route.Name, len(n.Alerts))
+}
+
+// recordFailure grava em notification_failures a notificação descartada após as retentativas
+func (s *NotificationService) recordFailure(delivery notifier.Delivery, cause error) {
+	n := delivery.Notification
+	payload, err := json.Marshal(n)
+	if err != nil {
+		log.Printf("⚠️ Erro ao serializar notificação do canal %s: %v", delivery.Route.Name, err)
+		return
+	}
+
+	failure := &models.NotificationFailure{
+		ChannelID: delivery.Route.ID,
+		GroupKey:  n.GroupKey,
+		DedupKey:  n.DedupKey,
+		Status:    n.Status,
+		Error:     cause.Error(),
+		Payload:   payload,
+	}
+	if err := s.repo.RecordFailure(failure); err != nil {
+		log.Printf("⚠️ Erro ao gravar falha de notificação do canal %s: %v", delivery.Route.Name, err)
+	}
+}
+
+// route monta a rota do dispatcher para o canal, com retentativas
+func (s *NotificationService) route(channel models.NotificationChannel) (notifier.Route, error) {
+	n, err := NewChannelNotifier(channel, s.mail)
+	if err != nil {
+		return notifier.Route{}, err
+	}
+
+	policy := s.retry
+	policy.MaxAttempts = channel.MaxAttempts
+	return notifier.Route{
+		ID:             channel.ID,
+		Name:           channel.Name,
+		Notifier:       notifier.WithRetry(n, policy),
+		GroupBy:        channel.GroupBy,
+		GroupWait:      time.Duration(channel.GroupWaitSeconds) * time.Second,
+		RepeatInterval: time.Duration(channel.RepeatIntervalSeconds) * time.Second,
+		MinSeverity:    channel.MinSeverity,
+	}, nil
+}
+
+// applyRequest valida a requisição, preenche o canal e verifica que o notificador pode ser criado
+func (s *NotificationService) applyRequest(channel *models.NotificationChannel, req models.NotificationChannelRequest) error {
+	if err := applyChannelRequest(channel, req); err != nil {
+		return err
+	}
+	if _, err := NewChannelNotifier(*channel, s.mail); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	return nil
+}
+
+// applyChannelRequest valida os campos da requisição e aplica os padrões
+func applyChannelRequest(channel *models.NotificationChannel, req models.NotificationChannelRequest) error {
+	settings := req.Settings
+	switch req.Type {
+	case models.ChannelTypeWebhook:
+		if err := validateChannelURL(settings.URL); err != nil {
+			return err
+		}
+	case models.ChannelTypeSlack:
+		if settings.URLRef == "" {
+			if err := validateChannelURL(settings.URL); err != nil {
+				return err
+			}
+		}
+	case models.ChannelTypeEmail:
+		if len(settings.To) == 0 {
+			return fmt.Errorf("%w: informe ao menos um destinatário em 'settings.to'", ErrInvalidChannel)
+		}
+		for _, to := range settings.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("%w: destinatário '%s' inválido", ErrInvalidChannel, to)
+			}
+		}
+	case models.ChannelTypePagerDuty:
+		if settings.RoutingKeyRef == "" {
+			return fmt.Errorf("%w: 'settings.routing_key_ref' é obrigatório", ErrInvalidChannel)
+		}
+		if settings.URL != "" {
+			if err := validateChannelURL(settings.URL); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: tipo '%s' desconhecido (use webhook, slack, email ou pagerduty)", ErrInvalidChannel, req.Type)
+	}
+
+	if _, err := notifier.ParseTemplates(req.TitleTemplate, req.BodyTemplate); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+
+	groupBy := req.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = notifier.DefaultGroupBy
+	}
+	for _, field := range groupBy {
+		if !notifier.ValidGroupBy(field) {
+			return fmt.Errorf("%w: campo de agrupamento '%s' desconhecido (use target, source, event_key ou severity)", ErrInvalidChannel, field)
+		}
+	}
+
+	minSeverity := req.MinSeverity
+	if minSeverity == "" {
+		minSeverity = models.AlertSeverityInfo
+	}
+	if !notifier.ValidSeverity(minSeverity) {
+		return fmt.Errorf("%w: severidade '%s' desconhecida (use info, warning ou critical)", ErrInvalidChannel, minSeverity)
+	}
+
+	groupWait := DefaultChannelGroupWaitSeconds
+	if req.GroupWaitSeconds != nil {
+		groupWait = *req.GroupWaitSeconds
+	}
+	repeat := DefaultChannelRepeatIntervalSeconds
+	if req.RepeatIntervalSeconds != nil {
+		repeat = *req.RepeatIntervalSeconds
+	}
+	if groupWait < 0 || repeat < 0 {
+		return fmt.Errorf("%w: 'group_wait_seconds' e 'repeat_interval_seconds' não podem ser negativos", ErrInvalidChannel)
+	}
+
+	attempts := req.MaxAttempts
+	if attempts == 0 {
+		attempts = DefaultChannelMaxAttempts
+	}
+	if attempts < 1 || attempts > MaxChannelAttempts {
+		return fmt.Errorf("%w: 'max_attempts' deve estar entre 1 e %d", ErrInvalidChannel, MaxChannelAttempts)
+	}
+
+	channel.Name = req.Name
+	channel.Type = req.Type
+	channel.Settings = settings
+	channel.TitleTemplate = req.TitleTemplate
+	channel.BodyTemplate = req.BodyTemplate
+	channel.GroupBy = append([]string(nil), groupBy...)
+	channel.MinSeverity = minSeverity
+	channel.GroupWaitSeconds = groupWait
+	channel.RepeatIntervalSeconds = repeat
+	channel.MaxAttempts = attempts
+	channel.Enabled = true
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+
+	return nil
+}
+
+// validateChannelURL exige uma URL http(s) absoluta
+func validateChannelURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: 'settings.url' deve ser uma URL http(s) absoluta", ErrInvalidChannel)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"errors"
+
+	"pganalytics-backend/pkg/validation"
+)
+
+// ErrWeakPassword indica senha fora da política de senhas
+var ErrWeakPassword = errors.New("a senha deve ter entre 8 e 100 caracteres, com letras maiúsculas, minúsculas e números")
+
+// ValidatePassword retorna ErrWeakPassword quando a senha não atende à política de validation.IsValidPassword
+func ValidatePassword(password string) error {
+	if !validation.IsValidPassword(password) {
+		return ErrWeakPassword
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"pganalytics-backend/internal/mailer"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// ErrInvalidResetToken indica token de redefinição inexistente, expirado ou já utilizado
+var ErrInvalidResetToken = errors.New("token de redefinição inválido ou expirado")
+
+// defaultResetTokenMinutes é usado quando auth.password_reset_token_minutes não está configurado
+const defaultResetTokenMinutes = 60
+
+// PasswordService implementa a redefinição de senha por email com tokens de uso único
+type PasswordService struct {
+	users    *repositories.UserRepository
+	resets   *repositories.PasswordResetRepository
+	tokens   *repositories.RefreshTokenRepository
+	audit    *AuditService
+	config   *repositories.ConfigRepository
+	mailer   mailer.Mailer
+	resetURL string
+	pending  sync.WaitGroup
+}
+
+// NewPasswordService cria um novo serviço de senhas.
+// resetURL é a página do frontend que recebe o token no parâmetro "token".
+func NewPasswordService(users *repositories.UserRepository, resets *repositories.PasswordResetRepository, tokens *repositories.RefreshTokenRepository, audit *AuditService, config *repositories.ConfigRepository, m mailer.Mailer, resetURL string) *PasswordService {
+	return &PasswordService{
+		users:    users,
+		resets:   resets,
+		tokens:   tokens,
+		audit:    audit,
+		config:   config,
+		mailer:   m,
+		resetURL: resetURL,
+	}
+}
+
+// Forgot envia um link de redefinição para o email, se ele pertencer a um usuário.
+// Emails desconhecidos não geram erro para não revelar quais contas existem; pelo mesmo
+// motivo o link é gerado e enviado em segundo plano. Solicitações são limitadas por IP
+// e por email.
+func (s *PasswordService) Forgot(email string, actor models.AuditActor) error {
+	policy := s.resetPolicy()
+	if err := s.checkIPThrottle(policy, actor.IPAddress); err != nil {
+		s.recordThrottled(actor, email, "ip_throttled")
+		return err
+	}
+	if s.emailThrottled(policy, email) {
+		s.recordThrottled(actor, email, "email_throttled")
+		return nil
+	}
+
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			s.recordRequest(actor, "", email, "unknown_user")
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		s.recordRequest(actor, user.ID, user.Email, "account_disabled")
+		return nil
+	}
+
+	s.recordRequest(actor, user.ID, user.Email, "")
+
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		if err := s.sendResetLink(user); err != nil {
+			log.Printf("⚠️ Erro ao enviar redefinição de senha para %s: %v", user.Email, err)
+		}
+	}()
+	return nil
+}
+
+// Stop aguarda o término dos envios de redefinição em andamento
+func (s *PasswordService) Stop() {
+	s.pending.Wait()
+}
+
+// Reset troca a senha do dono do token, encerrando todas as suas sessões
+func (s *PasswordService) Reset(token, password string, actor models.AuditActor) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	email, err := s.resets.Consume(hashToken(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !user.IsActive {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(user, password); err != nil {
+		return err
+	}
+	revoked := s.revokeSessions(user)
+
+	if actor.UserID == "" {
+		actor.UserID = user.ID
+	}
+	s.audit.RecordDiff(actor, models.AuditActionPasswordReset, "user", user.ID, nil,
+		models.AuditValues{"email": user.Email, "sessions_revoked": revoked})
+	return nil
+}
+
+// ForceReset troca a senha atual por uma aleatória, encerra as sessões e envia um link
+// de redefinição. Usado por administradores quando a senha pode estar comprometida.
+func (s *PasswordService) ForceReset(user *models.User, actor models.AuditActor) error {
+	random, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	if err := s.setPassword(user, random); err != nil {
+		return err
+	}
+	revoked := s.revokeSessions(user)
+
+	s.audit.RecordDiff(actor, models.AuditActionPasswordResetForced, "user", user.ID, nil,
+		models.AuditValues{"email": user.Email, "sessions_revoked": revoked})
+
+	if err := s.sendResetLink(user); err != nil {
+		return fmt.Errorf("senha invalidada, mas o envio do link falhou: %w", err)
+	}
+	return nil
+}
+
+// setPassword grava o hash bcrypt da nova senha
+func (s *PasswordService) setPassword(user *models.User, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("falha ao gerar hash da senha: %w", err)
+	}
+	return s.users.SetPassword(user.ID, string(hash))
+}
+
+// revokeSessions encerra todas as sessões do usuário, retornando quantas foram revogadas
+func (s *PasswordService) revokeSessions(user *models.User) int64 {
+	revoked, err := s.tokens.RevokeAllForUser(user.ID)
+	if err != nil {
+		log.Printf("⚠️ Erro ao revogar sessões de %s após troca de senha: %v", user.Email, err)
+	}
+	return revoked
+}
+
+// sendResetLink gera um token de uso único e envia o link por email
+func (s *PasswordService) sendResetLink(user *models.User) error {
+	token, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	minutes := s.config.GetInt("auth.password_reset_token_minutes", defaultResetTokenMinutes)
+	if minutes <= 0 {
+		minutes = defaultResetTokenMinutes
+	}
+	expiresAt := time.Now().Add(time.Duration(minutes) * time.Minute)
+
+	if err := s.resets.Create(user.Email, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link, err := resetLink(s.resetURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      []string{user.Email},
+		Subject: "PG Analytics - Redefinição de senha",
+		Body: fmt.Sprintf(
+			"Olá %s,\n\nRecebemos uma solicitação para redefinir sua senha.\n"+
+				"Use o link abaixo em até %d minutos:\n\n%s\n\n"+
+				"Se você não fez essa solicitação, ignore este email.\n",
+			user.Name, minutes, link),
+	})
+}
+
+func (s *PasswordService) recordRequest(actor models.AuditActor, userID, email, reason string) {
+	values := models.AuditValues{"email": email}
+	if reason != "" {
+		values["reason"] = reason
+	}
+	if actor.UserID == "" {
+		actor.UserID = userID
+	}
+	s.audit.RecordDiff(actor, models.AuditActionPasswordResetRequested, "user", userID, nil, values)
+}
+
+// recordThrottled grava uma solicitação recusada pelos limites. A ação é separada para que
+// recusas não entrem nas contagens nem estendam a janela.
+func (s *PasswordService) recordThrottled(actor models.AuditActor, email, reason string) {
+	s.audit.RecordDiff(actor, models.AuditActionPasswordResetThrottled, "user", "", nil,
+		models.AuditValues{"email": email, "reason": reason})
+}
+
+// resetLink acrescenta o token como parâmetro "token" à URL do frontend
+func resetLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("PASSWORD_RESET_URL inválida: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
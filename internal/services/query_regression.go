@@ -0,0 +1,309 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// AlertSourceQueryRegression identifica em alert_events os eventos do detector de regressões
+const AlertSourceQueryRegression = "query_regression"
+
+// DefaultRegressionMinCalls é o mínimo de execuções em cada janela para comparar latências
+const DefaultRegressionMinCalls = 10
+
+// DefaultRegressionOptions lê os limites do detector de system_config
+func DefaultRegressionOptions(cfg *repositories.ConfigRepository) models.RegressionOptions {
+	return models.RegressionOptions{
+		Factor:          cfg.GetFloat("analytics.query_regression_factor", 2),
+		DominantPercent: cfg.GetFloat("analytics.query_regression_dominant_percent", 20),
+		MinCalls:        DefaultRegressionMinCalls,
+		Window:          time.Duration(cfg.GetInt("analytics.query_regression_window_minutes", 60)) * time.Minute,
+		Baseline:        time.Duration(cfg.GetInt("analytics.query_regression_baseline_days", 7)) * 24 * time.Hour,
+	}
+}
+
+// QueryRegressionService compara a latência recente de cada query normalizada com sua linha de
+// base em slow_queries_log e grava os achados como eventos de alerta
+type QueryRegressionService struct {
+	history  *repositories.HistoryRepository
+	alerts   *AlertService
+	targets  *TargetService
+	options  models.RegressionOptions
+	interval time.Duration
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewQueryRegressionService cria um novo detector de regressões.
+// interval é o intervalo entre avaliações em background.
+func NewQueryRegressionService(history *repositories.HistoryRepository, alerts *AlertService, targets *TargetService, options models.RegressionOptions, interval time.Duration) *QueryRegressionService {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &QueryRegressionService{
+		history:  history,
+		alerts:   alerts,
+		targets:  targets,
+		options:  options,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Options retorna os limites padrão do detector
+func (s *QueryRegressionService) Options() models.RegressionOptions {
+	return s.options
+}
+
+// GetRegressions executa o detector para um target (vazio = banco local) com os limites informados
+func (s *QueryRegressionService) GetRegressions(target string, opts models.RegressionOptions) (*models.AnalyticsResponse, error) {
+	if opts.Factor <= 1 {
+		return nil, fmt.Errorf("%w: 'factor' deve ser maior que 1", ErrInvalidAnalyticsQuery)
+	}
+	if opts.DominantPercent <= 0 || opts.DominantPercent > 100 {
+		return nil, fmt.Errorf("%w: 'dominant_percent' deve estar entre 0 e 100", ErrInvalidAnalyticsQuery)
+	}
+	if opts.Window < time.Minute || opts.Baseline < opts.Window {
+		return nil, fmt.Errorf("%w: 'window' deve ser de pelo menos 1m e 'baseline' não pode ser menor que 'window'", ErrInvalidAnalyticsQuery)
+	}
+	if target != "" {
+		if _, err := s.targets.Get(target); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := s.detect(target, opts, time.Now())
+	if err != nil {
+		return nil, collectorError("detectar regressões de queries", err)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Regressões de queries obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target": target,
+			"report": report,
+		},
+	}, nil
+}
+
+// Start inicia a avaliação periódica em background
+func (s *QueryRegressionService) Start() {
+	go s.run()
+}
+
+// Stop interrompe a avaliação periódica
+func (s *QueryRegressionService) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *QueryRegressionService) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Printf("📈 Detector de regressões de queries iniciado (intervalo %s)", s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.Evaluate()
+		case <-s.stop:
+			log.Printf("📈 Detector de regressões de queries finalizado")
+			return
+		}
+	}
+}
+
+// Evaluate executa o detector no banco local e nos targets habilitados e grava os achados em
+// alert_events. Um achado para a mesma query não é repetido dentro da janela recente.
+func (s *QueryRegressionService) Evaluate() {
+	names := []string{""}
+	if targets, err := s.targets.List(); err != nil {
+		log.Printf("⚠️ Erro ao listar targets para o detector de regressões: %v", err)
+	} else {
+		for _, t := range targets {
+			if t.Enabled {
+				names = append(names, t.Name)
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		report, err := s.detect(name, s.options, now)
+		if err != nil {
+			log.Printf("⚠️ Detector de regressões falhou para %s: %v", targetLabel(name), err)
+			continue
+		}
+		for _, regression := range report.Regressions {
+			if _, err := s.alerts.Record(regressionEvent(name, regression), report.RecentFrom); err != nil {
+				log.Printf("⚠️ Erro ao gravar evento de regressão: %v", err)
+			}
+		}
+	}
+}
+
+// detect lê as janelas de slow_queries_log e aplica DetectQueryRegressions
+func (s *QueryRegressionService) detect(target string, opts models.RegressionOptions, now time.Time) (*models.QueryRegressionReport, error) {
+	if opts.MinCalls <= 0 {
+		opts.MinCalls = DefaultRegressionMinCalls
+	}
+	recentFrom := now.Add(-opts.Window)
+	baselineFrom := recentFrom.Add(-opts.Baseline)
+
+	windows, err := s.history.GetQueryWindows(target, baselineFrom, recentFrom, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.QueryRegressionReport{
+		Options:      opts,
+		BaselineFrom: baselineFrom,
+		RecentFrom:   recentFrom,
+		To:           now,
+		QueriesSeen:  len(windows),
+		Regressions:  DetectQueryRegressions(windows, opts),
+	}
+	for _, w := range windows {
+		report.RecentTimeMs += w.RecentTimeMs
+	}
+	return report, nil
+}
+
+// DetectQueryRegressions compara a janela recente de cada query com sua linha de base.
+// Uma query regrediu quando tem pelo menos MinCalls execuções nas duas janelas e a latência
+// média ou o p95 recente é pelo menos Factor vezes o da linha de base. Queries sem linha de base
+// são sinalizadas quando respondem por pelo menos DominantPercent do tempo total recente.
+// Achados com o dobro do limite são críticos. O resultado é ordenado pelo tempo recente.
+func DetectQueryRegressions(windows []models.QueryWindowStats, opts models.RegressionOptions) []models.QueryRegression {
+	var totalTime float64
+	for _, w := range windows {
+		totalTime += w.RecentTimeMs
+	}
+
+	regressions := []models.QueryRegression{}
+	for _, w := range windows {
+		if w.RecentCalls == 0 {
+			continue
+		}
+
+		r := models.QueryRegression{
+			QueryHash:     w.QueryHash,
+			QueryText:     w.QueryText,
+			DatabaseName:  w.DatabaseName,
+			RecentMeanMs:  w.RecentTimeMs / float64(w.RecentCalls),
+			BaselineP95Ms: w.BaselineP95Ms,
+			RecentP95Ms:   w.RecentP95Ms,
+			RecentCalls:   w.RecentCalls,
+			RecentTimeMs:  w.RecentTimeMs,
+		}
+		if totalTime > 0 {
+			r.TimePercent = roundPercent(w.RecentTimeMs / totalTime * 100)
+		}
+
+		if w.BaselineCalls == 0 {
+			if r.TimePercent < opts.DominantPercent {
+				continue
+			}
+			r.Kind = models.RegressionKindNewDominant
+			r.Severity = regressionSeverity(r.TimePercent, opts.DominantPercent)
+			regressions = append(regressions, r)
+			continue
+		}
+
+		if w.BaselineCalls < opts.MinCalls || w.RecentCalls < opts.MinCalls {
+			continue
+		}
+
+		baselineMean := w.BaselineTimeMs / float64(w.BaselineCalls)
+		r.BaselineMeanMs = &baselineMean
+		r.MeanRatio = latencyRatio(&r.RecentMeanMs, &baselineMean)
+		r.P95Ratio = latencyRatio(w.RecentP95Ms, w.BaselineP95Ms)
+
+		worst := 0.0
+		for _, ratio := range []*float64{r.MeanRatio, r.P95Ratio} {
+			if ratio != nil && *ratio > worst {
+				worst = *ratio
+			}
+		}
+		if worst < opts.Factor {
+			continue
+		}
+		r.Kind = models.RegressionKindLatency
+		r.Severity = regressionSeverity(worst, opts.Factor)
+		regressions = append(regressions, r)
+	}
+
+	sort.SliceStable(regressions, func(i, j int) bool {
+		return regressions[i].RecentTimeMs > regressions[j].RecentTimeMs
+	})
+	return regressions
+}
+
+// latencyRatio divide a latência recente pela da linha de base; nulo quando não há base comparável
+func latencyRatio(recent, baseline *float64) *float64 {
+	if recent == nil || baseline == nil || *baseline <= 0 {
+		return nil
+	}
+	ratio := roundPercent(*recent / *baseline)
+	return &ratio
+}
+
+// regressionSeverity é crítica quando o valor chega ao dobro do limite
+func regressionSeverity(value, threshold float64) string {
+	if value >= 2*threshold {
+		return models.AlertSeverityCritical
+	}
+	return models.AlertSeverityWarning
+}
+
+// regressionEvent converte um achado do detector em evento de alerta
+func regressionEvent(target string, r models.QueryRegression) *models.AlertEvent {
+	event := &models.AlertEvent{
+		Source:   AlertSourceQueryRegression,
+		EventKey: r.QueryHash + ":" + r.DatabaseName,
+		Severity: r.Severity,
+	}
+	if target != "" {
+		event.TargetName = &target
+	}
+
+	switch r.Kind {
+	case models.RegressionKindNewDominant:
+		event.Title = fmt.Sprintf("Query nova domina %.0f%% do tempo em %s", r.TimePercent, targetLabel(target))
+		event.Message = fmt.Sprintf("Query sem histórico na linha de base respondeu por %.1f%% do tempo total recente (%d execuções, média %.1fms) no banco %s: %s",
+			r.TimePercent, r.RecentCalls, r.RecentMeanMs, r.DatabaseName, r.QueryText)
+	default:
+		event.Title = fmt.Sprintf("Regressão de latência em %s", targetLabel(target))
+		event.Message = fmt.Sprintf("Latência média passou de %.1fms para %.1fms (%d execuções recentes) no banco %s: %s",
+			derefFloat(r.BaselineMeanMs), r.RecentMeanMs, r.RecentCalls, r.DatabaseName, r.QueryText)
+	}
+
+	if details, err := json.Marshal(r); err == nil {
+		event.Details = details
+	}
+	return event
+}
+
+// targetLabel identifica o target em logs e mensagens
+func targetLabel(target string) string {
+	if target == "" {
+		return "banco local"
+	}
+	return target
+}
+
+func derefFloat(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// RateTracker guarda a amostra anterior de pg_stat_database e calcula taxas a partir dos contadores cumulativos
+type RateTracker struct {
+	mu     sync.Mutex
+	prev   *models.PerformanceStats
+	latest *models.PerformanceRates
+}
+
+// NewRateTracker cria um novo calculador de taxas
+func NewRateTracker() *RateTracker {
+	return &RateTracker{}
+}
+
+// Observe registra uma nova amostra e retorna as taxas em relação à anterior.
+// Retorna nil na primeira amostra ou quando não é possível determinar o intervalo.
+func (t *RateTracker) Observe(curr *models.PerformanceStats) *models.PerformanceRates {
+	if curr == nil {
+		return nil
+	}
+
+	sample := *curr
+	if sample.CollectedAt.IsZero() {
+		sample.CollectedAt = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.prev
+	t.prev = &sample
+	if prev == nil {
+		return nil
+	}
+
+	t.latest = ComputePerformanceRates(prev, &sample)
+	return t.latest
+}
+
+// Latest retorna as taxas da última observação, sem registrar uma nova amostra
+func (t *RateTracker) Latest() *models.PerformanceRates {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.latest
+}
+
+// ComputePerformanceRates calcula taxas e deltas entre duas amostras.
+//
+// Quando stats_reset muda entre as amostras (pg_stat_reset), os contadores atuais
+// representam o que aconteceu desde o reset e o intervalo passa a contar a partir dele.
+// Quando algum contador diminui sem mudança em stats_reset (ex: restart após crash),
+// não há como saber o que aconteceu no intervalo e apenas CounterReset é marcado.
+func ComputePerformanceRates(prev, curr *models.PerformanceStats) *models.PerformanceRates {
+	base := prev
+	since := prev.CollectedAt
+	reset := false
+
+	if statsResetChanged(prev.StatsReset, curr.StatsReset) {
+		reset = true
+		base = &models.PerformanceStats{}
+		if curr.StatsReset != nil && curr.StatsReset.After(prev.CollectedAt) {
+			since = *curr.StatsReset
+		}
+	} else if countersDecreased(prev, curr) {
+		return &models.PerformanceRates{CounterReset: true}
+	}
+
+	interval := curr.CollectedAt.Sub(since).Seconds()
+	if interval <= 0 {
+		return nil
+	}
+
+	perSecond := func(c, p int64) float64 {
+		return float64(c-p) / interval
+	}
+
+	rates := &models.PerformanceRates{
+		IntervalSeconds:     interval,
+		CounterReset:        reset,
+		CommitsPerSecond:    perSecond(curr.XactCommit, base.XactCommit),
+		RollbacksPerSecond:  perSecond(curr.XactRollback, base.XactRollback),
+		ReturnedPerSecond:   perSecond(curr.TuplesReturned, base.TuplesReturned),
+		FetchedPerSecond:    perSecond(curr.TuplesFetched, base.TuplesFetched),
+		InsertsPerSecond:    perSecond(curr.TuplesInserted, base.TuplesInserted),
+		UpdatesPerSecond:    perSecond(curr.TuplesUpdated, base.TuplesUpdated),
+		DeletesPerSecond:    perSecond(curr.TuplesDeleted, base.TuplesDeleted),
+		BlocksReadPerSecond: perSecond(curr.BlocksRead, base.BlocksRead),
+		DeadlocksDelta:      curr.Deadlocks - base.Deadlocks,
+		ConflictsDelta:      curr.Conflicts - base.Conflicts,
+		TempFilesDelta:      curr.TempFiles - base.TempFiles,
+		TempBytesDelta:      curr.TempBytes - base.TempBytes,
+	}
+	rates.TransactionsPerSecond = rates.CommitsPerSecond + rates.RollbacksPerSecond
+
+	hit := curr.BlocksHit - base.BlocksHit
+	read := curr.BlocksRead - base.BlocksRead
+	if hit+read > 0 {
+		rates.IntervalCacheHitRatio = float64(hit) / float64(hit+read) * 100
+	}
+
+	return rates
+}
+
+func statsResetChanged(prev, curr *time.Time) bool {
+	switch {
+	case prev == nil && curr == nil:
+		return false
+	case prev == nil || curr == nil:
+		return true
+	default:
+		return !prev.Equal(*curr)
+	}
+}
+
+func countersDecreased(prev, curr *models.PerformanceStats) bool {
+	return curr.XactCommit < prev.XactCommit ||
+		curr.XactRollback < prev.XactRollback ||
+		curr.TuplesReturned < prev.TuplesReturned ||
+		curr.TuplesFetched < prev.TuplesFetched ||
+		curr.TuplesInserted < prev.TuplesInserted ||
+		curr.TuplesUpdated < prev.TuplesUpdated ||
+		curr.TuplesDeleted < prev.TuplesDeleted ||
+		curr.BlocksRead < prev.BlocksRead ||
+		curr.BlocksHit < prev.BlocksHit ||
+		curr.Deadlocks < prev.Deadlocks ||
+		curr.Conflicts < prev.Conflicts ||
+		curr.TempFiles < prev.TempFiles ||
+		curr.TempBytes < prev.TempBytes
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// DefaultReplicationThresholds retorna os limites padrão de saúde da replicação
+func DefaultReplicationThresholds() models.ReplicationThresholds {
+	return models.ReplicationThresholds{
+		LagWarningSeconds:    30,
+		LagCriticalSeconds:   300,
+		LagWarningBytes:      1024 * 1024 * 1024,
+		SlotRetainedCritical: 10 * 1024 * 1024 * 1024,
+	}
+}
+
+// GetReplicationTopology retorna o papel da instância, seus standbys ou receptor de WAL,
+// os slots de replicação e a saúde de cada componente
+func (s *AnalyticsService) GetReplicationTopology(target string) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	topology, err := collectReplication(repo)
+	if err != nil {
+		return nil, collectorError("obter topologia de replicação", err)
+	}
+
+	thresholds := DefaultReplicationThresholds()
+	AssessReplication(topology, thresholds)
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Topologia de replicação obtida com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":       target,
+			"topology":     topology,
+			"thresholds":   thresholds,
+			"last_updated": time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// replicationSource é a parte do repositório de analytics usada para montar a topologia
+type replicationSource interface {
+	GetReplicationNode() (*models.ReplicationNode, error)
+	GetReplicationStandbys() ([]models.ReplicationStandby, error)
+	GetWalReceiver() (*models.WalReceiver, error)
+	GetReplicationSlots() ([]models.ReplicationSlot, error)
+}
+
+// collectReplication consulta papel, standbys, receptor de WAL e slots da instância
+func collectReplication(repo replicationSource) (*models.ReplicationTopology, error) {
+	node, err := repo.GetReplicationNode()
+	if err != nil {
+		return nil, err
+	}
+	standbys, err := repo.GetReplicationStandbys()
+	if err != nil {
+		return nil, err
+	}
+	slots, err := repo.GetReplicationSlots()
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &models.ReplicationTopology{
+		Role:     "primary",
+		Node:     node,
+		Standbys: standbys,
+		Slots:    slots,
+	}
+	if node.InRecovery {
+		topology.Role = "standby"
+		if topology.WalReceiver, err = repo.GetWalReceiver(); err != nil {
+			return nil, err
+		}
+	}
+
+	return topology, nil
+}
+
+// AssessReplication classifica a saúde de cada standby, do receptor de WAL e dos slots,
+// e define a saúde geral da topologia como o pior estado encontrado
+func AssessReplication(topology *models.ReplicationTopology, thresholds models.ReplicationThresholds) {
+	topology.Health = models.ReplicationHealthy
+	topology.Issues = []string{}
+	report := func(health, issue string) {
+		topology.Issues = append(topology.Issues, issue)
+		topology.Health = worseHealth(topology.Health, health)
+	}
+
+	for i := range topology.Standbys {
+		standby := &topology.Standbys[i]
+		standby.Health = models.ReplicationHealthy
+		standby.Issues = []string{}
+		flag := func(health, issue string) {
+			standby.Issues = append(standby.Issues, issue)
+			standby.Health = worseHealth(standby.Health, health)
+			report(health, fmt.Sprintf("standby %s: %s", standby.ApplicationName, issue))
+		}
+
+		if standby.State != "streaming" {
+			flag(models.ReplicationCritical, fmt.Sprintf("estado %s", standby.State))
+		}
+		if standby.ReplayLagMs != nil {
+			lag := *standby.ReplayLagMs / 1000
+			switch {
+			case lag >= thresholds.LagCriticalSeconds:
+				flag(models.ReplicationCritical, fmt.Sprintf("replay_lag de %.0fs", lag))
+			case lag >= thresholds.LagWarningSeconds:
+				flag(models.ReplicationWarning, fmt.Sprintf("replay_lag de %.0fs", lag))
+			}
+		}
+		if standby.ReplayLagBytes >= thresholds.LagWarningBytes {
+			flag(models.ReplicationWarning, fmt.Sprintf("%d bytes de WAL não aplicados", standby.ReplayLagBytes))
+		}
+	}
+
+	if topology.Role == "standby" {
+		receiver := topology.WalReceiver
+		switch {
+		case receiver == nil:
+			report(models.ReplicationCritical, "nenhum receptor de WAL ativo")
+		case receiver.Status != "streaming":
+			report(models.ReplicationCritical, fmt.Sprintf("receptor de WAL em estado %s", receiver.Status))
+		}
+
+		// Sem WAL pendente o atraso de aplicação só reflete um primary sem escritas
+		node := topology.Node
+		if node != nil && node.ReplayDelaySeconds != nil && node.ReplayLagBytes != nil && *node.ReplayLagBytes > 0 {
+			delay := *node.ReplayDelaySeconds
+			switch {
+			case delay >= thresholds.LagCriticalSeconds:
+				report(models.ReplicationCritical, fmt.Sprintf("aplicação de WAL atrasada em %.0fs", delay))
+			case delay >= thresholds.LagWarningSeconds:
+				report(models.ReplicationWarning, fmt.Sprintf("aplicação de WAL atrasada em %.0fs", delay))
+			}
+		}
+	}
+
+	for _, slot := range topology.Slots {
+		switch {
+		case slot.WALStatus != nil && *slot.WALStatus == "lost":
+			report(models.ReplicationCritical, fmt.Sprintf("slot %s perdeu WAL necessário", slot.SlotName))
+		case slot.RetainedWALBytes >= thresholds.SlotRetainedCritical:
+			report(models.ReplicationCritical, fmt.Sprintf("slot %s retém %d bytes de WAL", slot.SlotName, slot.RetainedWALBytes))
+		case !slot.Active:
+			report(models.ReplicationWarning, fmt.Sprintf("slot %s inativo retendo %d bytes de WAL", slot.SlotName, slot.RetainedWALBytes))
+		}
+	}
+}
+
+// replicationHealthOrder ordena os estados de saúde do melhor para o pior
+var replicationHealthOrder = map[string]int{
+	models.ReplicationHealthy:  0,
+	models.ReplicationWarning:  1,
+	models.ReplicationCritical: 2,
+}
+
+// worseHealth retorna o pior entre dois estados de saúde
+func worseHealth(a, b string) string {
+	if replicationHealthOrder[b] > replicationHealthOrder[a] {
+		return b
+	}
+	return a
+}
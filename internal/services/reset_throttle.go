@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+// ErrTooManyResetRequests indica excesso de solicitações de redefinição de senha vindas do mesmo IP
+var ErrTooManyResetRequests = errors.New("muitas solicitações de redefinição de senha, tente novamente mais tarde")
+
+// resetPolicy são os limites de solicitações lidos de system_config a cada uso
+type resetPolicy struct {
+	maxPerEmail int
+	maxPerIP    int
+	window      time.Duration
+}
+
+func (s *PasswordService) resetPolicy() resetPolicy {
+	policy := resetPolicy{
+		maxPerEmail: s.config.GetInt("auth.max_password_resets_per_email", 3),
+		maxPerIP:    s.config.GetInt("auth.max_password_resets_per_ip", 10),
+		window:      time.Duration(s.config.GetInt("auth.password_reset_window_minutes", 60)) * time.Minute,
+	}
+	if policy.window <= 0 {
+		policy.window = time.Hour
+	}
+	return policy
+}
+
+// checkIPThrottle bloqueia o IP que acumulou maxPerIP solicitações dentro da janela
+func (s *PasswordService) checkIPThrottle(policy resetPolicy, ip string) error {
+	if ip == "" || policy.maxPerIP <= 0 {
+		return nil
+	}
+
+	requests, oldest, err := s.audit.CountByIP(models.AuditActionPasswordResetRequested, ip, time.Now().Add(-policy.window))
+	if err != nil {
+		log.Printf("⚠️ Erro ao verificar solicitações de redefinição por IP: %v", err)
+		return nil
+	}
+	if requests < policy.maxPerIP || oldest == nil {
+		return nil
+	}
+
+	return &LockoutError{Err: ErrTooManyResetRequests, Until: oldest.Add(policy.window)}
+}
+
+// emailThrottled indica se o email já recebeu maxPerEmail solicitações dentro da janela.
+// O bloqueio por email não é informado ao cliente para não revelar quais contas existem.
+func (s *PasswordService) emailThrottled(policy resetPolicy, email string) bool {
+	if policy.maxPerEmail <= 0 {
+		return false
+	}
+
+	requests, _, err := s.audit.CountByEmail(models.AuditActionPasswordResetRequested, email, time.Now().Add(-policy.window))
+	if err != nil {
+		log.Printf("⚠️ Erro ao verificar solicitações de redefinição por email: %v", err)
+		return false
+	}
+	return requests >= policy.maxPerEmail
+}
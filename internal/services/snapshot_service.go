@@ -0,0 +1,387 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// SnapshotConfig define o comportamento do gravador de snapshots
+type SnapshotConfig struct {
+	Target         string        // Nome do target monitorado (vazio = banco local da API)
+	Interval       time.Duration // Intervalo entre coletas
+	RetentionDays  int           // Dias de retenção nas tabelas *_log (0 desativa a limpeza)
+	LogConnections bool          // Gravar backends individuais em pg_connections_log
+	LockWaitMs     float64       // Espera por lock (ms) que dispara a gravação da árvore de bloqueio (0 desativa)
+	StatementLimit int           // Entradas de pg_stat_statements gravadas por coleta (maior tempo total)
+}
+
+// DefaultSnapshotConfig lê a configuração padrão de system_config
+func DefaultSnapshotConfig(cfg *repositories.ConfigRepository) SnapshotConfig {
+	return SnapshotConfig{
+		Interval:       time.Duration(cfg.GetInt("monitoring.metrics_collection_interval_seconds", 60)) * time.Second,
+		RetentionDays:  cfg.GetInt("analytics.retention_days", 30),
+		LogConnections: cfg.GetBool("analytics.connection_log_enabled", true),
+		LockWaitMs:     float64(cfg.GetInt("analytics.lock_wait_snapshot_threshold_ms", 5000)),
+		StatementLimit: DefaultStatementLimit,
+	}
+}
+
+// SnapshotService coleta periodicamente as estatísticas e grava o histórico nas tabelas *_log
+type SnapshotService struct {
+	repo        *repositories.AnalyticsRepository
+	store       *repositories.SnapshotRepository
+	config      SnapshotConfig
+	rates       *RateTracker
+	checkpoints *CheckpointTracker
+	stop        chan struct{}
+	once        sync.Once
+}
+
+// NewSnapshotService cria um novo gravador de snapshots
+func NewSnapshotService(repo *repositories.AnalyticsRepository, store *repositories.SnapshotRepository, config SnapshotConfig) *SnapshotService {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.StatementLimit <= 0 {
+		config.StatementLimit = DefaultStatementLimit
+	}
+	return &SnapshotService{
+		repo:        repo,
+		store:       store,
+		config:      config,
+		rates:       NewRateTracker(),
+		checkpoints: NewCheckpointTracker(),
+		stop:        make(chan struct{}),
+	}
+}
+
+// PerformanceRates retorna as taxas de pg_stat_database entre as duas últimas coletas
+func (s *SnapshotService) PerformanceRates() *models.PerformanceRates {
+	return s.rates.Latest()
+}
+
+// CheckpointRates retorna as taxas de checkpoint e WAL entre as duas últimas coletas
+func (s *SnapshotService) CheckpointRates() *models.CheckpointRates {
+	return s.checkpoints.Latest()
+}
+
+// Start inicia a coleta em background
+func (s *SnapshotService) Start() {
+	go s.run()
+}
+
+// Stop interrompe a coleta em background
+func (s *SnapshotService) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *SnapshotService) run() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("📸 Gravador de snapshots iniciado para %s (intervalo %s)", s.label(), s.config.Interval)
+	s.CollectOnce()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.CollectOnce()
+		case <-s.stop:
+			log.Printf("📸 Gravador de snapshots finalizado para %s", s.label())
+			return
+		}
+	}
+}
+
+// CollectOnce executa todos os coletores e grava os resultados.
+// Falhas em um coletor são registradas e não impedem os demais.
+func (s *SnapshotService) CollectOnce() {
+	start := time.Now()
+
+	databaseName := ""
+	if size, err := s.repo.GetDatabaseSize(); err == nil && size != nil {
+		databaseName = size.DatabaseName
+	}
+
+	if statements, err := s.repo.GetStatements(models.StatementQuery{SortBy: models.StatementSortTotalTime, Limit: s.config.StatementLimit}); err != nil {
+		log.Printf("⚠️ Snapshot de queries lentas falhou: %v", err)
+	} else if err := s.store.InsertStatementSnapshots(s.config.Target, statements); err != nil {
+		log.Printf("⚠️ Erro ao gravar queries lentas: %v", err)
+	}
+
+	if tables, err := s.repo.GetTableSnapshots(); err != nil {
+		log.Printf("⚠️ Snapshot de tabelas falhou: %v", err)
+	} else if err := s.store.InsertTableStats(s.config.Target, databaseName, tables); err != nil {
+		log.Printf("⚠️ Erro ao gravar estatísticas de tabelas: %v", err)
+	}
+
+	if s.config.LogConnections {
+		if connections, err := s.repo.GetActiveConnections(); err != nil {
+			log.Printf("⚠️ Snapshot de conexões falhou: %v", err)
+		} else if err := s.store.InsertConnections(s.config.Target, connections); err != nil {
+			log.Printf("⚠️ Erro ao gravar conexões: %v", err)
+		}
+	}
+
+	if s.config.LockWaitMs > 0 {
+		s.collectLocks()
+	}
+
+	metrics := []models.SystemMetric{}
+	if stats, err := s.repo.GetConnectionStats(); err != nil {
+		log.Printf("⚠️ Snapshot de estatísticas de conexões falhou: %v", err)
+	} else {
+		metrics = append(metrics, connectionMetrics(databaseName, stats)...)
+	}
+	if stats, err := s.repo.GetPerformanceStats(); err != nil {
+		log.Printf("⚠️ Snapshot de performance falhou: %v", err)
+	} else {
+		metrics = append(metrics, performanceMetrics(databaseName, stats)...)
+		if rates := s.rates.Observe(stats); rates != nil && rates.IntervalSeconds > 0 {
+			metrics = append(metrics, rateMetrics(databaseName, rates)...)
+		}
+	}
+	metrics = append(metrics, s.vacuumMetrics(databaseName)...)
+	metrics = append(metrics, s.replicationMetrics(databaseName)...)
+	metrics = append(metrics, s.checkpointMetrics(databaseName)...)
+	if err := s.store.InsertSystemMetrics(s.config.Target, metrics); err != nil {
+		log.Printf("⚠️ Erro ao gravar métricas de sistema: %v", err)
+	}
+
+	if s.config.RetentionDays > 0 {
+		if purged, err := s.store.PurgeOlderThan(s.config.RetentionDays); err != nil {
+			log.Printf("⚠️ Erro ao limpar snapshots antigos: %v", err)
+		} else if purged > 0 {
+			log.Printf("🧹 %d snapshots antigos removidos", purged)
+		}
+	}
+
+	log.Printf("📸 Snapshot de %s gravado em %s", s.label(), time.Since(start).Round(time.Millisecond))
+}
+
+// collectLocks grava a árvore de bloqueio quando alguma espera excede o limite configurado
+func (s *SnapshotService) collectLocks() {
+	backends, err := s.repo.GetBlockingLocks()
+	if err != nil {
+		log.Printf("⚠️ Snapshot de locks falhou: %v", err)
+		return
+	}
+
+	tree := BuildLockTree(backends)
+	if tree.MaxWaitMs < s.config.LockWaitMs {
+		return
+	}
+
+	if err := s.store.InsertLockSnapshot(s.config.Target, tree); err != nil {
+		log.Printf("⚠️ Erro ao gravar árvore de bloqueio: %v", err)
+		return
+	}
+	log.Printf("🔒 Árvore de bloqueio de %s gravada: %d backends aguardando, maior espera %.0fms",
+		s.label(), tree.WaitingCount, tree.MaxWaitMs)
+}
+
+// vacuumMetrics coleta idade de XID, tabelas atrasadas para autovacuum e vacuums em execução
+func (s *SnapshotService) vacuumMetrics(databaseName string) []models.SystemMetric {
+	metrics := []models.SystemMetric{}
+
+	if databases, err := s.repo.GetDatabaseXIDAges(); err != nil {
+		log.Printf("⚠️ Snapshot de idade de XID falhou: %v", err)
+	} else {
+		metrics = append(metrics, xidAgeMetrics(databases)...)
+	}
+
+	if tables, err := s.repo.GetTableVacuumStatus(); err != nil {
+		log.Printf("⚠️ Snapshot de estado de vacuum falhou: %v", err)
+	} else {
+		metrics = append(metrics, vacuumStatusMetrics(databaseName, tables)...)
+	}
+
+	if progress, err := s.repo.GetVacuumProgress(); err != nil {
+		log.Printf("⚠️ Snapshot de vacuums em execução falhou: %v", err)
+	} else {
+		metrics = append(metrics, models.SystemMetric{MetricType: "vacuum", MetricName: "vacuums_running", MetricValue: float64(len(progress)), MetricUnit: "count", DatabaseName: databaseName})
+	}
+
+	return metrics
+}
+
+// xidAgeMetrics converte a idade de XID de cada banco em métricas
+func xidAgeMetrics(databases []models.DatabaseXIDAge) []models.SystemMetric {
+	metrics := make([]models.SystemMetric, 0, 2*len(databases))
+	for _, db := range databases {
+		risk := NewWraparoundRisk("database", db.DatabaseName, "", "", db.XIDAge, db.FreezeMaxAge)
+		metrics = append(metrics,
+			models.SystemMetric{MetricType: "vacuum", MetricName: "xid_age", MetricValue: float64(db.XIDAge), MetricUnit: "count", DatabaseName: db.DatabaseName},
+			models.SystemMetric{MetricType: "vacuum", MetricName: "xid_percent_to_freeze", MetricValue: risk.PercentToFreeze, MetricUnit: "percent", DatabaseName: db.DatabaseName},
+		)
+	}
+	return metrics
+}
+
+// vacuumStatusMetrics resume as tabelas atrasadas para autovacuum e o total de tuplas mortas
+func vacuumStatusMetrics(databaseName string, tables []models.TableVacuumStatus) []models.SystemMetric {
+	overdue := 0
+	var deadTuples int64
+	for _, t := range tables {
+		if t.Overdue {
+			overdue++
+		}
+		deadTuples += t.NDeadTup
+	}
+	return []models.SystemMetric{
+		{MetricType: "vacuum", MetricName: "tables_overdue_vacuum", MetricValue: float64(overdue), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "vacuum", MetricName: "dead_tuples", MetricValue: float64(deadTuples), MetricUnit: "count", DatabaseName: databaseName},
+	}
+}
+
+// replicationMetrics resume a topologia de replicação do banco monitorado
+func (s *SnapshotService) replicationMetrics(databaseName string) []models.SystemMetric {
+	topology, err := collectReplication(s.repo)
+	if err != nil {
+		log.Printf("⚠️ Snapshot de replicação falhou: %v", err)
+		return nil
+	}
+	return topologyMetrics(databaseName, topology)
+}
+
+// topologyMetrics converte a topologia em métricas: maior atraso, WAL não aplicado e slots
+func topologyMetrics(databaseName string, topology *models.ReplicationTopology) []models.SystemMetric {
+	var lagSeconds float64
+	var lagBytes, retained int64
+	for _, standby := range topology.Standbys {
+		if standby.ReplayLagMs != nil && *standby.ReplayLagMs/1000 > lagSeconds {
+			lagSeconds = *standby.ReplayLagMs / 1000
+		}
+		if standby.ReplayLagBytes > lagBytes {
+			lagBytes = standby.ReplayLagBytes
+		}
+	}
+	if node := topology.Node; node != nil && node.InRecovery && node.ReplayLagBytes != nil && *node.ReplayLagBytes > 0 {
+		lagBytes = *node.ReplayLagBytes
+		if node.ReplayDelaySeconds != nil {
+			lagSeconds = *node.ReplayDelaySeconds
+		}
+	}
+
+	inactive := 0
+	for _, slot := range topology.Slots {
+		if !slot.Active {
+			inactive++
+		}
+		if slot.RetainedWALBytes > retained {
+			retained = slot.RetainedWALBytes
+		}
+	}
+
+	return []models.SystemMetric{
+		{MetricType: "replication", MetricName: "replication_lag_seconds", MetricValue: lagSeconds, MetricUnit: "seconds", DatabaseName: databaseName},
+		{MetricType: "replication", MetricName: "replication_lag_bytes", MetricValue: float64(lagBytes), MetricUnit: "bytes", DatabaseName: databaseName},
+		{MetricType: "replication", MetricName: "replication_standbys", MetricValue: float64(len(topology.Standbys)), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "replication", MetricName: "replication_slots_inactive", MetricValue: float64(inactive), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "replication", MetricName: "replication_slot_retained_bytes", MetricValue: float64(retained), MetricUnit: "bytes", DatabaseName: databaseName},
+	}
+}
+
+// checkpointMetrics grava os contadores de checkpoint e WAL e as taxas desde a coleta anterior
+func (s *SnapshotService) checkpointMetrics(databaseName string) []models.SystemMetric {
+	stats, err := s.repo.GetCheckpointStats()
+	if err != nil {
+		log.Printf("⚠️ Snapshot de checkpoints falhou: %v", err)
+		return nil
+	}
+	return checkpointStatsMetrics(databaseName, stats, s.checkpoints.Observe(stats))
+}
+
+// checkpointStatsMetrics converte os contadores e as taxas (nil na primeira coleta) em métricas
+func checkpointStatsMetrics(databaseName string, stats *models.CheckpointStats, rates *models.CheckpointRates) []models.SystemMetric {
+	metric := func(name string, value float64, unit string) models.SystemMetric {
+		return models.SystemMetric{MetricType: "checkpoints", MetricName: name, MetricValue: value, MetricUnit: unit, DatabaseName: databaseName}
+	}
+	metrics := []models.SystemMetric{
+		metric("checkpoints_timed", float64(stats.CheckpointsTimed), "count"),
+		metric("checkpoints_requested", float64(stats.CheckpointsReq), "count"),
+		metric("wal_bytes", float64(stats.WALBytes), "bytes"),
+		metric("backend_fsyncs", float64(stats.BackendFsyncs), "count"),
+	}
+
+	if rates == nil || rates.CounterReset {
+		return metrics
+	}
+	metrics = append(metrics,
+		metric("wal_bytes_per_second", rates.WALBytesPerSecond, "bytes/s"),
+		metric("checkpoints_per_hour", rates.CheckpointsPerHour, "count/h"),
+		metric("checkpoints_requested_percent", rates.RequestedPercent, "percent"),
+		metric("buffers_checkpoint_per_second", rates.BuffersCheckpointPerSecond, "buffers/s"),
+		metric("buffers_clean_per_second", rates.BuffersCleanPerSecond, "buffers/s"),
+		metric("backend_fsyncs_delta", float64(rates.BackendFsyncsDelta), "count"),
+	)
+	if rates.WALFPIPerSecond != nil {
+		metrics = append(metrics, metric("wal_fpi_per_second", *rates.WALFPIPerSecond, "count/s"))
+	}
+	if rates.FPIPercent != nil {
+		metrics = append(metrics, metric("wal_fpi_percent", *rates.FPIPercent, "percent"))
+	}
+	if rates.BuffersBackendPerSecond != nil {
+		metrics = append(metrics, metric("buffers_backend_per_second", *rates.BuffersBackendPerSecond, "buffers/s"))
+	}
+
+	return metrics
+}
+
+// label identifica o target nos logs
+func (s *SnapshotService) label() string {
+	if s.config.Target == "" {
+		return "banco local"
+	}
+	return s.config.Target
+}
+
+// connectionMetrics converte ConnectionStats em linhas de system_metrics_log
+func connectionMetrics(databaseName string, stats *models.ConnectionStats) []models.SystemMetric {
+	percent := 0.0
+	if stats.MaxConnections > 0 {
+		percent = float64(stats.TotalConnections) / float64(stats.MaxConnections) * 100
+	}
+	return []models.SystemMetric{
+		{MetricType: "connections", MetricName: "total_connections", MetricValue: float64(stats.TotalConnections), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "connections", MetricName: "active_connections", MetricValue: float64(stats.ActiveConnections), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "connections", MetricName: "idle_connections", MetricValue: float64(stats.IdleConnections), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "connections", MetricName: "idle_in_transaction", MetricValue: float64(stats.IdleInTransaction), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "connections", MetricName: "max_connections", MetricValue: float64(stats.MaxConnections), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "connections", MetricName: "connections_percent", MetricValue: percent, MetricUnit: "percent", DatabaseName: databaseName},
+	}
+}
+
+// performanceMetrics converte PerformanceStats em linhas de system_metrics_log
+func performanceMetrics(databaseName string, stats *models.PerformanceStats) []models.SystemMetric {
+	return []models.SystemMetric{
+		{MetricType: "performance", MetricName: "cache_hit_ratio", MetricValue: stats.CacheHitRatio, MetricUnit: "percent", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "tuples_returned", MetricValue: float64(stats.TuplesReturned), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "tuples_fetched", MetricValue: float64(stats.TuplesFetched), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "tuples_inserted", MetricValue: float64(stats.TuplesInserted), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "tuples_updated", MetricValue: float64(stats.TuplesUpdated), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "tuples_deleted", MetricValue: float64(stats.TuplesDeleted), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "conflicts", MetricValue: float64(stats.Conflicts), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "temp_files", MetricValue: float64(stats.TempFiles), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "performance", MetricName: "deadlocks", MetricValue: float64(stats.Deadlocks), MetricUnit: "count", DatabaseName: databaseName},
+	}
+}
+
+// rateMetrics converte PerformanceRates em linhas de system_metrics_log
+func rateMetrics(databaseName string, rates *models.PerformanceRates) []models.SystemMetric {
+	return []models.SystemMetric{
+		{MetricType: "rates", MetricName: "tps", MetricValue: rates.TransactionsPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "commits_per_second", MetricValue: rates.CommitsPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "rollbacks_per_second", MetricValue: rates.RollbacksPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "inserts_per_second", MetricValue: rates.InsertsPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "updates_per_second", MetricValue: rates.UpdatesPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "deletes_per_second", MetricValue: rates.DeletesPerSecond, MetricUnit: "per_second", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "interval_cache_hit_ratio", MetricValue: rates.IntervalCacheHitRatio, MetricUnit: "percent", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "deadlocks_delta", MetricValue: float64(rates.DeadlocksDelta), MetricUnit: "count", DatabaseName: databaseName},
+		{MetricType: "rates", MetricName: "temp_files_delta", MetricValue: float64(rates.TempFilesDelta), MetricUnit: "count", DatabaseName: databaseName},
+	}
+}
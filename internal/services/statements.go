@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pganalytics-backend/internal/models"
+)
+
+const (
+	// DefaultStatementLimit é o tamanho padrão da página do explorador
+	DefaultStatementLimit = 50
+	// MaxStatementLimit é o maior tamanho de página aceito
+	MaxStatementLimit = 500
+)
+
+// ErrStatementNotFound indica que o queryid não está em pg_stat_statements
+var ErrStatementNotFound = errors.New("queryid não encontrado em pg_stat_statements")
+
+// GetStatements retorna uma página do explorador de pg_stat_statements.
+// cursor é o next_cursor da página anterior (vazio para a primeira página).
+func (s *AnalyticsService) GetStatements(target string, q models.StatementQuery, cursor string) (*models.AnalyticsResponse, error) {
+	if q.SortBy == "" {
+		q.SortBy = models.StatementSortTotalTime
+	}
+	if !validStatementSort(q.SortBy) {
+		return nil, fmt.Errorf("%w: 'sort' deve ser um de %v", ErrInvalidAnalyticsQuery, models.StatementSortColumns)
+	}
+	if q.Limit <= 0 || q.Limit > MaxStatementLimit {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxStatementLimit)
+	}
+	if cursor != "" {
+		decoded, err := DecodeStatementCursor(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 'cursor' inválido", ErrInvalidAnalyticsQuery)
+		}
+		q.Cursor = decoded
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uma linha a mais indica que existe próxima página
+	limit := q.Limit
+	q.Limit++
+	statements, err := repo.GetStatements(q)
+	if err != nil {
+		return nil, collectorError("consultar pg_stat_statements", err)
+	}
+
+	nextCursor := ""
+	if len(statements) > limit {
+		statements = statements[:limit]
+		nextCursor = EncodeStatementCursor(statements[limit-1])
+	}
+
+	order := "desc"
+	if q.Ascending {
+		order = "asc"
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Estatísticas de queries obtidas com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":       target,
+			"statements":   statements,
+			"count":        len(statements),
+			"sort":         q.SortBy,
+			"order":        order,
+			"next_cursor":  nextCursor,
+			"last_updated": time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// GetStatement retorna o texto completo e todos os contadores de um queryid,
+// com uma entrada por banco, usuário e nível de execução
+func (s *AnalyticsService) GetStatement(target string, queryID int64) (*models.AnalyticsResponse, error) {
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := repo.GetStatement(queryID)
+	if err != nil {
+		return nil, collectorError("consultar pg_stat_statements", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrStatementNotFound, queryID)
+	}
+
+	totals := models.StatementStats{QueryID: queryID, Query: entries[0].Query}
+	for _, e := range entries {
+		totals.Calls += e.Calls
+		totals.TotalTimeMs += e.TotalTimeMs
+		totals.Rows += e.Rows
+		totals.SharedBlksHit += e.SharedBlksHit
+		totals.SharedBlksRead += e.SharedBlksRead
+		totals.TempBlksRead += e.TempBlksRead
+		totals.TempBlksWritten += e.TempBlksWritten
+	}
+	if totals.Calls > 0 {
+		totals.MeanTimeMs = totals.TotalTimeMs / float64(totals.Calls)
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Detalhes da query obtidos com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":  target,
+			"queryid": queryID,
+			"query":   entries[0].Query,
+			"entries": entries,
+			"totals": map[string]interface{}{
+				"calls":             totals.Calls,
+				"total_time_ms":     totals.TotalTimeMs,
+				"mean_time_ms":      totals.MeanTimeMs,
+				"rows":              totals.Rows,
+				"shared_blks_hit":   totals.SharedBlksHit,
+				"shared_blks_read":  totals.SharedBlksRead,
+				"temp_blks_read":    totals.TempBlksRead,
+				"temp_blks_written": totals.TempBlksWritten,
+			},
+			"last_updated": time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// EncodeStatementCursor gera o cursor opaco que continua a listagem após a linha indicada
+func EncodeStatementCursor(last models.StatementStats) string {
+	raw, _ := json.Marshal(models.StatementCursor{
+		SortValue:  last.SortValue,
+		QueryID:    last.QueryID,
+		DatabaseID: last.DatabaseID,
+		UserID:     last.UserID,
+		TopLevel:   last.TopLevel,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeStatementCursor lê um cursor gerado por EncodeStatementCursor
+func DecodeStatementCursor(cursor string) (*models.StatementCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded models.StatementCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	if _, err := strconv.ParseFloat(decoded.SortValue, 64); err != nil {
+		return nil, fmt.Errorf("valor de ordenação inválido no cursor: %w", err)
+	}
+	return &decoded, nil
+}
+
+func validStatementSort(sort string) bool {
+	for _, column := range models.StatementSortColumns {
+		if column == sort {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"pganalytics-backend/internal/database"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// ErrInvalidTarget indica dados inválidos no cadastro de um target
+var ErrInvalidTarget = errors.New("target inválido")
+
+// ErrTargetUnavailable indica que o target existe mas não foi possível conectar
+var ErrTargetUnavailable = errors.New("target indisponível")
+
+// TargetService gerencia o registro de instâncias monitoradas, suas conexões e coletores
+type TargetService struct {
+	repo     *repositories.TargetRepository
+	pool     *database.TargetPool
+	store    *repositories.SnapshotRepository
+	audit    *AuditService
+	snapshot SnapshotConfig
+
+	mu          sync.Mutex
+	collectors  map[string]*SnapshotService
+	generations map[string]int
+	catalogs    map[string]*repositories.QueryCatalog
+}
+
+// NewTargetService cria um novo serviço de targets.
+// snapshot é usado como base para os coletores de cada target (Target e Interval são sobrescritos).
+func NewTargetService(repo *repositories.TargetRepository, pool *database.TargetPool, store *repositories.SnapshotRepository, audit *AuditService, snapshot SnapshotConfig) *TargetService {
+	// A limpeza por retenção fica a cargo do gravador do banco local
+	snapshot.RetentionDays = 0
+	return &TargetService{
+		repo:        repo,
+		pool:        pool,
+		store:       store,
+		audit:       audit,
+		snapshot:    snapshot,
+		collectors:  make(map[string]*SnapshotService),
+		generations: make(map[string]int),
+		catalogs:    make(map[string]*repositories.QueryCatalog),
+	}
+}
+
+// List retorna todos os targets registrados
+func (s *TargetService) List() ([]models.Target, error) {
+	return s.repo.List()
+}
+
+// Get busca um target pelo ID ou nome
+func (s *TargetService) Get(idOrName string) (*models.Target, error) {
+	return s.repo.Find(idOrName)
+}
+
+// Create valida e registra um novo target, iniciando seu coletor
+func (s *TargetService) Create(req models.TargetRequest, actor models.AuditActor) (*models.Target, error) {
+	target := &models.Target{}
+	if err := applyTargetRequest(target, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(target); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionCreate, "target", target.ID, nil, target)
+	s.restartCollector(target)
+	return target, nil
+}
+
+// Update altera um target existente, reabrindo a conexão e o coletor
+func (s *TargetService) Update(id string, req models.TargetRequest, actor models.AuditActor) (*models.Target, error) {
+	target, err := s.repo.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *target
+
+	if err := applyTargetRequest(target, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(target); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionUpdate, "target", target.ID, before, target)
+
+	s.pool.Remove(target.ID)
+	s.restartCollector(target)
+	s.forgetCatalog(target.ID)
+	return target, nil
+}
+
+// Delete remove um target, fechando conexão e coletor
+func (s *TargetService) Delete(id string, actor models.AuditActor) error {
+	target, err := s.repo.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(target.ID); err != nil {
+		return err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionDelete, "target", target.ID, target, nil)
+
+	s.stopCollector(target.ID)
+	s.pool.Remove(target.ID)
+	s.forgetCatalog(target.ID)
+	return nil
+}
+
+// Connection retorna a conexão com o target identificado por ID ou nome
+func (s *TargetService) Connection(idOrName string) (*database.DB, *models.Target, error) {
+	target, err := s.repo.Find(idOrName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := s.pool.Get(target.ID, target.DSN, target.CredentialsRef)
+	if err != nil {
+		return nil, target, fmt.Errorf("%w: %v", ErrTargetUnavailable, err)
+	}
+
+	return db, target, nil
+}
+
+// AttachCatalog reaproveita no repositório o catálogo de consultas já detectado para o target.
+// A detecção é refeita após repositories.CatalogTTL e quando o target é alterado ou removido.
+func (s *TargetService) AttachCatalog(target *models.Target, repo *repositories.AnalyticsRepository) {
+	s.mu.Lock()
+	catalog, ok := s.catalogs[target.ID]
+	generation := s.generations[target.ID]
+	s.mu.Unlock()
+
+	if ok && !catalog.Expired() {
+		repo.SetCatalog(catalog)
+		return
+	}
+
+	catalog, err := repo.Catalog()
+	if err != nil {
+		log.Printf("⚠️ Erro ao detectar versão do target %s: %v", target.Name, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Não guarda o catálogo de uma conexão que o target alterado já substituiu
+	if s.generations[target.ID] == generation {
+		s.catalogs[target.ID] = catalog
+	}
+}
+
+// forgetCatalog descarta o catálogo do target, que pode apontar para outro servidor após a alteração
+func (s *TargetService) forgetCatalog(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.catalogs, id)
+}
+
+// PerformanceRates retorna as taxas de pg_stat_database calculadas pelo coletor do target,
+// ou nil se o coletor não está em execução
+func (s *TargetService) PerformanceRates(idOrName string) *models.PerformanceRates {
+	collector := s.collector(idOrName)
+	if collector == nil {
+		return nil
+	}
+	return collector.PerformanceRates()
+}
+
+// CheckpointRates retorna as taxas de checkpoint e WAL calculadas pelo coletor do target,
+// ou nil se o coletor não está em execução
+func (s *TargetService) CheckpointRates(idOrName string) *models.CheckpointRates {
+	collector := s.collector(idOrName)
+	if collector == nil {
+		return nil
+	}
+	return collector.CheckpointRates()
+}
+
+// collector retorna o coletor de snapshots em execução para o target, ou nil
+func (s *TargetService) collector(idOrName string) *SnapshotService {
+	target, err := s.repo.Find(idOrName)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.collectors[target.ID]
+}
+
+// Test verifica se é possível conectar ao target
+func (s *TargetService) Test(idOrName string) error {
+	db, _, err := s.Connection(idOrName)
+	if err != nil {
+		return err
+	}
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("%w: %v", ErrTargetUnavailable, err)
+	}
+	return nil
+}
+
+// StartCollectors inicia um gravador de snapshots para cada target habilitado
+func (s *TargetService) StartCollectors() {
+	targets, err := s.repo.ListEnabled()
+	if err != nil {
+		log.Printf("⚠️ Erro ao carregar targets monitorados: %v", err)
+		return
+	}
+
+	for i := range targets {
+		s.restartCollector(&targets[i])
+	}
+	log.Printf("🎯 %d targets monitorados", len(targets))
+}
+
+// StopCollectors interrompe todos os coletores de targets
+func (s *TargetService) StopCollectors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, collector := range s.collectors {
+		collector.Stop()
+		delete(s.collectors, id)
+	}
+	for id := range s.generations {
+		s.generations[id]++
+	}
+}
+
+// restartCollector interrompe o coletor atual e inicia outro em background, para que
+// a conexão com um target lento ou inacessível não bloqueie a requisição de cadastro
+func (s *TargetService) restartCollector(target *models.Target) {
+	generation := s.stopCollector(target.ID)
+	if !target.Enabled {
+		return
+	}
+
+	go s.startCollector(*target, generation)
+}
+
+// startCollector conecta ao target e registra o coletor, exceto se o target foi
+// alterado ou removido enquanto a conexão era aberta
+func (s *TargetService) startCollector(target models.Target, generation int) {
+	db, err := s.pool.Get(target.ID, target.DSN, target.CredentialsRef)
+	if err != nil {
+		log.Printf("⚠️ Coletor do target %s não iniciado: %v", target.Name, err)
+		return
+	}
+
+	config := s.snapshot
+	config.Target = target.Name
+	config.Interval = time.Duration(target.CollectionIntervalSeconds) * time.Second
+
+	collector := NewSnapshotService(repositories.NewAnalyticsRepository(db), s.store, config)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generations[target.ID] != generation {
+		return
+	}
+	s.collectors[target.ID] = collector
+	collector.Start()
+}
+
+// stopCollector interrompe o coletor do target e invalida inicializações ainda em andamento
+func (s *TargetService) stopCollector(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if collector, ok := s.collectors[id]; ok {
+		collector.Stop()
+		delete(s.collectors, id)
+	}
+	s.generations[id]++
+	return s.generations[id]
+}
+
+// applyTargetRequest valida a requisição e copia os campos para o target
+func applyTargetRequest(target *models.Target, req models.TargetRequest) error {
+	if err := database.ValidateDSN(req.DSN); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if database.DSNHasPassword(req.DSN) {
+		return fmt.Errorf("%w: o DSN não deve conter senha, use credentials_ref", ErrInvalidTarget)
+	}
+	if _, err := database.ResolveCredentials(req.CredentialsRef); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+
+	target.Name = req.Name
+	target.DSN = req.DSN
+	target.CredentialsRef = req.CredentialsRef
+	target.Labels = req.Labels
+	if target.Labels == nil {
+		target.Labels = models.Labels{}
+	}
+	target.CollectionIntervalSeconds = req.CollectionIntervalSeconds
+	if target.CollectionIntervalSeconds == 0 {
+		target.CollectionIntervalSeconds = 60
+	}
+	target.Enabled = true
+	if req.Enabled != nil {
+		target.Enabled = *req.Enabled
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// ErrInvalidUser indica uma operação de administração de usuários não permitida
+var ErrInvalidUser = errors.New("operação de usuário inválida")
+
+// ErrLastAdmin indica que a operação deixaria o sistema sem administradores ativos
+var ErrLastAdmin = errors.New("o sistema precisa de ao menos um administrador ativo")
+
+// UserService implementa a administração de usuários
+type UserService struct {
+	users     *repositories.UserRepository
+	tokens    *repositories.RefreshTokenRepository
+	passwords *PasswordService
+	audit     *AuditService
+}
+
+// NewUserService cria um novo serviço de usuários
+func NewUserService(users *repositories.UserRepository, tokens *repositories.RefreshTokenRepository, passwords *PasswordService, audit *AuditService) *UserService {
+	return &UserService{
+		users:     users,
+		tokens:    tokens,
+		passwords: passwords,
+		audit:     audit,
+	}
+}
+
+// List retorna os usuários, opcionalmente filtrados por papel
+func (s *UserService) List(role string) ([]models.User, error) {
+	return s.users.List(role)
+}
+
+// Get busca um usuário pelo ID
+func (s *UserService) Get(id string) (*models.User, error) {
+	return s.users.FindByID(id)
+}
+
+// Create valida a senha inicial e cadastra o usuário
+func (s *UserService) Create(req models.UserCreateRequest, actor models.AuditActor) (*models.User, error) {
+	if err := ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar hash da senha: %w", err)
+	}
+
+	user := &models.User{
+		Email:        strings.TrimSpace(req.Email),
+		Name:         strings.TrimSpace(req.Name),
+		PasswordHash: string(hash),
+		Role:         req.Role,
+		IsActive:     true,
+	}
+	if err := s.users.Create(user); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionCreate, "user", user.ID, nil, user)
+	return user, nil
+}
+
+// Update altera email, nome e verificação de email
+func (s *UserService) Update(id string, req models.UserUpdateRequest, actor models.AuditActor) (*models.User, error) {
+	return s.change(id, actor, func(user *models.User) error {
+		if req.Email != nil {
+			user.Email = strings.TrimSpace(*req.Email)
+		}
+		if req.Name != nil {
+			user.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.EmailVerified != nil {
+			user.EmailVerified = *req.EmailVerified
+		}
+		return nil
+	})
+}
+
+// ChangeRole troca o papel do usuário. As sessões são encerradas para que o novo papel
+// valha no próximo login em vez de esperar a expiração dos tokens.
+func (s *UserService) ChangeRole(id, role string, actor models.AuditActor) (*models.User, error) {
+	user, err := s.change(id, actor, func(user *models.User) error {
+		if user.Role == models.RoleAdmin && role != models.RoleAdmin {
+			if err := s.ensureAnotherAdmin(user); err != nil {
+				return err
+			}
+		}
+		user.Role = role
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.revokeSessions(user)
+	return user, nil
+}
+
+// SetActive ativa ou desativa o usuário. Desativar encerra todas as sessões.
+func (s *UserService) SetActive(id string, active bool, actor models.AuditActor) (*models.User, error) {
+	user, err := s.change(id, actor, func(user *models.User) error {
+		if !active {
+			if user.ID == actor.UserID {
+				return fmt.Errorf("%w: não é possível desativar a própria conta", ErrInvalidUser)
+			}
+			if err := s.ensureAnotherAdmin(user); err != nil {
+				return err
+			}
+		}
+		user.IsActive = active
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !active {
+		s.revokeSessions(user)
+	}
+	return user, nil
+}
+
+// Delete remove o usuário. O histórico de auditoria é mantido com user_id nulo.
+func (s *UserService) Delete(id string, actor models.AuditActor) error {
+	user, err := s.users.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if user.ID == actor.UserID {
+		return fmt.Errorf("%w: não é possível remover a própria conta", ErrInvalidUser)
+	}
+	if err := s.ensureAnotherAdmin(user); err != nil {
+		return err
+	}
+
+	if err := s.users.Delete(user.ID); err != nil {
+		return err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionDelete, "user", user.ID, user, nil)
+	return nil
+}
+
+// ForceReset invalida a senha atual, encerra as sessões e envia um link de redefinição
+func (s *UserService) ForceReset(id string, actor models.AuditActor) error {
+	user, err := s.users.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.passwords.ForceReset(user, actor)
+}
+
+// change aplica mutate a uma cópia do usuário, grava e audita o diff
+func (s *UserService) change(id string, actor models.AuditActor, mutate func(*models.User) error) (*models.User, error) {
+	user, err := s.users.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+
+	if err := mutate(user); err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordChange(actor, models.AuditActionUpdate, "user", user.ID, before, user)
+	return user, nil
+}
+
+// ensureAnotherAdmin impede remover, desativar ou rebaixar o último administrador ativo
+func (s *UserService) ensureAnotherAdmin(user *models.User) error {
+	if user.Role != models.RoleAdmin || !user.IsActive {
+		return nil
+	}
+
+	admins, err := s.users.CountActiveAdmins()
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func (s *UserService) revokeSessions(user *models.User) {
+	if _, err := s.tokens.RevokeAllForUser(user.ID); err != nil {
+		log.Printf("⚠️ Erro ao encerrar sessões de %s: %v", user.Email, err)
+	}
+}
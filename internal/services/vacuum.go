@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"pganalytics-backend/internal/models"
+	"pganalytics-backend/internal/repositories"
+)
+
+// DefaultVacuumLimit é a quantidade de tabelas retornada quando 'limit' não é informado
+const DefaultVacuumLimit = 50
+
+// MaxVacuumLimit limita a quantidade de tabelas retornadas pelos endpoints de vacuum
+const MaxVacuumLimit = 1000
+
+// GetVacuumStatus retorna a idade de XID dos bancos, as tabelas com mais tuplas mortas
+// (as atrasadas para autovacuum primeiro) e os vacuums em execução
+func (s *AnalyticsService) GetVacuumStatus(target string, limit int) (*models.AnalyticsResponse, error) {
+	if limit <= 0 || limit > MaxVacuumLimit {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxVacuumLimit)
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	databases, err := repo.GetDatabaseXIDAges()
+	if err != nil {
+		return nil, collectorError("obter idade de XID dos bancos", err)
+	}
+	tables, err := repo.GetTableVacuumStatus()
+	if err != nil {
+		return nil, collectorError("obter estado de vacuum das tabelas", err)
+	}
+	unsupported := []string{}
+	progress, err := repo.GetVacuumProgress()
+	if errors.Is(err, repositories.ErrCollectorUnsupported) {
+		unsupported = append(unsupported, err.Error())
+		progress = []models.VacuumProgress{}
+	} else if err != nil {
+		return nil, collectorError("obter vacuums em execução", err)
+	}
+
+	RankVacuumStatus(tables)
+	overdue := 0
+	for _, t := range tables {
+		if t.Overdue {
+			overdue++
+		}
+	}
+	if len(tables) > limit {
+		tables = tables[:limit]
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Estado de vacuum obtido com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":         target,
+			"databases":      databases,
+			"tables":         tables,
+			"overdue_tables": overdue,
+			"running":        progress,
+			"unsupported":    unsupported,
+			"last_updated":   time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// GetWraparoundRisk retorna bancos e tabelas ordenados pela distância até autovacuum_freeze_max_age
+func (s *AnalyticsService) GetWraparoundRisk(target string, limit int) (*models.AnalyticsResponse, error) {
+	if limit <= 0 || limit > MaxVacuumLimit {
+		return nil, fmt.Errorf("%w: 'limit' deve estar entre 1 e %d", ErrInvalidAnalyticsQuery, MaxVacuumLimit)
+	}
+
+	repo, err := s.repoFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	databases, err := repo.GetDatabaseXIDAges()
+	if err != nil {
+		return nil, collectorError("obter idade de XID dos bancos", err)
+	}
+	tables, err := repo.GetTableVacuumStatus()
+	if err != nil {
+		return nil, collectorError("obter estado de vacuum das tabelas", err)
+	}
+
+	databaseRisks := make([]models.WraparoundRisk, 0, len(databases))
+	for _, db := range databases {
+		databaseRisks = append(databaseRisks, NewWraparoundRisk("database", db.DatabaseName, "", "", db.XIDAge, db.FreezeMaxAge))
+	}
+	tableRisks := make([]models.WraparoundRisk, 0, len(tables))
+	for _, t := range tables {
+		tableRisks = append(tableRisks, NewWraparoundRisk("table", t.DatabaseName, t.SchemaName, t.TableName, t.XIDAge, t.FreezeMaxAge))
+	}
+
+	RankWraparoundRisk(databaseRisks)
+	RankWraparoundRisk(tableRisks)
+	if len(tableRisks) > limit {
+		tableRisks = tableRisks[:limit]
+	}
+
+	return &models.AnalyticsResponse{
+		Success:     true,
+		Message:     "Risco de wraparound obtido com sucesso",
+		Timestamp:   time.Now().Unix(),
+		Environment: getEnvironment(),
+		Data: map[string]interface{}{
+			"target":       target,
+			"databases":    databaseRisks,
+			"tables":       tableRisks,
+			"last_updated": time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// NewWraparoundRisk calcula a distância de um objeto até o autovacuum anti-wraparound
+// e até o limite em que o PostgreSQL para de aceitar escritas
+func NewWraparoundRisk(objectType, databaseName, schemaName, tableName string, xidAge, freezeMaxAge int64) models.WraparoundRisk {
+	risk := models.WraparoundRisk{
+		ObjectType:        objectType,
+		DatabaseName:      databaseName,
+		SchemaName:        schemaName,
+		TableName:         tableName,
+		XIDAge:            xidAge,
+		FreezeMaxAge:      freezeMaxAge,
+		RemainingXIDs:     freezeMaxAge - xidAge,
+		PercentWraparound: roundPercent(float64(xidAge) / models.MaxTransactionAge * 100),
+	}
+	if freezeMaxAge > 0 {
+		risk.PercentToFreeze = roundPercent(float64(xidAge) / float64(freezeMaxAge) * 100)
+	}
+	return risk
+}
+
+// RankWraparoundRisk ordena pelo menor número de transações restantes até autovacuum_freeze_max_age
+func RankWraparoundRisk(risks []models.WraparoundRisk) {
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].RemainingXIDs != risks[j].RemainingXIDs {
+			return risks[i].RemainingXIDs < risks[j].RemainingXIDs
+		}
+		return risks[i].XIDAge > risks[j].XIDAge
+	})
+}
+
+// RankVacuumStatus ordena as tabelas atrasadas para autovacuum primeiro e depois por tuplas mortas
+func RankVacuumStatus(tables []models.TableVacuumStatus) {
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].Overdue != tables[j].Overdue {
+			return tables[i].Overdue
+		}
+		return tables[i].NDeadTup > tables[j].NDeadTup
+	})
+}
+
+// roundPercent arredonda um percentual para duas casas decimais
+func roundPercent(v float64) float64 {
+	return math.Round(v*100) / 100
+}
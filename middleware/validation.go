@@ -5,6 +5,7 @@ import (
     "regexp"
     "strings"
     "github.com/gin-gonic/gin"
+    "pganalytics-backend/pkg/validation"
 )
 
 // Input validation middleware
@@ -63,14 +64,5 @@ func isValidUsername(username string) bool {
 
 // Validate password strength
 func isValidPassword(password string) bool {
-    if len(password) < 8 || len(password) > 100 {
-        return false
-    }
-    
-    // Check for at least one uppercase, lowercase, digit
-    hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-    hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-    hasDigit := regexp.MustCompile(`[0-9]`).MatchString(password)
-    
-    return hasUpper && hasLower && hasDigit
+    return validation.IsValidPassword(password)
 }
@@ -0,0 +1,13 @@
+-- Remover coluna de origem dos snapshots
+DROP INDEX IF EXISTS idx_system_metrics_target;
+DROP INDEX IF EXISTS idx_table_stats_target;
+DROP INDEX IF EXISTS idx_slow_queries_target;
+DROP INDEX IF EXISTS idx_pg_connections_target;
+
+ALTER TABLE system_metrics_log DROP COLUMN IF EXISTS target_name;
+ALTER TABLE table_stats_log DROP COLUMN IF EXISTS target_name;
+ALTER TABLE slow_queries_log DROP COLUMN IF EXISTS target_name;
+ALTER TABLE pg_connections_log DROP COLUMN IF EXISTS target_name;
+
+-- Remover tabela de instâncias monitoradas
+DROP TABLE IF EXISTS monitored_targets;
@@ -0,0 +1,39 @@
+-- Criar tabela de instâncias PostgreSQL monitoradas
+CREATE TABLE IF NOT EXISTS monitored_targets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    dsn TEXT NOT NULL,
+    credentials_ref VARCHAR(255),
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    collection_interval_seconds INTEGER NOT NULL DEFAULT 60 CHECK (collection_interval_seconds >= 5),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_monitored_targets_enabled ON monitored_targets(enabled);
+CREATE INDEX IF NOT EXISTS idx_monitored_targets_labels ON monitored_targets USING GIN (labels);
+
+-- Trigger
+CREATE TRIGGER update_monitored_targets_updated_at 
+    BEFORE UPDATE ON monitored_targets 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Identificar a instância de origem nos snapshots (NULL = banco local da API)
+ALTER TABLE pg_connections_log ADD COLUMN IF NOT EXISTS target_name VARCHAR(100);
+ALTER TABLE slow_queries_log ADD COLUMN IF NOT EXISTS target_name VARCHAR(100);
+ALTER TABLE table_stats_log ADD COLUMN IF NOT EXISTS target_name VARCHAR(100);
+ALTER TABLE system_metrics_log ADD COLUMN IF NOT EXISTS target_name VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_pg_connections_target ON pg_connections_log(target_name);
+CREATE INDEX IF NOT EXISTS idx_slow_queries_target ON slow_queries_log(target_name);
+CREATE INDEX IF NOT EXISTS idx_table_stats_target ON table_stats_log(target_name);
+CREATE INDEX IF NOT EXISTS idx_system_metrics_target ON system_metrics_log(target_name);
+
+-- Comentários
+COMMENT ON TABLE monitored_targets IS 'Instâncias PostgreSQL monitoradas';
+COMMENT ON COLUMN monitored_targets.dsn IS 'DSN de conexão sem senha (URL ou key=value)';
+COMMENT ON COLUMN monitored_targets.credentials_ref IS 'Referência para a senha (env:VAR ou file:/caminho)';
+COMMENT ON COLUMN monitored_targets.labels IS 'Labels livres em formato JSON';
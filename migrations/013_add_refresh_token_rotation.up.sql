@@ -0,0 +1,20 @@
+-- Suporte a rotação de refresh tokens com detecção de reuso
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip_address INET;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS user_agent TEXT;
+
+-- Tokens existentes passam a formar sua própria família
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+-- Índices
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- Comentários
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'SHA-256 do refresh token (o valor em claro nunca é gravado)';
+COMMENT ON COLUMN refresh_tokens.family_id IS 'Cadeia de rotação iniciada em um login';
+COMMENT ON COLUMN refresh_tokens.replaced_by IS 'Token emitido na rotação deste token';
+COMMENT ON COLUMN refresh_tokens.revoked_at IS 'Data de revogação do token';
@@ -0,0 +1,9 @@
+-- Remover controle de tentativas de login
+DELETE FROM system_config WHERE config_key = 'auth.max_failed_login_attempts_per_ip';
+
+DROP INDEX IF EXISTS idx_audit_logs_ip_action;
+DROP INDEX IF EXISTS idx_users_last_login;
+
+ALTER TABLE users DROP COLUMN IF EXISTS account_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
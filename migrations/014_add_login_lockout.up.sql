@@ -0,0 +1,17 @@
+-- Controle de tentativas de login e bloqueio de conta
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS account_locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_users_last_login ON users(last_login_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_ip_action ON audit_logs(ip_address, action, created_at);
+
+-- Limite por IP de origem, contado sobre audit_logs na janela de bloqueio
+INSERT INTO system_config (config_key, config_value, config_type, description) VALUES
+('auth.max_failed_login_attempts_per_ip', '20', 'number', 'Máximo de tentativas de login falhadas por IP na janela de bloqueio')
+ON CONFLICT (config_key) DO NOTHING;
+
+-- Comentários
+COMMENT ON COLUMN users.failed_login_attempts IS 'Contador de tentativas de login falhadas';
+COMMENT ON COLUMN users.account_locked_until IS 'Data até quando a conta está bloqueada';
@@ -0,0 +1,4 @@
+-- Remover configuração do fluxo de redefinição de senha
+DROP INDEX IF EXISTS idx_password_resets_token_hash_unique;
+
+DELETE FROM system_config WHERE config_key = 'auth.password_reset_token_minutes';
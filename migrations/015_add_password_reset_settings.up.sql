@@ -0,0 +1,11 @@
+-- Configuração do fluxo de redefinição de senha
+INSERT INTO system_config (config_key, config_value, config_type, description) VALUES
+('auth.password_reset_token_minutes', '60', 'number', 'Minutos de validade do token de redefinição de senha')
+ON CONFLICT (config_key) DO NOTHING;
+
+-- Índices
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_resets_token_hash_unique ON password_resets(token_hash);
+
+-- Comentários
+COMMENT ON TABLE password_resets IS 'Tokens de redefinição de senha (apenas o hash SHA-256 é gravado)';
+COMMENT ON COLUMN password_resets.used_at IS 'Data de uso do token; tokens são de uso único';
@@ -0,0 +1,19 @@
+-- Restaurar view sem o filtro de usuários ativos
+CREATE OR REPLACE VIEW v_active_users AS
+SELECT 
+    u.id,
+    u.email,
+    u.name,
+    u.role,
+    u.last_login_at,
+    u.created_at,
+    COUNT(rt.id) as active_sessions
+FROM users u
+LEFT JOIN refresh_tokens rt ON u.id = rt.user_id 
+    AND rt.expires_at > NOW() 
+    AND rt.revoked_at IS NULL
+WHERE u.account_locked_until IS NULL OR u.account_locked_until < NOW()
+GROUP BY u.id, u.email, u.name, u.role, u.last_login_at, u.created_at;
+
+DROP INDEX IF EXISTS idx_users_is_active;
+ALTER TABLE users DROP COLUMN IF EXISTS is_active;
@@ -0,0 +1,26 @@
+-- Desativação de usuários sem remover o histórico
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
+
+-- Usuários desativados deixam de aparecer como ativos
+CREATE OR REPLACE VIEW v_active_users AS
+SELECT 
+    u.id,
+    u.email,
+    u.name,
+    u.role,
+    u.last_login_at,
+    u.created_at,
+    COUNT(rt.id) as active_sessions
+FROM users u
+LEFT JOIN refresh_tokens rt ON u.id = rt.user_id 
+    AND rt.expires_at > NOW() 
+    AND rt.revoked_at IS NULL
+WHERE u.is_active
+    AND (u.account_locked_until IS NULL OR u.account_locked_until < NOW())
+GROUP BY u.id, u.email, u.name, u.role, u.last_login_at, u.created_at;
+
+-- Comentários
+COMMENT ON COLUMN users.is_active IS 'Usuários desativados não podem autenticar';
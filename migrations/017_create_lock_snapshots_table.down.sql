@@ -0,0 +1,3 @@
+-- Remover tabela de snapshots de bloqueio
+DELETE FROM system_config WHERE config_key = 'analytics.lock_wait_snapshot_threshold_ms';
+DROP TABLE IF EXISTS lock_snapshots_log;
@@ -0,0 +1,24 @@
+-- Criar tabela para snapshots de árvores de bloqueio
+CREATE TABLE IF NOT EXISTS lock_snapshots_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_name VARCHAR(100),
+    max_wait_ms NUMERIC(15,2) NOT NULL,
+    waiting_count INTEGER NOT NULL,
+    blocker_count INTEGER NOT NULL,
+    tree JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_lock_snapshots_created_at ON lock_snapshots_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_lock_snapshots_target ON lock_snapshots_log(target_name);
+
+-- Configuração do limite de espera que dispara o snapshot
+INSERT INTO system_config (config_key, config_value, config_type, description) VALUES
+('analytics.lock_wait_snapshot_threshold_ms', '5000', 'number', 'Espera por lock (ms) a partir da qual a árvore de bloqueio é gravada (0 desativa)')
+ON CONFLICT (config_key) DO NOTHING;
+
+-- Comentários
+COMMENT ON TABLE lock_snapshots_log IS 'Árvores de bloqueio gravadas quando uma espera por lock excede o limite configurado';
+COMMENT ON COLUMN lock_snapshots_log.target_name IS 'Target de origem (NULL = banco local da API)';
+COMMENT ON COLUMN lock_snapshots_log.tree IS 'Árvore de bloqueio (bloqueadores raiz e backends em espera) em formato JSON';
@@ -0,0 +1,11 @@
+DELETE FROM system_config WHERE config_key IN (
+    'analytics.query_regression_factor',
+    'analytics.query_regression_dominant_percent',
+    'analytics.query_regression_window_minutes',
+    'analytics.query_regression_baseline_days'
+);
+DROP TABLE IF EXISTS alert_events;
+DROP INDEX IF EXISTS idx_slow_queries_hash_created_at;
+ALTER TABLE slow_queries_log DROP COLUMN IF EXISTS total_time_ms;
+ALTER TABLE slow_queries_log DROP COLUMN IF EXISTS calls;
+ALTER TABLE slow_queries_log DROP COLUMN IF EXISTS queryid;
@@ -0,0 +1,38 @@
+-- Contadores cumulativos de pg_stat_statements para calcular latência por intervalo
+ALTER TABLE slow_queries_log ADD COLUMN IF NOT EXISTS queryid BIGINT;
+ALTER TABLE slow_queries_log ADD COLUMN IF NOT EXISTS calls BIGINT;
+ALTER TABLE slow_queries_log ADD COLUMN IF NOT EXISTS total_time_ms DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_slow_queries_hash_created_at ON slow_queries_log(query_hash, created_at);
+
+-- Criar tabela de eventos de alerta
+CREATE TABLE IF NOT EXISTS alert_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_name VARCHAR(100),
+    source VARCHAR(50) NOT NULL,
+    event_key VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('info', 'warning', 'critical')),
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_alert_events_created_at ON alert_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_alert_events_key ON alert_events(source, event_key, created_at);
+CREATE INDEX IF NOT EXISTS idx_alert_events_target ON alert_events(target_name);
+
+-- Configuração do detector de regressões
+INSERT INTO system_config (config_key, config_value, config_type, description) VALUES
+('analytics.query_regression_factor', '2', 'number', 'Fator de aumento da latência média ou p95 em relação à linha de base que caracteriza regressão'),
+('analytics.query_regression_dominant_percent', '20', 'number', 'Percentual do tempo total na janela recente a partir do qual uma query nova é sinalizada'),
+('analytics.query_regression_window_minutes', '60', 'number', 'Tamanho da janela recente comparada com a linha de base (minutos)'),
+('analytics.query_regression_baseline_days', '7', 'number', 'Tamanho da linha de base anterior à janela recente (dias)')
+ON CONFLICT (config_key) DO NOTHING;
+
+-- Comentários
+COMMENT ON COLUMN slow_queries_log.calls IS 'calls cumulativo de pg_stat_statements no momento do snapshot';
+COMMENT ON COLUMN slow_queries_log.total_time_ms IS 'Tempo total cumulativo de pg_stat_statements no momento do snapshot';
+COMMENT ON TABLE alert_events IS 'Eventos de alerta gerados pelos detectores (ex: regressão de queries)';
+COMMENT ON COLUMN alert_events.event_key IS 'Chave de deduplicação do evento (ex: query_hash)';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_slow_queries_plans;
+ALTER TABLE slow_queries_log DROP COLUMN IF EXISTS plan_options;
@@ -0,0 +1,10 @@
+-- Opções do EXPLAIN usadas na captura sob demanda de planos
+ALTER TABLE slow_queries_log ADD COLUMN IF NOT EXISTS plan_options JSONB;
+
+-- Índice parcial para listar os planos capturados de uma query
+CREATE INDEX IF NOT EXISTS idx_slow_queries_plans ON slow_queries_log(query_hash, created_at DESC)
+    WHERE query_plan IS NOT NULL OR explain_analyze IS NOT NULL;
+
+-- Comentários
+COMMENT ON COLUMN slow_queries_log.explain_analyze IS 'Plano de execução com EXPLAIN ANALYZE (JSON)';
+COMMENT ON COLUMN slow_queries_log.plan_options IS 'Opções do EXPLAIN usadas na captura (analyze, buffers, generic_plan, timeout_ms)';
@@ -0,0 +1,5 @@
+ALTER TABLE alert_events DROP COLUMN IF EXISTS value;
+ALTER TABLE alert_events DROP COLUMN IF EXISTS state;
+DROP TABLE IF EXISTS alert_rule_states;
+DROP TRIGGER IF EXISTS update_alert_rules_updated_at ON alert_rules;
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,52 @@
+-- Criar tabela de regras de alerta avaliadas sobre system_metrics_log
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    target_name VARCHAR(100),
+    metric VARCHAR(50) NOT NULL,
+    condition VARCHAR(20) NOT NULL CHECK (condition IN ('threshold', 'rate_of_change')),
+    operator VARCHAR(2) NOT NULL CHECK (operator IN ('>', '>=', '<', '<=')),
+    threshold DOUBLE PRECISION NOT NULL,
+    window_seconds INTEGER NOT NULL DEFAULT 300 CHECK (window_seconds >= 60),
+    for_seconds INTEGER NOT NULL DEFAULT 0 CHECK (for_seconds >= 0),
+    severity VARCHAR(20) NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Estado corrente de cada regra
+CREATE TABLE IF NOT EXISTS alert_rule_states (
+    rule_id UUID PRIMARY KEY REFERENCES alert_rules(id) ON DELETE CASCADE,
+    state VARCHAR(20) NOT NULL CHECK (state IN ('inactive', 'pending', 'firing', 'resolved')),
+    value DOUBLE PRECISION,
+    active_since TIMESTAMP WITH TIME ZONE,
+    fired_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    evaluated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_alert_rules_enabled ON alert_rules(enabled);
+CREATE INDEX IF NOT EXISTS idx_alert_rule_states_state ON alert_rule_states(state);
+
+-- Trigger
+CREATE TRIGGER update_alert_rules_updated_at
+    BEFORE UPDATE ON alert_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Transições das regras são gravadas como eventos de alerta (histórico)
+ALTER TABLE alert_events ADD COLUMN IF NOT EXISTS state VARCHAR(20);
+ALTER TABLE alert_events ADD COLUMN IF NOT EXISTS value DOUBLE PRECISION;
+
+-- Comentários
+COMMENT ON TABLE alert_rules IS 'Regras de alerta por limite ou taxa de variação sobre as métricas coletadas';
+COMMENT ON COLUMN alert_rules.target_name IS 'Target avaliado (NULL = banco local da API)';
+COMMENT ON COLUMN alert_rules.metric IS 'Métrica avaliada (ex: connection_percent, cache_hit_ratio, deadlock_rate)';
+COMMENT ON COLUMN alert_rules.window_seconds IS 'Janela de amostras usada na avaliação';
+COMMENT ON COLUMN alert_rules.for_seconds IS 'Tempo em que a condição deve se manter antes de disparar';
+COMMENT ON TABLE alert_rule_states IS 'Estado corrente das regras de alerta (inactive, pending, firing, resolved)';
+COMMENT ON COLUMN alert_events.state IS 'Estado da regra de alerta na transição (firing ou resolved)';
+COMMENT ON COLUMN alert_events.value IS 'Valor da métrica na transição';
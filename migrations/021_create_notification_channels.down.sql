@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_notification_channels_updated_at ON notification_channels;
+DROP TABLE IF EXISTS notification_channels;
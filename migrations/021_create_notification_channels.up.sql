@@ -0,0 +1,33 @@
+-- Criar tabela de canais de notificação de alertas
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('webhook', 'slack', 'email', 'pagerduty')),
+    settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+    title_template TEXT NOT NULL DEFAULT '',
+    body_template TEXT NOT NULL DEFAULT '',
+    group_by TEXT[] NOT NULL DEFAULT ARRAY['target', 'source', 'event_key'],
+    min_severity VARCHAR(20) NOT NULL DEFAULT 'info' CHECK (min_severity IN ('info', 'warning', 'critical')),
+    group_wait_seconds INTEGER NOT NULL DEFAULT 30 CHECK (group_wait_seconds >= 0),
+    repeat_interval_seconds INTEGER NOT NULL DEFAULT 14400 CHECK (repeat_interval_seconds >= 0),
+    max_attempts INTEGER NOT NULL DEFAULT 5 CHECK (max_attempts BETWEEN 1 AND 20),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_notification_channels_enabled ON notification_channels(enabled);
+
+-- Trigger
+CREATE TRIGGER update_notification_channels_updated_at
+    BEFORE UPDATE ON notification_channels
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comentários
+COMMENT ON TABLE notification_channels IS 'Canais que recebem os eventos de alerta (webhook, slack, email, pagerduty)';
+COMMENT ON COLUMN notification_channels.settings IS 'Configuração do tipo de canal; segredos como referências env:VAR ou file:/caminho';
+COMMENT ON COLUMN notification_channels.group_by IS 'Campos de agrupamento dos eventos (target, source, event_key, severity)';
+COMMENT ON COLUMN notification_channels.group_wait_seconds IS 'Espera para acumular eventos do mesmo grupo antes de enviar';
+COMMENT ON COLUMN notification_channels.repeat_interval_seconds IS 'Janela em que uma notificação idêntica não é reenviada';
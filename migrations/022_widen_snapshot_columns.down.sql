@@ -0,0 +1,21 @@
+-- Restaurar os tipos originais; valores fora do intervalo são limitados
+DROP VIEW IF EXISTS v_latest_system_metrics;
+
+ALTER TABLE system_metrics_log ALTER COLUMN metric_value TYPE NUMERIC(15,6)
+    USING least(greatest(metric_value, -999999999.999999), 999999999.999999);
+ALTER TABLE pg_connections_log ALTER COLUMN connection_duration_ms TYPE INTEGER
+    USING least(connection_duration_ms, 2147483647);
+
+CREATE OR REPLACE VIEW v_latest_system_metrics AS
+SELECT DISTINCT ON (metric_type, metric_name, database_name)
+    metric_type,
+    metric_name,
+    metric_value,
+    metric_unit,
+    labels,
+    database_name,
+    created_at
+FROM system_metrics_log
+ORDER BY metric_type, metric_name, database_name, created_at DESC;
+
+COMMENT ON VIEW v_latest_system_metrics IS 'Métricas de sistema mais recentes';
@@ -0,0 +1,26 @@
+-- Contadores cumulativos (tuplas, WAL, idade de XID, bytes de replicação) ultrapassam
+-- NUMERIC(15,6) (~1e9) e uma única linha fora do intervalo descartava todo o lote
+DROP VIEW IF EXISTS v_latest_system_metrics;
+
+ALTER TABLE system_metrics_log ALTER COLUMN metric_value TYPE DOUBLE PRECISION;
+
+-- Conexões de poolers ficam abertas por semanas; INTEGER estoura após ~24,8 dias
+ALTER TABLE pg_connections_log ALTER COLUMN connection_duration_ms TYPE BIGINT;
+
+-- View de métricas de sistema mais recentes
+CREATE OR REPLACE VIEW v_latest_system_metrics AS
+SELECT DISTINCT ON (metric_type, metric_name, database_name)
+    metric_type,
+    metric_name,
+    metric_value,
+    metric_unit,
+    labels,
+    database_name,
+    created_at
+FROM system_metrics_log
+ORDER BY metric_type, metric_name, database_name, created_at DESC;
+
+-- Comentários
+COMMENT ON VIEW v_latest_system_metrics IS 'Métricas de sistema mais recentes';
+COMMENT ON COLUMN system_metrics_log.metric_value IS 'Valor da métrica; contadores cumulativos podem exceder 1e9';
+COMMENT ON COLUMN pg_connections_log.connection_duration_ms IS 'Tempo desde backend_start em milissegundos';
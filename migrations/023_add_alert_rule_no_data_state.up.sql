@@ -0,0 +1,8 @@
+-- Sem no_data_state uma regra disparada ficava em firing indefinidamente quando a métrica
+-- parava de ser coletada (target removido, réplica promovida)
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS no_data_state VARCHAR(20) NOT NULL DEFAULT 'ok'
+    CHECK (no_data_state IN ('ok', 'alerting', 'keep'));
+
+-- Comentários
+COMMENT ON COLUMN alert_rules.no_data_state IS 'Tratamento de janela sem amostras: ok (resolve), alerting (dispara) ou keep (mantém o estado)';
+COMMENT ON COLUMN alert_rule_states.value IS 'Último valor avaliado (NULL = janela sem amostras)';
@@ -0,0 +1,18 @@
+-- Notificações descartadas após esgotar as tentativas de entrega
+CREATE TABLE IF NOT EXISTS notification_failures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    channel_id UUID NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+    group_key TEXT NOT NULL,
+    dedup_key VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('firing', 'resolved')),
+    error TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_notification_failures_channel ON notification_failures(channel_id, created_at DESC);
+
+-- Comentários
+COMMENT ON TABLE notification_failures IS 'Notificações que não foram entregues ao canal após as retentativas';
+COMMENT ON COLUMN notification_failures.payload IS 'Notificação completa (alertas, labels, status), para consulta';
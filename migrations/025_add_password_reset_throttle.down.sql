@@ -0,0 +1,8 @@
+-- Remover limites de solicitações de redefinição de senha
+DROP INDEX IF EXISTS idx_audit_logs_action_email;
+
+DELETE FROM system_config WHERE config_key IN (
+    'auth.max_password_resets_per_email',
+    'auth.max_password_resets_per_ip',
+    'auth.password_reset_window_minutes'
+);
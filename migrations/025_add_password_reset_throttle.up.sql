@@ -0,0 +1,9 @@
+-- Limites de solicitações de redefinição de senha
+INSERT INTO system_config (config_key, config_value, config_type, description) VALUES
+('auth.max_password_resets_per_email', '3', 'number', 'Máximo de solicitações de redefinição de senha por email na janela'),
+('auth.max_password_resets_per_ip', '10', 'number', 'Máximo de solicitações de redefinição de senha por IP na janela'),
+('auth.password_reset_window_minutes', '60', 'number', 'Janela, em minutos, dos limites de solicitações de redefinição de senha')
+ON CONFLICT (config_key) DO NOTHING;
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_email ON audit_logs(action, lower(new_values->>'email'), created_at);
@@ -0,0 +1,22 @@
+// Package validation reúne as regras de validação compartilhadas entre a API e os serviços.
+package validation
+
+import "regexp"
+
+var (
+	passwordUpper = regexp.MustCompile(`[A-Z]`)
+	passwordLower = regexp.MustCompile(`[a-z]`)
+	passwordDigit = regexp.MustCompile(`[0-9]`)
+)
+
+// IsValidPassword aplica a política de senhas: 8 a 100 caracteres com ao menos
+// uma maiúscula, uma minúscula e um dígito
+func IsValidPassword(password string) bool {
+	if len(password) < 8 || len(password) > 100 {
+		return false
+	}
+
+	return passwordUpper.MatchString(password) &&
+		passwordLower.MatchString(password) &&
+		passwordDigit.MatchString(password)
+}
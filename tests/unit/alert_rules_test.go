@@ -0,0 +1,224 @@
+package unit
+
+import (
+    "database/sql/driver"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/middleware"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+const testRuleID = "7d1e6a2b-3c4f-4e5a-9b8c-2d3e4f5a6b7c"
+
+var (
+    alertRuleColumns = []string{"id", "name", "description", "target_name", "metric", "condition", "operator", "threshold",
+        "window_seconds", "for_seconds", "severity", "no_data_state", "enabled", "created_at", "updated_at"}
+    alertRuleStateColumns = []string{"rule_id", "state", "value", "active_since", "fired_at", "resolved_at", "evaluated_at"}
+)
+
+func newAlertRuleService(db *database.DB) *services.AlertRuleService {
+    return services.NewAlertRuleService(
+        repositories.NewAlertRuleRepository(db),
+        repositories.NewHistoryRepository(db),
+        services.NewAlertService(repositories.NewAlertRepository(db)),
+        nil,
+        services.NewAuditService(repositories.NewAuditRepository(db)),
+        time.Minute,
+    )
+}
+
+// storeFiringRule cadastra uma regra de replication_lag_seconds já disparada e sem amostras na janela
+func storeFiringRule(fake *fakeDB, noDataState string) {
+    firedAt := time.Now().Add(-10 * time.Minute)
+    fake.on("FROM alert_rules WHERE enabled", alertRuleColumns,
+        []driver.Value{testRuleID, "lag-replica", "", nil, "replication_lag_seconds", "threshold", ">", 30.0,
+            int64(300), int64(0), "critical", noDataState, true, time.Now(), time.Now()})
+    fake.on("FROM alert_rule_states", alertRuleStateColumns,
+        []driver.Value{testRuleID, models.AlertStateFiring, 120.0, firedAt, firedAt, nil, time.Now().Add(-time.Minute)})
+    fake.on("INSERT INTO alert_events", []string{"id", "created_at"}, []driver.Value{int64(1), time.Now()})
+}
+
+func samplesEvery(start time.Time, step time.Duration, values ...float64) []models.MetricSample {
+    samples := make([]models.MetricSample, len(values))
+    for i, v := range values {
+        samples[i] = models.MetricSample{At: start.Add(time.Duration(i) * step), Value: v}
+    }
+    return samples
+}
+
+func TestAlertRuleValue_Threshold(t *testing.T) {
+    rule := models.AlertRule{Metric: "connection_percent", Condition: models.AlertConditionThreshold, WindowSeconds: 300}
+    samples := samplesEvery(time.Now(), time.Minute, 40, 70, 85)
+
+    value, ok := services.AlertRuleValue(rule, samples)
+
+    require.True(t, ok)
+    assert.Equal(t, 85.0, value)
+
+    _, ok = services.AlertRuleValue(rule, nil)
+    assert.False(t, ok)
+}
+
+func TestAlertRuleValue_CounterIsRatePerMinute(t *testing.T) {
+    // deadlocks_delta somado na janela de 5 minutos
+    rule := models.AlertRule{Metric: "deadlock_rate", Condition: models.AlertConditionThreshold, WindowSeconds: 300}
+    samples := samplesEvery(time.Now(), time.Minute, 2, 0, 3, 0, 5)
+
+    value, ok := services.AlertRuleValue(rule, samples)
+
+    require.True(t, ok)
+    assert.InDelta(t, 2, value, 0.0001)
+}
+
+func TestAlertRuleValue_RateOfChange(t *testing.T) {
+    rule := models.AlertRule{Metric: "replication_lag_seconds", Condition: models.AlertConditionRateOfChange, WindowSeconds: 600}
+    samples := samplesEvery(time.Now(), 2*time.Minute, 10, 30, 50)
+
+    value, ok := services.AlertRuleValue(rule, samples)
+
+    require.True(t, ok)
+    assert.InDelta(t, 10, value, 0.0001)
+
+    _, ok = services.AlertRuleValue(rule, samples[:1])
+    assert.False(t, ok, "uma amostra não define variação")
+}
+
+func TestAlertConditionMet(t *testing.T) {
+    assert.True(t, services.AlertConditionMet(">", 81, 80))
+    assert.False(t, services.AlertConditionMet(">", 80, 80))
+    assert.True(t, services.AlertConditionMet(">=", 80, 80))
+    assert.True(t, services.AlertConditionMet("<", 89.9, 90))
+    assert.True(t, services.AlertConditionMet("<=", 90, 90))
+    assert.False(t, services.AlertConditionMet("==", 90, 90))
+}
+
+func TestNextAlertState_PendingThenFiringThenResolved(t *testing.T) {
+    start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+    forDuration := 2 * time.Minute
+    state := models.AlertRuleState{RuleID: "r1"}
+
+    state = services.NextAlertState(state, true, 90, forDuration, start)
+    assert.Equal(t, models.AlertStatePending, state.State)
+    require.NotNil(t, state.ActiveSince)
+    assert.Equal(t, start, *state.ActiveSince)
+
+    state = services.NextAlertState(state, true, 91, forDuration, start.Add(time.Minute))
+    assert.Equal(t, models.AlertStatePending, state.State, "ainda não cumpriu a duração")
+
+    state = services.NextAlertState(state, true, 92, forDuration, start.Add(2*time.Minute))
+    assert.Equal(t, models.AlertStateFiring, state.State)
+    require.NotNil(t, state.FiredAt)
+    assert.Equal(t, start.Add(2*time.Minute), *state.FiredAt)
+
+    state = services.NextAlertState(state, true, 93, forDuration, start.Add(3*time.Minute))
+    assert.Equal(t, models.AlertStateFiring, state.State)
+    assert.Equal(t, start.Add(2*time.Minute), *state.FiredAt, "disparo não é renovado enquanto firing")
+
+    state = services.NextAlertState(state, false, 50, forDuration, start.Add(4*time.Minute))
+    assert.Equal(t, models.AlertStateResolved, state.State)
+    require.NotNil(t, state.ResolvedAt)
+    assert.Nil(t, state.ActiveSince)
+    assert.Equal(t, 50.0, *state.Value)
+    assert.Equal(t, "r1", state.RuleID)
+}
+
+func TestNextAlertState_PendingCancelledAndImmediateFiring(t *testing.T) {
+    now := time.Now()
+
+    state := services.NextAlertState(models.AlertRuleState{}, true, 90, time.Minute, now)
+    assert.Equal(t, models.AlertStatePending, state.State)
+    state = services.NextAlertState(state, false, 10, time.Minute, now.Add(30*time.Second))
+    assert.Equal(t, models.AlertStateInactive, state.State)
+    assert.Nil(t, state.ActiveSince)
+    assert.Nil(t, state.FiredAt)
+
+    state = services.NextAlertState(models.AlertRuleState{State: models.AlertStateResolved}, true, 90, 0, now)
+    assert.Equal(t, models.AlertStateFiring, state.State, "sem duração mínima dispara na primeira avaliação")
+
+    state = services.NextAlertState(models.AlertRuleState{}, false, 10, time.Minute, now)
+    assert.Equal(t, models.AlertStateInactive, state.State)
+}
+
+func TestAlertMetrics_CoverAnalyticsValues(t *testing.T) {
+    names := map[string]bool{}
+    for _, m := range services.AlertMetrics() {
+        names[m.Name] = m.RateOfChange
+    }
+
+    for _, name := range []string{"connection_percent", "cache_hit_ratio", "deadlock_rate", "replication_lag_seconds", "wraparound_age"} {
+        _, ok := names[name]
+        assert.True(t, ok, name)
+    }
+    assert.False(t, names["deadlock_rate"], "deadlock_rate já é uma taxa")
+    assert.True(t, names["connection_percent"])
+}
+
+func TestManageAlertsPermission_AdminOnly(t *testing.T) {
+    assert.True(t, middleware.HasPermission(models.RoleAdmin, middleware.PermissionManageAlerts))
+    assert.False(t, middleware.HasPermission(models.RoleUser, middleware.PermissionManageAlerts))
+    assert.False(t, middleware.HasPermission(models.RoleReadonly, middleware.PermissionManageAlerts))
+}
+
+func TestEvaluate_NoDataResolvesFiringRuleByDefault(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeFiringRule(fake, models.AlertNoDataOK)
+
+    newAlertRuleService(db).Evaluate()
+
+    saves := fake.called("INSERT INTO alert_rule_states")
+    require.Len(t, saves, 1)
+    assert.Equal(t, models.AlertStateResolved, saves[0].args[1])
+    assert.Nil(t, saves[0].args[2], "janela sem amostras não tem valor")
+
+    events := fake.called("INSERT INTO alert_events")
+    require.Len(t, events, 1)
+    assert.Equal(t, models.AlertStateResolved, events[0].args[7])
+    assert.Nil(t, events[0].args[8])
+    assert.Contains(t, events[0].args[5], "sem amostras de replication_lag_seconds")
+}
+
+func TestEvaluate_NoDataAlertingFiresInactiveRule(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeFiringRule(fake, models.AlertNoDataAlerting)
+    fake.on("FROM alert_rule_states", alertRuleStateColumns)
+
+    newAlertRuleService(db).Evaluate()
+
+    saves := fake.called("INSERT INTO alert_rule_states")
+    require.Len(t, saves, 1)
+    assert.Equal(t, models.AlertStateFiring, saves[0].args[1])
+    events := fake.called("INSERT INTO alert_events")
+    require.Len(t, events, 1)
+    assert.Equal(t, models.AlertStateFiring, events[0].args[7])
+}
+
+func TestEvaluate_NoDataKeepLeavesStateUntouched(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeFiringRule(fake, models.AlertNoDataKeep)
+
+    newAlertRuleService(db).Evaluate()
+
+    assert.Empty(t, fake.called("INSERT INTO alert_rule_states"))
+    assert.Empty(t, fake.called("INSERT INTO alert_events"))
+}
+
+func TestCreateAlertRule_NoDataStateDefaultAndValidation(t *testing.T) {
+    fake, db := newFakeDB(t)
+    fake.on("INSERT INTO alert_rules", []string{"id", "created_at", "updated_at"}, []driver.Value{testRuleID, time.Now(), time.Now()})
+    threshold := 80.0
+    req := models.AlertRuleRequest{Name: "conexoes", Metric: "connection_percent", Operator: ">", Threshold: &threshold}
+
+    rule, err := newAlertRuleService(db).Create(req, models.AuditActor{UserID: testUserID})
+    require.NoError(t, err)
+    assert.Equal(t, models.AlertNoDataOK, rule.NoDataState)
+
+    req.NoDataState = "nodata"
+    _, err = newAlertRuleService(db).Create(req, models.AuditActor{UserID: testUserID})
+    assert.ErrorIs(t, err, services.ErrInvalidAlertRule)
+}
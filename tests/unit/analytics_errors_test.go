@@ -0,0 +1,53 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func TestAnalyticsService_NoDatabaseIsUnavailable(t *testing.T) {
+    service := services.NewAnalyticsService(repositories.NewAnalyticsRepository(nil), nil)
+
+    response, err := service.GetSlowQueries("")
+    assert.Nil(t, response)
+    assert.ErrorIs(t, err, services.ErrAnalyticsUnavailable)
+    assert.ErrorIs(t, err, repositories.ErrNoDatabase)
+
+    _, err = service.GetLocks("")
+    assert.ErrorIs(t, err, services.ErrAnalyticsUnavailable)
+
+    _, err = service.GetFullAnalytics("")
+    assert.ErrorIs(t, err, services.ErrAnalyticsUnavailable)
+}
+
+func TestAnalyticsService_DemoModeIsExplicit(t *testing.T) {
+    service := services.NewAnalyticsService(repositories.NewAnalyticsRepository(nil), nil)
+    service.SetDemoMode(true)
+
+    response, err := service.GetTableStats("")
+    require.NoError(t, err)
+    assert.Equal(t, models.DataSourceDemo, response.DataSource)
+    assert.NotEmpty(t, response.Data.(map[string]interface{})["tables"])
+
+    response, err = service.GetFullAnalytics("")
+    require.NoError(t, err)
+    assert.Equal(t, models.DataSourceDemo, response.DataSource)
+    assert.Empty(t, response.Data.(map[string]interface{})["errors"])
+
+    // Coletores sem dados fictícios continuam consultando o banco
+    _, err = service.GetLocks("")
+    assert.ErrorIs(t, err, services.ErrAnalyticsUnavailable)
+}
+
+func TestAnalyticsService_DemoModeDoesNotApplyToTargets(t *testing.T) {
+    service := services.NewAnalyticsService(repositories.NewAnalyticsRepository(nil), nil)
+    service.SetDemoMode(true)
+
+    _, err := service.GetSlowQueries("replica-1")
+    assert.ErrorIs(t, err, repositories.ErrTargetNotFound)
+}
@@ -0,0 +1,96 @@
+package unit
+
+import (
+    "net/http"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "pganalytics-backend/internal/middleware"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func TestAuditDiff_OnlyChangedFields(t *testing.T) {
+    before := models.Target{ID: "t1", Name: "orders", DSN: "postgres://a@db/orders", CollectionIntervalSeconds: 60, Enabled: true}
+    after := before
+    after.CollectionIntervalSeconds = 30
+    after.Enabled = false
+
+    oldValues, newValues, err := services.AuditDiff(before, after)
+
+    assert.NoError(t, err)
+    assert.Equal(t, models.AuditValues{"collection_interval_seconds": float64(60), "enabled": true}, oldValues)
+    assert.Equal(t, models.AuditValues{"collection_interval_seconds": float64(30), "enabled": false}, newValues)
+}
+
+func TestAuditDiff_CreateAndDelete(t *testing.T) {
+    target := &models.Target{ID: "t1", Name: "orders"}
+
+    oldValues, newValues, err := services.AuditDiff(nil, target)
+    assert.NoError(t, err)
+    assert.Nil(t, oldValues)
+    assert.Equal(t, "orders", newValues["name"])
+
+    oldValues, newValues, err = services.AuditDiff(target, nil)
+    assert.NoError(t, err)
+    assert.Equal(t, "orders", oldValues["name"])
+    assert.Nil(t, newValues)
+}
+
+func TestAuditDiff_NoChanges(t *testing.T) {
+    oldValues, newValues, err := services.AuditDiff(models.AuditValues{"a": 1}, models.AuditValues{"a": 1})
+
+    assert.NoError(t, err)
+    assert.Nil(t, oldValues)
+    assert.Nil(t, newValues)
+}
+
+type recordedAudit struct {
+    entries []*models.AuditLog
+}
+
+func (r *recordedAudit) Record(entry *models.AuditLog) {
+    r.entries = append(r.entries, entry)
+}
+
+func auditRouter(recorder *recordedAudit) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(middleware.AuditTrail(recorder), middleware.AuthMiddleware(rbacTestSecret, usersWithRole(models.RoleReadonly)))
+    router.GET("/targets", func(c *gin.Context) { c.Status(http.StatusOK) })
+    router.POST("/targets/:id/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+    router.PUT("/targets/:id", func(c *gin.Context) {
+        middleware.Actor(c)
+        c.Status(http.StatusOK)
+    })
+    router.DELETE("/targets/:id", middleware.RequirePermission(middleware.PermissionManageTargets), func(c *gin.Context) {
+        c.Status(http.StatusNoContent)
+    })
+    return router
+}
+
+func TestAuditTrail_RecordsDeniedAndUnauditedWrites(t *testing.T) {
+    recorder := &recordedAudit{}
+    router := auditRouter(recorder)
+    readonly := signedToken(t)
+
+    doRequest(router, http.MethodGet, "/targets", readonly)
+    doRequest(router, http.MethodPut, "/targets/prod", readonly)
+    doRequest(router, http.MethodGet, "/targets", "")
+    doRequest(router, http.MethodDelete, "/targets/prod", readonly)
+    doRequest(router, http.MethodPost, "/targets/prod/test", readonly)
+
+    if assert.Len(t, recorder.entries, 3) {
+        assert.Equal(t, models.AuditActionAccessDenied, recorder.entries[0].Action)
+        assert.Nil(t, recorder.entries[0].UserID)
+
+        assert.Equal(t, models.AuditActionAccessDenied, recorder.entries[1].Action)
+        assert.Equal(t, "DELETE /targets/prod", recorder.entries[1].ResourceID)
+        if assert.NotNil(t, recorder.entries[1].UserID) {
+            assert.Equal(t, "5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23", *recorder.entries[1].UserID)
+        }
+
+        assert.Equal(t, models.AuditActionRequest, recorder.entries[2].Action)
+    }
+}
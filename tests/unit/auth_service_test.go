@@ -0,0 +1,88 @@
+package unit
+
+import (
+    "crypto/sha256"
+    "database/sql/driver"
+    "encoding/hex"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+const (
+    testUserID   = "5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23"
+    testFamilyID = "0e4a1b7c-2f3d-4c5e-8a9b-1c2d3e4f5a6b"
+)
+
+var refreshTokenColumns = []string{"id", "user_id", "family_id", "token_hash", "expires_at", "revoked_at", "ip_address", "user_agent", "created_at"}
+
+func newAuthService(db *database.DB) *services.AuthService {
+    return services.NewAuthService(
+        repositories.NewUserRepository(db),
+        repositories.NewRefreshTokenRepository(db),
+        services.NewAuditService(repositories.NewAuditRepository(db)),
+        repositories.NewConfigRepository(db),
+        rbacTestSecret,
+        15*time.Minute,
+    )
+}
+
+// storeRefreshToken faz FindByHash devolver o token plain com a validade e revogação informadas
+func storeRefreshToken(fake *fakeDB, plain string, expiresAt time.Time, revokedAt interface{}) {
+    sum := sha256.Sum256([]byte(plain))
+    fake.on("FROM refresh_tokens WHERE token_hash", refreshTokenColumns,
+        []driver.Value{"a1", testUserID, testFamilyID, hex.EncodeToString(sum[:]), expiresAt, revokedAt, nil, "", time.Now()})
+}
+
+func TestLogout_RevokesOnlyTheSessionFamily(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeRefreshToken(fake, "valid-token", time.Now().Add(time.Hour), nil)
+
+    require.NoError(t, newAuthService(db).Logout("valid-token", false))
+
+    revokes := fake.called("UPDATE refresh_tokens SET revoked_at")
+    require.Len(t, revokes, 1)
+    assert.Contains(t, revokes[0].query, "family_id = $1")
+    assert.Equal(t, []driver.Value{testFamilyID}, revokes[0].args)
+}
+
+func TestLogout_AllSessionsRevokesEveryUserToken(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeRefreshToken(fake, "valid-token", time.Now().Add(time.Hour), nil)
+
+    require.NoError(t, newAuthService(db).Logout("valid-token", true))
+
+    revokes := fake.called("UPDATE refresh_tokens SET revoked_at")
+    require.Len(t, revokes, 1)
+    assert.Contains(t, revokes[0].query, "user_id = $1")
+    assert.Equal(t, []driver.Value{testUserID}, revokes[0].args)
+}
+
+func TestLogout_RejectsRevokedAndExpiredTokens(t *testing.T) {
+    cases := map[string]func(*fakeDB){
+        "revogado": func(fake *fakeDB) {
+            storeRefreshToken(fake, "leaked-token", time.Now().Add(time.Hour), time.Now().Add(-time.Minute))
+        },
+        "expirado": func(fake *fakeDB) {
+            storeRefreshToken(fake, "leaked-token", time.Now().Add(-time.Minute), nil)
+        },
+        "inexistente": func(*fakeDB) {},
+    }
+
+    for name, setup := range cases {
+        for _, allSessions := range []bool{false, true} {
+            fake, db := newFakeDB(t)
+            setup(fake)
+
+            err := newAuthService(db).Logout("leaked-token", allSessions)
+
+            assert.ErrorIs(t, err, services.ErrInvalidRefreshToken, name)
+            assert.Empty(t, fake.called("UPDATE refresh_tokens"), "%s: nenhuma sessão deve ser revogada", name)
+        }
+    }
+}
@@ -0,0 +1,35 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func TestRankBloat_ByReclaimableSpace(t *testing.T) {
+    estimates := []models.BloatEstimate{
+        {ObjectType: "table", TableName: "small", WastedBytes: 8192, BloatPercent: 90},
+        {ObjectType: "index", TableName: "orders", IndexName: "orders_pkey", WastedBytes: 1 << 30, BloatPercent: 40},
+        {ObjectType: "table", TableName: "events", WastedBytes: 1 << 20, BloatPercent: 10},
+        {ObjectType: "table", TableName: "logs", WastedBytes: 1 << 20, BloatPercent: 60},
+    }
+
+    services.RankBloat(estimates)
+
+    assert.Equal(t, "orders_pkey", estimates[0].IndexName)
+    assert.Equal(t, "logs", estimates[1].TableName)
+    assert.Equal(t, "events", estimates[2].TableName)
+    assert.Equal(t, "small", estimates[3].TableName)
+}
+
+func TestGetBloat_InvalidParameters(t *testing.T) {
+    service := services.NewAnalyticsService(nil, nil)
+
+    _, err := service.GetBloat("", "view", services.DefaultBloatLimit)
+    assert.ErrorIs(t, err, services.ErrInvalidAnalyticsQuery)
+
+    _, err = service.GetBloat("", "table", services.MaxBloatLimit+1)
+    assert.ErrorIs(t, err, services.ErrInvalidAnalyticsQuery)
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+    "database/sql/driver"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func TestCheckpointStatsQuery_VersionSources(t *testing.T) {
+    pg13 := repositories.CheckpointStatsQuery(130011)
+    assert.Contains(t, pg13, "pg_current_wal_lsn()")
+    assert.NotContains(t, pg13, "pg_stat_wal")
+
+    pg16 := repositories.CheckpointStatsQuery(160002)
+    assert.Contains(t, pg16, "pg_stat_wal")
+    assert.Contains(t, pg16, "buffers_backend_fsync")
+    assert.NotContains(t, pg16, "pg_stat_checkpointer")
+
+    pg17 := repositories.CheckpointStatsQuery(170000)
+    assert.Contains(t, pg17, "pg_stat_checkpointer")
+    assert.Contains(t, pg17, "pg_stat_io")
+    assert.NotContains(t, pg17, "b.checkpoints_timed")
+}
+
+func TestComputeCheckpointRates(t *testing.T) {
+    start := time.Now()
+    prev := &models.CheckpointStats{
+        CheckpointsTimed: 10, CheckpointsReq: 2, BuffersCheckpoint: 1000, BuffersClean: 100,
+        BuffersBackend: int64Ptr(50), BackendFsyncs: 0, WALBytes: 1 << 20,
+        WALRecords: int64Ptr(1000), WALFPI: int64Ptr(100), CollectedAt: start,
+    }
+    curr := &models.CheckpointStats{
+        CheckpointsTimed: 11, CheckpointsReq: 5, BuffersCheckpoint: 1600, BuffersClean: 160,
+        BuffersBackend: int64Ptr(110), BackendFsyncs: 3, WALBytes: 61 << 20,
+        WALRecords: int64Ptr(3000), WALFPI: int64Ptr(600), CollectedAt: start.Add(60 * time.Second),
+    }
+
+    rates := services.ComputeCheckpointRates(prev, curr)
+
+    require.NotNil(t, rates)
+    assert.False(t, rates.CounterReset)
+    assert.InDelta(t, 1<<20, rates.WALBytesPerSecond, 0.001)
+    assert.InDelta(t, 240, rates.CheckpointsPerHour, 0.001)
+    assert.InDelta(t, 75, rates.RequestedPercent, 0.001)
+    assert.InDelta(t, 10, rates.BuffersCheckpointPerSecond, 0.001)
+    require.NotNil(t, rates.BuffersBackendPerSecond)
+    assert.InDelta(t, 1, *rates.BuffersBackendPerSecond, 0.001)
+    require.NotNil(t, rates.FPIPercent)
+    assert.InDelta(t, 25, *rates.FPIPercent, 0.001)
+    assert.Equal(t, int64(3), rates.BackendFsyncsDelta)
+}
+
+func TestComputeCheckpointRates_WithoutPgStatWal(t *testing.T) {
+    start := time.Now()
+    prev := &models.CheckpointStats{WALBytes: 100, CollectedAt: start}
+    curr := &models.CheckpointStats{WALBytes: 200, CollectedAt: start.Add(10 * time.Second)}
+
+    rates := services.ComputeCheckpointRates(prev, curr)
+
+    require.NotNil(t, rates)
+    assert.InDelta(t, 10, rates.WALBytesPerSecond, 0.001)
+    assert.Nil(t, rates.WALFPIPerSecond)
+    assert.Nil(t, rates.FPIPercent)
+    assert.Nil(t, rates.BuffersBackendPerSecond)
+    assert.Zero(t, rates.RequestedPercent)
+}
+
+func TestComputeCheckpointRates_CounterReset(t *testing.T) {
+    start := time.Now()
+    prev := &models.CheckpointStats{CheckpointsTimed: 100, WALBytes: 5000, CollectedAt: start}
+    curr := &models.CheckpointStats{CheckpointsTimed: 1, WALBytes: 6000, CollectedAt: start.Add(time.Minute)}
+
+    rates := services.ComputeCheckpointRates(prev, curr)
+
+    require.NotNil(t, rates)
+    assert.True(t, rates.CounterReset)
+    assert.Zero(t, rates.WALBytesPerSecond)
+
+    assert.Nil(t, services.ComputeCheckpointRates(curr, curr))
+}
+
+func TestCheckpointTracker_Observe(t *testing.T) {
+    tracker := services.NewCheckpointTracker()
+    start := time.Now()
+
+    assert.Nil(t, tracker.Observe(&models.CheckpointStats{WALBytes: 0, CollectedAt: start}))
+    rates := tracker.Observe(&models.CheckpointStats{WALBytes: 500, CollectedAt: start.Add(5 * time.Second)})
+    require.NotNil(t, rates)
+    assert.InDelta(t, 100, rates.WALBytesPerSecond, 0.001)
+}
+
+func TestRequestedCheckpointPercent(t *testing.T) {
+    assert.Zero(t, services.RequestedCheckpointPercent(&models.CheckpointStats{}))
+    assert.InDelta(t, 20, services.RequestedCheckpointPercent(&models.CheckpointStats{CheckpointsTimed: 8, CheckpointsReq: 2}), 0.001)
+}
+
+// checkpointSample faz a consulta de checkpoints do fakeDB devolver os contadores informados
+func checkpointSample(fake *fakeDB, walBytes int64, collectedAt time.Time) {
+    fake.on("pg_stat_bgwriter", []string{"checkpoints_timed", "checkpoints_req", "wal_bytes", "wal_source", "collected_at"},
+        []driver.Value{int64(10), int64(2), walBytes, "pg_stat_wal", collectedAt})
+}
+
+func TestGetCheckpointStats_OnlyReadsCollectorRates(t *testing.T) {
+    fake, db := newFakeDB(t)
+    repo := repositories.NewAnalyticsRepository(db)
+    repo.SetCatalog(repositories.NewQueryCatalog(models.ServerCapabilities{ServerVersionNum: 160002}))
+    collector := services.NewSnapshotService(repo, repositories.NewSnapshotRepository(db), services.SnapshotConfig{})
+    service := services.NewAnalyticsService(repo, nil)
+    service.SetLocalCollector(collector)
+
+    start := time.Now().Add(-time.Hour)
+    checkpointSample(fake, 1_000_000, start)
+    for i := 0; i < 3; i++ {
+        response, err := service.GetCheckpointStats("")
+        require.NoError(t, err)
+        assert.Nil(t, response.Data.(map[string]interface{})["rates"], "GET não registra amostras")
+    }
+
+    collector.CollectOnce()
+    checkpointSample(fake, 61_000_000, start.Add(time.Minute))
+    collector.CollectOnce()
+
+    // Outras consultas não alteram as taxas do coletor
+    checkpointSample(fake, 9_000_000_000, start.Add(61*time.Second))
+    for i := 0; i < 2; i++ {
+        response, err := service.GetCheckpointStats("")
+        require.NoError(t, err)
+        rates, ok := response.Data.(map[string]interface{})["rates"].(*models.CheckpointRates)
+        require.True(t, ok)
+        assert.Equal(t, 60.0, rates.IntervalSeconds)
+        assert.Equal(t, 1e6, rates.WALBytesPerSecond)
+    }
+}
@@ -0,0 +1,85 @@
+package unit
+
+import (
+    "bytes"
+    "database/sql/driver"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+// analyticsRouter registra as rotas de analytics sobre um banco local roteirizado pelo fakeDB
+func analyticsRouter(t *testing.T) (*fakeDB, *gin.Engine) {
+    fake, db := newFakeDB(t)
+    repo := repositories.NewAnalyticsRepository(db)
+    repo.SetCatalog(repositories.NewQueryCatalog(models.ServerCapabilities{
+        ServerVersionNum: 160002,
+        Extensions:       map[string]string{"pg_stat_statements": "1.10"},
+    }))
+    service := services.NewAnalyticsService(repo, nil)
+    service.SetPlanStore(repositories.NewPlanRepository(db))
+    analytics := handlers.NewAnalyticsHandler(service)
+    history := repositories.NewHistoryRepository(db)
+    historyHandler := handlers.NewHistoryHandler(services.NewHistoryService(history))
+    regressions := handlers.NewRegressionHandler(services.NewQueryRegressionService(history, nil, nil, services.DefaultRegressionOptions(repositories.NewConfigRepository(db)), time.Minute))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.GET("/bloat", analytics.GetBloat)
+    router.GET("/indexes/advice", analytics.GetIndexAdvice)
+    router.GET("/vacuum", analytics.GetVacuumStatus)
+    router.GET("/vacuum/wraparound", analytics.GetWraparoundRisk)
+    router.GET("/replication", analytics.GetReplicationTopology)
+    router.GET("/checkpoints", analytics.GetCheckpointStats)
+    router.GET("/capabilities", analytics.GetCapabilities)
+    router.GET("/locks", analytics.GetLocks)
+    router.GET("/locks/history", historyHandler.GetLockSnapshots)
+    router.GET("/queries/statements", analytics.GetStatements)
+    router.POST("/queries/explain", analytics.Explain)
+    router.GET("/queries/regressions", regressions.GetRegressions)
+    router.GET("/history", historyHandler.ListMetrics)
+    router.GET("/history/:metric", historyHandler.GetHistory)
+    return fake, router
+}
+
+func TestAnalyticsEndpoints_ReportDataSource(t *testing.T) {
+    fake, router := analyticsRouter(t)
+    fake.on("pg_stat_bgwriter", []string{"checkpoints_timed", "wal_bytes", "collected_at"}, []driver.Value{int64(1), int64(1), time.Now()})
+    fake.on("pg_is_in_recovery() as in_recovery", []string{"in_recovery"}, []driver.Value{false})
+    fake.on("INSERT INTO slow_queries_log", []string{"id", "created_at"}, []driver.Value{"p1", time.Now()})
+    fake.on(recentPlansMatch, []string{"count"}, []driver.Value{int64(0)})
+    fake.on("SELECT current_database(), current_user", []string{"current_database", "current_user"}, []driver.Value{"orders", "monitor"})
+    fake.on("EXPLAIN", []string{"QUERY PLAN"}, []driver.Value{`[{"Plan": {"Node Type": "Result", "Total Cost": 0.01}}]`})
+
+    paths := []string{"/bloat", "/indexes/advice", "/vacuum", "/vacuum/wraparound", "/replication", "/checkpoints",
+        "/capabilities", "/locks", "/locks/history", "/queries/statements", "/queries/regressions", "/history", "/history/cache_hit_ratio"}
+    for _, path := range paths {
+        w := doRequest(router, http.MethodGet, path, "")
+        require.Equal(t, http.StatusOK, w.Code, "%s: %s", path, w.Body.String())
+        assertDataSource(t, w, path)
+    }
+
+    body, _ := json.Marshal(models.ExplainRequest{Query: "SELECT 1"})
+    req := httptest.NewRequest(http.MethodPost, "/queries/explain", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+    assertDataSource(t, w, "/queries/explain")
+}
+
+func assertDataSource(t *testing.T, w *httptest.ResponseRecorder, path string) {
+    var response models.AnalyticsResponse
+    require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), path)
+    assert.Equal(t, models.DataSourceLive, response.DataSource, path)
+}
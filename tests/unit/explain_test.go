@@ -0,0 +1,144 @@
+package unit
+
+import (
+    "database/sql/driver"
+    "errors"
+    "net/http"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+// recentPlansMatch identifica a contagem de planos usada no limite de capturas
+const recentPlansMatch = "SELECT count(*) FROM slow_queries_log"
+
+func TestExplain_LimitsPlansPerMinute(t *testing.T) {
+    fake, db := newFakeDB(t)
+    fake.on(recentPlansMatch, []string{"count"}, []driver.Value{int64(services.MaxPlansPerMinute)})
+    service := services.NewAnalyticsService(repositories.NewAnalyticsRepository(db), nil)
+    service.SetPlanStore(repositories.NewPlanRepository(db))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.POST("/queries/explain", handlers.NewAnalyticsHandler(service).Explain)
+    w := doJSONRequest(router, http.MethodPost, "/queries/explain", "", models.ExplainRequest{Query: "SELECT 1"})
+
+    assert.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+    assert.Empty(t, fake.called("EXPLAIN"), "a query não é executada")
+    assert.Empty(t, fake.called("INSERT INTO slow_queries_log"))
+}
+
+func TestNormalizeExplainQuery(t *testing.T) {
+    text, err := services.NormalizeExplainQuery("  SELECT * FROM orders WHERE id = $1 ;\n")
+    require.NoError(t, err)
+    assert.Equal(t, "SELECT * FROM orders WHERE id = $1", text)
+
+    _, err = services.NormalizeExplainQuery(" ; ")
+    assert.True(t, errors.Is(err, services.ErrInvalidAnalyticsQuery))
+
+    _, err = services.NormalizeExplainQuery("SELECT 1; DROP TABLE orders")
+    assert.True(t, errors.Is(err, services.ErrInvalidAnalyticsQuery))
+}
+
+func TestNormalizeExplainQuery_IgnoresSemicolonsInLiteralsAndComments(t *testing.T) {
+    for _, text := range []string{
+        "SELECT ';' AS sep FROM orders",
+        "SELECT 'it''s; fine'",
+        `SELECT E'a\'; b'`,
+        `SELECT "a;b" FROM orders`,
+        "SELECT 1 -- fim; DROP TABLE orders\n",
+        "SELECT /* a; /* b; */ c; */ 1",
+        "SELECT $$;$$",
+        "SELECT $body$ ; $body$ WHERE id = $1",
+    } {
+        _, err := services.NormalizeExplainQuery(text)
+        assert.NoError(t, err, text)
+    }
+
+    for _, text := range []string{
+        "SELECT ';'; DROP TABLE orders",
+        "SELECT 1 /* x */; DROP TABLE orders",
+        "SELECT * FROM orders WHERE id = $1; DROP TABLE orders",
+        `SELECT "a"";"; DROP TABLE orders`,
+    } {
+        _, err := services.NormalizeExplainQuery(text)
+        assert.True(t, errors.Is(err, services.ErrInvalidAnalyticsQuery), text)
+    }
+}
+
+func TestHasQueryParameters(t *testing.T) {
+    assert.True(t, services.HasQueryParameters("SELECT * FROM orders WHERE id = $1"))
+    assert.False(t, services.HasQueryParameters("SELECT * FROM orders WHERE total > 10"))
+}
+
+func TestIsReadOnlyStatement(t *testing.T) {
+    for _, text := range []string{
+        "SELECT 1",
+        "  with recent AS (SELECT 1) SELECT * FROM recent",
+        "(SELECT 1) UNION (SELECT 2)",
+        "-- relatório\nSELECT count(*) FROM orders",
+        "/* dashboard */ VALUES (1)",
+        "TABLE orders",
+    } {
+        assert.True(t, services.IsReadOnlyStatement(text), text)
+    }
+
+    for _, text := range []string{
+        "UPDATE orders SET total = 0",
+        "DELETE FROM orders",
+        "INSERT INTO orders VALUES (1)",
+        "/* SELECT */ TRUNCATE orders",
+        "-- SELECT",
+        "selective_function()",
+    } {
+        assert.False(t, services.IsReadOnlyStatement(text), text)
+    }
+}
+
+func TestExplainTimings(t *testing.T) {
+    planning, execution := services.ExplainTimings([]byte(`[{"Plan": {"Node Type": "Seq Scan"}, "Planning Time": 0.12, "Execution Time": 3.5}]`))
+    require.NotNil(t, planning)
+    require.NotNil(t, execution)
+    assert.InDelta(t, 0.12, *planning, 0.0001)
+    assert.InDelta(t, 3.5, *execution, 0.0001)
+
+    planning, execution = services.ExplainTimings([]byte(`[{"Plan": {"Node Type": "Seq Scan"}}]`))
+    assert.Nil(t, planning)
+    assert.Nil(t, execution)
+}
+
+func TestQueryCatalog_ExplainStatementByVersion(t *testing.T) {
+    query := "SELECT * FROM orders WHERE id = $1"
+    generic := models.ExplainOptions{GenericPlan: true, Buffers: true}
+
+    pg16 := newCatalog(160002, nil)
+    statement, err := pg16.ExplainStatement(query, generic, 1)
+    require.NoError(t, err)
+    assert.Equal(t, "EXPLAIN (FORMAT JSON, BUFFERS, GENERIC_PLAN) "+query, statement)
+    assert.False(t, pg16.PreparesGenericPlan())
+
+    pg14 := newCatalog(140010, nil)
+    statement, err = pg14.ExplainStatement(query, generic, 2)
+    require.NoError(t, err)
+    assert.Equal(t, "EXPLAIN (FORMAT JSON, BUFFERS) EXECUTE pganalytics_explain(NULL, NULL)", statement)
+    assert.True(t, pg14.PreparesGenericPlan())
+
+    // BUFFERS sem ANALYZE só a partir do PostgreSQL 13
+    pg12 := newCatalog(120015, nil)
+    statement, err = pg12.ExplainStatement("SELECT 1", models.ExplainOptions{Buffers: true}, 0)
+    require.NoError(t, err)
+    assert.Equal(t, "EXPLAIN (FORMAT JSON) SELECT 1", statement)
+
+    statement, err = pg12.ExplainStatement("SELECT 1", models.ExplainOptions{Analyze: true, Buffers: true}, 0)
+    require.NoError(t, err)
+    assert.Equal(t, "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) SELECT 1", statement)
+
+    _, err = newCatalog(110020, nil).ExplainStatement(query, generic, 1)
+    assert.True(t, errors.Is(err, repositories.ErrCollectorUnsupported))
+}
@@ -0,0 +1,187 @@
+package unit
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "fmt"
+    "io"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+
+    "github.com/jmoiron/sqlx"
+    "pganalytics-backend/internal/database"
+)
+
+// fakeDB responde consultas SQL de forma roteirizada, para testar serviços e repositórios
+// sem um PostgreSQL real. Cada comando é respondido pela regra mais recente cujo trecho
+// aparece no SQL; consultas sem regra retornam zero linhas e comandos sem regra afetam zero linhas.
+type fakeDB struct {
+    mu    sync.Mutex
+    rules []*fakeRule
+    calls []fakeCall
+}
+
+type fakeRule struct {
+    match    string
+    columns  []string
+    rows     [][]driver.Value
+    affected int64
+    err      error
+}
+
+type fakeCall struct {
+    query string
+    args  []driver.Value
+}
+
+var (
+    fakeDBs   sync.Map
+    fakeDBSeq int64
+)
+
+func init() {
+    sql.Register("fakedb", fakeDriver{})
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *database.DB) {
+    f := &fakeDB{}
+    name := fmt.Sprintf("fake-%d", atomic.AddInt64(&fakeDBSeq, 1))
+    fakeDBs.Store(name, f)
+
+    conn, err := sql.Open("fakedb", name)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() {
+        conn.Close()
+        fakeDBs.Delete(name)
+    })
+    return f, &database.DB{DB: sqlx.NewDb(conn, "postgres")}
+}
+
+// on responde consultas que contenham match com as linhas informadas
+func (f *fakeDB) on(match string, columns []string, rows ...[]driver.Value) {
+    f.add(&fakeRule{match: match, columns: columns, rows: rows})
+}
+
+// onExec define as linhas afetadas por comandos que contenham match
+func (f *fakeDB) onExec(match string, affected int64) {
+    f.add(&fakeRule{match: match, affected: affected})
+}
+
+// fail faz comandos que contenham match retornarem err
+func (f *fakeDB) fail(match string, err error) {
+    f.add(&fakeRule{match: match, err: err})
+}
+
+// called retorna as chamadas cujo SQL contém match, na ordem em que foram feitas
+func (f *fakeDB) called(match string) []fakeCall {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    calls := []fakeCall{}
+    for _, c := range f.calls {
+        if strings.Contains(c.query, match) {
+            calls = append(calls, c)
+        }
+    }
+    return calls
+}
+
+func (f *fakeDB) add(rule *fakeRule) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.rules = append([]*fakeRule{rule}, f.rules...)
+}
+
+func (f *fakeDB) handle(query string, args []driver.Value) *fakeRule {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    f.calls = append(f.calls, fakeCall{query: query, args: args})
+    for _, rule := range f.rules {
+        if strings.Contains(query, rule.match) {
+            return rule
+        }
+    }
+    return &fakeRule{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+    db, ok := fakeDBs.Load(name)
+    if !ok {
+        return nil, fmt.Errorf("fakedb %s não registrado", name)
+    }
+    return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+    db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+    return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+// CheckNamedValue aceita qualquer argumento; os que o driver padrão não converte são registrados como estão
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+    if v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value); err == nil {
+        nv.Value = v
+    }
+    return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+    db    *fakeDB
+    query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+    rule := s.db.handle(s.query, args)
+    if rule.err != nil {
+        return nil, rule.err
+    }
+    return driver.RowsAffected(rule.affected), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+    rule := s.db.handle(s.query, args)
+    if rule.err != nil {
+        return nil, rule.err
+    }
+    return &fakeRows{columns: rule.columns, rows: rule.rows}, nil
+}
+
+type fakeRows struct {
+    columns []string
+    rows    [][]driver.Value
+    next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+    if r.next >= len(r.rows) {
+        return io.EOF
+    }
+    copy(dest, r.rows[r.next])
+    r.next++
+    return nil
+}
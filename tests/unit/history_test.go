@@ -0,0 +1,58 @@
+package unit
+
+import (
+    "net/http"
+    "sort"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func historyRouter() *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    handler := handlers.NewHistoryHandler(services.NewHistoryService(repositories.NewHistoryRepository(nil)))
+    router.GET("/history/:metric", handler.GetHistory)
+    return router
+}
+
+func TestHistory_RejectsInvalidQueries(t *testing.T) {
+    router := historyRouter()
+
+    cases := map[string]string{
+        "from após to":                "/history/cache_hit_ratio?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+        "from inválido":               "/history/cache_hit_ratio?from=ontem",
+        "step inválido":               "/history/cache_hit_ratio?step=abc",
+        "step menor que 1s":           "/history/cache_hit_ratio?step=500ms",
+        "pontos demais":               "/history/cache_hit_ratio?from=2026-01-01T00:00:00Z&to=2026-01-08T00:00:00Z&step=1",
+        "métrica de tabela sem table": "/history/n_dead_tup",
+    }
+    for name, path := range cases {
+        w := doRequest(router, http.MethodGet, path, "")
+        assert.Equal(t, http.StatusBadRequest, w.Code, name)
+    }
+}
+
+func TestHistory_AcceptsEpochAndDurationParams(t *testing.T) {
+    router := historyRouter()
+
+    // Sem banco a consulta falha depois da validação, mas não com 400
+    w := doRequest(router, http.MethodGet, "/history/n_dead_tup?table=public.orders&from=1767225600&to=1767229200&step=5m", "")
+
+    assert.NotEqual(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTableMetricNames(t *testing.T) {
+    names := repositories.TableMetricNames()
+
+    assert.True(t, sort.StringsAreSorted(names))
+    assert.Contains(t, names, "n_dead_tup")
+    for _, name := range names {
+        assert.True(t, repositories.IsTableMetric(name), name)
+    }
+    assert.False(t, repositories.IsTableMetric("cache_hit_ratio"))
+}
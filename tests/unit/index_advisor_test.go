@@ -0,0 +1,109 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func btreeIndex(name, columns string, scans, size int64) models.IndexDefinition {
+    return models.IndexDefinition{
+        SchemaName:   "public",
+        TableName:    "orders",
+        IndexName:    name,
+        AccessMethod: "btree",
+        Definition:   "CREATE INDEX " + name + " ON public.orders USING btree (x)",
+        Columns:      columns,
+        OpClasses:    "3124 3124 3124",
+        IsValid:      true,
+        IdxScan:      scans,
+        SizeBytes:    size,
+    }
+}
+
+func findingFor(findings []models.IndexFinding, index string) *models.IndexFinding {
+    for i := range findings {
+        if findings[i].IndexName == index {
+            return &findings[i]
+        }
+    }
+    return nil
+}
+
+func TestAdviseIndexes_Duplicates(t *testing.T) {
+    pkey := btreeIndex("orders_pkey", "1", 500, 1000)
+    pkey.IsPrimary, pkey.IsUnique, pkey.IsConstraint = true, true, true
+    copyOfPkey := btreeIndex("orders_id_idx", "1", 10, 1000)
+    byCustomer := btreeIndex("orders_customer_idx", "2", 50, 2000)
+    byCustomerDate := btreeIndex("orders_customer_date_idx", "2 3", 80, 3000)
+    byCustomerDateStatus := btreeIndex("orders_customer_date_status_idx", "2 3 4", 5, 4000)
+
+    findings := services.AdviseIndexes(
+        []models.IndexDefinition{copyOfPkey, pkey, byCustomer, byCustomerDate, byCustomerDateStatus},
+        nil, services.DefaultIndexAdvisorOptions())
+
+    duplicate := findingFor(findings, "orders_id_idx")
+    require.NotNil(t, duplicate)
+    assert.Equal(t, models.IndexFindingDuplicate, duplicate.Type)
+    assert.Equal(t, "orders_pkey", duplicate.RelatedIndex)
+    assert.Equal(t, int64(-1000), duplicate.SizeImpactBytes)
+    assert.Equal(t, `DROP INDEX CONCURRENTLY "public"."orders_id_idx";`, duplicate.Suggestion)
+
+    prefix := findingFor(findings, "orders_customer_idx")
+    require.NotNil(t, prefix)
+    assert.Equal(t, models.IndexFindingPrefix, prefix.Type)
+    assert.Equal(t, "orders_customer_date_status_idx", prefix.RelatedIndex)
+
+    prefix = findingFor(findings, "orders_customer_date_idx")
+    require.NotNil(t, prefix)
+    assert.Equal(t, "orders_customer_date_status_idx", prefix.RelatedIndex)
+
+    assert.Nil(t, findingFor(findings, "orders_pkey"))
+    assert.Nil(t, findingFor(findings, "orders_customer_date_status_idx"))
+}
+
+func TestAdviseIndexes_PrefixRequiresSamePredicate(t *testing.T) {
+    partial := btreeIndex("orders_open_customer_idx", "2", 5, 100)
+    partial.Predicate = "(status = 'open'::text)"
+    full := btreeIndex("orders_customer_date_idx", "2 3", 5, 100)
+
+    findings := services.AdviseIndexes([]models.IndexDefinition{partial, full}, nil, services.DefaultIndexAdvisorOptions())
+
+    assert.Empty(t, findings)
+}
+
+func TestAdviseIndexes_UnusedAndInvalid(t *testing.T) {
+    unused := btreeIndex("orders_note_idx", "5", 0, 5000)
+    unusedUnique := btreeIndex("orders_code_key", "6", 0, 5000)
+    unusedUnique.IsUnique = true
+    invalid := btreeIndex("orders_total_idx", "7", 0, 7000)
+    invalid.IsValid = false
+
+    findings := services.AdviseIndexes([]models.IndexDefinition{unused, unusedUnique, invalid}, nil, services.DefaultIndexAdvisorOptions())
+
+    require.Len(t, findings, 2)
+    assert.Equal(t, models.IndexFindingInvalid, findings[0].Type)
+    assert.Contains(t, findings[0].Suggestion, `DROP INDEX CONCURRENTLY "public"."orders_total_idx";`)
+    assert.Contains(t, findings[0].Suggestion, "CREATE INDEX CONCURRENTLY orders_total_idx ON public.orders")
+    assert.Equal(t, models.IndexFindingUnused, findings[1].Type)
+    assert.Equal(t, "orders_note_idx", findings[1].IndexName)
+}
+
+func TestAdviseIndexes_MissingIndex(t *testing.T) {
+    tables := []models.TableStatSnapshot{
+        {SchemaName: "public", TableName: "events", TotalSizeBytes: 1 << 30, SeqScanCount: 1000, SeqTupRead: 50000000, IdxScanCount: 10, NLiveTup: 900000},
+        {SchemaName: "public", TableName: "small", TotalSizeBytes: 1 << 20, SeqScanCount: 100000, SeqTupRead: 100000000},
+        {SchemaName: "public", TableName: "indexed", TotalSizeBytes: 1 << 30, SeqScanCount: 1000, SeqTupRead: 50000000, IdxScanCount: 100000},
+    }
+
+    findings := services.AdviseIndexes(nil, tables, services.DefaultIndexAdvisorOptions())
+
+    require.Len(t, findings, 1)
+    assert.Equal(t, models.IndexFindingMissing, findings[0].Type)
+    assert.Equal(t, "events", findings[0].TableName)
+    assert.Equal(t, int64(900000*20*100/90), findings[0].SizeImpactBytes)
+    assert.Contains(t, findings[0].Suggestion, `CREATE INDEX CONCURRENTLY ON "public"."events"`)
+}
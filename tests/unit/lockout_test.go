@@ -0,0 +1,188 @@
+package unit
+
+import (
+    "bytes"
+    "database/sql/driver"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "golang.org/x/crypto/bcrypt"
+    "pganalytics-backend/internal/config"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/models"
+)
+
+const (
+    testPassword = "Correta#123"
+    // testRemoteAddr é o endereço de origem padrão das requisições do httptest
+    testRemoteAddr  = "192.0.2.1"
+    ipThrottleMatch = "count(*) AS count, min(created_at) AS oldest"
+)
+
+var userColumns = []string{"id", "email", "password_hash", "name", "role", "email_verified", "is_active", "last_login_at",
+    "failed_login_attempts", "account_locked_until", "created_at", "updated_at"}
+
+// storeUser faz FindByEmail e FindByID devolverem um usuário ativo com senha testPassword
+func storeUser(t *testing.T, fake *fakeDB, lockedUntil interface{}) {
+    hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+    require.NoError(t, err)
+    fake.on("FROM users WHERE", userColumns,
+        []driver.Value{testUserID, "ana@example.com", string(hash), "Ana", "viewer", true, true, nil, int64(4), lockedUntil, time.Now(), time.Now()})
+}
+
+// loginRouter monta o login como em setupRouter, confiando apenas em trustedProxies
+func loginRouter(t *testing.T, db *database.DB, trustedProxies []string) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    require.NoError(t, router.SetTrustedProxies(trustedProxies))
+    router.POST("/auth/login", handlers.NewAuthHandler(newAuthService(db)).Login)
+    return router
+}
+
+func doLogin(router *gin.Engine, password, forwardedFor string) *httptest.ResponseRecorder {
+    body, _ := json.Marshal(models.LoginRequest{Username: "ana@example.com", Password: password})
+    req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    if forwardedFor != "" {
+        req.Header.Set("X-Forwarded-For", forwardedFor)
+        req.Header.Set("X-Real-IP", forwardedFor)
+    }
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w
+}
+
+func TestLogin_LocksAccountAtMaxFailures(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on("RETURNING account_locked_until", []string{"account_locked_until"}, []driver.Value{time.Now().Add(15 * time.Minute)})
+
+    w := doLogin(loginRouter(t, db, nil), "errada", "")
+
+    assert.Equal(t, http.StatusLocked, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+    failures := fake.called("RETURNING account_locked_until")
+    require.Len(t, failures, 1)
+    assert.Equal(t, []driver.Value{testUserID, int64(5), float64(900)}, failures[0].args)
+}
+
+func TestLogin_FailureBelowLimitIsInvalidCredentials(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on("RETURNING account_locked_until", []string{"account_locked_until"}, []driver.Value{nil})
+
+    w := doLogin(loginRouter(t, db, nil), "errada", "")
+
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+    assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestLogin_LockedAccountRejectsCorrectPassword(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, time.Now().Add(10*time.Minute))
+
+    w := doLogin(loginRouter(t, db, nil), testPassword, "")
+
+    assert.Equal(t, http.StatusLocked, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+    assert.Empty(t, fake.called("UPDATE users"), "conta bloqueada não altera contador nem last_login_at")
+}
+
+func TestLogin_SuccessResetsFailureCounter(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, time.Now().Add(-time.Minute))
+    fake.onExec("last_login_at = NOW()", 1)
+    fake.on("INSERT INTO refresh_tokens", []string{"id", "family_id", "created_at"}, []driver.Value{"a1", testFamilyID, time.Now()})
+
+    w := doLogin(loginRouter(t, db, nil), testPassword, "")
+
+    require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+    resets := fake.called("failed_login_attempts = 0, account_locked_until = NULL")
+    require.Len(t, resets, 1)
+    assert.Equal(t, []driver.Value{testUserID}, resets[0].args)
+}
+
+func TestUnlock_ResetsFailureCounterAndLock(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, time.Now().Add(10*time.Minute))
+    fake.onExec("failed_login_attempts = 0, account_locked_until = NULL", 1)
+
+    require.NoError(t, newAuthService(db).Unlock(testUserID, models.AuditActor{UserID: testUserID}))
+
+    resets := fake.called("failed_login_attempts = 0, account_locked_until = NULL")
+    require.Len(t, resets, 1)
+    assert.Equal(t, []driver.Value{testUserID}, resets[0].args)
+}
+
+func TestLogin_ThrottlesIPAtMaxFailures(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on(ipThrottleMatch, []string{"count", "oldest"}, []driver.Value{int64(20), time.Now().Add(-time.Minute)})
+
+    w := doLogin(loginRouter(t, db, nil), testPassword, "")
+
+    assert.Equal(t, http.StatusTooManyRequests, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+    assert.Empty(t, fake.called("FROM users WHERE"), "IP bloqueado não consulta o usuário")
+
+    checks := fake.called(ipThrottleMatch)
+    require.Len(t, checks, 1)
+    assert.Equal(t, models.AuditActionLoginFailed, checks[0].args[0])
+    entries := fake.called("INSERT INTO audit_logs")
+    require.Len(t, entries, 1)
+    assert.Equal(t, models.AuditActionLoginThrottled, entries[0].args[1], "recusa por IP não conta como falha de login")
+}
+
+func TestLogin_IgnoresSpoofedForwardedFor(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on(ipThrottleMatch, []string{"count", "oldest"}, []driver.Value{int64(20), time.Now().Add(-time.Minute)})
+    router := loginRouter(t, db, nil)
+
+    for _, spoofed := range []string{"203.0.113.7", "203.0.113.8", "198.51.100.1, 203.0.113.9"} {
+        w := doLogin(router, testPassword, spoofed)
+        assert.Equal(t, http.StatusTooManyRequests, w.Code, spoofed)
+    }
+
+    checks := fake.called(ipThrottleMatch)
+    require.Len(t, checks, 3)
+    for _, check := range checks {
+        assert.Equal(t, testRemoteAddr, check.args[1], "o IP deve ser o da conexão, não o do cabeçalho")
+    }
+    for _, entry := range fake.called("INSERT INTO audit_logs") {
+        assert.Equal(t, testRemoteAddr, entry.args[6])
+    }
+}
+
+func TestLogin_HonorsForwardedForFromTrustedProxy(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    router := loginRouter(t, db, []string{testRemoteAddr})
+
+    doLogin(router, "errada", "203.0.113.7")
+
+    checks := fake.called(ipThrottleMatch)
+    require.Len(t, checks, 1)
+    assert.Equal(t, "203.0.113.7", checks[0].args[1])
+}
+
+func TestConfig_TrustedProxiesDefaultsToNone(t *testing.T) {
+    t.Setenv("JWT_SECRET", rbacTestSecret)
+
+    t.Setenv("TRUSTED_PROXIES", "")
+    cfg, err := config.Load()
+    require.NoError(t, err)
+    assert.Empty(t, cfg.Server.TrustedProxies)
+
+    t.Setenv("TRUSTED_PROXIES", " 10.0.0.0/8, ,192.168.1.10 ")
+    cfg, err = config.Load()
+    require.NoError(t, err)
+    assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, cfg.Server.TrustedProxies)
+}
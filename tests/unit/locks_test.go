@@ -0,0 +1,68 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func TestBuildLockTree_Chain(t *testing.T) {
+    backends := []models.LockBackend{
+        {PID: 30, BlockedBy: []int{20}, WaitDurationMs: 1500},
+        {PID: 20, BlockedBy: []int{10}, WaitDurationMs: 8000},
+        {PID: 40, BlockedBy: []int{10}, WaitDurationMs: 200},
+        {PID: 10, Query: "UPDATE accounts SET balance = 0"},
+    }
+
+    tree := services.BuildLockTree(backends)
+
+    require.Len(t, tree.Roots, 1)
+    root := tree.Roots[0]
+    assert.Equal(t, 10, root.PID)
+    assert.Equal(t, "UPDATE accounts SET balance = 0", root.Query)
+    require.Len(t, root.Blocking, 2)
+    assert.Equal(t, 20, root.Blocking[0].PID)
+    require.Len(t, root.Blocking[0].Blocking, 1)
+    assert.Equal(t, 30, root.Blocking[0].Blocking[0].PID)
+    assert.Equal(t, 3, tree.WaitingCount)
+    assert.Equal(t, 1, tree.BlockerCount)
+    assert.Equal(t, 8000.0, tree.MaxWaitMs)
+}
+
+func TestBuildLockTree_UnknownBlocker(t *testing.T) {
+    backends := []models.LockBackend{{PID: 20, BlockedBy: []int{0}, WaitDurationMs: 100}}
+
+    tree := services.BuildLockTree(backends)
+
+    require.Len(t, tree.Roots, 1)
+    assert.Equal(t, 0, tree.Roots[0].PID)
+    assert.Equal(t, "unknown", tree.Roots[0].State)
+    require.Len(t, tree.Roots[0].Blocking, 1)
+    assert.Equal(t, 20, tree.Roots[0].Blocking[0].PID)
+}
+
+func TestBuildLockTree_Cycle(t *testing.T) {
+    backends := []models.LockBackend{
+        {PID: 10, BlockedBy: []int{20}, WaitDurationMs: 100},
+        {PID: 20, BlockedBy: []int{10}, WaitDurationMs: 900},
+    }
+
+    tree := services.BuildLockTree(backends)
+
+    require.Len(t, tree.Roots, 1)
+    assert.Equal(t, 20, tree.Roots[0].PID)
+    require.Len(t, tree.Roots[0].Blocking, 1)
+    assert.Equal(t, 10, tree.Roots[0].Blocking[0].PID)
+    assert.Empty(t, tree.Roots[0].Blocking[0].Blocking)
+}
+
+func TestBuildLockTree_Empty(t *testing.T) {
+    tree := services.BuildLockTree(nil)
+
+    assert.NotNil(t, tree.Roots)
+    assert.Empty(t, tree.Roots)
+    assert.Equal(t, 0, tree.WaitingCount)
+}
@@ -0,0 +1,536 @@
+package unit
+
+import (
+    "context"
+    "database/sql/driver"
+    "encoding/json"
+    "errors"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "net/textproto"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/mailer"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/notifier"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func alertEvent(target, key, severity, state string) models.AlertEvent {
+    event := models.AlertEvent{
+        Source:    services.AlertSourceRule,
+        EventKey:  key,
+        Severity:  severity,
+        Title:     "Alerta " + key + " em " + target,
+        Message:   "connection_percent = 91.00 percent",
+        CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+    }
+    if target != "" {
+        event.TargetName = &target
+    }
+    if state != "" {
+        event.State = &state
+    }
+    return event
+}
+
+func firingNotification() notifier.Notification {
+    return notifier.NewNotification(notifier.DefaultGroupBy, []models.AlertEvent{
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring),
+    })
+}
+
+// captureServer registra as requisições recebidas e responde com os status informados, em ordem
+type captureServer struct {
+    *httptest.Server
+    mu       sync.Mutex
+    bodies   [][]byte
+    headers  []http.Header
+    statuses []int
+    calls    int32
+}
+
+func newCaptureServer(t *testing.T, statuses ...int) *captureServer {
+    s := &captureServer{statuses: statuses}
+    s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        n := atomic.AddInt32(&s.calls, 1)
+        s.mu.Lock()
+        s.bodies = append(s.bodies, body)
+        s.headers = append(s.headers, r.Header.Clone())
+        s.mu.Unlock()
+
+        status := http.StatusOK
+        if int(n) <= len(s.statuses) {
+            status = s.statuses[n-1]
+        }
+        w.WriteHeader(status)
+    }))
+    t.Cleanup(s.Close)
+    return s
+}
+
+func (s *captureServer) last() ([]byte, http.Header) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.bodies[len(s.bodies)-1], s.headers[len(s.headers)-1]
+}
+
+func TestWebhookNotifier_SignsPayloadWithHMAC(t *testing.T) {
+    server := newCaptureServer(t)
+    n := &notifier.WebhookNotifier{URL: server.URL, Secret: "s3cr3t", Headers: map[string]string{"X-Team": "dba"}}
+
+    require.NoError(t, n.Notify(context.Background(), firingNotification()))
+
+    body, headers := server.last()
+    timestamp := headers.Get(notifier.TimestampHeader)
+    require.NotEmpty(t, timestamp)
+    assert.Equal(t, notifier.Sign("s3cr3t", timestamp, body), headers.Get(notifier.SignatureHeader))
+    assert.NotEqual(t, notifier.Sign("outro", timestamp, body), headers.Get(notifier.SignatureHeader))
+    assert.Equal(t, "dba", headers.Get("X-Team"))
+    assert.Equal(t, "application/json", headers.Get("Content-Type"))
+
+    var payload notifier.WebhookPayload
+    require.NoError(t, json.Unmarshal(body, &payload))
+    assert.Equal(t, notifier.StatusFiring, payload.Status)
+    assert.Equal(t, models.AlertSeverityCritical, payload.Severity)
+    assert.Equal(t, firingNotification().DedupKey, payload.DedupKey)
+    assert.Equal(t, "[FIRING:CRITICAL] Alerta conexoes em prod", payload.Title)
+    assert.Contains(t, payload.Body, "connection_percent = 91.00 percent")
+    require.Len(t, payload.Alerts, 1)
+}
+
+func TestWebhookNotifier_UnsignedWithoutSecret(t *testing.T) {
+    server := newCaptureServer(t)
+    n := &notifier.WebhookNotifier{URL: server.URL}
+
+    require.NoError(t, n.Notify(context.Background(), firingNotification()))
+
+    _, headers := server.last()
+    assert.Empty(t, headers.Get(notifier.SignatureHeader))
+}
+
+func TestWithRetry_RetriesTemporaryFailures(t *testing.T) {
+    server := newCaptureServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+    policy := notifier.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+    n := notifier.WithRetry(&notifier.WebhookNotifier{URL: server.URL}, policy)
+
+    require.NoError(t, n.Notify(context.Background(), firingNotification()))
+    assert.Equal(t, int32(3), atomic.LoadInt32(&server.calls))
+}
+
+func TestWithRetry_StopsOnPermanentFailure(t *testing.T) {
+    server := newCaptureServer(t, http.StatusBadRequest)
+    policy := notifier.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}
+    n := notifier.WithRetry(&notifier.WebhookNotifier{URL: server.URL}, policy)
+
+    err := n.Notify(context.Background(), firingNotification())
+
+    require.Error(t, err)
+    assert.True(t, notifier.IsPermanent(err))
+    assert.Equal(t, int32(1), atomic.LoadInt32(&server.calls))
+}
+
+func TestWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+    server := newCaptureServer(t, 500, 500, 500, 500)
+    policy := notifier.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+    n := notifier.WithRetry(&notifier.WebhookNotifier{URL: server.URL}, policy)
+
+    err := n.Notify(context.Background(), firingNotification())
+
+    require.Error(t, err)
+    assert.Contains(t, err.Error(), "3 tentativa(s)")
+    assert.Equal(t, int32(3), atomic.LoadInt32(&server.calls))
+}
+
+func TestRetryPolicy_BackoffDoublesUpToMax(t *testing.T) {
+    policy := notifier.RetryPolicy{MaxAttempts: 6, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+    assert.Equal(t, time.Second, policy.Backoff(1))
+    assert.Equal(t, 2*time.Second, policy.Backoff(2))
+    assert.Equal(t, 4*time.Second, policy.Backoff(3))
+    assert.Equal(t, 5*time.Second, policy.Backoff(4))
+    assert.Equal(t, 5*time.Second, policy.Backoff(10))
+}
+
+func TestSlackNotifier_Payload(t *testing.T) {
+    server := newCaptureServer(t)
+    n := &notifier.SlackNotifier{WebhookURL: server.URL, Channel: "#dba", Username: "pgAnalytics"}
+
+    resolved := notifier.NewNotification(notifier.DefaultGroupBy, []models.AlertEvent{
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateResolved),
+    })
+    require.NoError(t, n.Notify(context.Background(), resolved))
+
+    body, _ := server.last()
+    var payload struct {
+        Text        string `json:"text"`
+        Channel     string `json:"channel"`
+        Attachments []struct {
+            Color string `json:"color"`
+            Text  string `json:"text"`
+        } `json:"attachments"`
+    }
+    require.NoError(t, json.Unmarshal(body, &payload))
+    assert.Equal(t, "*[RESOLVED] Alerta conexoes em prod*", payload.Text)
+    assert.Equal(t, "#dba", payload.Channel)
+    require.Len(t, payload.Attachments, 1)
+    assert.Equal(t, "#2eb886", payload.Attachments[0].Color)
+    assert.Contains(t, payload.Attachments[0].Text, "resolved")
+}
+
+func TestPagerDutyNotifier_TriggerAndResolveShareDedupKey(t *testing.T) {
+    server := newCaptureServer(t, http.StatusAccepted, http.StatusAccepted)
+    n := &notifier.PagerDutyNotifier{RoutingKey: "R0UT1NG", URL: server.URL}
+
+    trigger := firingNotification()
+    require.NoError(t, n.Notify(context.Background(), trigger))
+    body, _ := server.last()
+    var event notifier.PagerDutyEvent
+    require.NoError(t, json.Unmarshal(body, &event))
+    assert.Equal(t, "R0UT1NG", event.RoutingKey)
+    assert.Equal(t, "trigger", event.EventAction)
+    require.NotNil(t, event.Payload)
+    assert.Equal(t, "critical", event.Payload.Severity)
+    assert.Equal(t, "prod", event.Payload.Source)
+    assert.Equal(t, services.AlertSourceRule, event.Payload.Class)
+    assert.Equal(t, "[FIRING:CRITICAL] Alerta conexoes em prod", event.Payload.Summary)
+
+    resolve := notifier.NewNotification(notifier.DefaultGroupBy, []models.AlertEvent{
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateResolved),
+    })
+    require.NoError(t, n.Notify(context.Background(), resolve))
+    body, _ = server.last()
+    var resolved notifier.PagerDutyEvent
+    require.NoError(t, json.Unmarshal(body, &resolved))
+    assert.Equal(t, "resolve", resolved.EventAction)
+    assert.Nil(t, resolved.Payload)
+    assert.Equal(t, event.DedupKey, resolved.DedupKey)
+}
+
+// smtpStandIn é um servidor SMTP mínimo que guarda as mensagens recebidas
+type smtpStandIn struct {
+    addr   string
+    reject int
+    mu     sync.Mutex
+    rcpts  []string
+    data   []string
+}
+
+func startSMTPStandIn(t *testing.T, reject int) *smtpStandIn {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    require.NoError(t, err)
+    t.Cleanup(func() { ln.Close() })
+
+    s := &smtpStandIn{addr: ln.Addr().String(), reject: reject}
+    go func() {
+        for {
+            conn, err := ln.Accept()
+            if err != nil {
+                return
+            }
+            go s.serve(conn)
+        }
+    }()
+    return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+    defer conn.Close()
+    tp := textproto.NewConn(conn)
+    tp.PrintfLine("220 localhost ESMTP stand-in")
+    for {
+        line, err := tp.ReadLine()
+        if err != nil {
+            return
+        }
+        cmd := strings.ToUpper(line)
+        switch {
+        case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+            tp.PrintfLine("250 localhost")
+        case strings.HasPrefix(cmd, "MAIL FROM"):
+            if s.reject != 0 {
+                tp.PrintfLine("%d mailbox unavailable", s.reject)
+                continue
+            }
+            tp.PrintfLine("250 OK")
+        case strings.HasPrefix(cmd, "RCPT TO"):
+            s.mu.Lock()
+            s.rcpts = append(s.rcpts, strings.Trim(line[len("RCPT TO:"):], "<> "))
+            s.mu.Unlock()
+            tp.PrintfLine("250 OK")
+        case cmd == "DATA":
+            tp.PrintfLine("354 end with .")
+            lines, err := tp.ReadDotLines()
+            if err != nil {
+                return
+            }
+            s.mu.Lock()
+            s.data = append(s.data, strings.Join(lines, "\n"))
+            s.mu.Unlock()
+            tp.PrintfLine("250 OK")
+        case cmd == "QUIT":
+            tp.PrintfLine("221 bye")
+            return
+        default:
+            tp.PrintfLine("250 OK")
+        }
+    }
+}
+
+func (s *smtpStandIn) mailer(t *testing.T) mailer.Mailer {
+    host, port, err := net.SplitHostPort(s.addr)
+    require.NoError(t, err)
+    p, err := net.LookupPort("tcp", port)
+    require.NoError(t, err)
+    return &mailer.SMTPMailer{Host: host, Port: p, From: "pganalytics@localhost"}
+}
+
+func TestEmailNotifier_SMTPStandIn(t *testing.T) {
+    server := startSMTPStandIn(t, 0)
+    n := &notifier.EmailNotifier{Mailer: server.mailer(t), To: []string{"dba@example.com", "oncall@example.com"}}
+
+    require.NoError(t, n.Notify(context.Background(), firingNotification()))
+
+    server.mu.Lock()
+    defer server.mu.Unlock()
+    assert.Equal(t, []string{"dba@example.com", "oncall@example.com"}, server.rcpts)
+    require.Len(t, server.data, 1)
+    assert.Contains(t, server.data[0], "Subject: [FIRING:CRITICAL] Alerta conexoes em prod")
+    assert.Contains(t, server.data[0], "connection_percent = 91.00 percent")
+}
+
+func TestEmailNotifier_RejectedIsPermanent(t *testing.T) {
+    server := startSMTPStandIn(t, 550)
+    n := &notifier.EmailNotifier{Mailer: server.mailer(t), To: []string{"dba@example.com"}}
+
+    err := n.Notify(context.Background(), firingNotification())
+
+    require.Error(t, err)
+    assert.True(t, notifier.IsPermanent(err))
+}
+
+func TestTemplates_CustomAndInvalid(t *testing.T) {
+    templates, err := notifier.ParseTemplates(`{{.Status}} ({{len .Alerts}}) {{index .Labels "target"}}`, `{{range .Alerts}}{{target .}}={{value .}};{{end}}`)
+    require.NoError(t, err)
+
+    event := alertEvent("", "conexoes", models.AlertSeverityWarning, models.AlertStateFiring)
+    value := 91.234
+    event.Value = &value
+    title, body, err := templates.Render(notifier.NewNotification([]string{notifier.GroupByTarget}, []models.AlertEvent{event}))
+
+    require.NoError(t, err)
+    assert.Equal(t, "firing (1)", title)
+    assert.Equal(t, "banco local=91.23;", body)
+
+    _, err = notifier.ParseTemplates("{{.Status", "")
+    assert.Error(t, err)
+}
+
+func TestNewNotification_GroupStatusAndDedup(t *testing.T) {
+    events := []models.AlertEvent{
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring),
+        alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateFiring),
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateResolved),
+    }
+
+    n := notifier.NewNotification([]string{notifier.GroupByTarget}, events)
+
+    require.Len(t, n.Alerts, 2, "eventos do mesmo alerta são reduzidos ao mais recente")
+    assert.Equal(t, notifier.StatusFiring, n.Status)
+    assert.Equal(t, models.AlertSeverityWarning, n.Severity, "severidade considera apenas os alertas ainda disparados")
+    assert.Equal(t, "target=prod", n.GroupKey)
+
+    resolved := notifier.NewNotification([]string{notifier.GroupByTarget}, append(events,
+        alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateResolved)))
+    assert.Equal(t, notifier.StatusResolved, resolved.Status)
+    assert.Equal(t, n.DedupKey, resolved.DedupKey)
+    assert.NotEqual(t, n.Fingerprint(), resolved.Fingerprint())
+}
+
+type recordingNotifier struct {
+    mu            sync.Mutex
+    notifications []notifier.Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n notifier.Notification) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.notifications = append(r.notifications, n)
+    return nil
+}
+
+func TestDispatcher_GroupsWaitsAndDeduplicates(t *testing.T) {
+    d := notifier.NewDispatcher()
+    route := notifier.Route{
+        ID:             "canal",
+        Notifier:       &recordingNotifier{},
+        GroupBy:        []string{notifier.GroupByTarget},
+        GroupWait:      30 * time.Second,
+        RepeatInterval: time.Hour,
+        MinSeverity:    models.AlertSeverityWarning,
+    }
+    t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+    assert.False(t, d.Add(route, alertEvent("prod", "info", models.AlertSeverityInfo, models.AlertStateFiring), t0), "abaixo da severidade mínima")
+    assert.True(t, d.Add(route, alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring), t0))
+    assert.True(t, d.Add(route, alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateFiring), t0.Add(10*time.Second)))
+    assert.True(t, d.Add(route, alertEvent("staging", "cache", models.AlertSeverityWarning, models.AlertStateFiring), t0.Add(10*time.Second)))
+
+    assert.Empty(t, d.Ready(t0.Add(20*time.Second)), "group wait ainda não venceu")
+
+    ready := d.Ready(t0.Add(30 * time.Second))
+    require.Len(t, ready, 1)
+    assert.Equal(t, "target=prod", ready[0].Notification.GroupKey)
+    assert.Len(t, ready[0].Notification.Alerts, 2)
+    d.Delivered(ready[0], t0.Add(31*time.Second))
+
+    ready = d.Ready(t0.Add(40 * time.Second))
+    require.Len(t, ready, 1)
+    assert.Equal(t, "target=staging", ready[0].Notification.GroupKey)
+
+    // Mesmo conteúdo dentro do repeat interval: suprimido
+    d.Add(route, alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring), t0.Add(time.Minute))
+    d.Add(route, alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateFiring), t0.Add(time.Minute))
+    assert.Empty(t, d.Ready(t0.Add(2*time.Minute)))
+
+    // Resolução muda o conteúdo e é entregue
+    d.Add(route, alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateResolved), t0.Add(3*time.Minute))
+    d.Add(route, alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateResolved), t0.Add(3*time.Minute))
+    ready = d.Ready(t0.Add(4 * time.Minute))
+    require.Len(t, ready, 1)
+    assert.Equal(t, notifier.StatusResolved, ready[0].Notification.Status)
+
+    // Após o repeat interval o mesmo conteúdo volta a ser entregue
+    d.Add(route, alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring), t0.Add(2*time.Hour))
+    d.Add(route, alertEvent("prod", "cache", models.AlertSeverityWarning, models.AlertStateFiring), t0.Add(2*time.Hour))
+    assert.Len(t, d.Ready(t0.Add(2*time.Hour+time.Minute)), 1)
+}
+
+func TestDispatcher_ForgetDropsPendingGroups(t *testing.T) {
+    d := notifier.NewDispatcher()
+    route := notifier.Route{ID: "canal", Notifier: &recordingNotifier{}}
+    now := time.Now()
+
+    d.Add(route, alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring), now)
+    d.Forget("canal")
+
+    assert.Empty(t, d.Ready(now.Add(time.Minute)))
+}
+
+// flakyNotifier falha nas primeiras failures tentativas e registra o status de cada uma
+type flakyNotifier struct {
+    mu       sync.Mutex
+    failures int
+    attempts []string
+}
+
+func (f *flakyNotifier) Notify(ctx context.Context, n notifier.Notification) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.attempts = append(f.attempts, n.Status)
+    if len(f.attempts) <= f.failures {
+        return errors.New("503 Service Unavailable")
+    }
+    return nil
+}
+
+func TestQueue_ResolveWaitsForFiringRetry(t *testing.T) {
+    flaky := &flakyNotifier{failures: 2}
+    route := notifier.Route{ID: "canal", Notifier: notifier.WithRetry(flaky, notifier.RetryPolicy{MaxAttempts: 3, InitialBackoff: 20 * time.Millisecond})}
+
+    var mu sync.Mutex
+    delivered := []string{}
+    q := notifier.NewQueue(func(d notifier.Delivery) {
+        if err := d.Route.Notifier.Notify(context.Background(), d.Notification); err == nil {
+            mu.Lock()
+            delivered = append(delivered, d.Notification.Status)
+            mu.Unlock()
+        }
+    })
+
+    firing := firingNotification()
+    resolved := notifier.NewNotification(notifier.DefaultGroupBy, []models.AlertEvent{
+        alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateResolved),
+    })
+    require.Equal(t, firing.GroupKey, resolved.GroupKey)
+
+    // A resolução chega enquanto o disparo ainda está em backoff
+    q.Push(notifier.Delivery{Route: route, Notification: firing})
+    q.Push(notifier.Delivery{Route: route, Notification: resolved})
+
+    require.Eventually(t, func() bool {
+        mu.Lock()
+        defer mu.Unlock()
+        return len(delivered) == 2
+    }, 2*time.Second, 5*time.Millisecond)
+    assert.Equal(t, []string{notifier.StatusFiring, notifier.StatusResolved}, delivered)
+    assert.Equal(t, []string{notifier.StatusFiring, notifier.StatusFiring, notifier.StatusFiring, notifier.StatusResolved}, flaky.attempts)
+}
+
+var channelColumns = []string{"id", "name", "type", "settings", "title_template", "body_template", "group_by", "min_severity",
+    "group_wait_seconds", "repeat_interval_seconds", "max_attempts", "enabled", "created_at", "updated_at"}
+
+func TestNotificationService_RecordsFailedDeliveries(t *testing.T) {
+    server := newCaptureServer(t, http.StatusInternalServerError)
+    fake, db := newFakeDB(t)
+    fake.on("FROM notification_channels WHERE enabled", channelColumns,
+        []driver.Value{"c1", "hook", models.ChannelTypeWebhook, `{"url":"` + server.URL + `"}`, "", "",
+            "{target,source,event_key}", models.AlertSeverityInfo, int64(0), int64(0), int64(1), true, time.Now(), time.Now()})
+    fake.on("INSERT INTO notification_failures", []string{"id", "created_at"}, []driver.Value{"f1", time.Now()})
+
+    svc := services.NewNotificationService(repositories.NewNotificationChannelRepository(db), nil,
+        services.NewAuditService(repositories.NewAuditRepository(db)))
+    t.Cleanup(svc.Stop)
+
+    svc.Submit(alertEvent("prod", "conexoes", models.AlertSeverityCritical, models.AlertStateFiring))
+    svc.Flush()
+
+    require.Eventually(t, func() bool { return len(fake.called("INSERT INTO notification_failures")) == 1 }, 2*time.Second, 5*time.Millisecond)
+    failure := fake.called("INSERT INTO notification_failures")[0]
+    n := firingNotification()
+    assert.Equal(t, []driver.Value{"c1", n.GroupKey, n.DedupKey, notifier.StatusFiring}, failure.args[:4])
+    assert.Contains(t, failure.args[4], "500")
+
+    var payload notifier.Notification
+    require.NoError(t, json.Unmarshal([]byte(failure.args[5].(string)), &payload))
+    require.Len(t, payload.Alerts, 1)
+    assert.Equal(t, "conexoes", payload.Alerts[0].EventKey)
+}
+
+func TestNewChannelNotifier_ResolvesWebhookSecret(t *testing.T) {
+    t.Setenv("PGA_TEST_WEBHOOK_SECRET", "from-env")
+    server := newCaptureServer(t)
+    channel := models.NotificationChannel{
+        Name:          "hook",
+        Type:          models.ChannelTypeWebhook,
+        Settings:      models.ChannelSettings{URL: server.URL, SecretRef: "env:PGA_TEST_WEBHOOK_SECRET"},
+        TitleTemplate: "{{.Status}}",
+    }
+
+    n, err := services.NewChannelNotifier(channel, nil)
+    require.NoError(t, err)
+    require.NoError(t, n.Notify(context.Background(), firingNotification()))
+
+    body, headers := server.last()
+    assert.Equal(t, notifier.Sign("from-env", headers.Get(notifier.TimestampHeader), body), headers.Get(notifier.SignatureHeader))
+    var payload notifier.WebhookPayload
+    require.NoError(t, json.Unmarshal(body, &payload))
+    assert.Equal(t, "firing", payload.Title)
+
+    channel.Settings.SecretRef = "env:PGA_TEST_MISSING_SECRET"
+    _, err = services.NewChannelNotifier(channel, nil)
+    assert.Error(t, err)
+
+    _, err = services.NewChannelNotifier(models.NotificationChannel{Type: models.ChannelTypeEmail}, nil)
+    assert.Error(t, err, "email sem mailer configurado")
+}
@@ -0,0 +1,180 @@
+package unit
+
+import (
+    "bytes"
+    "database/sql/driver"
+    "encoding/json"
+    "log"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/mailer"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+    "pganalytics-backend/pkg/validation"
+)
+
+const (
+    resetIPMatch    = "ip_address = $2::inet"
+    resetEmailMatch = "new_values->>'email'"
+)
+
+// blockingMailer segura cada envio até release ser fechado
+type blockingMailer struct {
+    release chan struct{}
+    mu      sync.Mutex
+    sent    []mailer.Message
+}
+
+func (m *blockingMailer) Send(msg mailer.Message) error {
+    <-m.release
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.sent = append(m.sent, msg)
+    return nil
+}
+
+func newPasswordService(db *database.DB, m mailer.Mailer) *services.PasswordService {
+    return services.NewPasswordService(
+        repositories.NewUserRepository(db),
+        repositories.NewPasswordResetRepository(db),
+        repositories.NewRefreshTokenRepository(db),
+        services.NewAuditService(repositories.NewAuditRepository(db)),
+        repositories.NewConfigRepository(db),
+        m,
+        "https://app/reset-password",
+    )
+}
+
+func doForgot(service *services.PasswordService) *httptest.ResponseRecorder {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.POST("/auth/password/forgot", handlers.NewPasswordHandler(service).Forgot)
+
+    body, _ := json.Marshal(models.ForgotPasswordRequest{Email: "ana@example.com"})
+    req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w
+}
+
+func TestIsValidPassword(t *testing.T) {
+    assert.True(t, validation.IsValidPassword("Str0ngPass"))
+    assert.False(t, validation.IsValidPassword("Sh0rt"))
+    assert.False(t, validation.IsValidPassword("nouppercase1"))
+    assert.False(t, validation.IsValidPassword("NOLOWERCASE1"))
+    assert.False(t, validation.IsValidPassword("NoDigitsHere"))
+    assert.False(t, validation.IsValidPassword("A1"+strings.Repeat("a", 99)))
+}
+
+func TestFileMailer_AppendsMessages(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "mail.log")
+    m, err := mailer.New(mailer.Config{Driver: "file", FilePath: path, From: "noreply@pganalytics.local"})
+    assert.NoError(t, err)
+
+    err = m.Send(mailer.Message{To: []string{"admin@pganalytics.local"}, Subject: "Redefinição de senha", Body: "link\nabaixo"})
+    assert.NoError(t, err)
+
+    data, err := os.ReadFile(path)
+    assert.NoError(t, err)
+    assert.Contains(t, string(data), "To: admin@pganalytics.local\r\n")
+    assert.Contains(t, string(data), "Subject: =?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?=\r\n")
+    assert.Contains(t, string(data), "link\r\nabaixo")
+}
+
+func TestFileMailer_WithoutPathDoesNotLogBody(t *testing.T) {
+    var out bytes.Buffer
+    log.SetOutput(&out)
+    defer log.SetOutput(os.Stderr)
+
+    m, err := mailer.New(mailer.Config{Driver: "file"})
+    assert.NoError(t, err)
+
+    err = m.Send(mailer.Message{To: []string{"admin@pganalytics.local"}, Subject: "Redefinição de senha", Body: "https://app/reset-password?token=s3cr3t-reset-token"})
+    assert.NoError(t, err)
+
+    assert.Contains(t, out.String(), "admin@pganalytics.local")
+    assert.Contains(t, out.String(), "Redefinição de senha")
+    assert.NotContains(t, out.String(), "s3cr3t-reset-token")
+}
+
+func TestMailerNew_RejectsUnknownDriver(t *testing.T) {
+    _, err := mailer.New(mailer.Config{Driver: "carrier-pigeon"})
+    assert.Error(t, err)
+
+    _, err = mailer.New(mailer.Config{Driver: "smtp"})
+    assert.Error(t, err)
+}
+
+func TestForgot_SendsLinkInBackground(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    m := &blockingMailer{release: make(chan struct{})}
+    service := newPasswordService(db, m)
+
+    w := doForgot(service)
+    assert.Equal(t, http.StatusAccepted, w.Code, "a resposta não espera o envio do email")
+
+    close(m.release)
+    service.Stop()
+
+    require.Len(t, m.sent, 1)
+    assert.Equal(t, []string{"ana@example.com"}, m.sent[0].To)
+    assert.Len(t, fake.called("INSERT INTO password_resets"), 1)
+    assert.Empty(t, fake.called("UPDATE password_resets"), "uma nova solicitação não invalida links já enviados")
+}
+
+func TestForgot_ThrottlesIP(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on(resetIPMatch, []string{"count", "oldest"}, []driver.Value{int64(10), time.Now().Add(-time.Minute)})
+    m := &blockingMailer{release: make(chan struct{})}
+    close(m.release)
+    service := newPasswordService(db, m)
+
+    w := doForgot(service)
+    service.Stop()
+
+    assert.Equal(t, http.StatusTooManyRequests, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+    assert.Empty(t, fake.called("FROM users WHERE"))
+    assert.Empty(t, m.sent)
+
+    entries := fake.called("INSERT INTO audit_logs")
+    require.Len(t, entries, 1)
+    assert.Equal(t, models.AuditActionPasswordResetThrottled, entries[0].args[1], "recusas não contam como solicitações")
+}
+
+func TestForgot_EmailThrottleKeepsSameResponse(t *testing.T) {
+    fake, db := newFakeDB(t)
+    storeUser(t, fake, nil)
+    fake.on(resetEmailMatch, []string{"count", "oldest"}, []driver.Value{int64(3), time.Now().Add(-time.Minute)})
+    m := &blockingMailer{release: make(chan struct{})}
+    close(m.release)
+    service := newPasswordService(db, m)
+
+    w := doForgot(service)
+    service.Stop()
+
+    assert.Equal(t, http.StatusAccepted, w.Code, "o limite por email não revela se a conta existe")
+    assert.Empty(t, m.sent)
+    assert.Empty(t, fake.called("INSERT INTO password_resets"))
+
+    checks := fake.called(resetEmailMatch)
+    require.Len(t, checks, 1)
+    assert.Equal(t, models.AuditActionPasswordResetRequested, checks[0].args[0])
+    assert.Equal(t, "ana@example.com", checks[0].args[1])
+}
@@ -0,0 +1,132 @@
+package unit
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/plananalyzer"
+)
+
+const analyzedPlan = `[{
+  "Plan": {
+    "Node Type": "Sort", "Total Cost": 5000, "Plan Rows": 1000, "Plan Width": 32,
+    "Actual Total Time": 100, "Actual Rows": 1000, "Actual Loops": 1,
+    "Sort Method": "external merge", "Sort Space Used": 2048, "Sort Space Type": "Disk",
+    "Plans": [{
+      "Node Type": "Hash Join", "Parent Relationship": "Outer", "Join Type": "Inner", "Total Cost": 4000,
+      "Plan Rows": 1000, "Actual Total Time": 80, "Actual Rows": 1000, "Actual Loops": 1,
+      "Plans": [
+        {"Node Type": "Seq Scan", "Parent Relationship": "Outer", "Relation Name": "orders", "Alias": "o",
+         "Total Cost": 3000, "Plan Rows": 10, "Actual Total Time": 60, "Actual Rows": 1000, "Actual Loops": 1,
+         "Filter": "(status = 'open'::text)", "Rows Removed by Filter": 500000},
+        {"Node Type": "Hash", "Parent Relationship": "Inner", "Total Cost": 500, "Plan Rows": 100,
+         "Actual Total Time": 15, "Actual Rows": 100, "Actual Loops": 1,
+         "Hash Batches": 4, "Original Hash Batches": 1, "Peak Memory Usage": 4096,
+         "Plans": [
+           {"Node Type": "Index Scan", "Parent Relationship": "Outer", "Index Name": "idx_customers_region",
+            "Relation Name": "customers", "Alias": "c", "Total Cost": 450, "Plan Rows": 100,
+            "Actual Total Time": 14, "Actual Rows": 100, "Actual Loops": 1,
+            "Filter": "active", "Rows Removed by Filter": 20000}
+         ]}
+      ]
+    }]
+  },
+  "Planning Time": 0.5,
+  "Execution Time": 101.2
+}]`
+
+func findingKinds(report *plananalyzer.Report, nodeID int) []string {
+    kinds := []string{}
+    for _, f := range report.Findings {
+        if f.NodeID == nodeID {
+            kinds = append(kinds, f.Kind)
+        }
+    }
+    return kinds
+}
+
+func TestPlanAnalyzer_AnalyzedPlanFindings(t *testing.T) {
+    report, err := plananalyzer.AnalyzeJSON([]byte(analyzedPlan), plananalyzer.DefaultOptions())
+    require.NoError(t, err)
+
+    assert.True(t, report.Analyzed)
+    assert.Equal(t, 5, report.NodeCount)
+    require.NotNil(t, report.ExecutionTimeMs)
+    assert.InDelta(t, 101.2, *report.ExecutionTimeMs, 0.001)
+
+    // 1 Sort, 2 Hash Join, 3 Seq Scan, 4 Hash, 5 Index Scan
+    assert.ElementsMatch(t, []string{plananalyzer.FindingDiskSpill, plananalyzer.FindingExclusiveHotspot}, findingKinds(report, 1))
+    assert.Empty(t, findingKinds(report, 2))
+    assert.ElementsMatch(t, []string{plananalyzer.FindingMisestimate, plananalyzer.FindingSeqScanLarge, plananalyzer.FindingExclusiveHotspot}, findingKinds(report, 3))
+    assert.Equal(t, []string{plananalyzer.FindingDiskSpill}, findingKinds(report, 4))
+    assert.Equal(t, []string{plananalyzer.FindingFilterHeavyIndex}, findingKinds(report, 5))
+
+    // Os mais graves primeiro: o hotspot de 20% do Sort é apenas informativo
+    last := report.Findings[len(report.Findings)-1]
+    assert.Equal(t, plananalyzer.SeverityInfo, last.Severity)
+    assert.Equal(t, 1, last.NodeID)
+
+    seqScan := report.Tree.Children[0].Children[0]
+    assert.Equal(t, "Seq Scan on orders o", seqScan.Label)
+    require.NotNil(t, seqScan.ExclusiveTimeMs)
+    assert.InDelta(t, 60, *seqScan.ExclusiveTimeMs, 0.001)
+    require.NotNil(t, seqScan.EstimateFactor)
+    assert.InDelta(t, 100, *seqScan.EstimateFactor, 0.001)
+
+    hashJoin := report.Tree.Children[0]
+    require.NotNil(t, hashJoin.ExclusiveTimeMs)
+    assert.InDelta(t, 5, *hashJoin.ExclusiveTimeMs, 0.001)
+
+    assert.Contains(t, report.Text, "Execution Time: 101.200 ms")
+    assert.Contains(t, report.Text, "   -> [#2] Hash Join")
+    assert.Contains(t, report.Text, "⚠ seq_scan_large")
+    assert.Contains(t, report.Text, "Index Scan using idx_customers_region on customers c")
+}
+
+func TestPlanAnalyzer_EstimatedPlanNestedLoop(t *testing.T) {
+    plan := `[{"Plan": {
+      "Node Type": "Nested Loop", "Join Type": "Inner", "Total Cost": 90000, "Plan Rows": 50000,
+      "Plans": [
+        {"Node Type": "Seq Scan", "Parent Relationship": "Outer", "Relation Name": "items", "Total Cost": 1000, "Plan Rows": 50000},
+        {"Node Type": "Index Scan", "Parent Relationship": "Inner", "Index Name": "products_pkey", "Relation Name": "products", "Total Cost": 2, "Plan Rows": 1}
+      ]}}]`
+
+    report, err := plananalyzer.AnalyzeJSON([]byte(plan), plananalyzer.DefaultOptions())
+    require.NoError(t, err)
+
+    assert.False(t, report.Analyzed)
+    require.Len(t, report.Findings, 1)
+    assert.Equal(t, plananalyzer.FindingNestedLoopOuter, report.Findings[0].Kind)
+    assert.Equal(t, 1, report.Findings[0].NodeID)
+    assert.InDelta(t, 50000, report.Findings[0].Metrics["outer_rows"], 0.001)
+    assert.Nil(t, report.Tree.InclusiveTimeMs)
+    assert.Contains(t, report.Text, "sem ANALYZE")
+}
+
+func TestPlanAnalyzer_ParallelChildrenNotMultiplied(t *testing.T) {
+    plan := `[{"Plan": {
+      "Node Type": "Gather", "Total Cost": 100, "Plan Rows": 300, "Actual Total Time": 50, "Actual Rows": 300, "Actual Loops": 1,
+      "Plans": [
+        {"Node Type": "Seq Scan", "Parallel Aware": true, "Relation Name": "events", "Total Cost": 90, "Plan Rows": 100,
+         "Actual Total Time": 45, "Actual Rows": 100, "Actual Loops": 3}
+      ]}}]`
+
+    report, err := plananalyzer.AnalyzeJSON([]byte(plan), plananalyzer.DefaultOptions())
+    require.NoError(t, err)
+
+    child := report.Tree.Children[0]
+    assert.Equal(t, "Parallel Seq Scan on events", child.Label)
+    require.NotNil(t, child.InclusiveTimeMs)
+    assert.InDelta(t, 45, *child.InclusiveTimeMs, 0.001)
+    assert.InDelta(t, 5, *report.Tree.ExclusiveTimeMs, 0.001)
+}
+
+func TestPlanAnalyzer_InvalidPlan(t *testing.T) {
+    _, err := plananalyzer.AnalyzeJSON([]byte(`{"foo": 1}`), plananalyzer.DefaultOptions())
+    assert.True(t, errors.Is(err, plananalyzer.ErrInvalidPlan))
+
+    _, err = plananalyzer.AnalyzeJSON([]byte(`not json`), plananalyzer.DefaultOptions())
+    assert.True(t, errors.Is(err, plananalyzer.ErrInvalidPlan))
+}
@@ -0,0 +1,157 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/plananalyzer"
+)
+
+const planBeforeRegression = `[{"Plan": {
+  "Node Type": "Hash Join", "Join Type": "Inner", "Total Cost": 1200, "Plan Rows": 500,
+  "Actual Total Time": 12, "Actual Rows": 480, "Actual Loops": 1,
+  "Plans": [
+    {"Node Type": "Index Scan", "Index Name": "orders_created_at_idx", "Relation Name": "orders", "Alias": "o",
+     "Total Cost": 800, "Plan Rows": 500, "Actual Total Time": 8, "Actual Rows": 480, "Actual Loops": 1},
+    {"Node Type": "Hash", "Total Cost": 300, "Plan Rows": 1000, "Actual Total Time": 3, "Actual Rows": 1000, "Actual Loops": 1,
+     "Plans": [
+       {"Node Type": "Seq Scan", "Relation Name": "customers", "Alias": "c", "Total Cost": 250, "Plan Rows": 1000,
+        "Actual Total Time": 2, "Actual Rows": 1000, "Actual Loops": 1}
+     ]}
+  ]},
+  "Execution Time": 12.5}]`
+
+const planAfterRegression = `[{"Plan": {
+  "Node Type": "Nested Loop", "Join Type": "Inner", "Total Cost": 900, "Plan Rows": 5,
+  "Actual Total Time": 950, "Actual Rows": 48000, "Actual Loops": 1,
+  "Plans": [
+    {"Node Type": "Seq Scan", "Relation Name": "customers", "Alias": "c", "Total Cost": 250, "Plan Rows": 1000,
+     "Actual Total Time": 2, "Actual Rows": 1000, "Actual Loops": 1},
+    {"Node Type": "Index Scan", "Index Name": "orders_customer_id_idx", "Relation Name": "orders", "Alias": "o",
+     "Total Cost": 0.6, "Plan Rows": 1, "Actual Total Time": 0.9, "Actual Rows": 48, "Actual Loops": 1000}
+  ]},
+  "Execution Time": 951}]`
+
+func TestPlanDiff_DetectsRegressionChanges(t *testing.T) {
+    before, err := plananalyzer.Parse([]byte(planBeforeRegression))
+    require.NoError(t, err)
+    after, err := plananalyzer.Parse([]byte(planAfterRegression))
+    require.NoError(t, err)
+
+    diff := plananalyzer.Diff(before, after, 10)
+
+    assert.False(t, diff.Identical)
+    assert.Equal(t, 4, diff.Before.NodeCount)
+    assert.Equal(t, 3, diff.After.NodeCount)
+    require.NotNil(t, diff.CostRatio)
+    assert.InDelta(t, 0.75, *diff.CostRatio, 0.001)
+
+    // O scan de customers perde o Hash em volta; o de orders troca apenas de índice (AccessChanges)
+    assert.ElementsMatch(t, []plananalyzer.NodeChange{
+        {Kind: plananalyzer.ChangeNodeType, Path: "1", Before: "Hash Join", After: "Nested Loop"},
+        {Kind: plananalyzer.ChangeNodeRemoved, Path: "1.2", Before: "Hash"},
+    }, diff.NodeChanges)
+
+    assert.True(t, diff.JoinOrder.Changed)
+    assert.Equal(t, []string{"o", "c"}, diff.JoinOrder.Before)
+    assert.Equal(t, []string{"c", "o"}, diff.JoinOrder.After)
+
+    require.Len(t, diff.AccessChanges, 1)
+    assert.Equal(t, "o", diff.AccessChanges[0].Relation)
+    assert.Equal(t, "orders_created_at_idx", diff.AccessChanges[0].IndexBefore)
+    assert.Equal(t, "orders_customer_id_idx", diff.AccessChanges[0].IndexAfter)
+
+    require.Len(t, diff.EstimateDrifts, 2)
+    orders := diff.EstimateDrifts[0]
+    assert.Equal(t, "o", orders.Relation)
+    require.NotNil(t, orders.AfterFactor)
+    assert.InDelta(t, 48, *orders.AfterFactor, 0.001)
+    assert.True(t, orders.Worsened)
+    assert.False(t, diff.EstimateDrifts[1].Worsened)
+}
+
+func TestPlanDiff_IdenticalPlans(t *testing.T) {
+    before, err := plananalyzer.Parse([]byte(planBeforeRegression))
+    require.NoError(t, err)
+    after, err := plananalyzer.Parse([]byte(planBeforeRegression))
+    require.NoError(t, err)
+
+    diff := plananalyzer.Diff(before, after, 10)
+
+    assert.True(t, diff.Identical)
+    assert.Empty(t, diff.NodeChanges)
+    assert.Empty(t, diff.AccessChanges)
+    assert.False(t, diff.JoinOrder.Changed)
+}
+
+// planWithGather é planBeforeRegression com o scan de orders sob um Gather
+const planWithGather = `[{"Plan": {
+  "Node Type": "Hash Join", "Join Type": "Inner", "Total Cost": 1100, "Plan Rows": 500,
+  "Plans": [
+    {"Node Type": "Gather", "Total Cost": 700, "Plan Rows": 500, "Workers Planned": 2,
+     "Plans": [
+       {"Node Type": "Index Scan", "Index Name": "orders_created_at_idx", "Relation Name": "orders", "Alias": "o",
+        "Total Cost": 650, "Plan Rows": 208, "Parallel Aware": true}
+     ]},
+    {"Node Type": "Hash", "Total Cost": 300, "Plan Rows": 1000,
+     "Plans": [
+       {"Node Type": "Seq Scan", "Relation Name": "customers", "Alias": "c", "Total Cost": 250, "Plan Rows": 1000}
+     ]}
+  ]}}]`
+
+func TestPlanDiff_InsertedWrapperDoesNotShiftSiblings(t *testing.T) {
+    before, err := plananalyzer.Parse([]byte(planBeforeRegression))
+    require.NoError(t, err)
+    after, err := plananalyzer.Parse([]byte(planWithGather))
+    require.NoError(t, err)
+
+    diff := plananalyzer.Diff(before, after, 10)
+    assert.Equal(t, []plananalyzer.NodeChange{{Kind: plananalyzer.ChangeNodeAdded, Path: "1.1", After: "Gather"}}, diff.NodeChanges)
+    assert.False(t, diff.JoinOrder.Changed)
+    require.Len(t, diff.AccessChanges, 1, "só o scan paralelo de orders muda de acesso")
+    assert.Equal(t, "o", diff.AccessChanges[0].Relation)
+
+    // No sentido inverso o mesmo Gather aparece como removido
+    reverse := plananalyzer.Diff(after, before, 10)
+    assert.Equal(t, []plananalyzer.NodeChange{{Kind: plananalyzer.ChangeNodeRemoved, Path: "1.1", Before: "Gather"}}, reverse.NodeChanges)
+}
+
+// planWithRootGather é planBeforeRegression sob Gather Merge e Sort, como num plano paralelo ordenado
+const planWithRootGather = `[{"Plan": {
+  "Node Type": "Gather Merge", "Total Cost": 1300, "Plan Rows": 500, "Workers Planned": 2,
+  "Plans": [
+    {"Node Type": "Sort", "Total Cost": 1250, "Plan Rows": 500,
+     "Plans": [
+       {"Node Type": "Hash Join", "Join Type": "Inner", "Total Cost": 1200, "Plan Rows": 500,
+        "Plans": [
+          {"Node Type": "Index Scan", "Index Name": "orders_created_at_idx", "Relation Name": "orders", "Alias": "o",
+           "Total Cost": 800, "Plan Rows": 500},
+          {"Node Type": "Hash", "Total Cost": 300, "Plan Rows": 1000,
+           "Plans": [
+             {"Node Type": "Seq Scan", "Relation Name": "customers", "Alias": "c", "Total Cost": 250, "Plan Rows": 1000}
+           ]}
+        ]}
+     ]}
+  ]}}]`
+
+func TestPlanDiff_RootWrapperDoesNotShiftPlan(t *testing.T) {
+    before, err := plananalyzer.Parse([]byte(planBeforeRegression))
+    require.NoError(t, err)
+    after, err := plananalyzer.Parse([]byte(planWithRootGather))
+    require.NoError(t, err)
+
+    diff := plananalyzer.Diff(before, after, 10)
+    assert.Equal(t, []plananalyzer.NodeChange{
+        {Kind: plananalyzer.ChangeNodeAdded, Path: "1", After: "Gather Merge"},
+        {Kind: plananalyzer.ChangeNodeAdded, Path: "1.1", After: "Sort"},
+    }, diff.NodeChanges)
+    assert.Empty(t, diff.AccessChanges)
+    assert.False(t, diff.JoinOrder.Changed)
+
+    reverse := plananalyzer.Diff(after, before, 10)
+    assert.Equal(t, []plananalyzer.NodeChange{
+        {Kind: plananalyzer.ChangeNodeRemoved, Path: "1", Before: "Gather Merge"},
+        {Kind: plananalyzer.ChangeNodeRemoved, Path: "1.1", Before: "Sort"},
+    }, reverse.NodeChanges)
+}
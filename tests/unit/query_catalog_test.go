@@ -0,0 +1,149 @@
+package unit
+
+import (
+    "database/sql/driver"
+    "errors"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/database"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func newCatalog(version int, extensions map[string]string) *repositories.QueryCatalog {
+    return repositories.NewQueryCatalog(models.ServerCapabilities{ServerVersionNum: version, Extensions: extensions})
+}
+
+func TestQueryCatalog_SlowQueriesVariantByExtensionVersion(t *testing.T) {
+    legacy := newCatalog(120010, map[string]string{"pg_stat_statements": "1.7"})
+    query, err := legacy.SlowQueriesQuery()
+    require.NoError(t, err)
+    assert.Contains(t, query, "mean_time")
+    assert.NotContains(t, query, "mean_exec_time")
+
+    // 1.10 deve ser comparado numericamente, não como texto
+    current := newCatalog(160002, map[string]string{"pg_stat_statements": "1.10"})
+    query, err = current.SlowQueriesQuery()
+    require.NoError(t, err)
+    assert.Contains(t, query, "mean_exec_time")
+    assert.Equal(t, "mean_exec_time", current.Status(models.CollectorSlowQueries).Variant)
+}
+
+func TestQueryCatalog_UnsupportedCollectors(t *testing.T) {
+    catalog := newCatalog(90500, map[string]string{"pgstattuple": "1.2"})
+
+    _, err := catalog.SlowQueriesQuery()
+    require.Error(t, err)
+    assert.True(t, errors.Is(err, repositories.ErrCollectorUnsupported))
+
+    var unsupported *repositories.UnsupportedCollectorError
+    require.True(t, errors.As(err, &unsupported))
+    assert.Equal(t, models.CollectorSlowQueries, unsupported.Collector)
+
+    _, err = catalog.CheckpointStatsQuery()
+    assert.True(t, errors.Is(err, repositories.ErrCollectorUnsupported))
+
+    statuses := map[string]models.CollectorStatus{}
+    for _, status := range catalog.Collectors() {
+        statuses[status.Name] = status
+    }
+    assert.False(t, statuses[models.CollectorLocks].Supported)
+    assert.False(t, statuses[models.CollectorReplication].Supported)
+    assert.False(t, statuses[models.CollectorBloatMeasure].Supported)
+    assert.NotEmpty(t, statuses[models.CollectorBloatMeasure].Reason)
+}
+
+func TestQueryCatalog_CheckpointVariants(t *testing.T) {
+    cases := map[int]string{
+        130011: "pg_stat_bgwriter",
+        150004: "pg_stat_wal",
+        170000: "pg_stat_checkpointer",
+    }
+    for version, variant := range cases {
+        catalog := newCatalog(version, nil)
+        assert.Equal(t, variant, catalog.Status(models.CollectorCheckpoints).Variant, "versão %d", version)
+
+        query, err := catalog.CheckpointStatsQuery()
+        require.NoError(t, err)
+        assert.Equal(t, repositories.CheckpointStatsQuery(version), query)
+    }
+}
+
+var targetColumns = []string{"id", "name", "dsn", "credentials_ref", "labels", "collection_interval_seconds", "enabled", "created_at", "updated_at"}
+
+// serverVersion faz a detecção de capacidades do fakeDB encontrar a versão informada
+func serverVersion(fake *fakeDB, version int) {
+    fake.on("current_setting('server_version_num')", []string{"server_version_num", "server_version"},
+        []driver.Value{int64(version), strconv.Itoa(version / 10000)})
+}
+
+func versionProbes(fake *fakeDB) int {
+    return len(fake.called("current_setting('server_version_num')"))
+}
+
+func TestQueryCatalog_ExpiresAfterTTL(t *testing.T) {
+    fresh := repositories.NewQueryCatalog(models.ServerCapabilities{ServerVersionNum: 160002, DetectedAt: time.Now()})
+    stale := repositories.NewQueryCatalog(models.ServerCapabilities{ServerVersionNum: 160002, DetectedAt: time.Now().Add(-repositories.CatalogTTL - time.Second)})
+
+    assert.False(t, fresh.Expired())
+    assert.True(t, stale.Expired())
+    assert.False(t, newCatalog(160002, nil).Expired(), "capacidades fixas não expiram")
+}
+
+func TestAnalyticsRepositoryCatalog_RedetectsExpiredCapabilities(t *testing.T) {
+    fake, db := newFakeDB(t)
+    serverVersion(fake, 170000)
+    repo := repositories.NewAnalyticsRepository(db)
+    repo.SetCatalog(repositories.NewQueryCatalog(models.ServerCapabilities{ServerVersionNum: 160002, DetectedAt: time.Now().Add(-time.Hour)}))
+
+    catalog, err := repo.Catalog()
+    require.NoError(t, err)
+    assert.Equal(t, 170000, catalog.Capabilities().ServerVersionNum)
+
+    _, err = repo.Catalog()
+    require.NoError(t, err)
+    assert.Equal(t, 1, versionProbes(fake), "catálogo recém-detectado é reaproveitado")
+}
+
+func TestTargetServiceCatalog_ClearedOnUpdateAndDelete(t *testing.T) {
+    registry, registryDB := newFakeDB(t)
+    registry.on("FROM monitored_targets WHERE", targetColumns,
+        []driver.Value{"t1", "orders", "postgres://monitor@db.example:5432/orders", "", []byte("{}"), int64(60), false, time.Now(), time.Now()})
+    registry.on("RETURNING updated_at", []string{"updated_at"}, []driver.Value{time.Now()})
+    registry.onExec("DELETE FROM monitored_targets", 1)
+    targets := services.NewTargetService(repositories.NewTargetRepository(registryDB), database.NewTargetPool(), nil,
+        services.NewAuditService(repositories.NewAuditRepository(registryDB)), services.SnapshotConfig{})
+
+    server, serverDB := newFakeDB(t)
+    serverVersion(server, 160002)
+    target, err := targets.Get("orders")
+    require.NoError(t, err)
+    attach := func() *repositories.QueryCatalog {
+        repo := repositories.NewAnalyticsRepository(serverDB)
+        targets.AttachCatalog(target, repo)
+        catalog, err := repo.Catalog()
+        require.NoError(t, err)
+        return catalog
+    }
+
+    attach()
+    attach()
+    assert.Equal(t, 1, versionProbes(server), "catálogo reaproveitado entre requisições")
+
+    // Após a alteração o DSN pode apontar para outro servidor
+    serverVersion(server, 170000)
+    disabled := false
+    _, err = targets.Update("orders", models.TargetRequest{Name: "orders", DSN: "postgres://monitor@db2.example:5432/orders", Enabled: &disabled}, models.AuditActor{})
+    require.NoError(t, err)
+    assert.Equal(t, 170000, attach().Capabilities().ServerVersionNum)
+    assert.Equal(t, 2, versionProbes(server))
+
+    require.NoError(t, targets.Delete("orders", models.AuditActor{}))
+    attach()
+    assert.Equal(t, 3, versionProbes(server), "catálogo descartado com o target")
+}
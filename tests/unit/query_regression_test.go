@@ -0,0 +1,94 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func regressionOptions() models.RegressionOptions {
+    return models.RegressionOptions{Factor: 2, DominantPercent: 20, MinCalls: 10}
+}
+
+func TestDetectQueryRegressions_MeanRegression(t *testing.T) {
+    windows := []models.QueryWindowStats{
+        // média 10ms -> 30ms
+        {QueryHash: "slow", DatabaseName: "app", BaselineCalls: 1000, BaselineTimeMs: 10000, BaselineSamples: 100,
+            RecentCalls: 100, RecentTimeMs: 3000, RecentSamples: 10},
+        // média estável
+        {QueryHash: "stable", DatabaseName: "app", BaselineCalls: 1000, BaselineTimeMs: 5000, BaselineSamples: 100,
+            RecentCalls: 100, RecentTimeMs: 600, RecentSamples: 10},
+    }
+
+    regressions := services.DetectQueryRegressions(windows, regressionOptions())
+
+    require.Len(t, regressions, 1)
+    r := regressions[0]
+    assert.Equal(t, "slow", r.QueryHash)
+    assert.Equal(t, models.RegressionKindLatency, r.Kind)
+    assert.Equal(t, models.AlertSeverityWarning, r.Severity)
+    require.NotNil(t, r.BaselineMeanMs)
+    assert.InDelta(t, 10, *r.BaselineMeanMs, 0.001)
+    assert.InDelta(t, 30, r.RecentMeanMs, 0.001)
+    require.NotNil(t, r.MeanRatio)
+    assert.InDelta(t, 3, *r.MeanRatio, 0.001)
+    assert.Nil(t, r.P95Ratio)
+}
+
+func TestDetectQueryRegressions_P95RegressionAndSeverity(t *testing.T) {
+    windows := []models.QueryWindowStats{
+        // média quase estável, mas o p95 quintuplicou
+        {QueryHash: "tail", BaselineCalls: 500, BaselineTimeMs: 5000, BaselineP95Ms: float64Ptr(12),
+            RecentCalls: 50, RecentTimeMs: 650, RecentP95Ms: float64Ptr(60)},
+    }
+
+    regressions := services.DetectQueryRegressions(windows, regressionOptions())
+
+    require.Len(t, regressions, 1)
+    assert.Equal(t, models.AlertSeverityCritical, regressions[0].Severity)
+    require.NotNil(t, regressions[0].P95Ratio)
+    assert.InDelta(t, 5, *regressions[0].P95Ratio, 0.001)
+}
+
+func TestDetectQueryRegressions_RequiresMinCalls(t *testing.T) {
+    windows := []models.QueryWindowStats{
+        {QueryHash: "rare", BaselineCalls: 5, BaselineTimeMs: 50, RecentCalls: 100, RecentTimeMs: 10000},
+        {QueryHash: "quiet", BaselineCalls: 100, BaselineTimeMs: 1000, RecentCalls: 3, RecentTimeMs: 300},
+    }
+
+    assert.Empty(t, services.DetectQueryRegressions(windows, regressionOptions()))
+}
+
+func TestDetectQueryRegressions_NewDominantQuery(t *testing.T) {
+    windows := []models.QueryWindowStats{
+        {QueryHash: "new-heavy", RecentCalls: 2, RecentTimeMs: 6000},
+        {QueryHash: "new-light", RecentCalls: 50, RecentTimeMs: 500},
+        {QueryHash: "old", BaselineCalls: 1000, BaselineTimeMs: 35000, RecentCalls: 100, RecentTimeMs: 3500},
+    }
+
+    regressions := services.DetectQueryRegressions(windows, regressionOptions())
+
+    require.Len(t, regressions, 1)
+    r := regressions[0]
+    assert.Equal(t, "new-heavy", r.QueryHash)
+    assert.Equal(t, models.RegressionKindNewDominant, r.Kind)
+    assert.InDelta(t, 60, r.TimePercent, 0.001)
+    assert.Equal(t, models.AlertSeverityCritical, r.Severity)
+    assert.Nil(t, r.BaselineMeanMs)
+}
+
+func TestDetectQueryRegressions_SortedByRecentTime(t *testing.T) {
+    windows := []models.QueryWindowStats{
+        {QueryHash: "a", BaselineCalls: 100, BaselineTimeMs: 100, RecentCalls: 100, RecentTimeMs: 300},
+        {QueryHash: "b", BaselineCalls: 100, BaselineTimeMs: 100, RecentCalls: 100, RecentTimeMs: 900},
+    }
+
+    regressions := services.DetectQueryRegressions(windows, regressionOptions())
+
+    require.Len(t, regressions, 2)
+    assert.Equal(t, "b", regressions[0].QueryHash)
+    assert.Equal(t, "a", regressions[1].QueryHash)
+}
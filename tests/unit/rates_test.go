@@ -0,0 +1,98 @@
+package unit
+
+import (
+    "database/sql/driver"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func TestComputePerformanceRates_Interval(t *testing.T) {
+    start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    prev := &models.PerformanceStats{XactCommit: 1000, XactRollback: 10, TuplesInserted: 500, Deadlocks: 1, CollectedAt: start}
+    curr := &models.PerformanceStats{XactCommit: 1600, XactRollback: 20, TuplesInserted: 800, Deadlocks: 3, CollectedAt: start.Add(60 * time.Second)}
+
+    rates := services.ComputePerformanceRates(prev, curr)
+
+    assert.NotNil(t, rates)
+    assert.False(t, rates.CounterReset)
+    assert.Equal(t, 60.0, rates.IntervalSeconds)
+    assert.InDelta(t, 10.0, rates.CommitsPerSecond, 0.001)
+    assert.InDelta(t, 610.0/60, rates.TransactionsPerSecond, 0.001)
+    assert.InDelta(t, 5.0, rates.InsertsPerSecond, 0.001)
+    assert.Equal(t, int64(2), rates.DeadlocksDelta)
+}
+
+func TestComputePerformanceRates_StatsReset(t *testing.T) {
+    start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    oldReset := start.Add(-24 * time.Hour)
+    newReset := start.Add(30 * time.Second)
+    prev := &models.PerformanceStats{XactCommit: 1000, StatsReset: &oldReset, CollectedAt: start}
+    curr := &models.PerformanceStats{XactCommit: 300, StatsReset: &newReset, CollectedAt: start.Add(60 * time.Second)}
+
+    rates := services.ComputePerformanceRates(prev, curr)
+
+    assert.NotNil(t, rates)
+    assert.True(t, rates.CounterReset)
+    assert.Equal(t, 30.0, rates.IntervalSeconds)
+    assert.InDelta(t, 10.0, rates.CommitsPerSecond, 0.001)
+}
+
+func TestComputePerformanceRates_CounterWentBackwards(t *testing.T) {
+    start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    prev := &models.PerformanceStats{XactCommit: 1000, CollectedAt: start}
+    curr := &models.PerformanceStats{XactCommit: 10, CollectedAt: start.Add(60 * time.Second)}
+
+    rates := services.ComputePerformanceRates(prev, curr)
+
+    assert.True(t, rates.CounterReset)
+    assert.Equal(t, 0.0, rates.TransactionsPerSecond)
+}
+
+func TestRateTracker_FirstSampleHasNoRates(t *testing.T) {
+    tracker := services.NewRateTracker()
+
+    assert.Nil(t, tracker.Observe(&models.PerformanceStats{XactCommit: 1}))
+}
+
+// performanceSample faz a consulta de pg_stat_database do fakeDB devolver xact_commit informado
+func performanceSample(fake *fakeDB, commits int64, collectedAt time.Time) {
+    fake.on("tup_returned as tuples_returned", []string{"cache_hit_ratio", "xact_commit", "collected_at"},
+        []driver.Value{99.0, commits, collectedAt})
+}
+
+func TestGetPerformanceStats_OnlyReadsCollectorRates(t *testing.T) {
+    fake, db := newFakeDB(t)
+    repo := repositories.NewAnalyticsRepository(db)
+    collector := services.NewSnapshotService(repo, repositories.NewSnapshotRepository(db), services.SnapshotConfig{})
+    service := services.NewAnalyticsService(repo, nil)
+    service.SetLocalCollector(collector)
+
+    start := time.Now().Add(-time.Hour)
+    performanceSample(fake, 1000, start)
+    for i := 0; i < 3; i++ {
+        response, err := service.GetPerformanceStats("")
+        require.NoError(t, err)
+        assert.Nil(t, response.Data.(map[string]interface{})["rates"], "GET não registra amostras")
+    }
+
+    collector.CollectOnce()
+    performanceSample(fake, 7000, start.Add(time.Minute))
+    collector.CollectOnce()
+
+    // Dois painéis consultando em sequência veem as mesmas taxas
+    performanceSample(fake, 9000, start.Add(61*time.Second))
+    for i := 0; i < 2; i++ {
+        response, err := service.GetPerformanceStats("")
+        require.NoError(t, err)
+        rates, ok := response.Data.(map[string]interface{})["rates"].(*models.PerformanceRates)
+        require.True(t, ok)
+        assert.Equal(t, 60.0, rates.IntervalSeconds)
+        assert.InDelta(t, 100, rates.CommitsPerSecond, 0.001)
+    }
+}
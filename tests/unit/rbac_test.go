@@ -0,0 +1,234 @@
+package unit
+
+import (
+    "bytes"
+    "database/sql/driver"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/handlers"
+    "pganalytics-backend/internal/middleware"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+const rbacTestSecret = "rbac-test-secret"
+
+// signedToken assina um access token de testUserID. O papel vem do UserFinder do router.
+func signedToken(t *testing.T) string {
+    claims := models.Claims{
+        UserID: testUserID,
+        Email:  "user@pganalytics.com",
+        RegisteredClaims: jwt.RegisteredClaims{
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+        },
+    }
+    token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(rbacTestSecret))
+    assert.NoError(t, err)
+    return token
+}
+
+// staticUsers é um UserFinder que sempre devolve user, ou err quando definido
+type staticUsers struct {
+    user  *models.User
+    err   error
+    calls int
+}
+
+func (s *staticUsers) FindByID(id string) (*models.User, error) {
+    s.calls++
+    if s.err != nil {
+        return nil, s.err
+    }
+    return s.user, nil
+}
+
+// usersWithRole devolve testUserID ativo com o papel informado
+func usersWithRole(role string) *staticUsers {
+    return &staticUsers{user: &models.User{ID: testUserID, Email: "ana@example.com", Role: role, IsActive: true}}
+}
+
+func rbacRouter(users middleware.UserFinder) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(middleware.AuthMiddleware(rbacTestSecret, users))
+    router.GET("/whoami", func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{
+            "user_id": c.GetString("user_id"),
+            "email":   c.GetString("email"),
+            "role":    c.GetString("role"),
+        })
+    })
+    router.DELETE("/targets/:id", middleware.RequirePermission(middleware.PermissionManageTargets), func(c *gin.Context) {
+        c.Status(http.StatusNoContent)
+    })
+    return router
+}
+
+func doRequest(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+    req := httptest.NewRequest(method, path, nil)
+    if token != "" {
+        req.Header.Set("Authorization", "Bearer "+token)
+    }
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w
+}
+
+func doJSONRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+    data, _ := json.Marshal(body)
+    req := httptest.NewRequest(method, path, bytes.NewReader(data))
+    req.Header.Set("Content-Type", "application/json")
+    if token != "" {
+        req.Header.Set("Authorization", "Bearer "+token)
+    }
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w
+}
+
+func TestAuthMiddleware_PopulatesContext(t *testing.T) {
+    w := doRequest(rbacRouter(usersWithRole(models.RoleReadonly)), http.MethodGet, "/whoami", signedToken(t))
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Email e papel vêm do cadastro, não das claims do token
+    assert.JSONEq(t, `{"user_id":"5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23","email":"ana@example.com","role":"readonly"}`, w.Body.String())
+}
+
+func TestAuthMiddleware_RejectsRemovedOrDisabledUser(t *testing.T) {
+    w := doRequest(rbacRouter(&staticUsers{err: repositories.ErrUserNotFound}), http.MethodGet, "/whoami", signedToken(t))
+    assert.Equal(t, http.StatusUnauthorized, w.Code, "usuário removido")
+
+    disabled := usersWithRole(models.RoleAdmin)
+    disabled.user.IsActive = false
+    w = doRequest(rbacRouter(disabled), http.MethodGet, "/whoami", signedToken(t))
+    assert.Equal(t, http.StatusUnauthorized, w.Code, "usuário desativado")
+}
+
+func TestAuthMiddleware_RoleChangeAppliesToIssuedTokens(t *testing.T) {
+    users := usersWithRole(models.RoleAdmin)
+    router := rbacRouter(users)
+    token := signedToken(t)
+
+    assert.Equal(t, http.StatusNoContent, doRequest(router, http.MethodDelete, "/targets/prod", token).Code)
+
+    users.user = &models.User{ID: testUserID, Role: models.RoleReadonly, IsActive: true}
+    assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodDelete, "/targets/prod", token).Code)
+}
+
+func TestUserCache_ReusesUserWithinTTL(t *testing.T) {
+    users := usersWithRole(models.RoleAdmin)
+    cache := middleware.NewUserCache(users, time.Minute)
+
+    for i := 0; i < 3; i++ {
+        user, err := cache.FindByID(testUserID)
+        require.NoError(t, err)
+        assert.Equal(t, models.RoleAdmin, user.Role)
+    }
+    assert.Equal(t, 1, users.calls)
+
+    expired := middleware.NewUserCache(users, 0)
+    expired.FindByID(testUserID)
+    expired.FindByID(testUserID)
+    assert.Equal(t, 3, users.calls, "entradas expiradas são recarregadas")
+
+    missing := &staticUsers{err: repositories.ErrUserNotFound}
+    cache = middleware.NewUserCache(missing, time.Minute)
+    cache.FindByID(testUserID)
+    cache.FindByID(testUserID)
+    assert.Equal(t, 2, missing.calls, "usuário inexistente não é guardado")
+}
+
+func TestAuthMiddleware_RejectsExpiredToken(t *testing.T) {
+    claims := models.Claims{
+        UserID: "5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23",
+        RegisteredClaims: jwt.RegisteredClaims{
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+        },
+    }
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(rbacTestSecret))
+
+    w := doRequest(rbacRouter(usersWithRole(models.RoleAdmin)), http.MethodGet, "/whoami", token)
+
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequirePermission_ByRole(t *testing.T) {
+    cases := map[string]int{
+        models.RoleAdmin:    http.StatusNoContent,
+        models.RoleUser:     http.StatusForbidden,
+        models.RoleReadonly: http.StatusForbidden,
+    }
+    for role, expected := range cases {
+        w := doRequest(rbacRouter(usersWithRole(role)), http.MethodDelete, "/targets/prod", signedToken(t))
+        assert.Equal(t, expected, w.Code, role)
+    }
+}
+
+func meRouter(t *testing.T) (*fakeDB, *gin.Engine) {
+    fake, db := newFakeDB(t)
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.GET("/auth/me", middleware.AuthMiddleware(rbacTestSecret, usersWithRole(models.RoleUser)), handlers.NewAuthHandler(newAuthService(db)).Me)
+    return fake, router
+}
+
+func TestAuthMe_ReturnsCurrentProfileFromDatabase(t *testing.T) {
+    fake, router := meRouter(t)
+    storeUser(t, fake, nil)
+
+    w := doRequest(router, http.MethodGet, "/auth/me", signedToken(t))
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Email e papel vêm do banco, não das claims do token
+    assert.JSONEq(t, `{"user_id":"5b0c2f9e-8a55-4c1e-9d8e-0f7b4a6c1d23","email":"ana@example.com","role":"viewer","message":"Profile data"}`, w.Body.String())
+    lookups := fake.called("FROM users WHERE id::text = $1")
+    require.Len(t, lookups, 1)
+    assert.Equal(t, []driver.Value{testUserID}, lookups[0].args)
+}
+
+func TestAuthMe_RejectsMissingOrInactiveUser(t *testing.T) {
+    fake, router := meRouter(t)
+    w := doRequest(router, http.MethodGet, "/auth/me", signedToken(t))
+    assert.Equal(t, http.StatusUnauthorized, w.Code, "usuário removido")
+
+    fake, router = meRouter(t)
+    fake.on("FROM users WHERE", userColumns,
+        []driver.Value{testUserID, "ana@example.com", "", "Ana", "viewer", true, false, nil, int64(0), nil, time.Now(), time.Now()})
+    w = doRequest(router, http.MethodGet, "/auth/me", signedToken(t))
+    assert.Equal(t, http.StatusUnauthorized, w.Code, "usuário desativado")
+}
+
+func TestExplain_RequiresExplainPermission(t *testing.T) {
+    service := services.NewAnalyticsService(repositories.NewAnalyticsRepository(nil), nil)
+    explainAs := func(role string, req models.ExplainRequest) *httptest.ResponseRecorder {
+        gin.SetMode(gin.TestMode)
+        router := gin.New()
+        router.POST("/queries/explain", middleware.AuthMiddleware(rbacTestSecret, usersWithRole(role)),
+            middleware.RequirePermission(middleware.PermissionExplainQueries), handlers.NewAnalyticsHandler(service).Explain)
+        return doJSONRequest(router, http.MethodPost, "/queries/explain", signedToken(t), req)
+    }
+
+    // Toda captura grava um plano; com ANALYZE a query também é executada no banco monitorado
+    plan := models.ExplainRequest{Query: "SELECT * FROM orders"}
+    analyze := models.ExplainRequest{Query: "SELECT * FROM orders", Analyze: true}
+    for _, role := range []string{models.RoleReadonly, models.RoleUser} {
+        assert.Equal(t, http.StatusForbidden, explainAs(role, plan).Code, role)
+        assert.Equal(t, http.StatusForbidden, explainAs(role, analyze).Code, role)
+    }
+
+    // Sem banco o admin falha depois da autorização
+    assert.Equal(t, http.StatusServiceUnavailable, explainAs(models.RoleAdmin, plan).Code)
+    assert.Equal(t, http.StatusServiceUnavailable, explainAs(models.RoleAdmin, analyze).Code)
+
+    assert.True(t, middleware.HasPermission(models.RoleAdmin, middleware.PermissionExplainQueries))
+    assert.False(t, middleware.HasPermission(models.RoleReadonly, middleware.PermissionExplainQueries))
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/services"
+)
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestAssessReplication_PrimaryHealthy(t *testing.T) {
+    topology := &models.ReplicationTopology{
+        Role: "primary",
+        Node: &models.ReplicationNode{},
+        Standbys: []models.ReplicationStandby{
+            {ApplicationName: "replica1", State: "streaming", ReplayLagMs: float64Ptr(120)},
+        },
+        Slots: []models.ReplicationSlot{{SlotName: "replica1", Active: true, RetainedWALBytes: 1024}},
+    }
+
+    services.AssessReplication(topology, services.DefaultReplicationThresholds())
+
+    assert.Equal(t, models.ReplicationHealthy, topology.Health)
+    assert.Empty(t, topology.Issues)
+    assert.Equal(t, models.ReplicationHealthy, topology.Standbys[0].Health)
+}
+
+func TestAssessReplication_LaggingStandbyAndInactiveSlot(t *testing.T) {
+    topology := &models.ReplicationTopology{
+        Role: "primary",
+        Node: &models.ReplicationNode{},
+        Standbys: []models.ReplicationStandby{
+            {ApplicationName: "replica1", State: "streaming", ReplayLagMs: float64Ptr(45000)},
+            {ApplicationName: "replica2", State: "catchup"},
+        },
+        Slots: []models.ReplicationSlot{{SlotName: "old_slot", Active: false, RetainedWALBytes: 4096}},
+    }
+
+    services.AssessReplication(topology, services.DefaultReplicationThresholds())
+
+    assert.Equal(t, models.ReplicationWarning, topology.Standbys[0].Health)
+    assert.Equal(t, models.ReplicationCritical, topology.Standbys[1].Health)
+    assert.Equal(t, models.ReplicationCritical, topology.Health)
+    assert.Len(t, topology.Issues, 3)
+}
+
+func TestAssessReplication_Standby(t *testing.T) {
+    idle := &models.ReplicationTopology{
+        Role:        "standby",
+        Node:        &models.ReplicationNode{InRecovery: true, ReplayDelaySeconds: float64Ptr(3600), ReplayLagBytes: int64Ptr(0)},
+        WalReceiver: &models.WalReceiver{Status: "streaming"},
+    }
+    services.AssessReplication(idle, services.DefaultReplicationThresholds())
+    assert.Equal(t, models.ReplicationHealthy, idle.Health)
+
+    behind := &models.ReplicationTopology{
+        Role:        "standby",
+        Node:        &models.ReplicationNode{InRecovery: true, ReplayDelaySeconds: float64Ptr(60), ReplayLagBytes: int64Ptr(8192)},
+        WalReceiver: &models.WalReceiver{Status: "streaming"},
+    }
+    services.AssessReplication(behind, services.DefaultReplicationThresholds())
+    assert.Equal(t, models.ReplicationWarning, behind.Health)
+
+    disconnected := &models.ReplicationTopology{Role: "standby", Node: &models.ReplicationNode{InRecovery: true}}
+    services.AssessReplication(disconnected, services.DefaultReplicationThresholds())
+    assert.Equal(t, models.ReplicationCritical, disconnected.Health)
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func TestStatementCursor_RoundTrip(t *testing.T) {
+    last := models.StatementStats{SortValue: "1234.567", QueryID: -8812345678901234, DatabaseID: 16384, UserID: 10, TopLevel: true}
+
+    cursor, err := services.DecodeStatementCursor(services.EncodeStatementCursor(last))
+
+    require.NoError(t, err)
+    assert.Equal(t, "1234.567", cursor.SortValue)
+    assert.Equal(t, int64(-8812345678901234), cursor.QueryID)
+    assert.Equal(t, int64(16384), cursor.DatabaseID)
+    assert.Equal(t, int64(10), cursor.UserID)
+    assert.True(t, cursor.TopLevel)
+
+    _, err = services.DecodeStatementCursor("not-a-cursor")
+    assert.Error(t, err)
+    _, err = services.DecodeStatementCursor(services.EncodeStatementCursor(models.StatementStats{SortValue: "1; DROP TABLE x"}))
+    assert.Error(t, err)
+}
+
+func TestStatementsQuery_FiltersAndKeyset(t *testing.T) {
+    catalog := newCatalog(160002, map[string]string{"pg_stat_statements": "1.10"})
+    queryID := int64(42)
+
+    query, args, err := catalog.StatementsQuery(models.StatementQuery{
+        SortBy:   models.StatementSortTempBlks,
+        Database: "app",
+        User:     "api",
+        QueryID:  &queryID,
+        Search:   "orders",
+        Limit:    51,
+        Cursor:   &models.StatementCursor{SortValue: "100", QueryID: 7, DatabaseID: 1, UserID: 2, TopLevel: true},
+    })
+
+    require.NoError(t, err)
+    assert.Contains(t, query, "s.total_exec_time as total_time_ms")
+    assert.Contains(t, query, "s.wal_bytes::bigint as wal_bytes")
+    assert.Contains(t, query, "temp_blks_read + temp_blks_written")
+    assert.Contains(t, query, "d.datname = $1")
+    assert.Contains(t, query, "r.rolname = $2")
+    assert.Contains(t, query, "s.queryid = $3")
+    assert.Contains(t, query, "lower($4)")
+    assert.Contains(t, query, ") < ($5::numeric, $6, $7, $8, $9)")
+    assert.Contains(t, query, "LIMIT $10")
+    assert.Equal(t, []interface{}{"app", "api", int64(42), "orders", "100", int64(7), int64(1), int64(2), true, 51}, args)
+}
+
+func TestStatementsQuery_LegacyExtension(t *testing.T) {
+    catalog := newCatalog(120010, map[string]string{"pg_stat_statements": "1.7"})
+
+    query, _, err := catalog.StatementsQuery(models.StatementQuery{SortBy: models.StatementSortMeanTime, Ascending: true, Limit: 10})
+    require.NoError(t, err)
+    assert.Contains(t, query, "s.mean_time as mean_time_ms")
+    assert.Contains(t, query, "NULL::bigint as wal_bytes")
+    assert.Contains(t, query, "ASC")
+
+    _, _, err = catalog.StatementsQuery(models.StatementQuery{SortBy: models.StatementSortWALBytes, Limit: 10})
+    assert.True(t, errors.Is(err, repositories.ErrCollectorUnsupported))
+
+    detail, err := catalog.StatementQuery()
+    require.NoError(t, err)
+    assert.Contains(t, detail, "s.query as query")
+    assert.Contains(t, detail, "s.queryid = $1")
+}
+
+func TestGetStatements_InvalidParameters(t *testing.T) {
+    service := services.NewAnalyticsService(nil, nil)
+
+    _, err := service.GetStatements("", models.StatementQuery{SortBy: "query", Limit: 10}, "")
+    assert.ErrorIs(t, err, services.ErrInvalidAnalyticsQuery)
+
+    _, err = service.GetStatements("", models.StatementQuery{Limit: services.MaxStatementLimit + 1}, "")
+    assert.ErrorIs(t, err, services.ErrInvalidAnalyticsQuery)
+
+    _, err = service.GetStatements("", models.StatementQuery{Limit: 10}, "%%%")
+    assert.ErrorIs(t, err, services.ErrInvalidAnalyticsQuery)
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "pganalytics-backend/internal/models"
+    "pganalytics-backend/internal/repositories"
+    "pganalytics-backend/internal/services"
+)
+
+func TestNewWraparoundRisk(t *testing.T) {
+    risk := services.NewWraparoundRisk("table", "app", "public", "events", 150000000, 200000000)
+
+    assert.Equal(t, int64(50000000), risk.RemainingXIDs)
+    assert.Equal(t, 75.0, risk.PercentToFreeze)
+    assert.InDelta(t, 6.98, risk.PercentWraparound, 0.01)
+}
+
+func TestRankWraparoundRisk(t *testing.T) {
+    risks := []models.WraparoundRisk{
+        services.NewWraparoundRisk("table", "app", "public", "young", 1000, 200000000),
+        // Limite reduzido por reloption deixa a tabela mais próxima do freeze mesmo com idade menor
+        services.NewWraparoundRisk("table", "app", "public", "tuned", 90000000, 100000000),
+        services.NewWraparoundRisk("table", "app", "public", "old", 180000000, 200000000),
+        services.NewWraparoundRisk("table", "app", "public", "overdue", 250000000, 200000000),
+    }
+
+    services.RankWraparoundRisk(risks)
+
+    assert.Equal(t, "overdue", risks[0].TableName)
+    assert.Equal(t, int64(-50000000), risks[0].RemainingXIDs)
+    assert.Equal(t, "tuned", risks[1].TableName)
+    assert.Equal(t, "old", risks[2].TableName)
+    assert.Equal(t, "young", risks[3].TableName)
+}
+
+func TestRankVacuumStatus_OverdueFirst(t *testing.T) {
+    tables := []models.TableVacuumStatus{
+        {TableName: "big_dead", NDeadTup: 900000, Overdue: false},
+        {TableName: "small_overdue", NDeadTup: 1000, Overdue: true},
+        {TableName: "large_overdue", NDeadTup: 50000, Overdue: true},
+    }
+
+    services.RankVacuumStatus(tables)
+
+    assert.Equal(t, "large_overdue", tables[0].TableName)
+    assert.Equal(t, "small_overdue", tables[1].TableName)
+    assert.Equal(t, "big_dead", tables[2].TableName)
+}
+
+func TestGetTableVacuumStatus_SkipsPartitionedParents(t *testing.T) {
+    fake, db := newFakeDB(t)
+
+    _, err := repositories.NewAnalyticsRepository(db).GetTableVacuumStatus()
+    require.NoError(t, err)
+
+    queries := fake.called("pg_stat_user_tables")
+    require.Len(t, queries, 1)
+    assert.Contains(t, queries[0].query, "c.relkind IN ('r', 'm', 't')")
+}